@@ -0,0 +1,215 @@
+package notionapi
+
+import (
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// Link describes a link from a block to a page
+type Link struct {
+	// FromPageID is id of the page with the block that links
+	FromPageID string
+	// FromBlockID is id of the block that links (can be the page itself
+	// e.g. when its title mentions a page)
+	FromBlockID string
+	// ToPageID is id of the linked page
+	ToPageID string
+}
+
+// returns true if uri is a link to notion.so or a relative link,
+// like the ones Notion creates for links to pages
+func isNotionURL(uri string) bool {
+	if strings.HasPrefix(uri, "/") {
+		return true
+	}
+	u, err := url.Parse(uri)
+	if err != nil {
+		return false
+	}
+	host := strings.ToLower(u.Host)
+	return host == "notion.so" || strings.HasSuffix(host, ".notion.so") || strings.HasSuffix(host, ".notion.site")
+}
+
+// returns ids of pages a block links to: in mentions, links to notion.so
+// and links to pages (alias blocks)
+func blockLinkedPageIDs(block *Block) []string {
+	var res []string
+	add := func(id string) {
+		if id = ToDashID(id); id != "" {
+			res = append(res, id)
+		}
+	}
+	for _, ts := range block.InlineContent {
+		for _, attr := range ts.Attrs {
+			switch AttrGetType(attr) {
+			case AttrPage:
+				add(AttrGetPageID(attr))
+			case AttrLink:
+				if uri := AttrGetLink(attr); isNotionURL(uri) {
+					add(ExtractNoDashIDFromNotionURL(uri))
+				}
+			}
+		}
+	}
+	switch block.Type {
+	case BlockLinkToPage:
+		id, _ := block.PropAsString("format.alias_pointer.id")
+		add(id)
+	case BlockBookmark:
+		if isNotionURL(block.Source) {
+			add(ExtractNoDashIDFromNotionURL(block.Source))
+		}
+	}
+	return res
+}
+
+// PageLinks returns links from blocks of a page to other pages.
+// Sub-pages are not links
+func PageLinks(page *Page) []*Link {
+	pageID := ToDashID(page.ID)
+	var res []*Link
+	page.ForEachBlock(func(block *Block) {
+		for _, id := range blockLinkedPageIDs(block) {
+			if id == pageID {
+				continue
+			}
+			link := &Link{
+				FromPageID:  pageID,
+				FromBlockID: block.ID,
+				ToPageID:    id,
+			}
+			res = append(res, link)
+		}
+	})
+	return res
+}
+
+// LinkGraph is a graph of links between pages, built from downloaded
+// pages e.g. to render "Referenced by" sections
+type LinkGraph struct {
+	// maps id of a page (in dash format) to links from it
+	idToLinks map[string][]*Link
+	// maps id of a page (in dash format) to links to it
+	idToBacklinks map[string][]*Link
+}
+
+// NewLinkGraph returns a LinkGraph of links between pages
+func NewLinkGraph(pages []*Page) *LinkGraph {
+	g := &LinkGraph{
+		idToLinks:     map[string][]*Link{},
+		idToBacklinks: map[string][]*Link{},
+	}
+	for _, page := range pages {
+		g.AddPage(page)
+	}
+	return g
+}
+
+// AddPage adds links from a page to the graph
+func (g *LinkGraph) AddPage(page *Page) {
+	pageID := ToDashID(page.ID)
+	if _, ok := g.idToLinks[pageID]; ok {
+		return
+	}
+	links := PageLinks(page)
+	g.idToLinks[pageID] = links
+	for _, link := range links {
+		g.idToBacklinks[link.ToPageID] = append(g.idToBacklinks[link.ToPageID], link)
+	}
+}
+
+// Links returns links from a page with a given id
+func (g *LinkGraph) Links(pageID string) []*Link {
+	return g.idToLinks[ToDashID(pageID)]
+}
+
+// Backlinks returns links to a page with a given id
+func (g *LinkGraph) Backlinks(pageID string) []*Link {
+	return g.idToBacklinks[ToDashID(pageID)]
+}
+
+// ReferencedBy returns ids of pages that link to a page with a given id,
+// sorted and without duplicates
+func (g *LinkGraph) ReferencedBy(pageID string) []string {
+	seen := map[string]bool{}
+	var res []string
+	for _, link := range g.Backlinks(pageID) {
+		if !seen[link.FromPageID] {
+			seen[link.FromPageID] = true
+			res = append(res, link.FromPageID)
+		}
+	}
+	sort.Strings(res)
+	return res
+}
+
+type getBacklinksRequest struct {
+	Block struct {
+		ID string `json:"id"`
+	} `json:"block"`
+}
+
+type getBacklinksResponse struct {
+	Backlinks []struct {
+		BlockID       string `json:"block_id"`
+		MentionedFrom struct {
+			Type    string `json:"type"`
+			BlockID string `json:"block_id"`
+		} `json:"mentioned_from"`
+	} `json:"backlinks"`
+	RecordMap *RecordMap `json:"recordMap"`
+}
+
+// returns id of the page a block is on, using blocks in recordMap
+func pageIDOfBlock(recordMap *RecordMap, blockID string) string {
+	id := blockID
+	// protect against cycles
+	for i := 0; i < 64; i++ {
+		r := recordMap.Blocks[id]
+		if r == nil || r.Block == nil {
+			return ""
+		}
+		b := r.Block
+		if isPageBlock(b) {
+			return b.ID
+		}
+		if b.ParentTable != TableBlock {
+			return ""
+		}
+		id = b.ParentID
+	}
+	return ""
+}
+
+// GetBacklinks returns links to a page with a given id from other pages,
+// as known by Notion
+func (c *Client) GetBacklinks(pageID string) ([]*Link, error) {
+	var req getBacklinksRequest
+	req.Block.ID = ToDashID(pageID)
+	var rsp getBacklinksResponse
+	apiURL := "/api/v3/getBacklinksForBlock"
+	if _, err := doNotionAPI(c, apiURL, req, &rsp); err != nil {
+		return nil, err
+	}
+	if rsp.RecordMap != nil {
+		if err := ParseRecordMap(rsp.RecordMap); err != nil {
+			return nil, err
+		}
+	}
+	var res []*Link
+	for _, bl := range rsp.Backlinks {
+		link := &Link{
+			FromBlockID: bl.MentionedFrom.BlockID,
+			ToPageID:    ToDashID(bl.BlockID),
+		}
+		if link.ToPageID == "" {
+			link.ToPageID = req.Block.ID
+		}
+		if rsp.RecordMap != nil {
+			link.FromPageID = pageIDOfBlock(rsp.RecordMap, link.FromBlockID)
+		}
+		res = append(res, link)
+	}
+	return res, nil
+}
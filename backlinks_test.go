@@ -0,0 +1,41 @@
+package notionapi
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLinkGraph(t *testing.T) {
+	idA := "6682351e-44bb-4f9c-a0e1-49b703265bdb"
+	idB := "94167af6-5670-4327-9811-dc923edd1f04"
+	idC := "ea07db1b-9bff-415a-b180-b0525f3898f6"
+	newPage := func(id string, content ...*Block) *Page {
+		root := &Block{ID: id, Type: BlockPage, Content: content}
+		return &Page{ID: id, idToBlock: map[string]*Block{id: root}}
+	}
+	mention := &Block{ID: "b1", Type: BlockText, InlineContent: []*TextSpan{
+		{Text: "‣", Attrs: []TextAttr{{AttrPage, idB}}},
+		{Text: "C", Attrs: []TextAttr{{AttrLink, "https://www.notion.so/Page-C-ea07db1b9bff415ab180b0525f3898f6"}}},
+		{Text: "other", Attrs: []TextAttr{{AttrLink, "https://example.com/ea07db1b9bff415ab180b0525f3898f6"}}},
+	}}
+	alias := &Block{ID: "b2", Type: BlockLinkToPage, RawJSON: map[string]interface{}{
+		"format": map[string]interface{}{"alias_pointer": map[string]interface{}{"id": idB}},
+	}}
+	pageA := newPage(idA, mention, alias)
+	pageC := newPage(idC, &Block{ID: "b3", Type: BlockText, InlineContent: []*TextSpan{
+		{Text: "‣", Attrs: []TextAttr{{AttrPage, idB}}},
+	}})
+
+	links := PageLinks(pageA)
+	assert.Equal(t, 3, len(links))
+	assert.Equal(t, &Link{FromPageID: idA, FromBlockID: "b1", ToPageID: idB}, links[0])
+	assert.Equal(t, idC, links[1].ToPageID)
+	assert.Equal(t, "b2", links[2].FromBlockID)
+
+	g := NewLinkGraph([]*Page{pageA, pageC})
+	assert.Equal(t, 3, len(g.Backlinks(idB)))
+	assert.Equal(t, []string{idA, idC}, g.ReferencedBy("94167af6567043279811dc923edd1f04"))
+	assert.Equal(t, []string{idA}, g.ReferencedBy(idC))
+	assert.Equal(t, 0, len(g.ReferencedBy(idA)))
+}
@@ -37,12 +37,17 @@ const (
 	BlockDivider = "divider"
 	// BlockDrive is embedded Google Drive file
 	BlockDrive = "drive"
+	// BlockDropbox is embedded Dropbox file
+	BlockDropbox = "dropbox"
 	// BlockEmbed is a generic oembed link
 	BlockEmbed = "embed"
 	// BlockEquation is TeX equation block
 	BlockEquation = "equation"
 	// BlockFactory represents a factory block
 	BlockFactory = "factory"
+	// BlockTemplateButton is a template button. Notion calls it "factory"
+	// and its children are the template that is copied when it's clicked
+	BlockTemplateButton = BlockFactory
 	// BlockFigma represents figma embed
 	BlockFigma = "figma"
 	// BlockFile is an embedded file
@@ -53,6 +58,9 @@ const (
 	BlockHeader = "header"
 	// BlockImage is an image block
 	BlockImage = "image"
+	// BlockLinkToPage is a link to another page. Id of the page is
+	// in format.alias_pointer.id
+	BlockLinkToPage = "alias"
 	// BlockMaps is embedded Google Map block
 	BlockMaps = "maps"
 	// BlockNumberedList is a numbered list block
@@ -96,7 +104,7 @@ type FormatBulletedList struct {
 // FormatCallout describes format for BlockCallout
 type FormatCallout struct {
 	BlockColor string `json:"block_color"`
-	Icon       string `json:"bookmark_icon"`
+	Icon       string `json:"page_icon"`
 }
 
 // FormatCode describes format for BlockCode
@@ -259,6 +267,7 @@ const (
 	// value of Permission.Type
 	PermissionUser   = "user_permission"
 	PermissionPublic = "public_permission"
+	PermissionSpace  = "space_permission"
 )
 
 // Permission represents user permissions o
@@ -309,6 +318,8 @@ type Block struct {
 	// not always available
 	Permissions *[]Permission          `json:"permissions,omitempty"`
 	Properties  map[string]interface{} `json:"properties,omitempty"`
+	// ID of the space (workspace) this block belongs to
+	SpaceID string `json:"space_id,omitempty"`
 	// type of the block e.g. TypeText, TypePage etc.
 	Type string `json:"type"`
 	// blocks are versioned
@@ -614,6 +625,39 @@ func (b *Block) FormatColumn() *FormatColumn {
 	return &format
 }
 
+func (b *Block) FormatFigma() *FormatFigma {
+	var format FormatFigma
+	if ok := b.unmarshalFormat(BlockFigma, &format); !ok {
+		return nil
+	}
+	return &format
+}
+
+func (b *Block) FormatCodepen() *FormatCodepen {
+	var format FormatCodepen
+	if ok := b.unmarshalFormat(BlockCodepen, &format); !ok {
+		return nil
+	}
+	return &format
+}
+
+// FormatDrive returns decoded format property for BlockDrive
+func (b *Block) FormatDrive() *FormatDrive {
+	var format FormatDrive
+	if ok := b.unmarshalFormat(BlockDrive, &format); !ok {
+		return nil
+	}
+	return &format
+}
+
+func (b *Block) FormatMaps() *FormatMaps {
+	var format FormatMaps
+	if ok := b.unmarshalFormat(BlockMaps, &format); !ok {
+		return nil
+	}
+	return &format
+}
+
 func (b *Block) FormatText() *FormatText {
 	var format FormatText
 	if ok := b.unmarshalFormat(BlockText, &format); !ok {
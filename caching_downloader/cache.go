@@ -4,6 +4,7 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"sync"
 
@@ -104,3 +105,69 @@ func NewDirectoryCache(dir string) (*DirectoryCache, error) {
 		Dir: dir,
 	}, nil
 }
+
+var _ Cache = &MemoryCache{}
+
+// MemoryCache implements in-memory Cache interface. Useful when there's
+// no file system e.g. when running in the browser (GOOS=js)
+type MemoryCache struct {
+	files map[string][]byte
+	mu    sync.Mutex
+}
+
+// NewMemoryCache returns a new, empty MemoryCache
+func NewMemoryCache() *MemoryCache {
+	return &MemoryCache{
+		files: map[string][]byte{},
+	}
+}
+
+// ReadFile reads a file with a given name from cache
+func (c *MemoryCache) ReadFile(name string) ([]byte, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	d, ok := c.files[name]
+	if !ok {
+		return nil, os.ErrNotExist
+	}
+	return d, nil
+}
+
+// WriteFile writes a file with a given name to cache
+func (c *MemoryCache) WriteFile(name string, data []byte) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.files[name] = append([]byte(nil), data...)
+	return nil
+}
+
+// Remove removes a file with a given name from cache
+func (c *MemoryCache) Remove(name string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.files, name)
+}
+
+// GetPageIDs returns ids of pages in the cache
+func (c *MemoryCache) GetPageIDs() ([]string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var ids []string
+	for name := range c.files {
+		// ${pageID}.txt, same as DirectoryCache
+		parts := strings.Split(name, ".")
+		if len(parts) != 2 || parts[1] != "txt" {
+			continue
+		}
+		id := notionapi.ToNoDashID(parts[0])
+		if notionapi.IsValidNoDashID(id) {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	return ids, nil
+}
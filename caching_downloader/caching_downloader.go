@@ -3,6 +3,8 @@ package caching_downloader
 import (
 	"crypto/sha1"
 	"fmt"
+	"mime"
+	"net/http"
 	"path/filepath"
 	"sort"
 	"strings"
@@ -39,6 +41,15 @@ type EventDidReadFromCache struct {
 	Duration time.Duration
 }
 
+// EventDidSkipFile is for logging. Emitted when a file is
+// not downloaded because of MaxFileSize, MaxTotalFileSize
+// or AllowedContentTypes limits
+type EventDidSkipFile struct {
+	FileURL string
+	// why the file was skipped
+	Reason string
+}
+
 // EventGotVersions is for logging. Emitted
 type EventGotVersions struct {
 	Count    int
@@ -80,6 +91,43 @@ type Downloader struct {
 	DownloadedFilesCount int
 	// number of files we got from cache
 	FilesFromCacheCount int
+	// total size of files we downloaded or got from cache
+	FilesTotalSize int64
+	// files that were skipped due to limits
+	SkippedFiles []*EventDidSkipFile
+
+	// PageCacheMetrics and AssetCacheMetrics describe how effective
+	// the cache of pages and files is
+	PageCacheMetrics  CacheMetrics
+	AssetCacheMetrics CacheMetrics
+	// Metrics, if set, receives the same counters as PageCacheMetrics
+	// and AssetCacheMetrics, as they change
+	Metrics Metrics
+
+	// MaxFileSize, if > 0, is the maximum size of a single file
+	MaxFileSize int64
+	// MaxTotalFileSize, if > 0, is the maximum size of all files
+	// returned by DownloadFile
+	MaxTotalFileSize int64
+	// AllowedContentTypes, if not empty, limits downloaded files
+	// to those content types (see notionapi.Client.AllowedContentTypes)
+	AllowedContentTypes []string
+
+	// MaxDepth, if > 0, is the maximum nesting of sub-pages
+	// DownloadPagesRecursively will follow
+	MaxDepth int
+	// FollowRowPages, if true, makes DownloadPagesRecursively also
+	// download pages of rows of collections (databases) on pages
+	FollowRowPages bool
+
+	// MaxAPICalls, if > 0, is a budget of API calls not served from
+	// the cache (page downloads, version checks, file downloads). When
+	// it's used up, DownloadPage and DownloadFile return ErrBudgetExceeded.
+	// The budget is checked before a page is downloaded so downloading
+	// a page that needs several API calls can go over it
+	MaxAPICalls int
+	// APICalls is the number of API calls made so far
+	APICalls int
 
 	EventObserver func(interface{})
 
@@ -88,6 +136,30 @@ type Downloader struct {
 	didMakeHTTPRequests bool
 }
 
+// ErrBudgetExceeded is returned when Downloader.MaxAPICalls is used up
+type ErrBudgetExceeded struct {
+	MaxAPICalls int
+}
+
+// Error return error string
+func (e *ErrBudgetExceeded) Error() string {
+	return fmt.Sprintf("budget of %d API calls exceeded", e.MaxAPICalls)
+}
+
+// IsErrBudgetExceeded returns true if err is an instance of ErrBudgetExceeded
+func IsErrBudgetExceeded(err error) bool {
+	_, ok := err.(*ErrBudgetExceeded)
+	return ok
+}
+
+// checkBudget returns an error if we can't make more API calls
+func (d *Downloader) checkBudget() error {
+	if d.MaxAPICalls > 0 && d.APICalls >= d.MaxAPICalls {
+		return &ErrBudgetExceeded{MaxAPICalls: d.MaxAPICalls}
+	}
+	return nil
+}
+
 // New returns a new Downloader which caches page loads on disk
 // and can return pages from that cache
 func New(cache Cache, client *notionapi.Client) *Downloader {
@@ -124,7 +196,11 @@ func (d *Downloader) GetClientCopy() *notionapi.Client {
 func (d *Downloader) getVersionsForPages(ids []string) ([]int64, error) {
 	// using new client because we don't want caching of http requests here
 	normalizeIDS(ids)
+	if err := d.checkBudget(); err != nil {
+		return nil, err
+	}
 	c := d.GetClientCopy()
+	d.APICalls++
 	recVals, err := c.GetBlockRecords(ids)
 	if err != nil {
 		return nil, err
@@ -210,9 +286,11 @@ func (d *Downloader) ReadPageFromCache(pageID string) (*notionapi.Page, error) {
 		// it's ok if file doesn't exit
 		return nil, nil
 	}
+	d.addPageMetric(MetricBytesRead, int64(len(data)))
 	httpCache, err := deserializeHTTPCache(data)
 	if err != nil {
 		d.Cache.Remove(name)
+		d.addPageMetric(MetricEvictions, 1)
 		return nil, err
 	}
 	httpCache.CompareNormalizedJSONBody = true
@@ -235,6 +313,8 @@ func (d *Downloader) ReadPageFromCache(pageID string) (*notionapi.Page, error) {
 		if err != nil {
 			d.emitError("ReadPageFromCache(): WriteFile('%s') failed with '%s'\n", name, err)
 			d.Cache.Remove(name)
+		} else {
+			d.addPageMetric(MetricBytesWritten, int64(len(data)))
 		}
 		nNew := httpCache.RequestsNotFromCache - nPrevRequestsFromCache
 		d.emitError("ReadPageFromCache(): unexpectedly made %d HTTP requests for page %s\n", nNew, pageID)
@@ -280,6 +360,10 @@ func (d *Downloader) getPageFromCache(pageID string) *notionapi.Page {
 	if d.canReturnCachedPage(p) {
 		return p
 	}
+	if p != nil {
+		// outdated, will be replaced by a newer version
+		d.addPageMetric(MetricEvictions, 1)
+	}
 	return nil
 }
 
@@ -290,13 +374,18 @@ func (d *Downloader) downloadPageRetry(pageID string) (*notionapi.Page, *caching
 	var err error
 	timeout := time.Second
 	for i := 0; i < 3; i++ {
+		if err := d.checkBudget(); err != nil {
+			return nil, nil, err
+		}
 		c := d.GetClientCopy()
 		httpCache := caching_http_client.NewCache()
 		c.HTTPClient = caching_http_client.New(httpCache)
 		res, err = c.DownloadPage(pageID)
 		if err == nil {
+			d.APICalls += res.Stats.APICalls
 			return res, httpCache, nil
 		}
+		d.APICalls++
 		// only report errors on the first failure
 		if i == 0 {
 			d.emitError("Download %s failed with: '%s'\n", pageID, err)
@@ -350,6 +439,8 @@ func (d *Downloader) downloadAndCachePage(pageID string) (*notionapi.Page, error
 	if err != nil {
 		d.emitError("Downloader.downloadAndCachePage(): d.Cache.WriteFile('%s') failed with '%s'\n", name, err)
 		// ignore file writing error
+	} else {
+		d.addPageMetric(MetricBytesWritten, int64(len(data)))
 	}
 
 	return page, nil
@@ -367,6 +458,7 @@ func (d *Downloader) DownloadPage(pageID string) (*notionapi.Page, error) {
 			return nil, err
 		}
 		d.DownloadedCount++
+		d.addPageMetric(MetricMisses, 1)
 		ev := &EventDidDownload{
 			PageID:   notionapi.ToDashID(pageID),
 			Duration: time.Since(timeStart),
@@ -374,6 +466,7 @@ func (d *Downloader) DownloadPage(pageID string) (*notionapi.Page, error) {
 		d.emitEvent(ev)
 	} else {
 		d.FromCacheCount++
+		d.addPageMetric(MetricHits, 1)
 		ev := &EventDidReadFromCache{
 			PageID:   notionapi.ToDashID(pageID),
 			Duration: time.Since(timeStart),
@@ -387,14 +480,22 @@ func (d *Downloader) DownloadPage(pageID string) (*notionapi.Page, error) {
 }
 
 func (d *Downloader) DownloadPagesRecursively(startPageID string, afterDownload func(*notionapi.Page) error) ([]*notionapi.Page, error) {
-	toVisit := []string{startPageID}
+	type pageToVisit struct {
+		id    string
+		depth int
+	}
+	toVisit := []pageToVisit{{id: startPageID}}
 	downloaded := map[string]*notionapi.Page{}
 	for len(toVisit) > 0 {
-		pageID := notionapi.ToNoDashID(toVisit[0])
+		pageID := notionapi.ToNoDashID(toVisit[0].id)
+		depth := toVisit[0].depth
 		toVisit = toVisit[1:]
 		if downloaded[pageID] != nil {
 			continue
 		}
+		if d.MaxDepth > 0 && depth > d.MaxDepth {
+			return nil, fmt.Errorf("page '%s' is nested deeper than MaxDepth of %d", pageID, d.MaxDepth)
+		}
 
 		page, err := d.DownloadPage(pageID)
 		if err != nil {
@@ -408,8 +509,14 @@ func (d *Downloader) DownloadPagesRecursively(startPageID string, afterDownload
 			}
 		}
 
-		subPages := page.GetSubPages()
-		toVisit = append(toVisit, subPages...)
+		for _, id := range page.GetSubPages() {
+			toVisit = append(toVisit, pageToVisit{id: id, depth: depth + 1})
+		}
+		if d.FollowRowPages {
+			for _, id := range rowPageIDs(page) {
+				toVisit = append(toVisit, pageToVisit{id: id, depth: depth + 1})
+			}
+		}
 	}
 	n := len(downloaded)
 	if n == 0 {
@@ -427,6 +534,29 @@ func (d *Downloader) DownloadPagesRecursively(startPageID string, afterDownload
 	return pages, nil
 }
 
+// LoadUserContent calls Client.LoadUserContent, counting towards MaxAPICalls
+func (d *Downloader) LoadUserContent() (*notionapi.LoadUserResponse, error) {
+	if err := d.checkBudget(); err != nil {
+		return nil, err
+	}
+	d.APICalls++
+	return d.GetClientCopy().LoadUserContent()
+}
+
+// rowPageIDs returns ids of pages of rows of collections on a page.
+// Rows with empty pages are skipped
+func rowPageIDs(page *notionapi.Page) []string {
+	var res []string
+	for _, tv := range page.TableViews {
+		for _, tr := range tv.Rows {
+			if len(tr.Page.ContentIDs) > 0 {
+				res = append(res, tr.Page.ID)
+			}
+		}
+	}
+	return res
+}
+
 // Sha1OfURL returns sha1 of url
 func Sha1OfURL(uri string) string {
 	// TODO: could benefit from normalizing url, e.g. with
@@ -448,6 +578,15 @@ func GetCacheFileNameFromURL(uri string) string {
 	return filepath.Join("files", name)
 }
 
+// cachedFileContentType returns content type of a cached file. We don't
+// cache http headers so we guess it from extension or the content
+func cachedFileContentType(cacheFileName string, data []byte) string {
+	if ct := mime.TypeByExtension(filepath.Ext(cacheFileName)); ct != "" {
+		return ct
+	}
+	return http.DetectContentType(data)
+}
+
 // DownloadFile downloads a file, caching in the cache
 func (d *Downloader) DownloadFile(uri string, blockID string) (*notionapi.DownloadFileResponse, error) {
 	//fmt.Printf("Downloader.DownloadFile('%s'\n", uri)
@@ -459,7 +598,14 @@ func (d *Downloader) DownloadFile(uri string, blockID string) (*notionapi.Downlo
 		data, err = d.Cache.ReadFile(cacheFileName)
 		if err != nil {
 			d.Cache.Remove(cacheFileName)
+		} else if d.MaxFileSize > 0 && int64(len(data)) > d.MaxFileSize {
+			return nil, d.skipFile(uri, fmt.Sprintf("size of %d bytes is bigger than MaxFileSize of %d bytes", len(data), d.MaxFileSize))
+		} else if contentType := cachedFileContentType(cacheFileName, data); !notionapi.IsContentTypeAllowed(contentType, d.AllowedContentTypes) {
+			return nil, d.skipFile(uri, fmt.Sprintf("content type '%s' is not in AllowedContentTypes", contentType))
+		} else if d.MaxTotalFileSize > 0 && d.FilesTotalSize+int64(len(data)) > d.MaxTotalFileSize {
+			return nil, d.skipFile(uri, fmt.Sprintf("total size of files would exceed MaxTotalFileSize of %d bytes", d.MaxTotalFileSize))
 		} else {
+			d.FilesTotalSize += int64(len(data))
 			res := &notionapi.DownloadFileResponse{
 				URL:           uri,
 				Data:          data,
@@ -471,27 +617,68 @@ func (d *Downloader) DownloadFile(uri string, blockID string) (*notionapi.Downlo
 			}
 			d.emitEvent(ev)
 			d.FilesFromCacheCount++
+			d.addAssetMetric(MetricHits, 1)
+			d.addAssetMetric(MetricBytesRead, int64(len(data)))
 			return res, nil
 		}
 	}
 
+	maxSize := d.MaxFileSize
+	if d.MaxTotalFileSize > 0 {
+		left := d.MaxTotalFileSize - d.FilesTotalSize
+		if left <= 0 {
+			return nil, d.skipFile(uri, fmt.Sprintf("total size of files reached MaxTotalFileSize of %d bytes", d.MaxTotalFileSize))
+		}
+		if maxSize <= 0 || left < maxSize {
+			maxSize = left
+		}
+	}
+
+	if err := d.checkBudget(); err != nil {
+		return nil, err
+	}
 	timeStart := time.Now()
-	res, err := d.Client.DownloadFile(uri, blockID)
+	c := d.GetClientCopy()
+	d.APICalls++
+	c.MaxFileSize = maxSize
+	c.AllowedContentTypes = d.AllowedContentTypes
+	res, err := c.DownloadFile(uri, blockID)
 	if err != nil {
+		if e, ok := err.(*notionapi.ErrDownloadSkipped); ok {
+			return nil, d.skipFile(uri, e.Reason)
+		}
 		d.emitError("Downloader.DownloadFile(): failed to download %s, error: %s", uri, err)
 		return nil, err
 	}
+	d.FilesTotalSize += int64(len(res.Data))
 	ev := &EventDidDownload{
 		FileURL:  uri,
 		Duration: time.Since(timeStart),
 	}
 	d.emitEvent(ev)
-	_ = d.Cache.WriteFile(cacheFileName, res.Data)
+	if err = d.Cache.WriteFile(cacheFileName, res.Data); err == nil {
+		d.addAssetMetric(MetricBytesWritten, int64(len(res.Data)))
+	}
 	res.CacheFileName = cacheFileName
 	d.DownloadedFilesCount++
+	d.addAssetMetric(MetricMisses, 1)
 	return res, nil
 }
 
+// records a skipped file and returns notionapi.ErrDownloadSkipped
+func (d *Downloader) skipFile(uri string, reason string) error {
+	ev := &EventDidSkipFile{
+		FileURL: uri,
+		Reason:  reason,
+	}
+	d.SkippedFiles = append(d.SkippedFiles, ev)
+	d.emitEvent(ev)
+	return &notionapi.ErrDownloadSkipped{
+		URL:    uri,
+		Reason: reason,
+	}
+}
+
 func normalizeIDS(ids []string) {
 	for i, id := range ids {
 		ids[i] = notionapi.ToNoDashID(id)
@@ -0,0 +1,63 @@
+package caching_downloader
+
+// names of counters reported to Metrics. They're prefixed with
+// "page_cache_" or "asset_cache_"
+const (
+	MetricHits         = "hits"
+	MetricMisses       = "misses"
+	MetricEvictions    = "evictions"
+	MetricBytesRead    = "bytes_read"
+	MetricBytesWritten = "bytes_written"
+)
+
+// Metrics receives counters of the Downloader, e.g. to export them
+// to a monitoring system
+type Metrics interface {
+	// AddCounter adds delta to a counter with a given name
+	// e.g. "page_cache_hits"
+	AddCounter(name string, delta int64)
+}
+
+// CacheMetrics describes effectiveness of a cache
+type CacheMetrics struct {
+	// Hits is the number of pages or files returned from the cache
+	Hits int64
+	// Misses is the number of pages or files that had to be downloaded
+	Misses int64
+	// Evictions is the number of cached pages that were invalid
+	// or outdated (see Downloader.RedownloadNewerVersions)
+	Evictions int64
+	// BytesRead is the total size of data read from the cache
+	BytesRead int64
+	// BytesWritten is the total size of data written to the cache
+	BytesWritten int64
+}
+
+func (m *CacheMetrics) add(name string, delta int64) {
+	switch name {
+	case MetricHits:
+		m.Hits += delta
+	case MetricMisses:
+		m.Misses += delta
+	case MetricEvictions:
+		m.Evictions += delta
+	case MetricBytesRead:
+		m.BytesRead += delta
+	case MetricBytesWritten:
+		m.BytesWritten += delta
+	}
+}
+
+func (d *Downloader) addPageMetric(name string, delta int64) {
+	d.PageCacheMetrics.add(name, delta)
+	if d.Metrics != nil {
+		d.Metrics.AddCounter("page_cache_"+name, delta)
+	}
+}
+
+func (d *Downloader) addAssetMetric(name string, delta int64) {
+	d.AssetCacheMetrics.add(name, delta)
+	if d.Metrics != nil {
+		d.Metrics.AddCounter("asset_cache_"+name, delta)
+	}
+}
@@ -0,0 +1,43 @@
+package caching_downloader
+
+import (
+	"path/filepath"
+	"runtime"
+
+	"github.com/ninja-1/notionapi"
+)
+
+// TestingT is the part of testing.TB used by test helpers. We don't
+// import testing so that it's not linked into programs
+type TestingT interface {
+	Helper()
+	Fatalf(format string, args ...interface{})
+}
+
+// TestDataDir returns the directory with pages cached for tests
+// of notionapi packages
+func TestDataDir() string {
+	_, file, _, _ := runtime.Caller(0)
+	return filepath.Join(filepath.Dir(file), "testdata")
+}
+
+// NewTestDownloader returns a Downloader that reads pages cached in
+// TestDataDir. It's meant for tests
+func NewTestDownloader(t TestingT) *Downloader {
+	t.Helper()
+	cache, err := NewDirectoryCache(TestDataDir())
+	if err != nil {
+		t.Fatalf("NewDirectoryCache() failed with '%s'", err)
+	}
+	return New(cache, &notionapi.Client{})
+}
+
+// LoadTestPage loads a page cached in TestDataDir. It's meant for tests
+func LoadTestPage(t TestingT, pageID string) *notionapi.Page {
+	t.Helper()
+	p, err := NewTestDownloader(t).ReadPageFromCache(pageID)
+	if err != nil {
+		t.Fatalf("ReadPageFromCache('%s') failed with '%s'", pageID, err)
+	}
+	return p
+}
@@ -1,6 +1,7 @@
 package caching_downloader
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/ninja-1/notionapi/tohtml"
@@ -31,6 +32,26 @@ func testDownloadFromCache(t *testing.T, pageID string) *notionapi.Page {
 	return p
 }
 
+// pages can be rendered from memory, without touching file system
+func TestMemoryCache(t *testing.T) {
+	pid := "94167af6567043279811dc923edd1f04"
+	dirCache, err := NewDirectoryCache("testdata")
+	require.NoError(t, err)
+	d, err := dirCache.ReadFile(pid + ".txt")
+	require.NoError(t, err)
+	cache := NewMemoryCache()
+	require.NoError(t, cache.WriteFile(pid+".txt", d))
+	ids, err := cache.GetPageIDs()
+	require.NoError(t, err)
+	require.Equal(t, []string{pid}, ids)
+
+	downloader := New(cache, &notionapi.Client{})
+	p, err := downloader.DownloadPage(pid)
+	require.NoError(t, err)
+	require.Equal(t, 1, downloader.FromCacheCount)
+	convertToMdAndHTML(t, p)
+}
+
 func convertToMdAndHTML(t *testing.T, page *notionapi.Page) {
 	{
 		conv := tomarkdown.NewConverter(page)
@@ -69,6 +90,13 @@ func TestPage6682351e44bb4f9ca0e149b703265bdb(t *testing.T) {
 	require.Equal(t, blockTypes, expected)
 }
 
+func TestPageLoadStats(t *testing.T) {
+	p := testDownloadFromCache(t, "6682351e44bb4f9ca0e149b703265bdb")
+	require.Equal(t, 1, p.Stats.Chunks)
+	require.True(t, p.Stats.APICalls >= 2)
+	require.Equal(t, "bc202e06-6caa-4e3f-81eb-f226ab5deef7", p.Stats.SpaceID)
+}
+
 // https://www.notion.so/Test-table-94167af6567043279811dc923edd1f04
 // simple table
 func TestPage94167af6567043279811dc923edd1f04(t *testing.T) {
@@ -106,3 +134,63 @@ func BenchmarkJSONDecode(b *testing.B) {
 		must(err)
 	}
 }
+
+type testMetrics map[string]int64
+
+func (m testMetrics) AddCounter(name string, delta int64) {
+	m[name] += delta
+}
+
+func TestCacheMetrics(t *testing.T) {
+	pid := "94167af6567043279811dc923edd1f04"
+	dirCache, err := NewDirectoryCache("testdata")
+	require.NoError(t, err)
+	d, err := dirCache.ReadFile(pid + ".txt")
+	require.NoError(t, err)
+	cache := NewMemoryCache()
+	require.NoError(t, cache.WriteFile(pid+".txt", d))
+	// a record of unknown type makes the cached page invalid
+	invalid := []byte(strings.Replace(string(d), "httpcache-v1", "httpcache-v0", 1))
+	require.NoError(t, cache.WriteFile("6682351e44bb4f9ca0e149b703265bdb.txt", invalid))
+
+	metrics := testMetrics{}
+	downloader := New(cache, &notionapi.Client{})
+	downloader.Metrics = metrics
+	_, err = downloader.DownloadPage(pid)
+	require.NoError(t, err)
+	_, err = downloader.ReadPageFromCache("6682351e44bb4f9ca0e149b703265bdb")
+	require.Error(t, err)
+
+	m := downloader.PageCacheMetrics
+	require.Equal(t, int64(1), m.Hits)
+	require.Equal(t, int64(0), m.Misses)
+	require.Equal(t, int64(1), m.Evictions)
+	require.Equal(t, int64(len(d)+len(invalid)), m.BytesRead)
+	require.Equal(t, m.Hits, metrics["page_cache_hits"])
+	require.Equal(t, m.BytesRead, metrics["page_cache_bytes_read"])
+	require.Equal(t, int64(0), downloader.AssetCacheMetrics.Hits)
+}
+
+// files read from cache are subject to the same limits as downloaded files
+func TestDownloadFileLimitsFromCache(t *testing.T) {
+	uri := "https://example.com/image.png"
+	cache := NewMemoryCache()
+	require.NoError(t, cache.WriteFile(GetCacheFileNameFromURL(uri), []byte("0123456789")))
+	d := New(cache, &notionapi.Client{})
+
+	d.MaxFileSize = 5
+	_, err := d.DownloadFile(uri, "")
+	require.True(t, notionapi.IsErrDownloadSkipped(err))
+
+	d.MaxFileSize = 0
+	d.AllowedContentTypes = []string{"application/pdf"}
+	_, err = d.DownloadFile(uri, "")
+	require.True(t, notionapi.IsErrDownloadSkipped(err))
+	require.Equal(t, 2, len(d.SkippedFiles))
+
+	d.AllowedContentTypes = []string{"image/"}
+	res, err := d.DownloadFile(uri, "")
+	require.NoError(t, err)
+	require.Equal(t, "0123456789", string(res.Data))
+	require.Equal(t, 0, d.APICalls)
+}
@@ -0,0 +1,232 @@
+// Package changelog generates a changelog in https://keepachangelog.com/
+// format from a Notion database of releases
+package changelog
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/ninja-1/notionapi"
+	"github.com/ninja-1/notionapi/tohtml"
+	"github.com/ninja-1/notionapi/tomarkdown"
+)
+
+// categories in the order recommended by keepachangelog.com
+var knownCategories = []string{"Added", "Changed", "Deprecated", "Removed", "Fixed", "Security"}
+
+// Entry is a single change, a row in the database
+type Entry struct {
+	Title    string
+	Category string
+	// row of the database. Its content is the body of the entry
+	Row *notionapi.Block
+	// page with content of the row, if Generator.PageByID is set
+	Page *notionapi.Page
+}
+
+// Release is a group of entries with the same version
+type Release struct {
+	// "Unreleased" if version is empty
+	Version string
+	// "2020-05-01"
+	Date    string
+	Entries []*Entry
+}
+
+// Categories returns categories of entries in the release,
+// in keepachangelog order
+func (r *Release) Categories() []string {
+	seen := map[string]bool{}
+	for _, e := range r.Entries {
+		seen[e.Category] = true
+	}
+	var res []string
+	for _, cat := range knownCategories {
+		if seen[cat] {
+			res = append(res, cat)
+			delete(seen, cat)
+		}
+	}
+	var rest []string
+	for cat := range seen {
+		rest = append(rest, cat)
+	}
+	sort.Strings(rest)
+	return append(res, rest...)
+}
+
+// EntriesInCategory returns entries with a given category
+func (r *Release) EntriesInCategory(category string) []*Entry {
+	var res []*Entry
+	for _, e := range r.Entries {
+		if e.Category == category {
+			res = append(res, e)
+		}
+	}
+	return res
+}
+
+// Generator generates a changelog from a database (table view) where
+// each row is a change
+type Generator struct {
+	TableView *notionapi.TableView
+
+	// names of columns. Default to "Version", "Date" and "Category"
+	VersionColumn  string
+	DateColumn     string
+	CategoryColumn string
+
+	// Title is the title of the changelog. Defaults to "Changelog"
+	Title string
+
+	// PageByID returns a page for a row of the database. If set, content
+	// of the page is rendered as the body of the entry.
+	// Can be caching_downloader.Downloader.DownloadPage
+	PageByID func(pageID string) (*notionapi.Page, error)
+}
+
+// New returns a Generator for a database
+func New(tv *notionapi.TableView) *Generator {
+	return &Generator{
+		TableView:      tv,
+		VersionColumn:  "Version",
+		DateColumn:     "Date",
+		CategoryColumn: "Category",
+		Title:          "Changelog",
+	}
+}
+
+// Releases returns releases, newest first
+func (g *Generator) Releases() ([]*Release, error) {
+	tv := g.TableView
+	versionCol := tv.ColumnIndexByName(g.VersionColumn)
+	if versionCol < 0 {
+		return nil, fmt.Errorf("database has no '%s' column", g.VersionColumn)
+	}
+	dateCol := tv.ColumnIndexByName(g.DateColumn)
+	categoryCol := tv.ColumnIndexByName(g.CategoryColumn)
+
+	var releases []*Release
+	byVersion := map[string]*Release{}
+	for row, tr := range tv.Rows {
+		version := tv.CellText(row, versionCol)
+		if version == "" {
+			version = "Unreleased"
+		}
+		r := byVersion[version]
+		if r == nil {
+			r = &Release{Version: version}
+			byVersion[version] = r
+			releases = append(releases, r)
+		}
+		if date := tv.CellText(row, dateCol); date > r.Date {
+			r.Date = date
+		}
+		e := &Entry{
+			Title:    tr.Page.Title,
+			Category: tv.CellText(row, categoryCol),
+			Row:      tr.Page,
+		}
+		if e.Category == "" {
+			e.Category = "Changed"
+		}
+		if g.PageByID != nil && len(tr.Page.ContentIDs) > 0 {
+			page, err := g.PageByID(tr.Page.ID)
+			if err != nil {
+				return nil, err
+			}
+			e.Page = page
+		}
+		r.Entries = append(r.Entries, e)
+	}
+	// unreleased first, then newest
+	sort.SliceStable(releases, func(i, j int) bool {
+		ri, rj := releases[i], releases[j]
+		if (ri.Version == "Unreleased") != (rj.Version == "Unreleased") {
+			return ri.Version == "Unreleased"
+		}
+		return ri.Date > rj.Date
+	})
+	return releases, nil
+}
+
+func releaseHeader(r *Release) string {
+	if r.Version == "Unreleased" || r.Date == "" {
+		return fmt.Sprintf("[%s]", r.Version)
+	}
+	return fmt.Sprintf("[%s] - %s", r.Version, r.Date)
+}
+
+func entryMarkdown(e *Entry) string {
+	if e.Page == nil {
+		return ""
+	}
+	c := tomarkdown.NewConverter(e.Page)
+	c.PushNewBuffer()
+	c.RenderChildren(e.Page.Root())
+	return strings.TrimSpace(c.PopBuffer().String())
+}
+
+// ToMarkdown returns changelog in Markdown format
+func (g *Generator) ToMarkdown() ([]byte, error) {
+	releases, err := g.Releases()
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "# %s\n", g.Title)
+	for _, r := range releases {
+		fmt.Fprintf(&buf, "\n## %s\n", releaseHeader(r))
+		for _, cat := range r.Categories() {
+			fmt.Fprintf(&buf, "\n### %s\n\n", cat)
+			for _, e := range r.EntriesInCategory(cat) {
+				fmt.Fprintf(&buf, "- %s\n", e.Title)
+				body := entryMarkdown(e)
+				if body == "" {
+					continue
+				}
+				// indent so that the body is part of the list item
+				for _, line := range strings.Split(body, "\n") {
+					if line == "" {
+						buf.WriteString("\n")
+						continue
+					}
+					fmt.Fprintf(&buf, "  %s\n", line)
+				}
+			}
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+// ToHTML returns changelog as HTML fragment
+func (g *Generator) ToHTML() ([]byte, error) {
+	releases, err := g.Releases()
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, `<div class="changelog"><h1>%s</h1>`, tohtml.EscapeHTML(g.Title))
+	for _, r := range releases {
+		fmt.Fprintf(&buf, `<section class="release"><h2>%s</h2>`, tohtml.EscapeHTML(releaseHeader(r)))
+		for _, cat := range r.Categories() {
+			fmt.Fprintf(&buf, `<h3>%s</h3><ul>`, tohtml.EscapeHTML(cat))
+			for _, e := range r.EntriesInCategory(cat) {
+				fmt.Fprintf(&buf, `<li>%s`, tohtml.EscapeHTML(e.Title))
+				if e.Page != nil {
+					c := tohtml.NewConverter(e.Page)
+					c.PushNewBuffer()
+					c.RenderChildren(e.Page.Root())
+					buf.Write(c.PopBuffer().Bytes())
+				}
+				buf.WriteString(`</li>`)
+			}
+			buf.WriteString(`</ul>`)
+		}
+		buf.WriteString(`</section>`)
+	}
+	buf.WriteString(`</div>`)
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,26 @@
+package changelog
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ninja-1/notionapi/caching_downloader"
+	"github.com/stretchr/testify/require"
+)
+
+// https://www.notion.so/Test-table-94167af6567043279811dc923edd1f04
+func TestChangelogFromTable(t *testing.T) {
+	p := caching_downloader.LoadTestPage(t, "94167af6567043279811dc923edd1f04")
+	g := New(p.TableViews[0])
+	g.VersionColumn = "Numbers"
+	g.CategoryColumn = "Tags"
+	md, err := g.ToMarkdown()
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(string(md), "# Changelog\n\n## ["))
+	_, err = g.ToHTML()
+	require.NoError(t, err)
+
+	g.VersionColumn = "no such column"
+	_, err = g.ToMarkdown()
+	require.Error(t, err)
+}
@@ -7,6 +7,7 @@ import (
 	"io"
 	"io/ioutil"
 	"net/http"
+	"net/url"
 	"sort"
 	"strings"
 	"time"
@@ -24,13 +25,93 @@ type Client struct {
 	// AuthToken allows accessing non-public pages.
 	AuthToken string
 	// HTTPClient allows over-riding http.Client to e.g. implement caching
-	// on a per-request level
+	// on a per-request level. Under GOOS=js the default client
+	// uses browser's fetch() API
 	HTTPClient *http.Client
 	// Logger is used to log requests and responses for debugging.
 	// By default is not set.
 	Logger io.Writer
 	// DebugLog enables debug logging
 	DebugLog bool
+
+	// MaxFileSize, if > 0, is the maximum size of a file downloaded with
+	// DownloadFile. Bigger files are not downloaded and DownloadFile
+	// returns ErrDownloadSkipped
+	MaxFileSize int64
+	// AllowedContentTypes, if not empty, limits files downloaded with
+	// DownloadFile to those content types. A value ending with "/"
+	// (e.g. "image/") matches all content types with that prefix.
+	// Other files are not downloaded and DownloadFile returns
+	// ErrDownloadSkipped
+	AllowedContentTypes []string
+
+	// DedupRequests, if true, coalesces concurrent identical requests
+	// (API calls and file downloads) made by clients that share the same
+	// AuthToken and HTTPClient into a single request to the server.
+	// Useful when serving pages from multiple goroutines.
+	DedupRequests bool
+
+	// FileToken is a value of file_token cookie, needed to download
+	// files from file.notion.so. Like AuthToken, it can be found in
+	// the browser's cookies when logged in to Notion
+	FileToken string
+
+	// BaseURL is where we send API requests. Defaults to
+	// https://www.notion.so. Pages published to notion.site can be
+	// accessed anonymously (without AuthToken) via their domain
+	// e.g. https://kjk.notion.site (see NewPublicClient)
+	BaseURL string
+
+	// DownloadDiscussions, if true, makes DownloadPage also download
+	// discussions and comments on blocks that were not returned
+	// together with the page
+	DownloadDiscussions bool
+
+	// MaxCollectionRows, if > 0, limits the number of rows of collections
+	// (databases) loaded by DownloadPage. TableView.Total is the number
+	// of all rows
+	MaxCollectionRows int
+	// CollectionPageSize, if > 0, is the number of rows of a collection
+	// loaded with one request by DownloadPage
+	CollectionPageSize int
+	// OnCollectionRows, if set, is called by DownloadPage with rows of
+	// collection views as they're loaded, which allows processing large
+	// collections before the whole page is downloaded
+	OnCollectionRows func(collectionViewID string, rows []*Block) error
+
+	// DriftReport, if set, collects block types and fields of blocks
+	// that we don't know about from pages downloaded with DownloadPage
+	DriftReport *DriftReport
+
+	// if set, called with every response of the API, see DownloadPageRaw
+	onRawResponse func(apiURL string, req []byte, rsp []byte, statusCode int)
+}
+
+// NewPublicClient returns a client for downloading a publicly shared page
+// without credentials, given its URL, and id of the page.
+// Supports www.notion.so and *.notion.site URLs
+func NewPublicClient(pageURL string) (*Client, string, error) {
+	u, err := url.Parse(pageURL)
+	if err != nil {
+		return nil, "", err
+	}
+	pageID := ExtractNoDashIDFromNotionURL(u.Path)
+	if pageID == "" {
+		return nil, "", fmt.Errorf("'%s' is not a url of Notion page", pageURL)
+	}
+	c := &Client{}
+	host := strings.ToLower(u.Hostname())
+	if strings.HasSuffix(host, ".notion.site") {
+		c.BaseURL = "https://" + host
+	}
+	return c, pageID, nil
+}
+
+func (c *Client) getBaseURL() string {
+	if c.BaseURL != "" {
+		return strings.TrimSuffix(c.BaseURL, "/")
+	}
+	return notionHost
 }
 
 func (c *Client) getHTTPClient() *http.Client {
@@ -66,20 +147,75 @@ func IsErrPageNotFound(err error) bool {
 	return ok
 }
 
+// ErrDownloadSkipped is returned by Client.DownloadFile if a file
+// was not downloaded due to MaxFileSize or AllowedContentTypes limits
+type ErrDownloadSkipped struct {
+	URL string
+	// why the file was skipped
+	Reason string
+}
+
+func newErrDownloadSkipped(uri string, format string, args ...interface{}) *ErrDownloadSkipped {
+	return &ErrDownloadSkipped{
+		URL:    uri,
+		Reason: fmt.Sprintf(format, args...),
+	}
+}
+
+// Error return error string
+func (e *ErrDownloadSkipped) Error() string {
+	return fmt.Sprintf("skipped downloading '%s': %s", e.URL, e.Reason)
+}
+
+// IsErrDownloadSkipped returns true if err is an instance of ErrDownloadSkipped
+func IsErrDownloadSkipped(err error) bool {
+	_, ok := err.(*ErrDownloadSkipped)
+	return ok
+}
+
 func closeNoError(c io.Closer) {
 	_ = c.Close()
 }
 
-func doNotionAPI(c *Client, apiURL string, requestData interface{}, result interface{}) (map[string]interface{}, error) {
-	var js []byte
-	var err error
-	if requestData != nil {
-		js, err = json.Marshal(requestData)
-		if err != nil {
-			return nil, err
-		}
+// sets token_v2 and file_token cookies, if we have them
+func (c *Client) setCookies(req *http.Request) {
+	var cookies []string
+	if c.AuthToken != "" {
+		cookies = append(cookies, "token_v2="+c.AuthToken)
+	}
+	if c.FileToken != "" {
+		cookies = append(cookies, "file_token="+c.FileToken)
 	}
-	uri := notionHost + apiURL
+	if len(cookies) > 0 {
+		req.Header.Set("cookie", strings.Join(cookies, "; "))
+	}
+}
+
+// errAPIStatus is returned by postNotionAPI when the server responds
+// with non-200 status code
+type errAPIStatus struct {
+	uri        string
+	statusCode int
+}
+
+func (e *errAPIStatus) Error() string {
+	return fmt.Sprintf("http.Post('%s') returned non-200 status code of %d", e.uri, e.statusCode)
+}
+
+// isErrAPIUnauthorized returns true if the server rejected
+// the request because we're not authorized
+func isErrAPIUnauthorized(err error) bool {
+	e, ok := err.(*errAPIStatus)
+	if !ok {
+		return false
+	}
+	return e.statusCode == http.StatusUnauthorized || e.statusCode == http.StatusForbidden
+}
+
+// postNotionAPI sends POST request with JSON body js to apiURL and
+// returns the body of the response
+func postNotionAPI(c *Client, apiURL string, js []byte) ([]byte, error) {
+	uri := c.getBaseURL() + apiURL
 	body := bytes.NewBuffer(js)
 	log(c, "POST %s\n", uri)
 	if len(js) > 0 {
@@ -93,9 +229,7 @@ func doNotionAPI(c *Client, apiURL string, requestData interface{}, result inter
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("User-Agent", userAgent)
 	req.Header.Set("Accept-Language", acceptLang)
-	if c.AuthToken != "" {
-		req.Header.Set("cookie", fmt.Sprintf("token_v2=%v", c.AuthToken))
-	}
+	c.setCookies(req)
 	var rsp *http.Response
 
 	httpClient := c.getHTTPClient()
@@ -110,7 +244,10 @@ func doNotionAPI(c *Client, apiURL string, requestData interface{}, result inter
 	if rsp.StatusCode != 200 {
 		d, _ := ioutil.ReadAll(rsp.Body)
 		log(c, "Error: status code %s\nBody:\n%s\n", rsp.Status, ppJSON(d))
-		return nil, fmt.Errorf("http.Post('%s') returned non-200 status code of %d", uri, rsp.StatusCode)
+		if c.onRawResponse != nil {
+			c.onRawResponse(apiURL, js, d, rsp.StatusCode)
+		}
+		return nil, &errAPIStatus{uri: uri, statusCode: rsp.StatusCode}
 	}
 	d, err := ioutil.ReadAll(rsp.Body)
 	if err != nil {
@@ -118,6 +255,35 @@ func doNotionAPI(c *Client, apiURL string, requestData interface{}, result inter
 		return nil, err
 	}
 	logJSON(c, d)
+	if c.onRawResponse != nil {
+		c.onRawResponse(apiURL, js, d, rsp.StatusCode)
+	}
+	return d, nil
+}
+
+func doNotionAPI(c *Client, apiURL string, requestData interface{}, result interface{}) (map[string]interface{}, error) {
+	var js []byte
+	var err error
+	if requestData != nil {
+		js, err = json.Marshal(requestData)
+		if err != nil {
+			return nil, err
+		}
+	}
+	var d []byte
+	if c.DedupRequests {
+		key := c.dedupKey("api", apiURL, string(js))
+		var v interface{}
+		v, err = inflight.do(key, func() (interface{}, error) {
+			return postNotionAPI(c, apiURL, js)
+		})
+		d, _ = v.([]byte)
+	} else {
+		d, err = postNotionAPI(c, apiURL, js)
+	}
+	if err != nil {
+		return nil, err
+	}
 	err = json.Unmarshal(d, result)
 	if err != nil {
 		log(c, "Error: json.Unmarshal() failed with %s\n. Body:\n%s\n", err, string(d))
@@ -335,6 +501,7 @@ func (c *Client) DownloadPage(pageID string) (*Page, error) {
 		return nil, fmt.Errorf("%s is not a valid Notion page id", id)
 	}
 	pageID = id
+	timeStart := time.Now()
 
 	p := &Page{
 		ID:                 pageID,
@@ -352,6 +519,7 @@ func (c *Client) DownloadPage(pageID string) (*Page, error) {
 	// get page's root block and then recursively download referenced blocks
 	{
 		recVals, err := c.GetBlockRecords([]string{pageID})
+		p.Stats.APICalls++
 		if err != nil {
 			return nil, err
 		}
@@ -370,6 +538,8 @@ func (c *Client) DownloadPage(pageID string) (*Page, error) {
 	for {
 		rsp, err := c.LoadPageChunk(pageID, chunkNo, cur)
 		chunkNo++
+		p.Stats.APICalls++
+		p.Stats.Chunks++
 		if err != nil {
 			return nil, err
 		}
@@ -434,6 +604,7 @@ func (c *Client) DownloadPage(pageID string) (*Page, error) {
 			}
 
 			recVals, err := c.GetBlockRecords(toGet)
+			p.Stats.APICalls++
 			if err != nil {
 				return nil, err
 			}
@@ -478,6 +649,12 @@ func (c *Client) DownloadPage(pageID string) (*Page, error) {
 		}
 	}
 
+	if c.DownloadDiscussions {
+		if err := c.downloadDiscussions(p); err != nil {
+			return nil, err
+		}
+	}
+
 	err := p.resolveBlocks()
 	if err != nil {
 		return nil, fmt.Errorf("failed to resolve blocks on page '%s': %s", p.ID, err)
@@ -514,10 +691,21 @@ func (c *Client) DownloadPage(pageID string) (*Page, error) {
 				continue
 			}
 			q := collectionView.Query
-			res, err := c.QueryCollection(collectionID, collectionViewID, q, user)
+			opts := &QueryCollectionOptions{
+				PageSize: c.CollectionPageSize,
+				MaxRows:  c.MaxCollectionRows,
+			}
+			if c.OnCollectionRows != nil {
+				viewID := collectionViewID
+				opts.OnRows = func(rows []*Block) error {
+					return c.OnCollectionRows(viewID, rows)
+				}
+			}
+			res, err := c.QueryCollectionWithOptions(collectionID, collectionViewID, q, user, opts)
 			if err != nil {
 				return nil, err
 			}
+			p.Stats.APICalls += res.Requests
 
 			tableView := &TableView{
 				Page:           p,
@@ -561,5 +749,17 @@ func (c *Client) DownloadPage(pageID string) (*Page, error) {
 		}
 	}
 
+	p.Stats.Blocks = len(p.idToBlock)
+	// space_id is not always set on the root block
+	for _, id := range blockIDs {
+		if spaceID := p.idToBlock[id].SpaceID; spaceID != "" {
+			p.Stats.SpaceID = spaceID
+			break
+		}
+	}
+	if c.DriftReport != nil {
+		c.DriftReport.AddPage(p)
+	}
+	p.Stats.Duration = time.Since(timeStart)
 	return p, nil
 }
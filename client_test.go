@@ -39,3 +39,17 @@ func TestExtractNoDashIDFromNotionURL(t *testing.T) {
 		assert.Equal(t, exp, got)
 	}
 }
+
+func TestNewPublicClient(t *testing.T) {
+	c, pageID, err := NewPublicClient("https://kjk.notion.site/Test-headers-6682351e44bb4f9ca0e149b703265bdb")
+	assert.NoError(t, err)
+	assert.Equal(t, "6682351e44bb4f9ca0e149b703265bdb", pageID)
+	assert.Equal(t, "https://kjk.notion.site", c.getBaseURL())
+
+	c, _, err = NewPublicClient("https://www.notion.so/Test-headers-6682351e44bb4f9ca0e149b703265bdb")
+	assert.NoError(t, err)
+	assert.Equal(t, notionHost, c.getBaseURL())
+
+	_, _, err = NewPublicClient("https://www.notion.so/")
+	assert.Error(t, err)
+}
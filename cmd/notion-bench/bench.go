@@ -0,0 +1,234 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"runtime"
+	"sort"
+	"time"
+
+	"github.com/ninja-1/notionapi"
+	"github.com/ninja-1/notionapi/caching_downloader"
+	"github.com/ninja-1/notionapi/tohtml"
+	"github.com/ninja-1/notionapi/tomarkdown"
+)
+
+const (
+	phaseDownload = "download"
+	phaseDecode   = "decode"
+	phaseHTML     = "render-html"
+	phaseMarkdown = "render-markdown"
+)
+
+var phases = []string{phaseDownload, phaseDecode, phaseHTML, phaseMarkdown}
+
+// PhaseStats are statistics of a phase over all pages and iterations
+type PhaseStats struct {
+	Phase string `json:"phase"`
+	// Count is the number of measurements
+	Count int           `json:"count"`
+	Min   time.Duration `json:"min_ns"`
+	Mean  time.Duration `json:"mean_ns"`
+	P50   time.Duration `json:"p50_ns"`
+	P90   time.Duration `json:"p90_ns"`
+	P99   time.Duration `json:"p99_ns"`
+	Max   time.Duration `json:"max_ns"`
+	// BytesPerOp and AllocsPerOp are average allocations of a measurement
+	BytesPerOp  uint64 `json:"bytes_per_op"`
+	AllocsPerOp uint64 `json:"allocs_per_op"`
+}
+
+// Result is the result of a benchmark run
+type Result struct {
+	// Live is true if pages were downloaded from Notion, false if they
+	// were read from recordings
+	Live       bool          `json:"live"`
+	Pages      int           `json:"pages"`
+	Iterations int           `json:"iterations"`
+	Phases     []*PhaseStats `json:"phases"`
+	// HeapInUse is the size of the heap after the run, in bytes
+	HeapInUse uint64 `json:"heap_in_use"`
+	GoVersion string `json:"go_version"`
+}
+
+type measurement struct {
+	dur    time.Duration
+	bytes  uint64
+	allocs uint64
+}
+
+type bench struct {
+	// if set, pages are downloaded with it, otherwise read from recordings
+	client *notionapi.Client
+	// recordings of pages, as written by caching_downloader
+	recorded caching_downloader.Cache
+
+	measurements map[string][]measurement
+}
+
+// measure calls fn and records its duration and allocations
+func (b *bench) measure(phase string, fn func() error) error {
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	timeStart := time.Now()
+	err := fn()
+	dur := time.Since(timeStart)
+	runtime.ReadMemStats(&after)
+	if err != nil {
+		return err
+	}
+	m := measurement{
+		dur:    dur,
+		bytes:  after.TotalAlloc - before.TotalAlloc,
+		allocs: after.Mallocs - before.Mallocs,
+	}
+	b.measurements[phase] = append(b.measurements[phase], m)
+	return nil
+}
+
+// download gets a recording of a page. Live pages are downloaded
+// only once, recordings are read in every iteration
+func (b *bench) download(pageID string, cache caching_downloader.Cache) error {
+	name := notionapi.ToNoDashID(pageID) + ".txt"
+	if b.client == nil {
+		return b.measure(phaseDownload, func() error {
+			d, err := b.recorded.ReadFile(name)
+			if err != nil {
+				return err
+			}
+			return cache.WriteFile(name, d)
+		})
+	}
+	if _, err := cache.ReadFile(name); err == nil {
+		return nil
+	}
+	return b.measure(phaseDownload, func() error {
+		d := caching_downloader.New(cache, b.client)
+		d.NoReadCache = true
+		_, err := d.DownloadPage(pageID)
+		return err
+	})
+}
+
+// runPage runs all phases for a page
+func (b *bench) runPage(pageID string, cache caching_downloader.Cache) error {
+	if err := b.download(pageID, cache); err != nil {
+		return fmt.Errorf("downloading page %s failed with '%s'", pageID, err)
+	}
+	var page *notionapi.Page
+	err := b.measure(phaseDecode, func() error {
+		d := caching_downloader.New(cache, &notionapi.Client{})
+		var err error
+		page, err = d.ReadPageFromCache(pageID)
+		if err == nil && page == nil {
+			err = fmt.Errorf("no recording")
+		}
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("decoding page %s failed with '%s'", pageID, err)
+	}
+	err = b.measure(phaseHTML, func() error {
+		_, err := tohtml.NewConverter(page).ToHTML()
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("rendering page %s as HTML failed with '%s'", pageID, err)
+	}
+	return b.measure(phaseMarkdown, func() error {
+		tomarkdown.NewConverter(page).ToMarkdown()
+		return nil
+	})
+}
+
+func (b *bench) run(pageIDs []string, iterations int) (*Result, error) {
+	b.measurements = map[string][]measurement{}
+	// recordings of live pages are kept in memory between iterations
+	cache := caching_downloader.NewMemoryCache()
+	for i := 0; i < iterations; i++ {
+		for _, pageID := range pageIDs {
+			if err := b.runPage(pageID, cache); err != nil {
+				return nil, err
+			}
+		}
+	}
+	res := &Result{
+		Live:       b.client != nil,
+		Pages:      len(pageIDs),
+		Iterations: iterations,
+		GoVersion:  runtime.Version(),
+	}
+	for _, phase := range phases {
+		if ms := b.measurements[phase]; len(ms) > 0 {
+			res.Phases = append(res.Phases, calcStats(phase, ms))
+		}
+	}
+	var ms runtime.MemStats
+	runtime.ReadMemStats(&ms)
+	res.HeapInUse = ms.HeapInuse
+	return res, nil
+}
+
+// percentile returns p-th percentile of sorted durations, using
+// the nearest-rank method
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	idx := int(math.Ceil(p/100*float64(len(sorted)))) - 1
+	if idx < 0 {
+		idx = 0
+	}
+	return sorted[idx]
+}
+
+func calcStats(phase string, ms []measurement) *PhaseStats {
+	n := len(ms)
+	durs := make([]time.Duration, n)
+	var total time.Duration
+	var bytes, allocs uint64
+	for i, m := range ms {
+		durs[i] = m.dur
+		total += m.dur
+		bytes += m.bytes
+		allocs += m.allocs
+	}
+	sort.Slice(durs, func(i, j int) bool { return durs[i] < durs[j] })
+	return &PhaseStats{
+		Phase:       phase,
+		Count:       n,
+		Min:         durs[0],
+		Mean:        total / time.Duration(n),
+		P50:         percentile(durs, 50),
+		P90:         percentile(durs, 90),
+		P99:         percentile(durs, 99),
+		Max:         durs[n-1],
+		BytesPerOp:  bytes / uint64(n),
+		AllocsPerOp: allocs / uint64(n),
+	}
+}
+
+func printResult(w io.Writer, res *Result) {
+	src := "recorded"
+	if res.Live {
+		src = "live"
+	}
+	fmt.Fprintf(w, "%d %s pages, %d iterations, %s\n\n", res.Pages, src, res.Iterations, res.GoVersion)
+	fmt.Fprintf(w, "%-16s %6s %10s %10s %10s %10s %10s %12s %10s\n", "phase", "count", "min", "p50", "p90", "p99", "max", "B/op", "allocs/op")
+	for _, s := range res.Phases {
+		fmt.Fprintf(w, "%-16s %6d %10s %10s %10s %10s %10s %12d %10d\n", s.Phase, s.Count,
+			fmtDur(s.Min), fmtDur(s.P50), fmtDur(s.P90), fmtDur(s.P99), fmtDur(s.Max), s.BytesPerOp, s.AllocsPerOp)
+	}
+	fmt.Fprintf(w, "\nheap in use: %.1f MB\n", float64(res.HeapInUse)/(1024*1024))
+}
+
+func fmtDur(d time.Duration) string {
+	switch {
+	case d >= time.Second:
+		return fmt.Sprintf("%.2fs", d.Seconds())
+	case d >= time.Millisecond:
+		return fmt.Sprintf("%.2fms", float64(d)/float64(time.Millisecond))
+	}
+	return fmt.Sprintf("%.1fµs", float64(d)/float64(time.Microsecond))
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/ninja-1/notionapi/caching_downloader"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPercentile(t *testing.T) {
+	var durs []time.Duration
+	for i := 1; i <= 10; i++ {
+		durs = append(durs, time.Duration(i))
+	}
+	require.Equal(t, time.Duration(5), percentile(durs, 50))
+	require.Equal(t, time.Duration(9), percentile(durs, 90))
+	require.Equal(t, time.Duration(10), percentile(durs, 99))
+	require.Equal(t, time.Duration(1), percentile(durs, 0))
+}
+
+func TestRunRecorded(t *testing.T) {
+	cache, err := caching_downloader.NewDirectoryCache(caching_downloader.TestDataDir())
+	require.NoError(t, err)
+	b := &bench{recorded: cache}
+	res, err := b.run([]string{"6682351e44bb4f9ca0e149b703265bdb"}, 3)
+	require.NoError(t, err)
+	require.False(t, res.Live)
+	require.Equal(t, len(phases), len(res.Phases))
+	for _, s := range res.Phases {
+		require.Equal(t, 3, s.Count)
+		require.True(t, s.Min <= s.P50 && s.P50 <= s.Max)
+	}
+	var buf bytes.Buffer
+	printResult(&buf, res)
+	require.Contains(t, buf.String(), "render-html")
+
+	_, err = b.run([]string{"00000000000000000000000000000000"}, 1)
+	require.Error(t, err)
+}
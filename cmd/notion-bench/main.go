@@ -0,0 +1,90 @@
+// notion-bench measures how long it takes to download, decode and render
+// Notion pages, with percentiles and allocations of each phase, so that
+// performance of releases can be compared.
+//
+// Pages are read from recordings made by caching_downloader (a directory
+// with ${pageID}.txt files) or downloaded from Notion, once per run:
+//
+//	notion-bench -recorded caching_downloader/testdata -n 20
+//	NOTION_TOKEN=... notion-bench -pages id1,id2 -json > bench.json
+//
+// For recorded pages, download phase is reading the recording.
+// Decode phase is parsing API responses into notionapi.Page
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ninja-1/notionapi"
+	"github.com/ninja-1/notionapi/caching_downloader"
+)
+
+func logf(format string, args ...interface{}) {
+	fmt.Fprintf(os.Stderr, format, args...)
+}
+
+func main() {
+	var (
+		flgRecorded   string
+		flgPages      string
+		flgIterations int
+		flgJSON       bool
+	)
+	flag.StringVar(&flgRecorded, "recorded", "", "directory with recorded pages. If not set, pages are downloaded from Notion")
+	flag.StringVar(&flgPages, "pages", "", "comma-separated list of page ids. Defaults to all recorded pages")
+	flag.IntVar(&flgIterations, "n", 10, "number of iterations")
+	flag.BoolVar(&flgJSON, "json", false, "if true, prints results as JSON")
+	flag.Parse()
+
+	var pageIDs []string
+	for _, id := range strings.Split(flgPages, ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			pageIDs = append(pageIDs, notionapi.ToNoDashID(id))
+		}
+	}
+
+	b := &bench{}
+	if flgRecorded != "" {
+		cache, err := caching_downloader.NewDirectoryCache(flgRecorded)
+		if err != nil {
+			logf("NewDirectoryCache('%s') failed with '%s'\n", flgRecorded, err)
+			os.Exit(1)
+		}
+		b.recorded = cache
+		if len(pageIDs) == 0 {
+			pageIDs, err = cache.GetPageIDs()
+			if err != nil {
+				logf("GetPageIDs() failed with '%s'\n", err)
+				os.Exit(1)
+			}
+		}
+	} else {
+		b.client = &notionapi.Client{
+			AuthToken: os.Getenv("NOTION_TOKEN"),
+		}
+	}
+	if len(pageIDs) == 0 {
+		logf("no pages to benchmark, use -pages or -recorded\n")
+		flag.Usage()
+		os.Exit(1)
+	}
+	if flgIterations < 1 {
+		flgIterations = 1
+	}
+
+	res, err := b.run(pageIDs, flgIterations)
+	if err != nil {
+		logf("%s\n", err)
+		os.Exit(1)
+	}
+	if flgJSON {
+		d, _ := json.MarshalIndent(res, "", "  ")
+		fmt.Printf("%s\n", d)
+		return
+	}
+	printResult(os.Stdout, res)
+}
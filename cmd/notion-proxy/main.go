@@ -0,0 +1,77 @@
+// notion-proxy is an HTTP server that exposes Notion pages and databases
+// through a small, read-only REST API:
+//
+//	GET /pages/{id}.html
+//	GET /pages/{id}.json
+//	GET /databases/{id}/rows?filter=Column:value&limit=N
+//	GET /openapi.json
+//
+// Usage:
+//
+//	NOTION_TOKEN=... notion-proxy -addr :8080 -api-key secret -pages id1,id2
+package main
+
+import (
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ninja-1/notionapi"
+)
+
+func logf(format string, args ...interface{}) {
+	fmt.Fprintf(os.Stderr, format, args...)
+}
+
+func main() {
+	var (
+		flgAddr     string
+		flgAPIKey   string
+		flgPages    string
+		flgCacheTTL time.Duration
+		flgMaxPages int
+		flgVerbose  bool
+	)
+	flag.StringVar(&flgAddr, "addr", "127.0.0.1:8080", "address to listen on")
+	flag.StringVar(&flgAPIKey, "api-key", os.Getenv("NOTION_PROXY_API_KEY"), "if set, clients must send it in X-API-Key header")
+	flag.StringVar(&flgPages, "pages", "", "comma-separated list of page ids that can be accessed. If empty, all pages the token can read are accessible")
+	flag.DurationVar(&flgCacheTTL, "cache-ttl", 5*time.Minute, "how long downloaded pages are cached")
+	flag.IntVar(&flgMaxPages, "max-cached-pages", 256, "maximum number of pages cached in memory")
+	flag.BoolVar(&flgVerbose, "verbose", false, "if true, logs Notion API calls")
+	flag.Parse()
+
+	client := &notionapi.Client{
+		AuthToken: os.Getenv("NOTION_TOKEN"),
+		DebugLog:  flgVerbose,
+		Logger:    os.Stderr,
+	}
+	s := newServer(client.DownloadPage)
+	s.apiKey = flgAPIKey
+	s.cacheTTL = flgCacheTTL
+	s.maxCachedPages = flgMaxPages
+	if flgPages != "" {
+		s.allowedPages = map[string]bool{}
+		for _, id := range strings.Split(flgPages, ",") {
+			id = notionapi.ToNoDashID(strings.TrimSpace(id))
+			s.allowedPages[id] = true
+		}
+	}
+
+	httpSrv := &http.Server{
+		Addr:              flgAddr,
+		Handler:           s,
+		ReadHeaderTimeout: 10 * time.Second,
+		ReadTimeout:       10 * time.Second,
+		WriteTimeout:      2 * time.Minute,
+		IdleTimeout:       2 * time.Minute,
+		MaxHeaderBytes:    64 * 1024,
+	}
+	logf("notion-proxy listening on %s\n", flgAddr)
+	if err := httpSrv.ListenAndServe(); err != nil {
+		logf("ListenAndServe() failed with '%s'\n", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,95 @@
+package main
+
+// openAPISpec describes the API served by notion-proxy
+const openAPISpec = `{
+  "openapi": "3.0.0",
+  "info": {
+    "title": "notion-proxy",
+    "description": "Read-only REST API for Notion pages and databases",
+    "version": "1.0.0"
+  },
+  "components": {
+    "securitySchemes": {
+      "apiKey": { "type": "apiKey", "in": "header", "name": "X-API-Key" }
+    },
+    "parameters": {
+      "id": {
+        "name": "id", "in": "path", "required": true,
+        "description": "id of the page, with or without dashes",
+        "schema": { "type": "string" }
+      }
+    },
+    "schemas": {
+      "Error": {
+        "type": "object",
+        "properties": { "error": { "type": "string" } }
+      },
+      "Block": {
+        "type": "object",
+        "properties": {
+          "id": { "type": "string" },
+          "type": { "type": "string" },
+          "text": { "type": "string" },
+          "content": { "type": "array", "items": { "$ref": "#/components/schemas/Block" } }
+        }
+      },
+      "Rows": {
+        "type": "object",
+        "properties": {
+          "rows": {
+            "type": "array",
+            "items": {
+              "type": "object",
+              "properties": {
+                "id": { "type": "string" },
+                "properties": { "type": "object", "additionalProperties": { "type": "string" } }
+              }
+            }
+          }
+        }
+      }
+    }
+  },
+  "security": [ { "apiKey": [] } ],
+  "paths": {
+    "/pages/{id}.html": {
+      "get": {
+        "summary": "Page rendered as HTML",
+        "parameters": [ { "$ref": "#/components/parameters/id" } ],
+        "responses": {
+          "200": { "description": "HTML page", "content": { "text/html": {} } },
+          "404": { "description": "page not found" }
+        }
+      }
+    },
+    "/pages/{id}.json": {
+      "get": {
+        "summary": "Tree of blocks of a page",
+        "parameters": [ { "$ref": "#/components/parameters/id" } ],
+        "responses": {
+          "200": { "description": "root block", "content": { "application/json": { "schema": { "$ref": "#/components/schemas/Block" } } } },
+          "404": { "description": "page not found" }
+        }
+      }
+    },
+    "/databases/{id}/rows": {
+      "get": {
+        "summary": "Rows of a database",
+        "parameters": [
+          { "$ref": "#/components/parameters/id" },
+          {
+            "name": "filter", "in": "query",
+            "description": "Column:value, only rows where the column is equal to value (case-insensitive). Can be repeated",
+            "schema": { "type": "array", "items": { "type": "string" } }
+          },
+          { "name": "limit", "in": "query", "schema": { "type": "integer" } }
+        ],
+        "responses": {
+          "200": { "description": "rows", "content": { "application/json": { "schema": { "$ref": "#/components/schemas/Rows" } } } },
+          "400": { "description": "invalid filter or limit", "content": { "application/json": { "schema": { "$ref": "#/components/schemas/Error" } } } }
+        }
+      }
+    }
+  }
+}
+`
@@ -0,0 +1,331 @@
+package main
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ninja-1/notionapi"
+	"github.com/ninja-1/notionapi/tohtml"
+)
+
+type cachedPage struct {
+	page      *notionapi.Page
+	fetchedAt time.Time
+}
+
+// pageCall is a download of a page in progress. Concurrent requests for
+// the same page wait for it instead of downloading the page again
+type pageCall struct {
+	wg   sync.WaitGroup
+	page *notionapi.Page
+	err  error
+}
+
+// server is a read-only REST facade over Notion pages and databases
+type server struct {
+	// downloadPage is usually notionapi.Client.DownloadPage
+	downloadPage func(pageID string) (*notionapi.Page, error)
+	// how long a downloaded page is served from memory
+	cacheTTL time.Duration
+	// if not empty, requests must send it as "Authorization: Bearer ${apiKey}"
+	// or "X-API-Key" header
+	apiKey string
+	// if not empty, only those pages (in no-dash format) can be accessed
+	allowedPages map[string]bool
+	// maximum number of pages cached in memory. When full, the page
+	// fetched the longest time ago is evicted
+	maxCachedPages int
+
+	mu       sync.Mutex
+	pages    map[string]*cachedPage
+	inflight map[string]*pageCall
+}
+
+func newServer(downloadPage func(string) (*notionapi.Page, error)) *server {
+	return &server{
+		downloadPage:   downloadPage,
+		cacheTTL:       5 * time.Minute,
+		maxCachedPages: 256,
+		pages:          map[string]*cachedPage{},
+		inflight:       map[string]*pageCall{},
+	}
+}
+
+// getPage returns a page from memory cache or downloads it. The lock
+// is not held while downloading
+func (s *server) getPage(pageID string) (*notionapi.Page, error) {
+	s.mu.Lock()
+	if cp := s.pages[pageID]; cp != nil && time.Since(cp.fetchedAt) < s.cacheTTL {
+		s.mu.Unlock()
+		return cp.page, nil
+	}
+	if call := s.inflight[pageID]; call != nil {
+		s.mu.Unlock()
+		call.wg.Wait()
+		return call.page, call.err
+	}
+	call := &pageCall{}
+	call.wg.Add(1)
+	s.inflight[pageID] = call
+	s.mu.Unlock()
+
+	defer func() {
+		s.mu.Lock()
+		delete(s.inflight, pageID)
+		if call.err == nil && call.page != nil {
+			s.cachePage(pageID, call.page)
+		}
+		s.mu.Unlock()
+		call.wg.Done()
+	}()
+	call.page, call.err = s.downloadPage(pageID)
+	return call.page, call.err
+}
+
+// cachePage adds a page to the cache, evicting expired pages or
+// the oldest page if the cache is full. Must be called with s.mu held
+func (s *server) cachePage(pageID string, page *notionapi.Page) {
+	if s.maxCachedPages > 0 && len(s.pages) >= s.maxCachedPages {
+		oldestID := ""
+		var oldest time.Time
+		for id, cp := range s.pages {
+			if time.Since(cp.fetchedAt) >= s.cacheTTL {
+				delete(s.pages, id)
+				continue
+			}
+			if oldestID == "" || cp.fetchedAt.Before(oldest) {
+				oldestID, oldest = id, cp.fetchedAt
+			}
+		}
+		if len(s.pages) >= s.maxCachedPages {
+			delete(s.pages, oldestID)
+		}
+	}
+	s.pages[pageID] = &cachedPage{page: page, fetchedAt: time.Now()}
+}
+
+func writeJSON(w http.ResponseWriter, code int, v interface{}) {
+	d, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(code)
+	_, _ = w.Write(d)
+}
+
+func writeError(w http.ResponseWriter, code int, msg string) {
+	writeJSON(w, code, map[string]string{"error": msg})
+}
+
+func (s *server) isAuthorized(r *http.Request) bool {
+	if s.apiKey == "" {
+		return true
+	}
+	key := r.Header.Get("X-API-Key")
+	if key == "" {
+		key = strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	}
+	return subtle.ConstantTimeCompare([]byte(key), []byte(s.apiKey)) == 1
+}
+
+// parsePageID validates id from the url and returns it in no-dash format
+func (s *server) parsePageID(id string) (string, int) {
+	if notionapi.IsValidDashID(id) {
+		id = notionapi.ToNoDashID(id)
+	}
+	if !notionapi.IsValidNoDashID(id) {
+		return "", http.StatusBadRequest
+	}
+	if len(s.allowedPages) > 0 && !s.allowedPages[id] {
+		return "", http.StatusForbidden
+	}
+	return id, 0
+}
+
+func (s *server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		writeError(w, http.StatusMethodNotAllowed, "only GET is supported")
+		return
+	}
+	path := r.URL.Path
+	if path == "/openapi.json" {
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		_, _ = w.Write([]byte(openAPISpec))
+		return
+	}
+	if !s.isAuthorized(r) {
+		writeError(w, http.StatusUnauthorized, "invalid or missing API key")
+		return
+	}
+
+	switch {
+	case strings.HasPrefix(path, "/pages/"):
+		name := strings.TrimPrefix(path, "/pages/")
+		ext := ""
+		if idx := strings.LastIndex(name, "."); idx > 0 {
+			name, ext = name[:idx], name[idx:]
+		}
+		if ext != ".html" && ext != ".json" {
+			writeError(w, http.StatusNotFound, "expected /pages/{id}.html or /pages/{id}.json")
+			return
+		}
+		s.handlePage(w, name, ext)
+	case strings.HasPrefix(path, "/databases/") && strings.HasSuffix(path, "/rows"):
+		id := strings.TrimSuffix(strings.TrimPrefix(path, "/databases/"), "/rows")
+		s.handleRows(w, r, id)
+	default:
+		writeError(w, http.StatusNotFound, "not found")
+	}
+}
+
+func (s *server) loadPage(w http.ResponseWriter, id string) *notionapi.Page {
+	id, code := s.parsePageID(id)
+	if code != 0 {
+		writeError(w, code, http.StatusText(code))
+		return nil
+	}
+	page, err := s.getPage(id)
+	if err != nil {
+		if notionapi.IsErrPageNotFound(err) {
+			writeError(w, http.StatusNotFound, "page not found")
+			return nil
+		}
+		logf("downloading page %s failed with '%s'\n", id, err)
+		writeError(w, http.StatusBadGateway, "failed to download page from Notion")
+		return nil
+	}
+	return page
+}
+
+// blockNode is JSON representation of a block
+type blockNode struct {
+	ID      string       `json:"id"`
+	Type    string       `json:"type"`
+	Text    string       `json:"text,omitempty"`
+	Content []*blockNode `json:"content,omitempty"`
+}
+
+func toBlockNode(block *notionapi.Block) *blockNode {
+	res := &blockNode{
+		ID:   block.ID,
+		Type: block.Type,
+		Text: block.Title,
+	}
+	if res.Text == "" {
+		res.Text = notionapi.TextSpansToString(block.InlineContent)
+	}
+	// don't descend into sub-pages, they're separate resources
+	if block.Type == notionapi.BlockPage && block.Parent != nil {
+		return res
+	}
+	for _, child := range block.Content {
+		res.Content = append(res.Content, toBlockNode(child))
+	}
+	return res
+}
+
+func (s *server) handlePage(w http.ResponseWriter, id string, ext string) {
+	page := s.loadPage(w, id)
+	if page == nil {
+		return
+	}
+	if ext == ".json" {
+		writeJSON(w, http.StatusOK, toBlockNode(page.Root()))
+		return
+	}
+	c := tohtml.NewConverter(page)
+	c.FullHTML = true
+	html, err := c.ToHTML()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	_, _ = w.Write(html)
+}
+
+// rowFilter is parsed "filter=Column:value" query argument
+type rowFilter struct {
+	col   int
+	value string
+}
+
+func parseRowFilters(tv *notionapi.TableView, args []string) ([]rowFilter, string) {
+	var res []rowFilter
+	for _, arg := range args {
+		idx := strings.Index(arg, ":")
+		if idx <= 0 {
+			return nil, "filter must be in Column:value format"
+		}
+		name, value := arg[:idx], arg[idx+1:]
+		col := tv.ColumnIndexByName(name)
+		if col < 0 {
+			return nil, "unknown column '" + name + "'"
+		}
+		res = append(res, rowFilter{col: col, value: value})
+	}
+	return res, ""
+}
+
+func (s *server) handleRows(w http.ResponseWriter, r *http.Request, id string) {
+	page := s.loadPage(w, id)
+	if page == nil {
+		return
+	}
+	if len(page.TableViews) == 0 {
+		writeError(w, http.StatusNotFound, "page is not a database")
+		return
+	}
+	tv := page.TableViews[0]
+	q := r.URL.Query()
+	filters, errMsg := parseRowFilters(tv, q["filter"])
+	if errMsg != "" {
+		writeError(w, http.StatusBadRequest, errMsg)
+		return
+	}
+	limit := -1
+	if v := q.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			writeError(w, http.StatusBadRequest, "invalid limit")
+			return
+		}
+		limit = n
+	}
+
+	rows := []map[string]interface{}{}
+	for row, tr := range tv.Rows {
+		if limit >= 0 && len(rows) >= limit {
+			break
+		}
+		matches := true
+		for _, f := range filters {
+			if !strings.EqualFold(tv.CellText(row, f.col), f.value) {
+				matches = false
+				break
+			}
+		}
+		if !matches {
+			continue
+		}
+		props := map[string]string{}
+		for col, ci := range tv.Columns {
+			props[ci.Name()] = notionapi.TextSpansToString(tv.CellContent(row, col))
+		}
+		rows = append(rows, map[string]interface{}{
+			"id":         tr.Page.ID,
+			"properties": props,
+		})
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"rows": rows,
+	})
+}
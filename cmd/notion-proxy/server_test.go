@@ -0,0 +1,122 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ninja-1/notionapi"
+	"github.com/ninja-1/notionapi/caching_downloader"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestServer(t *testing.T) *server {
+	d := caching_downloader.NewTestDownloader(t)
+	return newServer(d.DownloadPage)
+}
+
+func doGet(s *server, uri string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, httptest.NewRequest("GET", uri, nil))
+	return w
+}
+
+func TestServer(t *testing.T) {
+	s := newTestServer(t)
+
+	w := doGet(s, "/pages/6682351e44bb4f9ca0e149b703265bdb.html")
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Contains(t, w.Body.String(), "<title>Test headers</title>")
+
+	w = doGet(s, "/pages/6682351e-44bb-4f9c-a0e1-49b703265bdb.json")
+	require.Equal(t, http.StatusOK, w.Code)
+	var node blockNode
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &node))
+	require.Equal(t, 6, len(node.Content))
+
+	w = doGet(s, "/databases/94167af6567043279811dc923edd1f04/rows?limit=2")
+	require.Equal(t, http.StatusOK, w.Code)
+	var rsp struct {
+		Rows []map[string]interface{} `json:"rows"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &rsp))
+	require.Equal(t, 2, len(rsp.Rows))
+
+	w = doGet(s, "/databases/94167af6567043279811dc923edd1f04/rows?filter=nosuchcolumn:x")
+	require.Equal(t, http.StatusBadRequest, w.Code)
+	w = doGet(s, "/pages/not-an-id.html")
+	require.Equal(t, http.StatusBadRequest, w.Code)
+
+	s.apiKey = "secret"
+	w = doGet(s, "/pages/6682351e44bb4f9ca0e149b703265bdb.html")
+	require.Equal(t, http.StatusUnauthorized, w.Code)
+	s.allowedPages = map[string]bool{"94167af6567043279811dc923edd1f04": true}
+	w = httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "/pages/6682351e44bb4f9ca0e149b703265bdb.html", nil)
+	r.Header.Set("X-API-Key", "secret")
+	s.ServeHTTP(w, r)
+	require.Equal(t, http.StatusForbidden, w.Code)
+
+	w = doGet(s, "/openapi.json")
+	require.Equal(t, http.StatusOK, w.Code)
+	require.True(t, json.Valid(w.Body.Bytes()))
+}
+
+func TestGetPageDedupAndEviction(t *testing.T) {
+	var mu sync.Mutex
+	calls := 0
+	release := make(chan bool)
+	s := newServer(func(pageID string) (*notionapi.Page, error) {
+		mu.Lock()
+		calls++
+		mu.Unlock()
+		<-release
+		return &notionapi.Page{ID: pageID}, nil
+	})
+	s.maxCachedPages = 2
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			p, err := s.getPage("a")
+			require.NoError(t, err)
+			require.Equal(t, "a", p.ID)
+		}()
+	}
+	// wait for the download to start before releasing it
+	for {
+		mu.Lock()
+		n := calls
+		mu.Unlock()
+		if n > 0 {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	close(release)
+	wg.Wait()
+	require.Equal(t, 1, calls)
+
+	for _, id := range []string{"b", "c"} {
+		_, err := s.getPage(id)
+		require.NoError(t, err)
+	}
+	require.Equal(t, 2, len(s.pages))
+	require.Nil(t, s.pages["a"])
+}
+
+func TestIsAuthorized(t *testing.T) {
+	s := newServer(nil)
+	s.apiKey = "secret"
+	r := httptest.NewRequest("GET", "/pages/x.html", nil)
+	require.False(t, s.isAuthorized(r))
+	r.Header.Set("Authorization", "Bearer secret")
+	require.True(t, s.isAuthorized(r))
+	r.Header.Set("X-API-Key", "secre")
+	require.False(t, s.isAuthorized(r))
+}
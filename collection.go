@@ -1,6 +1,10 @@
 package notionapi
 
-import "fmt"
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
 
 const (
 	// TODO: those are probably CollectionViewType
@@ -8,6 +12,14 @@ const (
 	CollectionViewTypeTable = "table"
 	// CollectionViewTypeTable is a lists block
 	CollectionViewTypeList = "list"
+	// CollectionViewTypeBoard is a board block
+	CollectionViewTypeBoard = "board"
+	// CollectionViewTypeGallery is a gallery of cards
+	CollectionViewTypeGallery = "gallery"
+	// CollectionViewTypeCalendar is a calendar
+	CollectionViewTypeCalendar = "calendar"
+	// CollectionViewTypeTimeline is a timeline
+	CollectionViewTypeTimeline = "timeline"
 )
 
 // CollectionColumnOption describes options for ColumnTypeMultiSelect
@@ -113,13 +125,63 @@ type TableProperty struct {
 	Width    int    `json:"width"`
 	Visible  bool   `json:"visible"`
 	Property string `json:"property"`
+	// if true, content of the property wraps in table cells
+	Wrap bool `json:"wrap"`
 }
 
-// FormatTable describes format for BlockTable
+// FormatTable describes format of a collection view. Despite the name,
+// it's the format of all types of views. Properties shown by a view
+// are in a field for its type (see CollectionView.Properties)
 type FormatTable struct {
 	PageSort        []string         `json:"page_sort"`
 	TableWrap       bool             `json:"table_wrap"`
 	TableProperties []*TableProperty `json:"table_properties"`
+
+	// for boards, the property the rows are grouped by
+	BoardColumnsBy  *BoardColumnsBy  `json:"board_columns_by,omitempty"`
+	BoardProperties []*TableProperty `json:"board_properties,omitempty"`
+	BoardCover      *ViewCover       `json:"board_cover,omitempty"`
+	// "cover" or "contain"
+	BoardCoverAspect string `json:"board_cover_aspect,omitempty"`
+	// "small", "medium" or "large"
+	BoardCoverSize string `json:"board_cover_size,omitempty"`
+
+	GalleryProperties []*TableProperty `json:"gallery_properties,omitempty"`
+	GalleryCover      *ViewCover       `json:"gallery_cover,omitempty"`
+	// "cover" or "contain"
+	GalleryCoverAspect string `json:"gallery_cover_aspect,omitempty"`
+	// "small", "medium" or "large"
+	GalleryCoverSize string `json:"gallery_cover_size,omitempty"`
+
+	ListProperties []*TableProperty `json:"list_properties,omitempty"`
+
+	CalendarProperties []*TableProperty `json:"calendar_properties,omitempty"`
+	// id of the date property by which rows are shown in a calendar
+	// (see CollectionView.CalendarByProperty)
+	CalendarBy string `json:"calendar_by,omitempty"`
+
+	TimelineProperties []*TableProperty `json:"timeline_properties,omitempty"`
+	// id of the date property by which rows are shown in a timeline
+	TimelineBy string `json:"timeline_by,omitempty"`
+	// if true, a table is shown next to the timeline, with
+	// TimelineTableProperties
+	TimelineShowTable       bool             `json:"timeline_show_table,omitempty"`
+	TimelineTableProperties []*TableProperty `json:"timeline_table_properties,omitempty"`
+}
+
+// ViewCover describes what is shown at the top of cards
+// in galleries and boards
+type ViewCover struct {
+	// "page_cover", "page_content", "property" or "none"
+	Type string `json:"type"`
+	// for Type "property", id of a files property with the image
+	Property string `json:"property,omitempty"`
+}
+
+// BoardColumnsBy describes a property by which a board is grouped
+type BoardColumnsBy struct {
+	Type     string `json:"type"`
+	Property string `json:"property"`
 }
 
 // CollectionView represents a collection view
@@ -176,6 +238,78 @@ func (c *ColumnInfo) Name() string {
 	return c.Schema.Name
 }
 
+// GroupByProperty returns id of the property by which rows of the view
+// are grouped (columns of a board, grouped lists and tables)
+// or "" if the view is not grouped
+func (cv *CollectionView) GroupByProperty() string {
+	if cv.Format != nil && cv.Format.BoardColumnsBy != nil && cv.Format.BoardColumnsBy.Property != "" {
+		return cv.Format.BoardColumnsBy.Property
+	}
+	if cv.Query != nil {
+		if s, ok := cv.Query.GroupBy.(string); ok {
+			return s
+		}
+	}
+	return ""
+}
+
+// Properties returns properties shown by the view, from the field of
+// its format for the type of the view e.g. GalleryProperties for
+// galleries. Returns TableProperties if that field is empty
+func (cv *CollectionView) Properties() []*TableProperty {
+	f := cv.Format
+	if f == nil {
+		return nil
+	}
+	var res []*TableProperty
+	switch cv.Type {
+	case CollectionViewTypeBoard:
+		res = f.BoardProperties
+	case CollectionViewTypeGallery:
+		res = f.GalleryProperties
+	case CollectionViewTypeList:
+		res = f.ListProperties
+	case CollectionViewTypeCalendar:
+		res = f.CalendarProperties
+	case CollectionViewTypeTimeline:
+		res = f.TimelineProperties
+	}
+	if len(res) == 0 {
+		res = f.TableProperties
+	}
+	return res
+}
+
+// CalendarByProperty returns id of the date property by which rows
+// of a calendar or timeline view are laid out, or "" if not known.
+// Older views have it in the query
+func (cv *CollectionView) CalendarByProperty() string {
+	if f := cv.Format; f != nil {
+		if cv.Type == CollectionViewTypeTimeline && f.TimelineBy != "" {
+			return f.TimelineBy
+		}
+		if f.CalendarBy != "" {
+			return f.CalendarBy
+		}
+	}
+	if cv.Query != nil {
+		if s, ok := cv.Query.CalendarBy.(string); ok {
+			return s
+		}
+	}
+	return ""
+}
+
+// TableGroup is a group of rows of a grouped TableView with the same
+// value of the property the view is grouped by
+type TableGroup struct {
+	// "" for rows without a value
+	Value string
+	// option of select properties, can be nil
+	Option *CollectionColumnOption
+	Rows   []*TableRow
+}
+
 // TableView represents a view of a table (Notion calls it a Collection View)
 // Meant to be a representation that is easier to work with
 type TableView struct {
@@ -187,6 +321,13 @@ type TableView struct {
 	// easier to work representation we calculate
 	Columns []*ColumnInfo
 	Rows    []*TableRow
+	// number of all rows, can be bigger than len(Rows) if
+	// Client.MaxCollectionRows was set
+	Total int
+	// if the view is grouped, id of the property it's grouped by
+	// and the groups of rows
+	GroupBy string
+	Groups  []*TableGroup
 }
 
 func (t *TableView) RowCount() int {
@@ -201,6 +342,30 @@ func (t *TableView) CellContent(row, col int) []*TextSpan {
 	return t.Rows[row].Columns[col]
 }
 
+// ColumnIndexByName returns index of a column with a given name,
+// ignoring case, or -1 if there's no such column
+func (t *TableView) ColumnIndexByName(name string) int {
+	for i, col := range t.Columns {
+		if strings.EqualFold(col.Name(), name) {
+			return i
+		}
+	}
+	return -1
+}
+
+// CellText returns content of a cell as text. For dates it's the start
+// date. Returns "" if col is < 0 (e.g. from ColumnIndexByName)
+func (t *TableView) CellText(row, col int) string {
+	if col < 0 {
+		return ""
+	}
+	spans := t.CellContent(row, col)
+	if d := TextSpansDate(spans); d != nil {
+		return d.StartDate
+	}
+	return strings.TrimSpace(TextSpansToString(spans))
+}
+
 // TODO: some tables miss title column in TableProperties
 // maybe synthesize it if doesn't exist as a first column
 func (c *Client) buildTableView(tv *TableView, res *QueryCollectionResponse) error {
@@ -229,7 +394,7 @@ func (c *Client) buildTableView(tv *TableView, res *QueryCollectionResponse) err
 	}
 
 	idx := 0
-	for _, prop := range cv.Format.TableProperties {
+	for _, prop := range cv.Properties() {
 		if !prop.Visible {
 			continue
 		}
@@ -263,6 +428,9 @@ func (c *Client) buildTableView(tv *TableView, res *QueryCollectionResponse) err
 		tv.Rows = append(tv.Rows, tr)
 	}
 
+	nLoaded := len(tv.Rows)
+	tv.applyViewQuery()
+
 	// pre-calculate cell content
 	for _, tr := range tv.Rows {
 		for _, ci := range tv.Columns {
@@ -271,5 +439,147 @@ func (c *Client) buildTableView(tv *TableView, res *QueryCollectionResponse) err
 			tr.Columns = append(tr.Columns, v)
 		}
 	}
+	// rows we filtered out are not in the view
+	tv.Total = res.Result.Total - (nLoaded - len(tv.Rows))
+	if tv.Total < len(tv.Rows) {
+		tv.Total = len(tv.Rows)
+	}
+	tv.buildGroups(res.Result.GroupResults)
 	return nil
 }
+
+// groupValue returns value of GroupResult.Value which is
+// either a string or e.g. {"type": "select", "value": "Done"}.
+// Values can also be numbers, checkboxes or nested objects
+// like {"type": "date", "value": {"start_date": "2021-01-02"}}
+func groupValue(v interface{}) string {
+	switch vt := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return vt
+	case float64:
+		return strconv.FormatFloat(vt, 'f', -1, 64)
+	case bool:
+		if vt {
+			return "Yes"
+		}
+		return "No"
+	case map[string]interface{}:
+		if val, ok := vt["value"]; ok {
+			return groupValue(val)
+		}
+		if s, ok := vt["start_date"].(string); ok {
+			return s
+		}
+	}
+	return fmt.Sprintf("%v", v)
+}
+
+func (t *TableView) findGroup(value string) *TableGroup {
+	for _, g := range t.Groups {
+		if g.Value == value {
+			return g
+		}
+	}
+	return nil
+}
+
+func (t *TableView) addGroup(value string) *TableGroup {
+	g := &TableGroup{
+		Value: value,
+	}
+	if schema := t.Collection.Schema[t.GroupBy]; schema != nil {
+		for _, opt := range schema.Options {
+			if opt.Value == value {
+				g.Option = opt
+				break
+			}
+		}
+	}
+	t.Groups = append(t.Groups, g)
+	return g
+}
+
+// noValueGroup returns the group of rows without a value ("No value"
+// in Notion), creating it as the first group if needed
+func (t *TableView) noValueGroup() *TableGroup {
+	if g := t.findGroup(""); g != nil {
+		return g
+	}
+	g := t.addGroup("")
+	t.Groups = append([]*TableGroup{g}, t.Groups[:len(t.Groups)-1]...)
+	return g
+}
+
+// buildGroups groups rows of a grouped view. We use groups returned
+// by the server if there are any. Otherwise we group rows ourselves,
+// ordered like Notion: rows without a value first, then in the order
+// of options of the property. Rows of multi-select properties are in
+// the group of each of their options
+func (t *TableView) buildGroups(groupResults []*GroupResult) {
+	t.GroupBy = t.CollectionView.GroupByProperty()
+	if t.GroupBy == "" {
+		return
+	}
+	rowByID := map[string]*TableRow{}
+	for _, tr := range t.Rows {
+		rowByID[tr.Page.ID] = tr
+	}
+	if len(groupResults) > 0 {
+		grouped := map[string]bool{}
+		for _, gr := range groupResults {
+			value := groupValue(gr.Value)
+			g := t.findGroup(value)
+			if g == nil {
+				g = t.addGroup(value)
+			}
+			for _, id := range gr.BlockIDS {
+				if tr := rowByID[id]; tr != nil {
+					g.Rows = append(g.Rows, tr)
+					grouped[id] = true
+				}
+			}
+		}
+		// rows the server didn't put in any group
+		for _, tr := range t.Rows {
+			if !grouped[tr.Page.ID] {
+				g := t.noValueGroup()
+				g.Rows = append(g.Rows, tr)
+			}
+		}
+		return
+	}
+
+	t.addGroup("")
+	if schema := t.Collection.Schema[t.GroupBy]; schema != nil {
+		for _, opt := range schema.Options {
+			t.addGroup(opt.Value)
+		}
+	}
+	typ := ""
+	if schema := t.Collection.Schema[t.GroupBy]; schema != nil {
+		typ = schema.Type
+	}
+	for _, tr := range t.Rows {
+		values := rollupValues(typ, TextSpansToString(tr.Page.GetProperty(t.GroupBy)))
+		if len(values) == 0 {
+			values = []string{""}
+		}
+		for _, value := range values {
+			g := t.findGroup(value)
+			if g == nil {
+				g = t.addGroup(value)
+			}
+			g.Rows = append(g.Rows, tr)
+		}
+	}
+	// like Notion, don't show empty groups
+	var groups []*TableGroup
+	for _, g := range t.Groups {
+		if len(g.Rows) > 0 {
+			groups = append(groups, g)
+		}
+	}
+	t.Groups = groups
+}
@@ -0,0 +1,114 @@
+package notionapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTableViewColumnHelpers(t *testing.T) {
+	tv := &TableView{
+		Columns: []*ColumnInfo{
+			{Index: 0, Schema: &ColumnSchema{Name: "Name"}},
+			{Index: 1, Schema: &ColumnSchema{Name: "Date"}},
+		},
+	}
+	assert.Equal(t, 1, tv.ColumnIndexByName("date"))
+	assert.Equal(t, -1, tv.ColumnIndexByName("Version"))
+
+	date := &TextSpan{Text: "‣", Attrs: [][]string{{AttrDate, `{"type":"date","start_date":"2020-05-01"}`}}}
+	tv.Rows = append(tv.Rows, &TableRow{Columns: [][]*TextSpan{{{Text: " Release "}}, {date}}})
+	assert.Equal(t, "Release", tv.CellText(0, 0))
+	assert.Equal(t, "2020-05-01", tv.CellText(0, 1))
+	assert.Equal(t, "", tv.CellText(0, -1))
+}
+
+func TestTableViewBuildGroups(t *testing.T) {
+	schema := &ColumnSchema{
+		Name:    "Status",
+		Type:    ColumnTypeSelect,
+		Options: []*CollectionColumnOption{{Value: "Todo"}, {Value: "Done"}, {Value: "Unused"}},
+	}
+	tv := &TableView{
+		CollectionView: &CollectionView{
+			Format: &FormatTable{BoardColumnsBy: &BoardColumnsBy{Property: "stat"}},
+		},
+		Collection: &Collection{Schema: map[string]*ColumnSchema{"stat": schema}},
+	}
+	for i, v := range []string{"Done", "", "Todo", "Done"} {
+		props := map[string]interface{}{}
+		if v != "" {
+			props["stat"] = textValue(v)
+		}
+		row := &Block{ID: fmt.Sprintf("r%d", i), Properties: props}
+		tv.Rows = append(tv.Rows, &TableRow{TableView: tv, Page: row})
+	}
+	tv.buildGroups(nil)
+	assert.Equal(t, "stat", tv.GroupBy)
+	var values []string
+	for _, g := range tv.Groups {
+		values = append(values, fmt.Sprintf("%s:%d", g.Value, len(g.Rows)))
+	}
+	assert.Equal(t, []string{":1", "Todo:1", "Done:2"}, values)
+	assert.Equal(t, schema.Options[1], tv.Groups[2].Option)
+
+	tv.Groups = nil
+	groupResults := []*GroupResult{
+		{Value: map[string]interface{}{"type": "select", "value": "Done"}, BlockIDS: []string{"r3"}},
+	}
+	tv.buildGroups(groupResults)
+	assert.Equal(t, 2, len(tv.Groups))
+	// rows not in any group returned by the server have no value
+	assert.Equal(t, "", tv.Groups[0].Value)
+	assert.Equal(t, 3, len(tv.Groups[0].Rows))
+	assert.Equal(t, "Done", tv.Groups[1].Value)
+	assert.Equal(t, "r3", tv.Groups[1].Rows[0].Page.ID)
+
+	// multi-select rows are in the group of each option
+	schema.Type = ColumnTypeMultiSelect
+	tv.Rows[0].Page.Properties["stat"] = textValue("Todo,Done")
+	tv.Groups = nil
+	tv.buildGroups(nil)
+	values = nil
+	for _, g := range tv.Groups {
+		values = append(values, fmt.Sprintf("%s:%d", g.Value, len(g.Rows)))
+	}
+	assert.Equal(t, []string{":1", "Todo:2", "Done:2"}, values)
+}
+
+func TestGroupValue(t *testing.T) {
+	assert.Equal(t, "", groupValue(nil))
+	assert.Equal(t, "Done", groupValue(map[string]interface{}{"type": "select", "value": "Done"}))
+	assert.Equal(t, "3.5", groupValue(map[string]interface{}{"type": "number", "value": 3.5}))
+	assert.Equal(t, "Yes", groupValue(map[string]interface{}{"type": "checkbox", "value": true}))
+	date := map[string]interface{}{"type": "date", "value": map[string]interface{}{"start_date": "2021-01-02"}}
+	assert.Equal(t, "2021-01-02", groupValue(date))
+}
+
+func TestCollectionViewFormat(t *testing.T) {
+	js := `{"id":"v1","type":"gallery","format":{
+		"gallery_cover":{"type":"property","property":"img"},
+		"gallery_cover_aspect":"contain","gallery_cover_size":"large",
+		"gallery_properties":[{"property":"title","visible":true},{"property":"tags","visible":false}],
+		"table_properties":[{"property":"title","visible":true,"width":200,"wrap":true}]}}`
+	var cv CollectionView
+	assert.NoError(t, json.Unmarshal([]byte(js), &cv))
+	assert.Equal(t, &ViewCover{Type: "property", Property: "img"}, cv.Format.GalleryCover)
+	assert.Equal(t, "large", cv.Format.GalleryCoverSize)
+	props := cv.Properties()
+	assert.Equal(t, 2, len(props))
+	assert.Equal(t, "tags", props[1].Property)
+
+	cv.Type = CollectionViewTypeTable
+	assert.Equal(t, 200, cv.Properties()[0].Width)
+	assert.True(t, cv.Properties()[0].Wrap)
+
+	js = `{"id":"v2","type":"calendar","format":{},"query":{"calendar_by":"date"}}`
+	cv = CollectionView{}
+	assert.NoError(t, json.Unmarshal([]byte(js), &cv))
+	assert.Equal(t, "date", cv.CalendarByProperty())
+	cv.Format.CalendarBy = "due"
+	assert.Equal(t, "due", cv.CalendarByProperty())
+}
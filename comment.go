@@ -1,5 +1,11 @@
 package notionapi
 
+import (
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
 // Comment describes a single comment in a discussion
 type Comment struct {
 	ID             string      `json:"id"`
@@ -15,3 +21,71 @@ type Comment struct {
 	// set by us
 	RawJSON map[string]interface{} `json:"-"`
 }
+
+// GetText returns text of the comment as text spans
+func (c *Comment) GetText() []*TextSpan {
+	spans, _ := ParseTextSpans(c.Text)
+	return spans
+}
+
+// addCommentOps returns operations that create a discussion on a block
+// with a single comment
+func addCommentOps(userID string, blockID string, text string) (*Discussion, []*Operation) {
+	now := Now()
+	disc := &Discussion{
+		ID:          uuid.New().String(),
+		Version:     1,
+		ParentID:    ToDashID(blockID),
+		ParentTable: TableBlock,
+		Comments:    []string{uuid.New().String()},
+	}
+	comment := &Comment{
+		ID:             disc.Comments[0],
+		Version:        1,
+		Alive:          true,
+		ParentID:       disc.ID,
+		ParentTable:    TableDiscussion,
+		CreatedBy:      userID,
+		CreatedTime:    now,
+		Text:           textValue(text),
+		LastEditedTime: now,
+	}
+	block := &Block{ID: disc.ParentID}
+	ops := []*Operation{
+		{
+			ID:      disc.ID,
+			Table:   TableDiscussion,
+			Path:    []string{},
+			Command: CommandSet,
+			Args:    disc,
+		},
+		{
+			ID:      comment.ID,
+			Table:   TableComment,
+			Path:    []string{},
+			Command: CommandSet,
+			Args:    comment,
+		},
+		block.buildOp(CommandListAfter, []string{"discussions"}, map[string]string{
+			"id": disc.ID,
+		}),
+	}
+	return disc, ops
+}
+
+// AddComment starts a discussion on a block with a comment with a given
+// text, like commenting on a block in Notion. Returns the new discussion
+func (c *Client) AddComment(blockID string, text string) (*Discussion, error) {
+	if text == "" {
+		return nil, fmt.Errorf("comment can't be empty")
+	}
+	userID, err := c.currentUserID()
+	if err != nil {
+		return nil, err
+	}
+	disc, ops := addCommentOps(userID, blockID, text)
+	if err = c.SubmitTransaction(ops); err != nil {
+		return nil, err
+	}
+	return disc, nil
+}
@@ -0,0 +1,21 @@
+package notionapi
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAddCommentOps(t *testing.T) {
+	disc, ops := addCommentOps("user1", "6682351e44bb4f9ca0e149b703265bdb", "Typo here")
+	assert.Equal(t, "6682351e-44bb-4f9c-a0e1-49b703265bdb", disc.ParentID)
+	assert.Len(t, ops, 3)
+	assert.Equal(t, TableDiscussion, ops[0].Table)
+	comment := ops[1].Args.(*Comment)
+	assert.Equal(t, disc.Comments[0], comment.ID)
+	assert.Equal(t, disc.ID, comment.ParentID)
+	assert.Equal(t, "user1", comment.CreatedBy)
+	assert.Equal(t, "Typo here", TextSpansToString(comment.GetText()))
+	assert.Equal(t, disc.ParentID, ops[2].ID)
+	assert.Equal(t, []string{"discussions"}, ops[2].Path)
+}
@@ -0,0 +1,224 @@
+package notionapi
+
+import (
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// colors of select options, assigned in order if SelectOption.Color is empty
+var selectOptionColors = []string{"default", "gray", "brown", "orange", "yellow", "green", "blue", "purple", "pink", "red"}
+
+// SelectOption is an option of ColumnTypeSelect or ColumnTypeMultiSelect property
+type SelectOption struct {
+	Value string
+	// e.g. "red". If empty, we pick one
+	Color string
+}
+
+// SchemaProperty describes a property (column) of a new collection
+type SchemaProperty struct {
+	Name string
+	// ColumnTypeText etc.
+	Type string
+	// for ColumnTypeSelect and ColumnTypeMultiSelect
+	Options []*SelectOption
+	// for ColumnTypeNumber e.g. "dollar", "number"
+	NumberFormat string
+}
+
+// Schema describes a new collection (database)
+type Schema struct {
+	// Title is the name of the collection
+	Title string
+	// Properties are in the order they are shown. If there's no property
+	// of type ColumnTypeTitle, "Name" is added as the first property
+	Properties []*SchemaProperty
+}
+
+// ViewSpec describes a view of a new collection
+type ViewSpec struct {
+	Name string
+	// CollectionViewTypeTable (the default), CollectionViewTypeList etc.
+	Type string
+	// names of properties visible in the view. If empty, all are visible
+	Properties []string
+}
+
+// ToColumnSchema converts a property to ColumnSchema
+func (p *SchemaProperty) ToColumnSchema() *ColumnSchema {
+	res := &ColumnSchema{
+		Name:         p.Name,
+		Type:         p.Type,
+		NumberFormat: p.NumberFormat,
+	}
+	for i, opt := range p.Options {
+		color := opt.Color
+		if color == "" {
+			color = selectOptionColors[i%len(selectOptionColors)]
+		}
+		o := &CollectionColumnOption{
+			ID:    uuid.New().String(),
+			Value: opt.Value,
+			Color: color,
+		}
+		res.Options = append(res.Options, o)
+	}
+	return res
+}
+
+// NewPropertyID returns a random id for a new collection property
+func NewPropertyID() string {
+	return uuid.New().String()[:4]
+}
+
+// buildCollectionSchema returns schema and ids of properties in schema order
+func buildCollectionSchema(s *Schema) (map[string]*ColumnSchema, []string) {
+	schema := map[string]*ColumnSchema{}
+	var order []string
+	hasTitle := false
+	for _, p := range s.Properties {
+		id := NewPropertyID()
+		if p.Type == ColumnTypeTitle && !hasTitle {
+			id = "title"
+			hasTitle = true
+		}
+		schema[id] = p.ToColumnSchema()
+		order = append(order, id)
+	}
+	if !hasTitle {
+		schema["title"] = &ColumnSchema{Name: "Name", Type: ColumnTypeTitle}
+		order = append([]string{"title"}, order...)
+	}
+	return schema, order
+}
+
+func collectionViewOp(viewID string, parentID string, view ViewSpec, schema map[string]*ColumnSchema, order []string) *Operation {
+	visible := map[string]bool{}
+	for _, name := range view.Properties {
+		visible[name] = true
+	}
+	var props []map[string]interface{}
+	for _, id := range order {
+		props = append(props, map[string]interface{}{
+			"property": id,
+			"visible":  len(visible) == 0 || visible[schema[id].Name],
+		})
+	}
+	viewType := view.Type
+	if viewType == "" {
+		viewType = CollectionViewTypeTable
+	}
+	name := view.Name
+	if name == "" {
+		name = "Default view"
+	}
+	return &Operation{
+		ID:      viewID,
+		Table:   TableCollectionView,
+		Path:    []string{},
+		Command: CommandSet,
+		Args: map[string]interface{}{
+			"id":           viewID,
+			"version":      1,
+			"type":         viewType,
+			"name":         name,
+			"parent_id":    parentID,
+			"parent_table": TableBlock,
+			"alive":        true,
+			"format": map[string]interface{}{
+				viewType + "_properties": props,
+			},
+		},
+	}
+}
+
+// CreateCollectionOps returns operations that create a full-page collection
+// as a child of parent, after afterID (at the end if empty).
+// If views is empty, a default table view is created.
+// Returns the new collection_view_page block
+func (c *Client) CreateCollectionOps(userID string, parent *Block, afterID string, schema *Schema, views []ViewSpec) (*Block, []*Operation) {
+	block, op := c.SetNewRecordOp(userID, parent, BlockCollectionViewPage)
+	if len(views) == 0 {
+		views = []ViewSpec{{}}
+	}
+	block.CollectionID = uuid.New().String()
+	for range views {
+		block.ViewIDs = append(block.ViewIDs, uuid.New().String())
+	}
+
+	colSchema, order := buildCollectionSchema(schema)
+	ops := []*Operation{
+		op,
+		{
+			ID:      block.CollectionID,
+			Table:   TableCollection,
+			Path:    []string{},
+			Command: CommandSet,
+			Args: map[string]interface{}{
+				"id":           block.CollectionID,
+				"name":         [][]string{{schema.Title}},
+				"schema":       colSchema,
+				"parent_id":    block.ID,
+				"parent_table": TableBlock,
+				"alive":        true,
+			},
+		},
+	}
+	for i, view := range views {
+		ops = append(ops, collectionViewOp(block.ViewIDs[i], block.ID, view, colSchema, order))
+	}
+	ops = append(ops, parent.ListAfterContentOp(block.ID, afterID))
+	return block, ops
+}
+
+// currentUserID returns id of the user whose AuthToken we use
+func (c *Client) currentUserID() (string, error) {
+	user, err := c.GetMe()
+	if err != nil {
+		return "", err
+	}
+	return user.ID, nil
+}
+
+// CreateCollection creates a full-page collection (database) at the end of
+// a page. Returns the new collection_view_page block. Its CollectionID
+// and ViewIDs are ids of the new collection and its views
+func (c *Client) CreateCollection(parentPageID string, schema Schema, views []ViewSpec) (*Block, error) {
+	// "Name" is added if there's no title property
+	titleName := "Name"
+	for _, p := range schema.Properties {
+		if p.Type == ColumnTypeTitle {
+			titleName = p.Name
+			break
+		}
+	}
+	for _, view := range views {
+		for _, name := range view.Properties {
+			found := name == titleName
+			for _, p := range schema.Properties {
+				found = found || p.Name == name
+			}
+			if !found {
+				return nil, fmt.Errorf("CreateCollection: view '%s' has unknown property '%s'", view.Name, name)
+			}
+		}
+	}
+	parent, err := c.getBlock(parentPageID)
+	if err != nil {
+		return nil, err
+	}
+	userID, err := c.currentUserID()
+	if err != nil {
+		return nil, err
+	}
+	afterID := ""
+	if n := len(parent.ContentIDs); n > 0 {
+		afterID = parent.ContentIDs[n-1]
+	}
+	block, ops := c.CreateCollectionOps(userID, parent, afterID, &schema, views)
+	if err = c.SubmitTransaction(ops); err != nil {
+		return nil, err
+	}
+	return block, nil
+}
@@ -0,0 +1,39 @@
+package notionapi
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildCollectionSchema(t *testing.T) {
+	s := &Schema{
+		Properties: []*SchemaProperty{
+			{Name: "Status", Type: ColumnTypeSelect, Options: []*SelectOption{{Value: "a"}, {Value: "b"}, {Value: "c", Color: "red"}}},
+		},
+	}
+	schema, order := buildCollectionSchema(s)
+	assert.Equal(t, 2, len(order))
+	assert.Equal(t, "title", order[0])
+	opts := schema[order[1]].Options
+	assert.Equal(t, "gray", opts[1].Color)
+	assert.Equal(t, "red", opts[2].Color)
+
+	c := &Client{}
+	parent := &Block{ID: "parent"}
+	views := []ViewSpec{{Name: "All"}, {Name: "Board", Type: "board", Properties: []string{"Status"}}}
+	block, ops := c.CreateCollectionOps("user", parent, "", s, views)
+	assert.Equal(t, 2, len(block.ViewIDs))
+	// block, collection, 2 views, list in parent
+	assert.Equal(t, 5, len(ops))
+	assert.Equal(t, TableCollectionView, ops[3].Table)
+	format := ops[3].Args.(map[string]interface{})["format"].(map[string]interface{})
+	props := format["board_properties"].([]map[string]interface{})
+	assert.Equal(t, false, props[0]["visible"])
+	assert.Equal(t, true, props[1]["visible"])
+
+	// "Name" is only added if there's no title property
+	s.Properties = append(s.Properties, &SchemaProperty{Name: "Title", Type: ColumnTypeTitle})
+	_, err := c.CreateCollection("parent", *s, []ViewSpec{{Name: "All", Properties: []string{"Name"}}})
+	assert.Error(t, err)
+}
@@ -35,21 +35,106 @@ type Reminder struct {
 }
 
 // parseNotionDateTime parses date and time as sent in JSON by notion
-// server and returns time.Time
+// server and returns time.Time in a given location
 // date is sent in "2019-04-09" format
 // time is optional and sent in "00:35" format
-func parseNotionDateTime(date string, t string) time.Time {
+func parseNotionDateTime(date string, t string, loc *time.Location) (time.Time, error) {
 	s := date
 	fmt := "2006-01-02"
 	if t != "" {
 		fmt += " 15:04"
 		s += " " + t
 	}
-	dt, err := time.Parse(fmt, s)
+	return time.ParseInLocation(fmt, s, loc)
+}
+
+// Location returns the time zone of the date. It's UTC if the date
+// has no time zone or it's not known
+func (d *Date) Location() *time.Location {
+	if d.TimeZone == nil || *d.TimeZone == "" {
+		return time.UTC
+	}
+	loc, err := time.LoadLocation(*d.TimeZone)
 	if err != nil {
-		MaybePanic("time.Parse('%s', '%s') failed with %s", fmt, s, err)
+		return time.UTC
 	}
-	return dt
+	return loc
+}
+
+// HasTime returns true if the date has time and not only a day
+func (d *Date) HasTime() bool {
+	return d.StartTime != "" || strings.HasPrefix(d.Type, "datetime")
+}
+
+// IsRange returns true if the date has an end
+func (d *Date) IsRange() bool {
+	return strings.HasSuffix(d.Type, "range") || d.EndDate != ""
+}
+
+// Start returns start of the date in its time zone (see Location).
+// It's zero time if the date is invalid
+func (d *Date) Start() time.Time {
+	t, err := parseNotionDateTime(d.StartDate, d.StartTime, d.Location())
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
+
+// End returns end of a date range or Start if the date is not a range
+func (d *Date) End() time.Time {
+	if !d.IsRange() || d.EndDate == "" {
+		return d.Start()
+	}
+	t, err := parseNotionDateTime(d.EndDate, d.EndTime, d.Location())
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
+
+// ReminderTime returns when a reminder of the date is due. Returns false
+// if the date has no reminder or we don't understand it
+func (d *Date) ReminderTime() (time.Time, bool) {
+	r := d.Reminder
+	start := d.Start()
+	if r == nil || start.IsZero() {
+		return time.Time{}, false
+	}
+	if r.Time != "" {
+		// reminders of dates without time are at a time of a day
+		t, err := parseNotionDateTime(d.StartDate, r.Time, d.Location())
+		if err != nil {
+			return time.Time{}, false
+		}
+		start = t
+	}
+	n := int(r.Value)
+	switch r.Unit {
+	case "minute":
+		return start.Add(-time.Duration(n) * time.Minute), true
+	case "hour":
+		return start.Add(-time.Duration(n) * time.Hour), true
+	case "day":
+		return start.AddDate(0, 0, -n), true
+	case "week":
+		return start.AddDate(0, 0, -7*n), true
+	case "":
+		return start, true
+	}
+	return time.Time{}, false
+}
+
+// TextSpansDate returns the first date mentioned in text spans or nil
+func TextSpansDate(spans []*TextSpan) *Date {
+	for _, ts := range spans {
+		for _, attr := range ts.Attrs {
+			if AttrGetType(attr) == AttrDate {
+				return AttrGetDate(attr)
+			}
+		}
+	}
+	return nil
 }
 
 // convertNotionTimeFormatToGoFormat converts a date format sent from Notion
@@ -85,7 +170,11 @@ func convertNotionTimeFormatToGoFormat(d *Date, withTime bool) string {
 // user-requested format
 func formatDateTime(d *Date, date string, t string) string {
 	withTime := t != ""
-	dt := parseNotionDateTime(date, t)
+	// formatting is in the time zone of the date so we don't convert it
+	dt, err := parseNotionDateTime(date, t, time.UTC)
+	if err != nil {
+		MaybePanic("parseNotionDateTime('%s', '%s') failed with %s", date, t, err)
+	}
 	goFormat := convertNotionTimeFormatToGoFormat(d, withTime)
 	s := dt.Format(goFormat)
 	// TODO: this is a lousy way of doing it
@@ -108,3 +197,130 @@ func FormatDate(d *Date) string {
 	}
 	return s
 }
+
+// DateLocale describes how dates are formatted in a language
+type DateLocale struct {
+	// MonthNames are names of months, January first. Used for "MMMM"
+	MonthNames [12]string
+	// ShortMonthNames are abbreviated names of months. Used for "MMM"
+	ShortMonthNames [12]string
+	// DateFormat, if set, is used instead of Date.DateFormat e.g.
+	// "DD.MM.YYYY" or "D MMMM YYYY". Relative dates use it too
+	DateFormat string
+	// Use24Hour forces 24 hour time. Otherwise it's 24 hour only if
+	// Date.TimeFormat says so
+	Use24Hour bool
+	// AM and PM are suffixes of 12 hour time
+	AM string
+	PM string
+}
+
+var (
+	// DateLocaleEnglish formats dates like Notion in English
+	DateLocaleEnglish = &DateLocale{
+		MonthNames:      [12]string{"January", "February", "March", "April", "May", "June", "July", "August", "September", "October", "November", "December"},
+		ShortMonthNames: [12]string{"Jan", "Feb", "Mar", "Apr", "May", "Jun", "Jul", "Aug", "Sep", "Oct", "Nov", "Dec"},
+		AM:              "AM",
+		PM:              "PM",
+	}
+	// DateLocaleGerman formats dates e.g. "12. Juli 2018 09:00"
+	DateLocaleGerman = &DateLocale{
+		MonthNames:      [12]string{"Januar", "Februar", "März", "April", "Mai", "Juni", "Juli", "August", "September", "Oktober", "November", "Dezember"},
+		ShortMonthNames: [12]string{"Jan.", "Feb.", "März", "Apr.", "Mai", "Juni", "Juli", "Aug.", "Sept.", "Okt.", "Nov.", "Dez."},
+		DateFormat:      "D. MMMM YYYY",
+		Use24Hour:       true,
+	}
+	// DateLocaleFrench formats dates e.g. "12 juillet 2018 09:00"
+	DateLocaleFrench = &DateLocale{
+		MonthNames:      [12]string{"janvier", "février", "mars", "avril", "mai", "juin", "juillet", "août", "septembre", "octobre", "novembre", "décembre"},
+		ShortMonthNames: [12]string{"janv.", "févr.", "mars", "avr.", "mai", "juin", "juil.", "août", "sept.", "oct.", "nov.", "déc."},
+		DateFormat:      "D MMMM YYYY",
+		Use24Hour:       true,
+	}
+	// DateLocaleSpanish formats dates e.g. "12 de julio de 2018 09:00"
+	DateLocaleSpanish = &DateLocale{
+		MonthNames:      [12]string{"enero", "febrero", "marzo", "abril", "mayo", "junio", "julio", "agosto", "septiembre", "octubre", "noviembre", "diciembre"},
+		ShortMonthNames: [12]string{"ene.", "feb.", "mar.", "abr.", "may.", "jun.", "jul.", "ago.", "sept.", "oct.", "nov.", "dic."},
+		DateFormat:      "D [de] MMMM [de] YYYY",
+		Use24Hour:       true,
+	}
+)
+
+// formats t according to a Notion-style format like "MMM DD, YYYY".
+// Text in [] is copied as is
+func formatWithLocale(t time.Time, format string, l *DateLocale) string {
+	var sb strings.Builder
+	for len(format) > 0 {
+		switch {
+		case format[0] == '[':
+			end := strings.IndexByte(format, ']')
+			if end < 0 {
+				sb.WriteString(format[1:])
+				return sb.String()
+			}
+			sb.WriteString(format[1:end])
+			format = format[end+1:]
+		case strings.HasPrefix(format, "YYYY"):
+			sb.WriteString(fmt.Sprintf("%d", t.Year()))
+			format = format[4:]
+		case strings.HasPrefix(format, "MMMM"):
+			sb.WriteString(l.MonthNames[t.Month()-1])
+			format = format[4:]
+		case strings.HasPrefix(format, "MMM"):
+			sb.WriteString(l.ShortMonthNames[t.Month()-1])
+			format = format[3:]
+		case strings.HasPrefix(format, "MM"):
+			sb.WriteString(fmt.Sprintf("%02d", int(t.Month())))
+			format = format[2:]
+		case strings.HasPrefix(format, "DD"):
+			sb.WriteString(fmt.Sprintf("%02d", t.Day()))
+			format = format[2:]
+		case strings.HasPrefix(format, "D"):
+			sb.WriteString(fmt.Sprintf("%d", t.Day()))
+			format = format[1:]
+		default:
+			sb.WriteByte(format[0])
+			format = format[1:]
+		}
+	}
+	return sb.String()
+}
+
+func formatDateTimeLocale(d *Date, date string, t string, l *DateLocale) string {
+	dt, err := parseNotionDateTime(date, t, time.UTC)
+	if err != nil {
+		MaybePanic("parseNotionDateTime('%s', '%s') failed with %s", date, t, err)
+	}
+	format := l.DateFormat
+	if format == "" {
+		format = d.DateFormat
+	}
+	if format == "relative" || format == "" {
+		format = "MMM DD, YYYY"
+	}
+	s := formatWithLocale(dt, format, l)
+	if t == "" {
+		return s
+	}
+	if l.Use24Hour || d.TimeFormat == "H:mm" {
+		return s + " " + dt.Format("15:04")
+	}
+	suffix := l.AM
+	if dt.Hour() >= 12 {
+		suffix = l.PM
+	}
+	return s + " " + strings.TrimSpace(dt.Format("3:04")+" "+suffix)
+}
+
+// FormatDateLocale formats a date with month names and conventions of
+// a locale. If l is nil, it's FormatDate
+func FormatDateLocale(d *Date, l *DateLocale) string {
+	if l == nil {
+		return FormatDate(d)
+	}
+	s := formatDateTimeLocale(d, d.StartDate, d.StartTime, l)
+	if strings.Contains(d.Type, "range") {
+		s += " → " + formatDateTimeLocale(d, d.EndDate, d.EndTime, l)
+	}
+	return s
+}
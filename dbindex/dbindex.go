@@ -0,0 +1,193 @@
+// Package dbindex builds a catalog of databases (collections) of a Notion
+// space: their names, columns, number of rows and pages they're in.
+// It's useful for getting an overview of a big workspace
+package dbindex
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/ninja-1/notionapi"
+	"github.com/ninja-1/notionapi/caching_downloader"
+	"github.com/ninja-1/notionapi/tohtml"
+)
+
+// Column describes a column (property) of a database
+type Column struct {
+	Name string `json:"name"`
+	// Type is notionapi.ColumnTypeTitle etc.
+	Type string `json:"type"`
+}
+
+// Database describes a database (collection)
+type Database struct {
+	// ID is id of the collection
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	// BlockID is id of the block that shows the database
+	BlockID string `json:"block_id"`
+	// Inline is true for databases shown inside a page
+	// (notionapi.BlockCollectionView) and false for full-page databases
+	// (notionapi.BlockCollectionViewPage)
+	Inline bool `json:"inline"`
+	// ParentPageID and ParentPageTitle describe the page that has
+	// the database. For full-page databases it's the database itself
+	ParentPageID    string `json:"parent_page_id"`
+	ParentPageTitle string `json:"parent_page_title"`
+	// Columns are in the order of the schema, with the title column first
+	Columns []*Column `json:"columns"`
+	// Rows is the number of rows
+	Rows int `json:"rows"`
+	// Views are names of views of the database
+	Views []string `json:"views"`
+	// URL is a url of the block in Notion
+	URL string `json:"url"`
+}
+
+// Index is a catalog of databases
+type Index struct {
+	Databases []*Database `json:"databases"`
+
+	seen map[string]bool
+}
+
+// New returns an empty Index
+func New() *Index {
+	return &Index{
+		seen: map[string]bool{},
+	}
+}
+
+// returns columns of a collection with the title column first, then
+// in alphabetical order
+func getColumns(collection *notionapi.Collection) []*Column {
+	var res []*Column
+	for _, schema := range collection.Schema {
+		if schema == nil {
+			continue
+		}
+		res = append(res, &Column{Name: schema.Name, Type: schema.Type})
+	}
+	sort.Slice(res, func(i, j int) bool {
+		c1, c2 := res[i], res[j]
+		isTitle1 := c1.Type == notionapi.ColumnTypeTitle
+		isTitle2 := c2.Type == notionapi.ColumnTypeTitle
+		if isTitle1 != isTitle2 {
+			return isTitle1
+		}
+		return c1.Name < c2.Name
+	})
+	return res
+}
+
+// AddPage adds databases of a downloaded page to the index. Databases
+// that are already in the index are skipped
+func (idx *Index) AddPage(page *notionapi.Page) {
+	if idx.seen == nil {
+		idx.seen = map[string]bool{}
+	}
+	root := page.Root()
+	page.Walk(&notionapi.Visitor{
+		Enter: func(block *notionapi.Block, depth int) bool {
+			if block.Type != notionapi.BlockCollectionView && block.Type != notionapi.BlockCollectionViewPage {
+				return true
+			}
+			if len(block.TableViews) == 0 {
+				// views are only loaded for blocks of the page, not
+				// for sub-pages
+				return true
+			}
+			collection := block.TableViews[0].Collection
+			if collection == nil || idx.seen[collection.ID] {
+				return true
+			}
+			idx.seen[collection.ID] = true
+			db := &Database{
+				ID:              collection.ID,
+				Name:            collection.GetName(),
+				BlockID:         block.ID,
+				Inline:          block.Type == notionapi.BlockCollectionView,
+				ParentPageID:    root.ID,
+				ParentPageTitle: root.Title,
+				Columns:         getColumns(collection),
+				URL:             "https://www.notion.so/" + notionapi.ToNoDashID(block.ID),
+			}
+			for _, tv := range block.TableViews {
+				if tv.Total > db.Rows {
+					db.Rows = tv.Total
+				}
+				if tv.CollectionView != nil {
+					db.Views = append(db.Views, tv.CollectionView.Name)
+				}
+			}
+			idx.Databases = append(idx.Databases, db)
+			return true
+		},
+	})
+}
+
+// Build crawls all pages of a space, starting with its top-level pages,
+// and returns an index of databases found in them. Set
+// Downloader.FollowRowPages to also find databases in pages of rows
+func Build(d *caching_downloader.Downloader, space *notionapi.Space) (*Index, error) {
+	idx := New()
+	for _, pageID := range space.Pages {
+		_, err := d.DownloadPagesRecursively(pageID, func(page *notionapi.Page) error {
+			idx.AddPage(page)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	idx.Sort()
+	return idx, nil
+}
+
+// Sort sorts databases by name
+func (idx *Index) Sort() {
+	sort.SliceStable(idx.Databases, func(i, j int) bool {
+		n1 := strings.ToLower(idx.Databases[i].Name)
+		n2 := strings.ToLower(idx.Databases[j].Name)
+		return n1 < n2
+	})
+}
+
+// ToJSON returns the index as JSON
+func (idx *Index) ToJSON() ([]byte, error) {
+	return json.MarshalIndent(idx, "", "  ")
+}
+
+func columnsSummary(columns []*Column) string {
+	var parts []string
+	for _, c := range columns {
+		parts = append(parts, c.Name+" ("+c.Type+")")
+	}
+	return strings.Join(parts, ", ")
+}
+
+// ToHTML returns a standalone HTML page with a table of databases
+func (idx *Index) ToHTML() []byte {
+	var buf bytes.Buffer
+	buf.WriteString(`<!DOCTYPE html><html><head><meta charset="utf-8"/><title>Databases</title></head><body>`)
+	buf.WriteString(fmt.Sprintf("\n<h1>Databases (%d)</h1>", len(idx.Databases)))
+	buf.WriteString("\n<table>\n<thead><tr><th>Name</th><th>Page</th><th>Rows</th><th>Columns</th><th>Views</th></tr></thead>\n<tbody>")
+	for _, db := range idx.Databases {
+		name := db.Name
+		if name == "" {
+			name = "Untitled"
+		}
+		buf.WriteString("\n<tr>")
+		buf.WriteString(fmt.Sprintf(`<td><a href="%s">%s</a></td>`, tohtml.EscapeHTML(db.URL), tohtml.EscapeHTML(name)))
+		buf.WriteString(fmt.Sprintf(`<td>%s</td>`, tohtml.EscapeHTML(db.ParentPageTitle)))
+		buf.WriteString(fmt.Sprintf(`<td>%d</td>`, db.Rows))
+		buf.WriteString(fmt.Sprintf(`<td>%s</td>`, tohtml.EscapeHTML(columnsSummary(db.Columns))))
+		buf.WriteString(fmt.Sprintf(`<td>%s</td>`, tohtml.EscapeHTML(strings.Join(db.Views, ", "))))
+		buf.WriteString("</tr>")
+	}
+	buf.WriteString("\n</tbody>\n</table>\n</body></html>\n")
+	return buf.Bytes()
+}
@@ -0,0 +1,37 @@
+package dbindex
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/ninja-1/notionapi"
+	"github.com/ninja-1/notionapi/caching_downloader"
+	"github.com/stretchr/testify/require"
+)
+
+// https://www.notion.so/Test-table-94167af6567043279811dc923edd1f04
+func TestBuild(t *testing.T) {
+	d := caching_downloader.NewTestDownloader(t)
+	space := &notionapi.Space{Pages: []string{"94167af6567043279811dc923edd1f04"}}
+	idx, err := Build(d, space)
+	require.NoError(t, err)
+	require.Equal(t, 1, len(idx.Databases))
+	db := idx.Databases[0]
+	require.NotEmpty(t, db.Name)
+	require.True(t, db.Rows > 0)
+	require.Equal(t, notionapi.ColumnTypeTitle, db.Columns[0].Type)
+
+	// adding the same page again doesn't duplicate databases
+	idx.AddPage(d.IdToPage["94167af6567043279811dc923edd1f04"])
+	require.Equal(t, 1, len(idx.Databases))
+
+	js, err := idx.ToJSON()
+	require.NoError(t, err)
+	var idx2 Index
+	require.NoError(t, json.Unmarshal(js, &idx2))
+	require.Equal(t, db.Name, idx2.Databases[0].Name)
+
+	s := string(idx.ToHTML())
+	require.True(t, strings.Contains(s, "<h1>Databases (1)</h1>"))
+}
@@ -0,0 +1,70 @@
+package notionapi
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// errPanicInFlight is returned to callers waiting for a call that panicked
+var errPanicInFlight = errors.New("in-flight request panicked")
+
+// inflightCall is an in-progress or completed request
+type inflightCall struct {
+	wg  sync.WaitGroup
+	val interface{}
+	err error
+}
+
+// requestGroup coalesces concurrent calls with the same key
+// into a single call (like golang.org/x/sync/singleflight)
+type requestGroup struct {
+	mu sync.Mutex
+	m  map[string]*inflightCall
+}
+
+// shared by all clients because Client is often copied.
+// Client.dedupKey ensures we only dedup requests that
+// would be the same
+var inflight = &requestGroup{}
+
+// do executes fn and returns its results, making sure that only one
+// execution for a given key is in-flight at a time. If a duplicate call
+// comes in, it waits for the original to complete and gets the same results.
+// The results are shared, not copied, so callers must not modify them.
+// If fn panics, waiting callers are released and get errPanicInFlight
+func (g *requestGroup) do(key string, fn func() (interface{}, error)) (interface{}, error) {
+	g.mu.Lock()
+	if g.m == nil {
+		g.m = map[string]*inflightCall{}
+	}
+	if call, ok := g.m[key]; ok {
+		g.mu.Unlock()
+		call.wg.Wait()
+		return call.val, call.err
+	}
+	call := &inflightCall{
+		err: errPanicInFlight,
+	}
+	call.wg.Add(1)
+	g.m[key] = call
+	g.mu.Unlock()
+
+	defer func() {
+		g.mu.Lock()
+		delete(g.m, key)
+		g.mu.Unlock()
+		call.wg.Done()
+	}()
+	call.val, call.err = fn()
+	return call.val, call.err
+}
+
+// dedupKey returns a key identifying a request made by this client.
+// We include HTTPClient because e.g. caching http client must see
+// all requests
+func (c *Client) dedupKey(parts ...string) string {
+	s := strings.Join(parts, "\x00")
+	return fmt.Sprintf("%p\x00%s\x00%s\x00%s", c.HTTPClient, c.AuthToken, c.getBaseURL(), s)
+}
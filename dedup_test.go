@@ -0,0 +1,76 @@
+package notionapi
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequestGroupDedups(t *testing.T) {
+	g := &requestGroup{}
+	var nCalls int32
+	fn := func() (interface{}, error) {
+		atomic.AddInt32(&nCalls, 1)
+		time.Sleep(50 * time.Millisecond)
+		return "result", nil
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			v, err := g.do("key", fn)
+			assert.NoError(t, err)
+			assert.Equal(t, "result", v)
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int32(1), atomic.LoadInt32(&nCalls))
+
+	// after completion, the call is made again
+	_, _ = g.do("key", fn)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&nCalls))
+}
+
+func TestRequestGroupPanic(t *testing.T) {
+	g := &requestGroup{}
+	started := make(chan bool)
+	release := make(chan bool)
+	done := make(chan error)
+	go func() {
+		defer func() {
+			assert.NotNil(t, recover())
+		}()
+		_, _ = g.do("key", func() (interface{}, error) {
+			close(started)
+			<-release
+			panic("boom")
+		})
+	}()
+	<-started
+	go func() {
+		_, err := g.do("key", func() (interface{}, error) {
+			return nil, nil
+		})
+		done <- err
+	}()
+	// give the second call time to start waiting
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	select {
+	case err := <-done:
+		assert.Error(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("waiting call didn't return after panic")
+	}
+
+	// the key is no longer in-flight
+	v, err := g.do("key", func() (interface{}, error) {
+		return "result", nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "result", v)
+}
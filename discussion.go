@@ -1,5 +1,7 @@
 package notionapi
 
+import "sort"
+
 // Discussion represents a discussion
 type Discussion struct {
 	ID          string   `json:"id"`
@@ -11,3 +13,142 @@ type Discussion struct {
 	// set by us
 	RawJSON map[string]interface{} `json:"-"`
 }
+
+// CommentsAll returns all alive comments in this discussion,
+// in order. Comments must be loaded in the page
+func (d *Discussion) CommentsAll(p *Page) []*Comment {
+	var res []*Comment
+	for _, id := range d.Comments {
+		c := p.CommentByID(id)
+		if c != nil && c.Alive {
+			res = append(res, c)
+		}
+	}
+	return res
+}
+
+// Discussions returns discussions on a block that are loaded in the page
+func (b *Block) Discussions() []*Discussion {
+	if b.Page == nil {
+		return nil
+	}
+	var res []*Discussion
+	for _, id := range b.DiscussionIDs {
+		d := b.Page.DiscussionByID(id)
+		if d != nil {
+			res = append(res, d)
+		}
+	}
+	return res
+}
+
+// download discussions and comments referenced by blocks
+// that we don't have yet
+func (c *Client) downloadDiscussions(p *Page) error {
+	var records []RecordRequest
+	for _, id := range getBlockIDsSorted(p.idToBlock) {
+		for _, discID := range p.idToBlock[id].DiscussionIDs {
+			if p.idToDiscussion[discID] == nil {
+				records = append(records, RecordRequest{Table: TableDiscussion, ID: discID})
+			}
+		}
+	}
+	if err := c.getPageRecords(p, records); err != nil {
+		return err
+	}
+
+	// sorted so that requests are the same for the same page
+	var ids []string
+	for id := range p.idToDiscussion {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	records = nil
+	for _, id := range ids {
+		d := p.idToDiscussion[id]
+		for _, commentID := range d.Comments {
+			if p.idToComment[commentID] == nil {
+				records = append(records, RecordRequest{Table: TableComment, ID: commentID})
+			}
+		}
+	}
+	return c.getPageRecords(p, records)
+}
+
+func (c *Client) getPageRecords(p *Page, records []RecordRequest) error {
+	if len(records) == 0 {
+		return nil
+	}
+	rsp, err := c.GetRecordValues(records)
+	p.Stats.APICalls++
+	if err != nil {
+		return err
+	}
+	for _, r := range rsp.Results {
+		if r.Discussion != nil {
+			p.DiscussionRecords = append(p.DiscussionRecords, r)
+			p.idToDiscussion[r.Discussion.ID] = r.Discussion
+		}
+		if r.Comment != nil {
+			p.CommentRecords = append(p.CommentRecords, r)
+			p.idToComment[r.Comment.ID] = r.Comment
+		}
+	}
+	return nil
+}
+
+// Discussions returns all discussions on blocks of the page, in the order
+// of blocks. Use Client.DownloadDiscussions to make sure all are loaded
+func (p *Page) Discussions() []*Discussion {
+	var res []*Discussion
+	p.ForEachBlock(func(block *Block) {
+		res = append(res, block.Discussions()...)
+	})
+	return res
+}
+
+// OpenDiscussions returns discussions on blocks of the page
+// that are not resolved
+func (p *Page) OpenDiscussions() []*Discussion {
+	var res []*Discussion
+	for _, d := range p.Discussions() {
+		if !d.Resolved {
+			res = append(res, d)
+		}
+	}
+	return res
+}
+
+func setDiscussionResolvedOp(discussionID string, resolved bool) *Operation {
+	return &Operation{
+		ID:      ToDashID(discussionID),
+		Table:   TableDiscussion,
+		Path:    []string{},
+		Command: CommandUpdate,
+		Args: map[string]interface{}{
+			"resolved": resolved,
+		},
+	}
+}
+
+// ResolveDiscussion marks a discussion (comment thread) as resolved
+func (c *Client) ResolveDiscussion(discussionID string) error {
+	return c.SubmitTransaction([]*Operation{setDiscussionResolvedOp(discussionID, true)})
+}
+
+// ReopenDiscussion marks a resolved discussion as not resolved
+func (c *Client) ReopenDiscussion(discussionID string) error {
+	return c.SubmitTransaction([]*Operation{setDiscussionResolvedOp(discussionID, false)})
+}
+
+// GetOpenDiscussions downloads a page with its discussions and returns
+// discussions that are not resolved, with their comments loaded in the page
+func (c *Client) GetOpenDiscussions(pageID string) (*Page, []*Discussion, error) {
+	client := *c
+	client.DownloadDiscussions = true
+	page, err := client.DownloadPage(pageID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return page, page.OpenDiscussions(), nil
+}
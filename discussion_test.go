@@ -0,0 +1,44 @@
+package notionapi
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBlockDiscussions(t *testing.T) {
+	p := &Page{
+		idToDiscussion: map[string]*Discussion{},
+		idToComment:    map[string]*Comment{},
+	}
+	p.idToDiscussion["d1"] = &Discussion{ID: "d1", Comments: []string{"c1", "c2", "c3"}}
+	p.idToComment["c1"] = &Comment{ID: "c1", Alive: true}
+	p.idToComment["c3"] = &Comment{ID: "c3", Alive: false}
+	b := &Block{Page: p, DiscussionIDs: []string{"d1", "d2"}}
+	discussions := b.Discussions()
+	assert.Equal(t, 1, len(discussions))
+	comments := discussions[0].CommentsAll(p)
+	assert.Equal(t, 1, len(comments))
+	assert.Equal(t, "c1", comments[0].ID)
+}
+
+func TestOpenDiscussions(t *testing.T) {
+	p := &Page{
+		ID:             "root",
+		idToBlock:      map[string]*Block{},
+		idToDiscussion: map[string]*Discussion{},
+	}
+	p.idToDiscussion["d1"] = &Discussion{ID: "d1", Resolved: true}
+	p.idToDiscussion["d2"] = &Discussion{ID: "d2"}
+	child := &Block{ID: "child", Type: BlockText, Page: p, DiscussionIDs: []string{"d2"}}
+	root := &Block{ID: "root", Type: BlockPage, Page: p, DiscussionIDs: []string{"d1"}, Content: []*Block{child}}
+	p.idToBlock["root"] = root
+	assert.Equal(t, 2, len(p.Discussions()))
+	open := p.OpenDiscussions()
+	assert.Equal(t, 1, len(open))
+	assert.Equal(t, "d2", open[0].ID)
+
+	op := setDiscussionResolvedOp("d2", true)
+	assert.Equal(t, TableDiscussion, op.Table)
+	assert.Equal(t, map[string]interface{}{"resolved": true}, op.Args)
+}
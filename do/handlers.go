@@ -97,7 +97,7 @@ func previewToHTML(pageID string) ([]byte, error) {
 	// change https://www.notion.so/Advanced-web-spidering-with-Puppeteer-ea07db1b9bff415ab180b0525f3898f6
 	// =>
 	// /previewhtml/${pageID}
-	rewriteURL := func(uri string) string {
+	rewriteURL := func(uri string, kind tohtml.URLKind) string {
 		logf("rewriteURL: '%s'", uri)
 		// ExtractNoDashIDFromNotionURL() only checks if last part of the url
 		// is a valid id. We only want to
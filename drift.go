@@ -0,0 +1,312 @@
+package notionapi
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+)
+
+const (
+	// DriftBlockType is a kind of DriftEntry for a block type we don't know
+	DriftBlockType = "block_type"
+	// DriftBlockField is a kind of DriftEntry for a field of a block
+	// we don't know
+	DriftBlockField = "block_field"
+	// DriftFormatField is a kind of DriftEntry for a field of format
+	// of a block we don't know
+	DriftFormatField = "format_field"
+	// DriftTextAttr is a kind of DriftEntry for an attribute of
+	// text we don't know
+	DriftTextAttr = "text_attr"
+)
+
+// max number of ids of blocks we remember for each DriftEntry
+const maxDriftExamples = 3
+
+var knownBlockTypes = map[string]bool{
+	BlockAudio:              true,
+	BlockBookmark:           true,
+	BlockBreadcrumb:         true,
+	BlockBulletedList:       true,
+	BlockCode:               true,
+	BlockCodepen:            true,
+	BlockCallout:            true,
+	BlockColumn:             true,
+	BlockColumnList:         true,
+	BlockCollectionView:     true,
+	BlockCollectionViewPage: true,
+	BlockComment:            true,
+	BlockDivider:            true,
+	BlockDrive:              true,
+	BlockDropbox:            true,
+	BlockEmbed:              true,
+	BlockEquation:           true,
+	BlockFactory:            true,
+	BlockFigma:              true,
+	BlockFile:               true,
+	BlockGist:               true,
+	BlockHeader:             true,
+	BlockImage:              true,
+	BlockLinkToPage:         true,
+	BlockMaps:               true,
+	BlockNumberedList:       true,
+	BlockPDF:                true,
+	BlockPage:               true,
+	BlockQuote:              true,
+	BlockSubHeader:          true,
+	BlockSubSubHeader:       true,
+	BlockTableOfContents:    true,
+	BlockText:               true,
+	BlockTodo:               true,
+	BlockToggle:             true,
+	BlockTweet:              true,
+	BlockVideo:              true,
+}
+
+var knownTextAttrs = map[string]bool{
+	AttrBold:           true,
+	AttrCode:           true,
+	AttrItalic:         true,
+	AttrStrikeThrought: true,
+	AttrComment:        true,
+	AttrLink:           true,
+	AttrUser:           true,
+	AttrHighlight:      true,
+	AttrDate:           true,
+	AttrPage:           true,
+	AttrUnderline:      true,
+	AttrEquation:       true,
+}
+
+// fields of a block we don't decode into Block but know about
+var knownExtraBlockFields = []string{
+	"format",
+	"created_by_id",
+	"created_by_table",
+	"last_edited_by_id",
+	"last_edited_by_table",
+}
+
+// Format* structs that describe format of a block type. We only check
+// format of those types
+var formatStructs = map[string]interface{}{
+	BlockBookmark:        FormatBookmark{},
+	BlockBulletedList:    FormatBulletedList{},
+	BlockCallout:         FormatCallout{},
+	BlockCode:            FormatCode{},
+	BlockCodepen:         FormatCodepen{},
+	BlockCollectionView:  FormatCollectionView{},
+	BlockColumn:          FormatColumn{},
+	BlockDrive:           FormatDrive{},
+	BlockEmbed:           FormatEmbed{},
+	BlockFigma:           FormatFigma{},
+	BlockHeader:          FormatHeader{},
+	BlockSubHeader:       FormatHeader{},
+	BlockSubSubHeader:    FormatHeader{},
+	BlockImage:           FormatImage{},
+	BlockMaps:            FormatMaps{},
+	BlockNumberedList:    FormatNumberedList{},
+	BlockPage:            FormatPage{},
+	BlockPDF:             FormatPDF{},
+	BlockTableOfContents: FormatTableOfContents{},
+	BlockText:            FormatText{},
+	BlockToggle:          FormatToggle{},
+	BlockVideo:           FormatVideo{},
+}
+
+// returns names of json fields of a struct
+func jsonFieldNames(v interface{}) map[string]bool {
+	res := map[string]bool{}
+	t := reflect.TypeOf(v)
+	for i := 0; i < t.NumField(); i++ {
+		tag := t.Field(i).Tag.Get("json")
+		name := strings.Split(tag, ",")[0]
+		if name != "" && name != "-" {
+			res[name] = true
+		}
+	}
+	return res
+}
+
+var (
+	knownFieldsOnce   sync.Once
+	knownBlockFields  map[string]bool
+	knownFormatFields map[string]map[string]bool
+)
+
+func initKnownFields() {
+	knownBlockFields = jsonFieldNames(Block{})
+	for _, name := range knownExtraBlockFields {
+		knownBlockFields[name] = true
+	}
+	knownFormatFields = map[string]map[string]bool{}
+	for blockType, v := range formatStructs {
+		knownFormatFields[blockType] = jsonFieldNames(v)
+	}
+}
+
+// DriftEntry describes something in data returned by Notion
+// that this package doesn't know about
+type DriftEntry struct {
+	// Kind is DriftBlockType, DriftBlockField, DriftFormatField
+	// or DriftTextAttr
+	Kind string `json:"kind"`
+	// Name is a block type, name of a field or an attribute
+	Name string `json:"name"`
+	// BlockType is a type of blocks where fields and attributes were seen
+	BlockType string `json:"block_type,omitempty"`
+	// Count is how many times we've seen it
+	Count int `json:"count"`
+	// Pages is the number of pages where we've seen it
+	Pages int `json:"pages"`
+	// ExampleBlockIDs are ids of a few blocks where we've seen it
+	ExampleBlockIDs []string `json:"example_block_ids"`
+
+	pageIDs map[string]bool
+}
+
+// DriftReport collects block types, fields of blocks and text attributes
+// that Notion returns but this package doesn't know about. Set
+// Client.DriftReport to collect it for all downloaded pages e.g. during
+// a crawl. Entries seen most often are the best candidates for adding
+// support. It's safe for concurrent use
+type DriftReport struct {
+	mu      sync.Mutex
+	entries map[string]*DriftEntry
+	pages   int
+}
+
+// NewDriftReport returns a new DriftReport
+func NewDriftReport() *DriftReport {
+	return &DriftReport{
+		entries: map[string]*DriftEntry{},
+	}
+}
+
+func (r *DriftReport) add(kind, blockType, name string, pageID string, blockID string) {
+	if r.entries == nil {
+		r.entries = map[string]*DriftEntry{}
+	}
+	key := kind + "\x00" + blockType + "\x00" + name
+	e := r.entries[key]
+	if e == nil {
+		e = &DriftEntry{
+			Kind:      kind,
+			Name:      name,
+			BlockType: blockType,
+			pageIDs:   map[string]bool{},
+		}
+		r.entries[key] = e
+	}
+	e.Count++
+	if !e.pageIDs[pageID] {
+		e.pageIDs[pageID] = true
+		e.Pages++
+	}
+	if len(e.ExampleBlockIDs) < maxDriftExamples {
+		e.ExampleBlockIDs = append(e.ExampleBlockIDs, blockID)
+	}
+}
+
+func (r *DriftReport) addBlock(block *Block, pageID string) {
+	knownFieldsOnce.Do(initKnownFields)
+	if !knownBlockTypes[block.Type] {
+		r.add(DriftBlockType, "", block.Type, pageID, block.ID)
+		// fields of unknown blocks would only be noise
+		return
+	}
+	for name := range block.RawJSON {
+		if !knownBlockFields[name] {
+			r.add(DriftBlockField, block.Type, name, pageID, block.ID)
+		}
+	}
+	if known, ok := knownFormatFields[block.Type]; ok {
+		format, _ := block.RawJSON["format"].(map[string]interface{})
+		for name := range format {
+			if !known[name] {
+				r.add(DriftFormatField, block.Type, name, pageID, block.ID)
+			}
+		}
+	}
+	for _, ts := range block.InlineContent {
+		for _, attr := range ts.Attrs {
+			attrType := AttrGetType(attr)
+			if !knownTextAttrs[attrType] {
+				r.add(DriftTextAttr, block.Type, attrType, pageID, block.ID)
+			}
+		}
+	}
+}
+
+// AddBlock records unknown things in a block
+func (r *DriftReport) AddBlock(block *Block) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	pageID := ""
+	if block.Page != nil {
+		pageID = block.Page.ID
+	}
+	r.addBlock(block, pageID)
+}
+
+// AddPage records unknown things in all blocks of a page
+func (r *DriftReport) AddPage(page *Page) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.pages++
+	for _, id := range getBlockIDsSorted(page.idToBlock) {
+		r.addBlock(page.idToBlock[id], page.ID)
+	}
+}
+
+// PagesChecked returns the number of pages added with AddPage
+func (r *DriftReport) PagesChecked() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.pages
+}
+
+// Entries returns copies of entries, most frequent first
+func (r *DriftReport) Entries() []*DriftEntry {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var res []*DriftEntry
+	for _, e := range r.entries {
+		c := *e
+		c.pageIDs = nil
+		c.ExampleBlockIDs = append([]string(nil), e.ExampleBlockIDs...)
+		res = append(res, &c)
+	}
+	sort.Slice(res, func(i, j int) bool {
+		e1, e2 := res[i], res[j]
+		if e1.Count != e2.Count {
+			return e1.Count > e2.Count
+		}
+		if e1.Kind != e2.Kind {
+			return e1.Kind < e2.Kind
+		}
+		if e1.BlockType != e2.BlockType {
+			return e1.BlockType < e2.BlockType
+		}
+		return e1.Name < e2.Name
+	})
+	return res
+}
+
+// String returns a human-readable summary of the report
+func (r *DriftReport) String() string {
+	entries := r.Entries()
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "%d unknown things in %d pages\n", len(entries), r.PagesChecked())
+	for _, e := range entries {
+		name := e.Name
+		if e.BlockType != "" {
+			name = e.BlockType + "." + name
+		}
+		fmt.Fprintf(&buf, "%s %s: %d times in %d pages e.g. %s\n", e.Kind, name, e.Count, e.Pages, strings.Join(e.ExampleBlockIDs, ", "))
+	}
+	return buf.String()
+}
@@ -0,0 +1,7 @@
+// Package examples contains runnable examples of using notionapi:
+// exporting a page, querying a database, creating a row and serving
+// a page over HTTP.
+//
+// Examples use pages cached in caching_downloader/testdata, so they
+// run offline as part of go test.
+package examples
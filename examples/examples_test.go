@@ -0,0 +1,149 @@
+package examples
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+
+	"github.com/ninja-1/notionapi"
+	"github.com/ninja-1/notionapi/caching_downloader"
+	"github.com/ninja-1/notionapi/exporter"
+	"github.com/ninja-1/notionapi/tohtml"
+)
+
+// pages recorded from the server
+const testDataDir = "../caching_downloader/testdata"
+
+func newDownloader() *caching_downloader.Downloader {
+	cache, err := caching_downloader.NewDirectoryCache(testDataDir)
+	if err != nil {
+		panic(err)
+	}
+	return caching_downloader.New(cache, &notionapi.Client{})
+}
+
+// Export a page and its sub-pages as HTML files
+func Example_exportPage() {
+	dir, err := ioutil.TempDir("", "notionapi-example")
+	if err != nil {
+		panic(err)
+	}
+	defer os.RemoveAll(dir)
+
+	e := exporter.New(newDownloader(), dir)
+	e.AfterRenderPage = func(e *exporter.Exporter, p *exporter.ExportedPage) error {
+		fmt.Printf("wrote %s\n", p.Path)
+		return nil
+	}
+	_, err = e.Export("6682351e44bb4f9ca0e149b703265bdb")
+	if err != nil {
+		panic(err)
+	}
+	// Output:
+	// wrote Test-headers-6682351e44bb4f9ca0e149b703265bdb.html
+}
+
+// Query a database (collection) embedded in a page
+func Example_queryDatabase() {
+	page, err := newDownloader().DownloadPage("94167af6567043279811dc923edd1f04")
+	if err != nil {
+		panic(err)
+	}
+	tv := page.TableViews[0]
+	var names []string
+	for _, col := range tv.Columns {
+		names = append(names, col.Name())
+	}
+	fmt.Printf("columns: %s\n", strings.Join(names, ", "))
+	fmt.Printf("rows: %d\n", tv.RowCount())
+	// Output:
+	// columns: Name, Numbers, Tags
+	// rows: 3
+}
+
+// recordingTransport pretends to be the Notion server and records
+// API calls
+type recordingTransport struct {
+	apiCalls []string
+}
+
+func (t *recordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.apiCalls = append(t.apiCalls, req.URL.Path)
+	rsp := &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       ioutil.NopCloser(bytes.NewBufferString("{}")),
+		Request:    req,
+	}
+	return rsp, nil
+}
+
+// Create a new row in a database (collection)
+func Example_createRow() {
+	page, err := newDownloader().DownloadPage("94167af6567043279811dc923edd1f04")
+	if err != nil {
+		panic(err)
+	}
+	tv := page.TableViews[0]
+	root := page.Root()
+	transport := &recordingTransport{}
+	client := &notionapi.Client{
+		HTTPClient: &http.Client{Transport: transport},
+	}
+	row, op := client.SetNewRecordOp(root.CreatedBy, root, notionapi.BlockPage)
+	// rows of a database are pages whose parent is the collection
+	row.ParentID = tv.Collection.ID
+	row.ParentTable = notionapi.TableCollection
+	ops := []*notionapi.Operation{op, row.SetTitleOp("New row")}
+	if err = client.SubmitTransaction(ops); err != nil {
+		panic(err)
+	}
+	d, _ := json.Marshal(ops[1].Path)
+	fmt.Printf("api calls: %v\n", transport.apiCalls)
+	fmt.Printf("parent table: %s\n", row.ParentTable)
+	fmt.Printf("set %s\n", d)
+	// Output:
+	// api calls: [/api/v3/submitTransaction]
+	// parent table: collection
+	// set ["properties","title"]
+}
+
+// Serve a page as HTML over HTTP
+func Example_servePage() {
+	page, err := newDownloader().DownloadPage("6682351e44bb4f9ca0e149b703265bdb")
+	if err != nil {
+		panic(err)
+	}
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		c := tohtml.NewConverter(page)
+		c.FullHTML = true
+		html, err := c.ToHTML()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_, _ = w.Write(html)
+	}
+	server := httptest.NewServer(http.HandlerFunc(handler))
+	defer server.Close()
+
+	rsp, err := http.Get(server.URL)
+	if err != nil {
+		panic(err)
+	}
+	defer rsp.Body.Close()
+	body, _ := ioutil.ReadAll(rsp.Body)
+	fmt.Printf("status: %d\n", rsp.StatusCode)
+	fmt.Printf("content type: %s\n", rsp.Header.Get("Content-Type"))
+	fmt.Printf("has title: %v\n", bytes.Contains(body, []byte("<title>Test headers</title>")))
+	// Output:
+	// status: 200
+	// content type: text/html; charset=utf-8
+	// has title: true
+}
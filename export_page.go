@@ -7,10 +7,15 @@ import (
 
 const (
 	eventExportBlock      = "exportBlock"
+	eventExportSpace      = "exportSpace"
 	defaultExportTimeZone = "America/Los_Angeles"
 	statusComplete        = "complete"
-	ExportTypeMarkdown    = "markdown"
-	ExportTypeHTML        = "html"
+	stateFailure          = "failure"
+	// ExportTypeMarkdown exports pages as Markdown and databases as CSV
+	ExportTypeMarkdown = "markdown"
+	ExportTypeHTML     = "html"
+	// ExportTypePDF is only available on paid plans
+	ExportTypePDF = "pdf"
 )
 
 type exportPageTaskRequest struct {
@@ -23,8 +28,9 @@ type exportPageTask struct {
 }
 
 type exportPageRequest struct {
-	BlockID       string             `json:"blockId"`
-	Recursive     bool               `json:"recursive"`
+	BlockID       string             `json:"blockId,omitempty"`
+	SpaceID       string             `json:"spaceId,omitempty"`
+	Recursive     bool               `json:"recursive,omitempty"`
 	ExportOptions *exportPageOptions `json:"exportOptions"`
 }
 
@@ -49,6 +55,7 @@ type exportPageResult struct {
 	UserID    string             `json:"userId"`
 	State     string             `json:"state"`
 	Status    *exportPageStatus  `json:"status"`
+	Error     string             `json:"error"`
 }
 
 type exportPageStatus struct {
@@ -61,57 +68,141 @@ type getTasksRequest struct {
 	TaskIDS []string `json:"taskIds"`
 }
 
-// ExportPages exports a page as html or markdown, potentially recursively
-func (c *Client) ExportPages(id string, exportType string, recursive bool) ([]byte, error) {
-	id = ToDashID(id)
-	if !IsValidDashID(id) {
-		return nil, fmt.Errorf("'%s' is not a valid notion id", id)
-	}
+// ExportTask describes the state of Notion's export task
+type ExportTask struct {
+	TaskID string
+	// e.g. "in_progress", "success" or "failure"
+	State string
+	// set when the task is complete
+	ExportURL     string
+	PagesExported int64
+	// set when the task failed
+	Error string
+}
 
-	req := &exportPageTaskRequest{
+// IsComplete returns true if the export is ready to download from ExportURL
+func (t *ExportTask) IsComplete() bool {
+	return t.ExportURL != ""
+}
+
+func (c *Client) enqueueExportTask(eventName string, req *exportPageRequest) (string, error) {
+	taskReq := &exportPageTaskRequest{
 		Task: &exportPageTask{
-			EventName: eventExportBlock,
-			Request: &exportPageRequest{
-				BlockID:   id,
-				Recursive: recursive,
-				ExportOptions: &exportPageOptions{
-					ExportType: exportType,
-					TimeZone:   defaultExportTimeZone,
-				},
-			},
+			EventName: eventName,
+			Request:   req,
 		},
 	}
-
 	apiURL := "/api/v3/enqueueTask"
 	var rsp enqueueTaskResponse
 	var err error
-	rsp.RawJSON, err = doNotionAPI(c, apiURL, req, &rsp)
+	rsp.RawJSON, err = doNotionAPI(c, apiURL, taskReq, &rsp)
+	if err != nil {
+		return "", err
+	}
+	return rsp.TaskID, nil
+}
+
+// EnqueueExportPage asks Notion to export a page (and, if recursive, its
+// sub-pages) as a ZIP file of exportType (ExportTypeMarkdown,
+// ExportTypeHTML or ExportTypePDF). Returns id of the task, to be used
+// with GetExportTask or WaitForExportTask
+func (c *Client) EnqueueExportPage(id string, exportType string, recursive bool) (string, error) {
+	id = ToDashID(id)
+	if !IsValidDashID(id) {
+		return "", fmt.Errorf("'%s' is not a valid notion id", id)
+	}
+	req := &exportPageRequest{
+		BlockID:   id,
+		Recursive: recursive,
+		ExportOptions: &exportPageOptions{
+			ExportType: exportType,
+			TimeZone:   defaultExportTimeZone,
+		},
+	}
+	return c.enqueueExportTask(eventExportBlock, req)
+}
+
+// EnqueueExportSpace is like EnqueueExportPage but exports the whole
+// space (workspace)
+func (c *Client) EnqueueExportSpace(spaceID string, exportType string) (string, error) {
+	spaceID = ToDashID(spaceID)
+	if !IsValidDashID(spaceID) {
+		return "", fmt.Errorf("'%s' is not a valid notion id", spaceID)
+	}
+	req := &exportPageRequest{
+		SpaceID: spaceID,
+		ExportOptions: &exportPageOptions{
+			ExportType: exportType,
+			TimeZone:   defaultExportTimeZone,
+		},
+	}
+	return c.enqueueExportTask(eventExportSpace, req)
+}
+
+// GetExportTask returns the current state of an export task
+func (c *Client) GetExportTask(taskID string) (*ExportTask, error) {
+	req := getTasksRequest{
+		TaskIDS: []string{taskID},
+	}
+	var rsp getTasksExportPageResponse
+	apiURL := "/api/v3/getTasks"
+	_, err := doNotionAPI(c, apiURL, req, &rsp)
 	if err != nil {
 		return nil, err
 	}
+	if len(rsp.Results) == 0 {
+		return nil, fmt.Errorf("task '%s' not found", taskID)
+	}
+	r := rsp.Results[0]
+	res := &ExportTask{
+		TaskID: taskID,
+		State:  r.State,
+		Error:  r.Error,
+	}
+	if r.Status != nil {
+		res.PagesExported = r.Status.PagesExported
+		if r.Status.Type == statusComplete {
+			res.ExportURL = r.Status.ExportURL
+		}
+	}
+	return res, nil
+}
 
-	var exportURL string
-	taskID := rsp.TaskID
+// WaitForExportTask polls an export task until it's complete, failed or
+// timeout passes. Timeout of 0 means no timeout
+func (c *Client) WaitForExportTask(taskID string, timeout time.Duration) (*ExportTask, error) {
+	timeStart := time.Now()
 	for {
 		time.Sleep(250 * time.Millisecond)
-		req := getTasksRequest{
-			TaskIDS: []string{taskID},
-		}
-		var err error
-		var rsp getTasksExportPageResponse
-		apiURL = "/api/v3/getTasks"
-		_, err = doNotionAPI(c, apiURL, req, &rsp)
+		task, err := c.GetExportTask(taskID)
 		if err != nil {
 			return nil, err
 		}
-		status := rsp.Results[0].Status
-		if status != nil && status.Type == statusComplete {
-			exportURL = status.ExportURL
-			break
+		if task.IsComplete() {
+			return task, nil
+		}
+		if task.State == stateFailure {
+			return nil, fmt.Errorf("export task '%s' failed with '%s'", taskID, task.Error)
+		}
+		if timeout > 0 && time.Since(timeStart) > timeout {
+			return nil, fmt.Errorf("export task '%s' didn't complete in %s", taskID, timeout)
 		}
 		time.Sleep(750 * time.Millisecond)
 	}
-	dlRsp, err := c.DownloadFile(exportURL, id)
+}
+
+// ExportPages exports a page as html or markdown, potentially recursively
+func (c *Client) ExportPages(id string, exportType string, recursive bool) ([]byte, error) {
+	id = ToDashID(id)
+	taskID, err := c.EnqueueExportPage(id, exportType, recursive)
+	if err != nil {
+		return nil, err
+	}
+	task, err := c.WaitForExportTask(taskID, 0)
+	if err != nil {
+		return nil, err
+	}
+	dlRsp, err := c.DownloadFile(task.ExportURL, id)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,33 @@
+package notionapi
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExportTask(t *testing.T) {
+	// pretends to be a server running an export task
+	nGetTasks := 0
+	c, _ := newFakeClient(map[string]fakeHandler{
+		"/api/v3/enqueueTask": func(req *http.Request, d []byte) interface{} {
+			return `{"taskId":"task1"}`
+		},
+		"/api/v3/getTasks": func(req *http.Request, d []byte) interface{} {
+			nGetTasks++
+			if nGetTasks > 1 {
+				return `{"results":[{"id":"task1","state":"success","status":{"type":"complete","exportURL":"https://example.com/export.zip","pagesExported":3}}]}`
+			}
+			return `{"results":[{"id":"task1","state":"in_progress"}]}`
+		},
+	})
+	taskID, err := c.EnqueueExportPage("6682351e44bb4f9ca0e149b703265bdb", ExportTypeMarkdown, true)
+	assert.NoError(t, err)
+	assert.Equal(t, "task1", taskID)
+	task, err := c.WaitForExportTask(taskID, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, "https://example.com/export.zip", task.ExportURL)
+	assert.Equal(t, int64(3), task.PagesExported)
+	assert.Equal(t, 2, nGetTasks)
+}
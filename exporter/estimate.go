@@ -0,0 +1,91 @@
+package exporter
+
+import (
+	"github.com/ninja-1/notionapi"
+	"github.com/ninja-1/notionapi/caching_downloader"
+)
+
+// used for pages not in the cache when we don't know better
+const defaultAPICallsPerPage = 3
+
+// Estimate describes the expected cost of Export, based on pages
+// and files in the cache of the Downloader
+type Estimate struct {
+	// Pages is the number of pages found in the cache
+	Pages int
+	// UncachedPages is the number of sub-pages not in the cache. We don't
+	// know their sub-pages so the real number of pages can be higher
+	UncachedPages int
+	// Assets is the number of files (images, attachments etc.)
+	// on cached pages
+	Assets int
+	// UncachedAssets is the number of those files not in the cache
+	UncachedAssets int
+	// APICalls is the expected number of API calls. Pages not in the cache
+	// are assumed to need as many calls as an average cached page and
+	// each file not in the cache one call
+	APICalls int
+}
+
+func isAssetBlock(block *notionapi.Block) bool {
+	switch block.Type {
+	case notionapi.BlockImage, notionapi.BlockFile, notionapi.BlockPDF,
+		notionapi.BlockVideo, notionapi.BlockAudio:
+		return block.Source != ""
+	}
+	return false
+}
+
+// Estimate estimates the cost of exporting a page with a given id
+// and its sub-pages without making API calls, so that it can be checked
+// before Export e.g. against Downloader.MaxAPICalls
+func (e *Exporter) Estimate(startPageID string) (*Estimate, error) {
+	d := e.Downloader
+	res := &Estimate{}
+	// API calls needed to re-download cached pages
+	cachedCalls := 0
+	toVisit := []string{startPageID}
+	seen := map[string]bool{}
+	for len(toVisit) > 0 {
+		pageID := notionapi.ToNoDashID(toVisit[0])
+		toVisit = toVisit[1:]
+		if seen[pageID] {
+			continue
+		}
+		seen[pageID] = true
+		page, err := d.ReadPageFromCache(pageID)
+		if err != nil {
+			return nil, err
+		}
+		if page == nil {
+			res.UncachedPages++
+			continue
+		}
+		res.Pages++
+		cachedCalls += page.Stats.APICalls
+		page.ForEachBlock(func(block *notionapi.Block) {
+			if !isAssetBlock(block) {
+				return
+			}
+			res.Assets++
+			name := caching_downloader.GetCacheFileNameFromURL(block.Source)
+			if _, err := d.Cache.ReadFile(name); err != nil {
+				res.UncachedAssets++
+			}
+		})
+		toVisit = append(toVisit, page.GetSubPages()...)
+	}
+
+	callsPerPage := defaultAPICallsPerPage
+	if res.Pages > 0 && cachedCalls > 0 {
+		callsPerPage = (cachedCalls + res.Pages - 1) / res.Pages
+	}
+	res.APICalls = res.UncachedPages*callsPerPage + res.UncachedAssets
+	if d.NoReadCache {
+		res.APICalls += cachedCalls
+	} else if d.RedownloadNewerVersions && res.Pages > 0 {
+		// checking versions of cached pages
+		res.APICalls++
+	}
+	return res, nil
+}
@@ -0,0 +1,390 @@
+package exporter
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ninja-1/notionapi"
+	"github.com/ninja-1/notionapi/caching_downloader"
+	"github.com/ninja-1/notionapi/tohtml"
+)
+
+// ExportedPage describes a page written by Exporter
+type ExportedPage struct {
+	Page *notionapi.Page
+	// path of the file relative to Exporter.Dir
+	Path string
+	// size of rendered HTML
+	Size int
+	// how long it took to render the page
+	Duration time.Duration
+	// data stashed with tohtml.Converter.SetBlockData during
+	// rendering, keyed by block id
+	Metadata map[string]map[string]interface{}
+	// text recognized in images by Exporter.OCR, keyed by block id.
+	// Can be used e.g. to make images searchable
+	ImageText map[string]string
+	// anchors of headings keyed by block id, if Exporter.HeadingAnchors
+	// is true
+	HeadingAnchors map[string]string
+}
+
+// Result describes the result of Exporter.Export
+type Result struct {
+	// Dir is a directory where files were written
+	Dir   string
+	Pages []*ExportedPage
+	// OutputFiles are files written to Exporter.Output, in the order
+	// they were written. Empty if files were written to Dir
+	OutputFiles []*ManifestFile
+	// how long the whole export took
+	Duration time.Duration
+}
+
+// Exporter exports a page and all its sub-pages as HTML files
+type Exporter struct {
+	Downloader *caching_downloader.Downloader
+	// Dir is a directory where we write files
+	Dir string
+	// Output, if set, is used to write files instead of Dir e.g. to write
+	// them to a zip archive with NewZipOutput. Files are hashed as they're
+	// written so that SignManifest can add the manifest to Output
+	Output Output
+
+	// NewConverter allows customizing HTML conversion of a page.
+	// If not set, we use tohtml.NewConverter with FullHTML set to true
+	NewConverter func(page *notionapi.Page) *tohtml.Converter
+
+	// FileNamer decides names of files for pages. If not set,
+	// HybridNamer is used
+	FileNamer FileNamer
+	// MaxPathLength, if > 0, is the maximum length of the absolute path
+	// of written page files. Longer names are shortened (see WindowsMaxPath)
+	MaxPathLength int
+
+	// AfterRenderPage is called after a page was rendered and written
+	// to disk. Can be used to e.g. minify HTML or upload the file.
+	// Returning an error aborts the export
+	AfterRenderPage func(e *Exporter, page *ExportedPage) error
+
+	// AfterRun is called after all pages were exported. Can be used to
+	// e.g. check links or upload the whole directory
+	AfterRun func(e *Exporter, res *Result) error
+
+	// Users is a directory of users shared by all pages, used to resolve
+	// user mentions. If not set, Export creates one with users of all
+	// exported pages
+	Users *notionapi.UserDirectory
+
+	// MaxCollectionRows, if > 0, limits the number of rows of inline
+	// databases rendered on pages. For databases with more rows we write
+	// a separate page with all rows (see CollectionViewFileNamer)
+	// and link to it
+	MaxCollectionRows int
+
+	// OCR, if set, is called with data of images on exported pages and
+	// returns text recognized in the image. The text is used as alt text
+	// of the image and is in ExportedPage.ImageText.
+	// Images that can't be downloaded or recognized are skipped and
+	// reported as caching_downloader.EventError to
+	// Downloader.EventObserver
+	OCR func(data []byte, contentType string) (string, error)
+
+	// HeadingAnchors, if true, gives headings readable anchors based on
+	// their text (see tohtml.HeadingAnchors) instead of block ids.
+	// They're recorded in the manifest (see SignManifest)
+	HeadingAnchors bool
+	// PreviousManifest is the manifest of the previous export (see
+	// ReadManifest). With HeadingAnchors, headings whose text changed
+	// only slightly keep anchors from it, so that deep links don't break
+	PreviousManifest *Manifest
+
+	// LinkTitles, if set, replaces text of bare links (links whose text
+	// is their url) with titles of linked pages before rendering.
+	// Titles of exported pages are known without downloading them
+	LinkTitles *notionapi.LinkTitleResolver
+
+	idToPath    map[string]string
+	outputFiles []*ManifestFile
+	// names of files with all rows of collection views by block id
+	cvToPath   map[string]string
+	takenNames map[string]bool
+	maxNameLen int
+}
+
+// New returns a new Exporter that writes files to dir
+func New(d *caching_downloader.Downloader, dir string) *Exporter {
+	return &Exporter{
+		Downloader: d,
+		Dir:        dir,
+	}
+}
+
+// FileNameForPage returns name of HTML file for a page
+func FileNameForPage(page *notionapi.Page) string {
+	title := notionapi.SafeName(page.Root().Title)
+	id := notionapi.ToNoDashID(page.ID)
+	if title == "" {
+		return id + ".html"
+	}
+	return title + "-" + id + ".html"
+}
+
+// FileNameForCollectionView returns name of HTML file with all rows
+// of a collection view block
+func FileNameForCollectionView(block *notionapi.Block) string {
+	id := notionapi.ToNoDashID(block.ID)
+	title := ""
+	if len(block.TableViews) > 0 && block.TableViews[0].Collection != nil {
+		title = notionapi.SafeName(block.TableViews[0].Collection.GetName())
+	}
+	if title == "" {
+		return id + "-all.html"
+	}
+	return title + "-" + id + "-all.html"
+}
+
+// rewrites links to pages we export to their local file names
+func (e *Exporter) rewriteURL(uri string, kind tohtml.URLKind) string {
+	id := notionapi.ExtractNoDashIDFromNotionURL(uri)
+	if id == "" || !strings.Contains(uri, "notion.so") {
+		return uri
+	}
+	if path, ok := e.idToPath[id]; ok {
+		return path
+	}
+	return uri
+}
+
+func (e *Exporter) newConverter(page *notionapi.Page) *tohtml.Converter {
+	var c *tohtml.Converter
+	if e.NewConverter != nil {
+		c = e.NewConverter(page)
+	} else {
+		c = tohtml.NewConverter(page)
+		c.FullHTML = true
+	}
+	if c.RewriteURL == nil {
+		c.RewriteURL = e.rewriteURL
+	}
+	if c.Users == nil {
+		c.Users = e.Users
+	}
+	if c.RowPageURL == nil {
+		// link to pages of rows we export (see Downloader.FollowRowPages)
+		c.RowPageURL = func(row *notionapi.Block) string {
+			return e.idToPath[notionapi.ToNoDashID(row.ID)]
+		}
+	}
+	if e.MaxCollectionRows > 0 {
+		c.MaxCollectionRows = e.MaxCollectionRows
+		if c.CollectionViewAllURL == nil {
+			c.CollectionViewAllURL = e.collectionViewFileName
+		}
+	}
+	return c
+}
+
+// largeCollectionViews returns collection view blocks of a page with
+// more than MaxCollectionRows rows
+func (e *Exporter) largeCollectionViews(page *notionapi.Page) []*notionapi.Block {
+	if e.MaxCollectionRows <= 0 {
+		return nil
+	}
+	c := e.newConverter(page)
+	var res []*notionapi.Block
+	page.ForEachBlock(func(block *notionapi.Block) {
+		tv := c.TableViewForBlock(block)
+		if tv != nil && tv.RowCount() > e.MaxCollectionRows {
+			res = append(res, block)
+		}
+	})
+	return res
+}
+
+// renderCollectionViewPage renders a standalone HTML page with all rows
+// of a collection view block of a page
+func (e *Exporter) renderCollectionViewPage(page *notionapi.Page, block *notionapi.Block) []byte {
+	c := e.newConverter(page)
+	c.MaxCollectionRows = 0
+	title := ""
+	if tv := c.TableViewForBlock(block); tv.Collection != nil {
+		title = tv.Collection.GetName()
+	}
+	pageName := e.idToPath[notionapi.ToNoDashID(page.ID)]
+
+	c.PushNewBuffer()
+	c.Printf(`<html><head>`)
+	c.Printf(`<meta http-equiv="Content-Type" content="text/html; charset=utf-8"/>`)
+	c.Printf(`<title>%s</title>`, tohtml.EscapeHTML(title))
+	c.Printf("<style>%s\t\n</style>", tohtml.CSS)
+	c.Printf(`</head><body>`)
+	c.Printf(`<article class="page sans"><div class="page-body">`)
+	c.Printf(`<p><a href="%s">%s</a></p>`, pageName, tohtml.EscapeHTML(page.Root().Title))
+	c.RenderCollectionView(block)
+	c.Printf(`</div></article></body></html>`)
+	return c.PopBuffer().Bytes()
+}
+
+// WriteFile writes a file with a given name, relative to Dir,
+// or to Output if it's set
+func (e *Exporter) WriteFile(name string, data []byte) error {
+	if e.Output != nil {
+		if err := e.Output.WriteFile(name, data); err != nil {
+			return err
+		}
+		e.outputFiles = append(e.outputFiles, newManifestFile(name, data))
+		return nil
+	}
+	path := filepath.Join(e.Dir, name)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, data, 0644)
+}
+
+// logError reports an error that doesn't stop the export to
+// Downloader.EventObserver, like errors of the Downloader
+func (e *Exporter) logError(format string, args ...interface{}) {
+	if e.Downloader.EventObserver == nil {
+		return
+	}
+	ev := &caching_downloader.EventError{
+		Error: fmt.Sprintf(format, args...),
+	}
+	e.Downloader.EventObserver(ev)
+}
+
+// recognizeImages runs OCR on images of a page and returns recognized
+// text keyed by block id. Images that fail are skipped
+func (e *Exporter) recognizeImages(page *notionapi.Page) map[string]string {
+	if e.OCR == nil {
+		return nil
+	}
+	var images []*notionapi.Block
+	page.ForEachBlock(func(block *notionapi.Block) {
+		if block.Type == notionapi.BlockImage && block.Source != "" {
+			images = append(images, block)
+		}
+	})
+	res := map[string]string{}
+	for _, block := range images {
+		rsp, err := e.Downloader.DownloadFile(block.Source, block.ID)
+		if err != nil {
+			e.logError("Exporter: skipping OCR of image in block '%s', download failed: %s", block.ID, err)
+			continue
+		}
+		text, err := e.OCR(rsp.Data, rsp.Header.Get("Content-Type"))
+		if err != nil {
+			e.logError("Exporter: skipping OCR of image in block '%s', OCR failed: %s", block.ID, err)
+			continue
+		}
+		if text = strings.TrimSpace(text); text != "" {
+			res[block.ID] = text
+		}
+	}
+	return res
+}
+
+// Export downloads a page with a given id and all its sub-pages
+// and writes them as HTML files to Dir
+func (e *Exporter) Export(startPageID string) (*Result, error) {
+	timeStart := time.Now()
+	pages, err := e.Downloader.DownloadPagesRecursively(startPageID, nil)
+	if err != nil {
+		return nil, err
+	}
+	e.assignFileNames(pages)
+	if e.Users == nil {
+		e.Users = notionapi.NewUserDirectory()
+		if e.Downloader.Client.AuthToken != "" {
+			// not fatal, we just might not be able to resolve some mentions
+			if rsp, err := e.Downloader.LoadUserContent(); err == nil {
+				e.Users.Add(rsp.User)
+			}
+		}
+	}
+	for _, page := range pages {
+		e.Users.AddPage(page)
+		if e.LinkTitles != nil {
+			e.LinkTitles.AddPage(page)
+		}
+	}
+
+	res := &Result{
+		Dir: e.Dir,
+	}
+	e.outputFiles = nil
+	for _, page := range pages {
+		pageStart := time.Now()
+		c := e.newConverter(page)
+		var anchors map[string]string
+		if e.HeadingAnchors {
+			anchors = tohtml.HeadingAnchors(page, e.PreviousManifest.headingAnchors(page.ID))
+			if c.HeadingID == nil {
+				c.HeadingID = func(block *notionapi.Block) string {
+					return anchors[block.ID]
+				}
+			}
+		}
+		if e.LinkTitles != nil {
+			if err = e.LinkTitles.ResolvePage(page); err != nil {
+				return nil, err
+			}
+		}
+		imageText := e.recognizeImages(page)
+		if len(imageText) > 0 {
+			imageAlt := c.ImageAlt
+			c.ImageAlt = func(block *notionapi.Block) string {
+				if imageAlt != nil {
+					if alt := imageAlt(block); alt != "" {
+						return alt
+					}
+				}
+				return imageText[block.ID]
+			}
+		}
+		d, err := c.ToHTML()
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert page '%s' to HTML: %s", page.ID, err)
+		}
+		name := e.idToPath[notionapi.ToNoDashID(page.ID)]
+		if err = e.WriteFile(name, d); err != nil {
+			return nil, err
+		}
+		for _, block := range e.largeCollectionViews(page) {
+			d := e.renderCollectionViewPage(page, block)
+			if err = e.WriteFile(e.collectionViewFileName(block), d); err != nil {
+				return nil, err
+			}
+		}
+		ep := &ExportedPage{
+			Page:      page,
+			Path:      name,
+			Size:      len(d),
+			Duration:  time.Since(pageStart),
+			Metadata:  c.RenderMetadata(),
+			ImageText: imageText,
+
+			HeadingAnchors: anchors,
+		}
+		res.Pages = append(res.Pages, ep)
+		if e.AfterRenderPage != nil {
+			if err = e.AfterRenderPage(e, ep); err != nil {
+				return nil, err
+			}
+		}
+	}
+	res.Duration = time.Since(timeStart)
+	res.OutputFiles = e.outputFiles
+	if e.AfterRun != nil {
+		if err = e.AfterRun(e, res); err != nil {
+			return nil, err
+		}
+	}
+	return res, nil
+}
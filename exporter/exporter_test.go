@@ -0,0 +1,367 @@
+package exporter
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"crypto/ed25519"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ninja-1/notionapi"
+	"github.com/ninja-1/notionapi/caching_downloader"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestExporter(t *testing.T) (*Exporter, func()) {
+	d := caching_downloader.NewTestDownloader(t)
+	dir, err := ioutil.TempDir("", "notionapi-exporter")
+	require.NoError(t, err)
+	return New(d, dir), func() { os.RemoveAll(dir) }
+}
+
+// https://www.notion.so/Test-headers-6682351e44bb4f9ca0e149b703265bdb
+func TestExportHooks(t *testing.T) {
+	e, cleanup := newTestExporter(t)
+	defer cleanup()
+
+	var rendered []string
+	e.AfterRenderPage = func(e *Exporter, p *ExportedPage) error {
+		rendered = append(rendered, p.Path)
+		_, err := os.Stat(filepath.Join(e.Dir, p.Path))
+		return err
+	}
+	didRun := false
+	e.AfterRun = func(e *Exporter, res *Result) error {
+		didRun = true
+		require.Equal(t, len(rendered), len(res.Pages))
+		return nil
+	}
+	res, err := e.Export("6682351e44bb4f9ca0e149b703265bdb")
+	require.NoError(t, err)
+	require.True(t, didRun)
+	require.Equal(t, []string{"Test-headers-6682351e44bb4f9ca0e149b703265bdb.html"}, rendered)
+	require.Equal(t, 1, len(res.Pages))
+}
+
+func TestExportVault(t *testing.T) {
+	e, cleanup := newTestExporter(t)
+	defer cleanup()
+
+	res, err := e.ExportVault("6682351e44bb4f9ca0e149b703265bdb")
+	require.NoError(t, err)
+	require.Equal(t, 1, len(res.Pages))
+	require.Equal(t, "Test headers.md", res.Pages[0].Path)
+	d, err := ioutil.ReadFile(filepath.Join(e.Dir, res.Pages[0].Path))
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(string(d), "---\ntitle: \"Test headers\"\naliases:\n  - \"Test headers\"\n"))
+}
+
+func TestExportRoamGraph(t *testing.T) {
+	e, cleanup := newTestExporter(t)
+	defer cleanup()
+
+	_, err := e.ExportRoamGraph("6682351e44bb4f9ca0e149b703265bdb", "graph.json")
+	require.NoError(t, err)
+	d, err := ioutil.ReadFile(filepath.Join(e.Dir, "graph.json"))
+	require.NoError(t, err)
+	var graph []*RoamPage
+	require.NoError(t, json.Unmarshal(d, &graph))
+	require.Equal(t, 1, len(graph))
+	require.Equal(t, "Test headers", graph[0].Title)
+	require.Equal(t, 1, graph[0].Children[0].Heading)
+}
+
+func TestSignedManifest(t *testing.T) {
+	e, cleanup := newTestExporter(t)
+	defer cleanup()
+
+	pub, priv, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+	e.AfterRun = SignManifest(priv)
+	_, err = e.Export("6682351e44bb4f9ca0e149b703265bdb")
+	require.NoError(t, err)
+
+	m, err := VerifyManifest(e.Dir, pub)
+	require.NoError(t, err)
+	require.Equal(t, 1, len(m.Files))
+	require.Equal(t, 1, len(m.Pages))
+	require.True(t, m.Pages[0].Version > 0)
+
+	otherPub, _, _ := ed25519.GenerateKey(nil)
+	_, err = VerifyManifest(e.Dir, otherPub)
+	require.Error(t, err)
+
+	path := filepath.Join(e.Dir, m.Files[0].Path)
+	require.NoError(t, ioutil.WriteFile(path, []byte("tampered"), 0644))
+	_, err = VerifyManifest(e.Dir, pub)
+	require.Error(t, err)
+}
+
+// a page with 3 images: one recognized, one that can't be downloaded
+// and one for which OCR fails
+func TestExportOCR(t *testing.T) {
+	pageID := "8a1e3c5b7d9f4a2c8e6b4d2f0a1c3e5b"
+	dirCache, err := caching_downloader.NewDirectoryCache(caching_downloader.TestDataDir())
+	require.NoError(t, err)
+	d, err := dirCache.ReadFile(pageID + ".txt")
+	require.NoError(t, err)
+	cache := caching_downloader.NewMemoryCache()
+	require.NoError(t, cache.WriteFile(pageID+".txt", d))
+	for _, uri := range []string{"https://example.com/ocr.png", "https://example.com/ocr-error.png"} {
+		require.NoError(t, cache.WriteFile(caching_downloader.GetCacheFileNameFromURL(uri), []byte(uri)))
+	}
+	// missing.png is not in the cache and can't be downloaded
+	client := &notionapi.Client{HTTPClient: &http.Client{Transport: &notionapi.RestrictedTransport{}}}
+	var logged []string
+	downloader := caching_downloader.New(cache, client)
+	downloader.EventObserver = func(ev interface{}) {
+		if e, ok := ev.(*caching_downloader.EventError); ok {
+			logged = append(logged, e.Error)
+		}
+	}
+	dir, err := ioutil.TempDir("", "notionapi-exporter")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	e := New(downloader, dir)
+	e.OCR = func(data []byte, contentType string) (string, error) {
+		if strings.HasSuffix(string(data), "ocr-error.png") {
+			return "", errors.New("can't recognize")
+		}
+		return " Recognized text ", nil
+	}
+	res, err := e.Export(pageID)
+	require.NoError(t, err)
+	page := res.Pages[0]
+	require.Equal(t, map[string]string{"a2c3d4e5-f6a7-4b8c-9d0e-1f2a3b4c5d6e": "Recognized text"}, page.ImageText)
+	html, err := ioutil.ReadFile(filepath.Join(dir, page.Path))
+	require.NoError(t, err)
+	require.Contains(t, string(html), `alt="Recognized text"`)
+
+	var skipped []string
+	for _, s := range logged {
+		if strings.Contains(s, "skipping OCR") {
+			skipped = append(skipped, s)
+		}
+	}
+	require.Equal(t, 2, len(skipped))
+	require.Contains(t, skipped[0], "b3d4e5f6-a7b8-4c9d-8e1f-2a3b4c5d6e7f")
+	require.Contains(t, skipped[1], "can't recognize")
+}
+
+// https://www.notion.so/94167af6567043279811dc923edd1f04
+func TestExportMaxCollectionRows(t *testing.T) {
+	e, cleanup := newTestExporter(t)
+	defer cleanup()
+
+	e.MaxCollectionRows = 1
+	res, err := e.Export("94167af6567043279811dc923edd1f04")
+	require.NoError(t, err)
+	require.Equal(t, 1, len(res.Pages))
+	d, err := ioutil.ReadFile(filepath.Join(e.Dir, res.Pages[0].Path))
+	require.NoError(t, err)
+	html := string(d)
+	require.Contains(t, html, `class="collection-view-more"`)
+
+	blocks := e.largeCollectionViews(res.Pages[0].Page)
+	require.Equal(t, 1, len(blocks))
+	name := e.collectionViewFileName(blocks[0])
+	require.Equal(t, FileNameForCollectionView(blocks[0]), name)
+	require.Contains(t, html, `href="`+name+`"`)
+	d, err = ioutil.ReadFile(filepath.Join(e.Dir, name))
+	require.NoError(t, err)
+	require.NotContains(t, string(d), `class="collection-view-more"`)
+	require.Contains(t, string(d), res.Pages[0].Path)
+}
+
+func TestEstimateAndBudget(t *testing.T) {
+	e, cleanup := newTestExporter(t)
+	defer cleanup()
+
+	est, err := e.Estimate("6682351e44bb4f9ca0e149b703265bdb")
+	require.NoError(t, err)
+	require.Equal(t, 1, est.Pages)
+	require.Equal(t, 0, est.UncachedPages)
+	require.Equal(t, 0, est.APICalls)
+
+	e.Downloader.NoReadCache = true
+	est, err = e.Estimate("6682351e44bb4f9ca0e149b703265bdb")
+	require.NoError(t, err)
+	require.True(t, est.APICalls > 0)
+
+	// budget is used up so we must not make any requests
+	e.Downloader.MaxAPICalls = 5
+	e.Downloader.APICalls = 5
+	_, err = e.Export("6682351e44bb4f9ca0e149b703265bdb")
+	require.True(t, caching_downloader.IsErrBudgetExceeded(err))
+}
+
+func TestFileNames(t *testing.T) {
+	name := strings.Repeat("a", 100) + ".html"
+	short := shortenFileName(name, 50)
+	require.Equal(t, 50, len(short))
+	require.True(t, strings.HasSuffix(short, ".html"))
+	require.NotEqual(t, short, shortenFileName(strings.Repeat("a", 99)+"b.html", 50))
+	require.Equal(t, "ąę", truncateUTF8("ąęć", 5))
+
+	idA := "6682351e44bb4f9ca0e149b703265bdb"
+	idB := "94167af6567043279811dc923edd1f04"
+	titles := map[string]string{idA: "Notes.html", idB: "notes.html"}
+	namer := FileNamerFunc(func(page *notionapi.Page) string {
+		return titles[page.ID]
+	})
+	e := &Exporter{Dir: "out", FileNamer: namer}
+	e.assignFileNames([]*notionapi.Page{{ID: idA}, {ID: idB}})
+	require.Equal(t, "Notes.html", e.idToPath[idA])
+	require.Equal(t, "notes-2.html", e.idToPath[idB])
+}
+
+type cvNamer struct {
+	FileNamerFunc
+}
+
+func (cvNamer) CollectionViewFileName(block *notionapi.Block) string {
+	return "notes.html"
+}
+
+func TestCollectionViewFileNames(t *testing.T) {
+	idA := "6682351e44bb4f9ca0e149b703265bdb"
+	block := &notionapi.Block{ID: "94167af6-5670-4327-9811-dc923edd1f04"}
+	namer := cvNamer{func(page *notionapi.Page) string {
+		return "Notes.html"
+	}}
+	e := &Exporter{Dir: "out", FileNamer: namer}
+	e.assignFileNames([]*notionapi.Page{{ID: idA}})
+	require.Equal(t, "notes-2.html", e.collectionViewFileName(block))
+	require.Equal(t, "notes-2.html", e.collectionViewFileName(block))
+
+	e = &Exporter{Dir: "/out", MaxPathLength: 30}
+	e.assignFileNames(nil)
+	name := e.collectionViewFileName(block)
+	require.Equal(t, 30-len("/out")-1, len(name))
+	require.True(t, strings.HasSuffix(name, ".html"))
+}
+
+func TestExportToArchive(t *testing.T) {
+	e, cleanup := newTestExporter(t)
+	defer cleanup()
+
+	var buf bytes.Buffer
+	zo := NewZipOutput(&buf)
+	e.Output = zo
+	_, err := e.Export("6682351e44bb4f9ca0e149b703265bdb")
+	require.NoError(t, err)
+	require.NoError(t, zo.Close())
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+	require.Equal(t, 1, len(zr.File))
+	require.Equal(t, "Test-headers-6682351e44bb4f9ca0e149b703265bdb.html", zr.File[0].Name)
+
+	buf.Reset()
+	to := NewTarOutput(&buf)
+	e.Output = to
+	_, err = e.Export("6682351e44bb4f9ca0e149b703265bdb")
+	require.NoError(t, err)
+	require.NoError(t, to.Close())
+	tr := tar.NewReader(&buf)
+	hdr, err := tr.Next()
+	require.NoError(t, err)
+	require.Equal(t, "Test-headers-6682351e44bb4f9ca0e149b703265bdb.html", hdr.Name)
+	d, err := ioutil.ReadAll(tr)
+	require.NoError(t, err)
+	require.True(t, bytes.Contains(d, []byte("<html")))
+
+	// nothing was written to the directory
+	files, err := ioutil.ReadDir(e.Dir)
+	require.NoError(t, err)
+	require.Equal(t, 0, len(files))
+}
+
+func TestSignManifestToArchive(t *testing.T) {
+	e, cleanup := newTestExporter(t)
+	defer cleanup()
+
+	_, priv, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+	var buf bytes.Buffer
+	zo := NewZipOutput(&buf)
+	e.Output = zo
+	e.AfterRun = SignManifest(priv)
+	res, err := e.Export("6682351e44bb4f9ca0e149b703265bdb")
+	require.NoError(t, err)
+	require.NoError(t, zo.Close())
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+	require.Equal(t, 2, len(zr.File))
+	require.Equal(t, ManifestFileName, zr.File[1].Name)
+
+	// extract the archive and check the manifest against extracted files
+	for _, zf := range zr.File {
+		r, err := zf.Open()
+		require.NoError(t, err)
+		d, err := ioutil.ReadAll(r)
+		r.Close()
+		require.NoError(t, err)
+		require.NoError(t, ioutil.WriteFile(filepath.Join(e.Dir, zf.Name), d, 0644))
+	}
+	m, err := VerifyManifest(e.Dir, priv.Public().(ed25519.PublicKey))
+	require.NoError(t, err)
+	require.Equal(t, 1, len(m.Files))
+	require.Equal(t, res.Pages[0].Path, m.Files[0].Path)
+}
+
+func TestStableHeadingAnchors(t *testing.T) {
+	e, cleanup := newTestExporter(t)
+	defer cleanup()
+
+	_, priv, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+	e.HeadingAnchors = true
+	e.AfterRun = SignManifest(priv)
+	res, err := e.Export("6682351e44bb4f9ca0e149b703265bdb")
+	require.NoError(t, err)
+	anchors := res.Pages[0].HeadingAnchors
+	require.Equal(t, "this-is-a-sub-header", anchors["e736dec2-817e-452c-8256-f5215a7cdf0e"])
+	d, err := ioutil.ReadFile(filepath.Join(e.Dir, res.Pages[0].Path))
+	require.NoError(t, err)
+	require.Contains(t, string(d), `id="this-is-a-sub-header"`)
+
+	m, err := ReadManifest(e.Dir)
+	require.NoError(t, err)
+	require.Equal(t, anchors, m.Pages[0].Anchors)
+
+	// pretend that headings had a different text when previously published
+	m.Pages[0].Anchors["e736dec2-817e-452c-8256-f5215a7cdf0e"] = "this-is-sub-header"
+	m.Pages[0].Anchors["eee1a03e-7f07-4499-bff7-5e2f1396f76a"] = "something-else"
+	e.PreviousManifest = m
+	res, err = e.Export("6682351e44bb4f9ca0e149b703265bdb")
+	require.NoError(t, err)
+	anchors = res.Pages[0].HeadingAnchors
+	require.Equal(t, "this-is-sub-header", anchors["e736dec2-817e-452c-8256-f5215a7cdf0e"])
+	require.Equal(t, "this-is-a-sub-sub-header", anchors["eee1a03e-7f07-4499-bff7-5e2f1396f76a"])
+}
+
+func TestWriteSitemap(t *testing.T) {
+	e, cleanup := newTestExporter(t)
+	defer cleanup()
+
+	e.AfterRun = WriteSitemap("https://example.com/docs/", true)
+	_, err := e.Export("6682351e44bb4f9ca0e149b703265bdb")
+	require.NoError(t, err)
+	d, err := ioutil.ReadFile(filepath.Join(e.Dir, SitemapFileName))
+	require.NoError(t, err)
+	s := string(d)
+	require.Contains(t, s, `<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">`)
+	require.Contains(t, s, "<loc>https://example.com/docs/Test-headers-6682351e44bb4f9ca0e149b703265bdb.html</loc>")
+	require.Contains(t, s, "<lastmod>")
+	d, err = ioutil.ReadFile(filepath.Join(e.Dir, RobotsFileName))
+	require.NoError(t, err)
+	require.Contains(t, string(d), "Sitemap: https://example.com/docs/sitemap.xml\n")
+}
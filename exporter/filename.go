@@ -0,0 +1,154 @@
+package exporter
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/ninja-1/notionapi"
+)
+
+// WindowsMaxPath is the maximum length of a path on Windows (MAX_PATH)
+// and a good value for Exporter.MaxPathLength if the files can end up there
+const WindowsMaxPath = 259
+
+// FileNamer decides names of files of exported pages
+type FileNamer interface {
+	FileName(page *notionapi.Page) string
+}
+
+// CollectionViewFileNamer can be implemented by a FileNamer to also decide
+// names of files with all rows of large collection views (see
+// Exporter.MaxCollectionRows). Otherwise we use FileNameForCollectionView
+type CollectionViewFileNamer interface {
+	CollectionViewFileName(block *notionapi.Block) string
+}
+
+// FileNamerFunc is a function that implements FileNamer
+type FileNamerFunc func(page *notionapi.Page) string
+
+// FileName returns name of the file for a page
+func (f FileNamerFunc) FileName(page *notionapi.Page) string {
+	return f(page)
+}
+
+func shortHash(s string) string {
+	h := sha1.Sum([]byte(s))
+	return hex.EncodeToString(h[:])[:8]
+}
+
+var (
+	// FullIDNamer names files by id of the page e.g.
+	// "6682351e44bb4f9ca0e149b703265bdb.html"
+	FullIDNamer FileNamer = FileNamerFunc(func(page *notionapi.Page) string {
+		return notionapi.ToNoDashID(page.ID) + ".html"
+	})
+	// ShortHashNamer names files by a short hash of id of the page
+	// e.g. "1a2b3c4d.html"
+	ShortHashNamer FileNamer = FileNamerFunc(func(page *notionapi.Page) string {
+		return shortHash(notionapi.ToNoDashID(page.ID)) + ".html"
+	})
+	// SlugNamer names files by title of the page e.g. "Test-headers.html".
+	// Pages with the same title get a numeric suffix
+	SlugNamer FileNamer = FileNamerFunc(func(page *notionapi.Page) string {
+		title := notionapi.SafeName(page.Root().Title)
+		if title == "" {
+			return notionapi.ToNoDashID(page.ID) + ".html"
+		}
+		return title + ".html"
+	})
+	// HybridNamer names files by title and id of the page e.g.
+	// "Test-headers-6682351e44bb4f9ca0e149b703265bdb.html". It's the default
+	HybridNamer FileNamer = FileNamerFunc(FileNameForPage)
+)
+
+// truncateUTF8 returns the longest prefix of s not longer than n bytes
+// that doesn't split a character
+func truncateUTF8(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
+
+// shortenFileName shortens name to at most maxLen bytes. To keep shortened
+// names unique, we replace the end with a hash of the whole name
+func shortenFileName(name string, maxLen int) string {
+	if maxLen <= 0 || len(name) <= maxLen {
+		return name
+	}
+	ext := filepath.Ext(name)
+	hash := shortHash(name)
+	n := maxLen - len(ext) - len(hash) - 1
+	if n <= 0 {
+		return hash + ext
+	}
+	base := truncateUTF8(strings.TrimSuffix(name, ext), n)
+	return base + "-" + hash + ext
+}
+
+// assignFileNames sets names of files for pages, making sure they're
+// unique (also on case-insensitive file systems) and fit in MaxPathLength
+func (e *Exporter) assignFileNames(pages []*notionapi.Page) {
+	namer := e.FileNamer
+	if namer == nil {
+		namer = HybridNamer
+	}
+	maxLen := 0
+	if e.MaxPathLength > 0 {
+		dir, err := filepath.Abs(e.Dir)
+		if err != nil {
+			dir = e.Dir
+		}
+		// at least enough for a hash
+		maxLen = e.MaxPathLength - len(dir) - 1
+		if maxLen < 16 {
+			maxLen = 16
+		}
+	}
+	e.maxNameLen = maxLen
+	e.idToPath = map[string]string{}
+	e.cvToPath = map[string]string{}
+	e.takenNames = map[string]bool{}
+	for _, page := range pages {
+		e.idToPath[notionapi.ToNoDashID(page.ID)] = e.uniqueFileName(namer.FileName(page))
+	}
+}
+
+// uniqueFileName shortens name to fit in MaxPathLength and adds a numeric
+// suffix if it's already taken
+func (e *Exporter) uniqueFileName(name string) string {
+	res := shortenFileName(name, e.maxNameLen)
+	ext := filepath.Ext(res)
+	for i := 2; e.takenNames[strings.ToLower(res)]; i++ {
+		suffix := fmt.Sprintf("-%d", i)
+		res = shortenFileName(name, e.maxNameLen-len(suffix))
+		res = strings.TrimSuffix(res, ext) + suffix + ext
+	}
+	e.takenNames[strings.ToLower(res)] = true
+	return res
+}
+
+// collectionViewFileName returns name of the file with all rows of
+// a collection view block, assigning it on first use
+func (e *Exporter) collectionViewFileName(block *notionapi.Block) string {
+	id := notionapi.ToNoDashID(block.ID)
+	if name, ok := e.cvToPath[id]; ok {
+		return name
+	}
+	name := ""
+	if namer, ok := e.FileNamer.(CollectionViewFileNamer); ok {
+		name = namer.CollectionViewFileName(block)
+	} else {
+		name = FileNameForCollectionView(block)
+	}
+	name = e.uniqueFileName(name)
+	e.cvToPath[id] = name
+	return name
+}
@@ -0,0 +1,284 @@
+package exporter
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/ninja-1/notionapi"
+)
+
+// ManifestFileName is the name of a file with signed manifest
+const ManifestFileName = "manifest.json"
+
+// ManifestFile describes an exported file
+type ManifestFile struct {
+	// path relative to export directory, with forward slashes
+	Path   string `json:"path"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+// ManifestPage describes a version of a Notion page that was exported
+type ManifestPage struct {
+	ID             string `json:"id"`
+	Version        int64  `json:"version"`
+	LastEditedTime int64  `json:"last_edited_time"`
+	Path           string `json:"path"`
+	// anchors of headings keyed by block id (see Exporter.HeadingAnchors)
+	Anchors map[string]string `json:"anchors,omitempty"`
+}
+
+// Manifest lists exported files and pages
+type Manifest struct {
+	CreatedAt time.Time       `json:"created_at"`
+	Files     []*ManifestFile `json:"files"`
+	Pages     []*ManifestPage `json:"pages"`
+}
+
+// SignedManifest is what we write to ManifestFileName
+type SignedManifest struct {
+	// JSON of Manifest. Signature is of its compact form
+	Manifest json.RawMessage `json:"manifest"`
+	// base64-encoded ed25519 signature of Manifest
+	Signature string `json:"signature"`
+	// base64-encoded public key that can verify the signature
+	PublicKey string `json:"public_key"`
+}
+
+func sha256OfFile(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err = io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func newManifestFile(name string, data []byte) *ManifestFile {
+	sum := sha256.Sum256(data)
+	return &ManifestFile{
+		Path:   filepath.ToSlash(name),
+		Size:   int64(len(data)),
+		SHA256: hex.EncodeToString(sum[:]),
+	}
+}
+
+// hashFiles returns all files in dir except the manifest, sorted by path
+func hashFiles(dir string) ([]*ManifestFile, error) {
+	var res []*ManifestFile
+	err := filepath.Walk(dir, func(path string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if fi.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if rel == ManifestFileName {
+			return nil
+		}
+		sha, err := sha256OfFile(path)
+		if err != nil {
+			return err
+		}
+		res = append(res, &ManifestFile{Path: rel, Size: fi.Size(), SHA256: sha})
+		return nil
+	})
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].Path < res[j].Path
+	})
+	return res, err
+}
+
+// outputFiles returns files written to Output except the manifest,
+// sorted by path
+func outputFiles(res *Result) []*ManifestFile {
+	var files []*ManifestFile
+	for _, f := range res.OutputFiles {
+		if f.Path != ManifestFileName {
+			files = append(files, f)
+		}
+	}
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].Path < files[j].Path
+	})
+	return files
+}
+
+// NewManifest returns a manifest for the result of Export. Files written
+// to Exporter.Output are hashed as they're written, otherwise we hash
+// files in Dir
+func NewManifest(res *Result) (*Manifest, error) {
+	var files []*ManifestFile
+	var err error
+	if len(res.OutputFiles) > 0 {
+		files = outputFiles(res)
+	} else if files, err = hashFiles(res.Dir); err != nil {
+		return nil, err
+	}
+	m := &Manifest{
+		CreatedAt: time.Now().UTC(),
+		Files:     files,
+	}
+	for _, ep := range res.Pages {
+		root := ep.Page.Root()
+		mp := &ManifestPage{
+			ID:             notionapi.ToNoDashID(ep.Page.ID),
+			Version:        root.Version,
+			LastEditedTime: root.LastEditedTime,
+			Path:           filepath.ToSlash(ep.Path),
+			Anchors:        ep.HeadingAnchors,
+		}
+		m.Pages = append(m.Pages, mp)
+	}
+	return m, nil
+}
+
+// headingAnchors returns anchors of headings of a page with a given id.
+// m can be nil
+func (m *Manifest) headingAnchors(pageID string) map[string]string {
+	if m == nil {
+		return nil
+	}
+	id := notionapi.ToNoDashID(pageID)
+	for _, mp := range m.Pages {
+		if mp.ID == id {
+			return mp.Anchors
+		}
+	}
+	return nil
+}
+
+// ReadManifest reads the manifest from ManifestFileName in dir, without
+// verifying it (see VerifyManifest). Returns nil if there's no manifest
+func ReadManifest(dir string) (*Manifest, error) {
+	d, err := ioutil.ReadFile(filepath.Join(dir, ManifestFileName))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var sm SignedManifest
+	if err = json.Unmarshal(d, &sm); err != nil {
+		return nil, err
+	}
+	var m Manifest
+	if err = json.Unmarshal(sm.Manifest, &m); err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+// Sign signs the manifest with a private key
+func (m *Manifest) Sign(key ed25519.PrivateKey) (*SignedManifest, error) {
+	d, err := json.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+	sig := ed25519.Sign(key, d)
+	pub := key.Public().(ed25519.PublicKey)
+	res := &SignedManifest{
+		Manifest:  d,
+		Signature: base64.StdEncoding.EncodeToString(sig),
+		PublicKey: base64.StdEncoding.EncodeToString(pub),
+	}
+	return res, nil
+}
+
+// SignManifest returns a function that can be used as Exporter.AfterRun.
+// It writes a manifest signed with key to ManifestFileName, in Dir or
+// in Exporter.Output if it's set
+func SignManifest(key ed25519.PrivateKey) func(e *Exporter, res *Result) error {
+	return func(e *Exporter, res *Result) error {
+		m, err := NewManifest(res)
+		if err != nil {
+			return err
+		}
+		sm, err := m.Sign(key)
+		if err != nil {
+			return err
+		}
+		d, err := json.MarshalIndent(sm, "", "  ")
+		if err != nil {
+			return err
+		}
+		return e.WriteFile(ManifestFileName, d)
+	}
+}
+
+// VerifyManifest checks that files in dir match the manifest and that
+// the manifest was signed by pub. Returns the manifest if everything matches
+func VerifyManifest(dir string, pub ed25519.PublicKey) (*Manifest, error) {
+	d, err := ioutil.ReadFile(filepath.Join(dir, ManifestFileName))
+	if err != nil {
+		return nil, err
+	}
+	var sm SignedManifest
+	if err = json.Unmarshal(d, &sm); err != nil {
+		return nil, err
+	}
+	sig, err := base64.StdEncoding.DecodeString(sm.Signature)
+	if err != nil {
+		return nil, err
+	}
+	// the manifest was signed in compact form but we write it indented
+	var signed bytes.Buffer
+	if err = json.Compact(&signed, sm.Manifest); err != nil {
+		return nil, err
+	}
+	if !ed25519.Verify(pub, signed.Bytes(), sig) {
+		return nil, errors.New("manifest signature is not valid")
+	}
+	var m Manifest
+	if err = json.Unmarshal(sm.Manifest, &m); err != nil {
+		return nil, err
+	}
+	files, err := hashFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+	expected := map[string]*ManifestFile{}
+	for _, f := range m.Files {
+		expected[f.Path] = f
+	}
+	for _, f := range files {
+		exp := expected[f.Path]
+		if exp == nil {
+			return nil, fmt.Errorf("file '%s' is not in the manifest", f.Path)
+		}
+		if exp.SHA256 != f.SHA256 || exp.Size != f.Size {
+			return nil, fmt.Errorf("file '%s' was modified", f.Path)
+		}
+		delete(expected, f.Path)
+	}
+	if len(expected) > 0 {
+		var missing []string
+		for path := range expected {
+			missing = append(missing, path)
+		}
+		sort.Strings(missing)
+		return nil, fmt.Errorf("file '%s' is missing", missing[0])
+	}
+	return &m, nil
+}
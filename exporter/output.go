@@ -0,0 +1,85 @@
+package exporter
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"io"
+	"path/filepath"
+	"time"
+)
+
+// Output is where Exporter writes files, if it's not a directory
+type Output interface {
+	// WriteFile writes a file with a given name (a path relative to
+	// the root of the output)
+	WriteFile(name string, data []byte) error
+}
+
+// ZipOutput writes exported files to a zip archive as they're exported,
+// without temporary files. Close must be called after Export to finish
+// the archive
+type ZipOutput struct {
+	w *zip.Writer
+}
+
+// NewZipOutput returns Output that writes a zip archive to w
+func NewZipOutput(w io.Writer) *ZipOutput {
+	return &ZipOutput{
+		w: zip.NewWriter(w),
+	}
+}
+
+// WriteFile adds a file to the archive
+func (o *ZipOutput) WriteFile(name string, data []byte) error {
+	hdr := &zip.FileHeader{
+		Name:     filepath.ToSlash(name),
+		Method:   zip.Deflate,
+		Modified: time.Now(),
+	}
+	f, err := o.w.CreateHeader(hdr)
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(data)
+	return err
+}
+
+// Close finishes writing the archive. It doesn't close the underlying writer
+func (o *ZipOutput) Close() error {
+	return o.w.Close()
+}
+
+// TarOutput writes exported files to a tar archive as they're exported.
+// For .tar.gz, wrap w in gzip.Writer. Close must be called after Export
+// to finish the archive
+type TarOutput struct {
+	w *tar.Writer
+}
+
+// NewTarOutput returns Output that writes a tar archive to w
+func NewTarOutput(w io.Writer) *TarOutput {
+	return &TarOutput{
+		w: tar.NewWriter(w),
+	}
+}
+
+// WriteFile adds a file to the archive
+func (o *TarOutput) WriteFile(name string, data []byte) error {
+	hdr := &tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     filepath.ToSlash(name),
+		Mode:     0644,
+		Size:     int64(len(data)),
+		ModTime:  time.Now(),
+	}
+	if err := o.w.WriteHeader(hdr); err != nil {
+		return err
+	}
+	_, err := o.w.Write(data)
+	return err
+}
+
+// Close finishes writing the archive. It doesn't close the underlying writer
+func (o *TarOutput) Close() error {
+	return o.w.Close()
+}
@@ -0,0 +1,172 @@
+package exporter
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ninja-1/notionapi"
+	"github.com/ninja-1/notionapi/tomarkdown"
+)
+
+// RoamBlock is a block in Roam Research JSON export format,
+// which can also be imported by Logseq
+type RoamBlock struct {
+	String     string       `json:"string"`
+	UID        string       `json:"uid"`
+	Heading    int          `json:"heading,omitempty"`
+	CreateTime int64        `json:"create-time,omitempty"`
+	EditTime   int64        `json:"edit-time,omitempty"`
+	Children   []*RoamBlock `json:"children,omitempty"`
+}
+
+// RoamPage is a page in Roam Research JSON export format
+type RoamPage struct {
+	Title      string       `json:"title"`
+	UID        string       `json:"uid"`
+	CreateTime int64        `json:"create-time,omitempty"`
+	EditTime   int64        `json:"edit-time,omitempty"`
+	Children   []*RoamBlock `json:"children,omitempty"`
+}
+
+type roamConverter struct {
+	page *notionapi.Page
+	md   *tomarkdown.Converter
+}
+
+func (r *roamConverter) inlines(block *notionapi.Block) string {
+	return r.md.GetInlineContent(block.InlineContent, true)
+}
+
+// returns text of a block in Roam markup
+func (r *roamConverter) blockString(block *notionapi.Block) string {
+	switch block.Type {
+	case notionapi.BlockTodo:
+		if block.IsChecked {
+			return "{{[[DONE]]}} " + r.inlines(block)
+		}
+		return "{{[[TODO]]}} " + r.inlines(block)
+	case notionapi.BlockCode:
+		return fmt.Sprintf("```%s\n%s```", strings.ToLower(block.CodeLanguage), block.Code)
+	case notionapi.BlockQuote:
+		return "> " + r.inlines(block)
+	case notionapi.BlockDivider:
+		return "---"
+	case notionapi.BlockImage:
+		return fmt.Sprintf("![](%s)", block.Source)
+	case notionapi.BlockPage:
+		return r.md.Wikilink(block.ID, block.Title)
+	case notionapi.BlockCollectionView, notionapi.BlockCollectionViewPage:
+		if col := r.page.CollectionByID(block.CollectionID); col != nil {
+			return col.GetName()
+		}
+		return ""
+	case notionapi.BlockBookmark, notionapi.BlockEmbed, notionapi.BlockVideo,
+		notionapi.BlockAudio, notionapi.BlockFile, notionapi.BlockPDF,
+		notionapi.BlockTweet:
+		return block.Source
+	}
+	return r.inlines(block)
+}
+
+func (r *roamConverter) blocks(blocks []*notionapi.Block) []*RoamBlock {
+	var res []*RoamBlock
+	for _, block := range blocks {
+		if block == nil {
+			continue
+		}
+		// Roam has no columns, content of columns is inlined
+		if block.Type == notionapi.BlockColumnList || block.Type == notionapi.BlockColumn {
+			res = append(res, r.blocks(block.Content)...)
+			continue
+		}
+		rb := &RoamBlock{
+			String:     r.blockString(block),
+			UID:        block.ID,
+			Heading:    notionapi.HeadingLevel(block),
+			CreateTime: block.CreatedTime,
+			EditTime:   block.LastEditedTime,
+		}
+		// content of sub-pages is exported as separate pages
+		if block.Type != notionapi.BlockPage {
+			rb.Children = r.blocks(block.Content)
+		}
+		res = append(res, rb)
+	}
+	return res
+}
+
+// ToRoamPage converts a page to Roam Research JSON format. Links to other
+// pages are [[wikilinks]] to the title returned by wikilinkTarget, which
+// should be a title of the linked page in the exported graph
+func ToRoamPage(page *notionapi.Page, wikilinkTarget func(pageID string) string) *RoamPage {
+	md := tomarkdown.NewConverter(page)
+	md.Wikilinks = true
+	md.WikilinkTarget = wikilinkTarget
+	r := &roamConverter{
+		page: page,
+		md:   md,
+	}
+	root := page.Root()
+	title := root.Title
+	if wikilinkTarget != nil {
+		if s := wikilinkTarget(page.ID); s != "" {
+			title = s
+		}
+	}
+	return &RoamPage{
+		Title:      title,
+		UID:        root.ID,
+		CreateTime: root.CreatedTime,
+		EditTime:   root.LastEditedTime,
+		Children:   r.blocks(root.Content),
+	}
+}
+
+// ExportRoamGraph downloads a page with a given id and all its sub-pages
+// and writes them to Dir as a single JSON file in Roam Research format,
+// which can be imported to Roam Research and Logseq
+func (e *Exporter) ExportRoamGraph(startPageID string, name string) (*Result, error) {
+	timeStart := time.Now()
+	pages, err := e.Downloader.DownloadPagesRecursively(startPageID, nil)
+	if err != nil {
+		return nil, err
+	}
+	targets := wikilinkTargets(pages)
+	wikilinkTarget := func(pageID string) string {
+		return targets[notionapi.ToNoDashID(pageID)]
+	}
+	var graph []*RoamPage
+	res := &Result{
+		Dir: e.Dir,
+	}
+	for _, page := range pages {
+		graph = append(graph, ToRoamPage(page, wikilinkTarget))
+		res.Pages = append(res.Pages, &ExportedPage{
+			Page: page,
+			Path: name,
+		})
+	}
+	d, err := json.MarshalIndent(graph, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err = e.WriteFile(name, d); err != nil {
+		return nil, err
+	}
+	for _, ep := range res.Pages {
+		if e.AfterRenderPage != nil {
+			if err = e.AfterRenderPage(e, ep); err != nil {
+				return nil, err
+			}
+		}
+	}
+	res.Duration = time.Since(timeStart)
+	if e.AfterRun != nil {
+		if err = e.AfterRun(e, res); err != nil {
+			return nil, err
+		}
+	}
+	return res, nil
+}
@@ -0,0 +1,92 @@
+package exporter
+
+import (
+	"encoding/xml"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	// SitemapFileName is the name of a file with sitemap
+	SitemapFileName = "sitemap.xml"
+	// RobotsFileName is the name of robots.txt file
+	RobotsFileName = "robots.txt"
+)
+
+// SitemapURL is an entry of a sitemap
+type SitemapURL struct {
+	Loc string `xml:"loc"`
+	// LastMod is the time of the last edit of the page in W3C format
+	LastMod string `xml:"lastmod,omitempty"`
+}
+
+// Sitemap is a sitemap as described in https://www.sitemaps.org/protocol.html
+type Sitemap struct {
+	XMLName xml.Name      `xml:"http://www.sitemaps.org/schemas/sitemap/0.9 urlset"`
+	URLs    []*SitemapURL `xml:"url"`
+}
+
+// returns url of a file with a given path, relative to baseURL
+func fileURL(baseURL string, path string) string {
+	parts := strings.Split(filepath.ToSlash(path), "/")
+	for i, part := range parts {
+		parts[i] = url.PathEscape(part)
+	}
+	return strings.TrimSuffix(baseURL, "/") + "/" + strings.Join(parts, "/")
+}
+
+// NewSitemap returns a sitemap of exported pages. baseURL is the url
+// where the export directory is published e.g. https://example.com/docs.
+// lastmod of a page is its last edit time
+func NewSitemap(res *Result, baseURL string) *Sitemap {
+	sm := &Sitemap{}
+	for _, ep := range res.Pages {
+		u := &SitemapURL{
+			Loc: fileURL(baseURL, ep.Path),
+		}
+		root := ep.Page.Root()
+		if root.LastEditedTime > 0 {
+			u.LastMod = root.LastEditedOn().UTC().Format(time.RFC3339)
+		}
+		sm.URLs = append(sm.URLs, u)
+	}
+	return sm
+}
+
+// ToXML returns the sitemap as XML
+func (sm *Sitemap) ToXML() ([]byte, error) {
+	d, err := xml.MarshalIndent(sm, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), d...), nil
+}
+
+// NewRobotsTxt returns robots.txt that allows everything and points
+// to the sitemap published at baseURL
+func NewRobotsTxt(baseURL string) []byte {
+	s := "User-agent: *\nAllow: /\n\nSitemap: " + fileURL(baseURL, SitemapFileName) + "\n"
+	return []byte(s)
+}
+
+// WriteSitemap returns a function that can be used as Exporter.AfterRun.
+// It writes SitemapFileName with all exported pages and, if robots
+// is true, RobotsFileName. baseURL is the url where the export
+// is published
+func WriteSitemap(baseURL string, robots bool) func(e *Exporter, res *Result) error {
+	return func(e *Exporter, res *Result) error {
+		d, err := NewSitemap(res, baseURL).ToXML()
+		if err != nil {
+			return err
+		}
+		if err = e.WriteFile(SitemapFileName, d); err != nil {
+			return err
+		}
+		if !robots {
+			return nil
+		}
+		return e.WriteFile(RobotsFileName, NewRobotsTxt(baseURL))
+	}
+}
@@ -0,0 +1,75 @@
+package exporter
+
+import (
+	"strings"
+	"time"
+
+	"github.com/ninja-1/notionapi"
+	"github.com/ninja-1/notionapi/tomarkdown"
+)
+
+// assigns unique wikilink targets (file names without .md) to pages.
+// Pages with the same title get id appended
+func wikilinkTargets(pages []*notionapi.Page) map[string]string {
+	res := map[string]string{}
+	used := map[string]bool{}
+	for _, page := range pages {
+		name := strings.TrimSuffix(tomarkdown.WikilinkFileName(page.Root().Title), ".md")
+		if used[strings.ToLower(name)] {
+			name += " " + notionapi.ToNoDashID(page.ID)
+		}
+		used[strings.ToLower(name)] = true
+		res[notionapi.ToNoDashID(page.ID)] = name
+	}
+	return res
+}
+
+// ExportVault downloads a page with a given id and all its sub-pages
+// and writes them to Dir as markdown files, one file per page, with
+// [[wikilinks]] between pages, so that Dir can be opened as
+// Obsidian or Logseq vault
+func (e *Exporter) ExportVault(startPageID string) (*Result, error) {
+	timeStart := time.Now()
+	pages, err := e.Downloader.DownloadPagesRecursively(startPageID, nil)
+	if err != nil {
+		return nil, err
+	}
+	targets := wikilinkTargets(pages)
+	wikilinkTarget := func(pageID string) string {
+		return targets[notionapi.ToNoDashID(pageID)]
+	}
+
+	res := &Result{
+		Dir: e.Dir,
+	}
+	for _, page := range pages {
+		pageStart := time.Now()
+		c := tomarkdown.NewConverter(page)
+		c.Wikilinks = true
+		c.WikilinkTarget = wikilinkTarget
+		d := c.ToMarkdown()
+		name := targets[notionapi.ToNoDashID(page.ID)] + ".md"
+		if err = e.WriteFile(name, d); err != nil {
+			return nil, err
+		}
+		ep := &ExportedPage{
+			Page:     page,
+			Path:     name,
+			Size:     len(d),
+			Duration: time.Since(pageStart),
+		}
+		res.Pages = append(res.Pages, ep)
+		if e.AfterRenderPage != nil {
+			if err = e.AfterRenderPage(e, ep); err != nil {
+				return nil, err
+			}
+		}
+	}
+	res.Duration = time.Since(timeStart)
+	if e.AfterRun != nil {
+		if err = e.AfterRun(e, res); err != nil {
+			return nil, err
+		}
+	}
+	return res, nil
+}
@@ -0,0 +1,58 @@
+package notionapi
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"strings"
+)
+
+// fakeHandler returns response to a request with body d: *http.Response,
+// a string or a value encoded as JSON
+type fakeHandler func(req *http.Request, d []byte) interface{}
+
+// fakeTransport pretends to be a Notion server. Requests are handled by
+// handlers keyed by URL path (e.g. "/api/v3/getRecordValues"), unknown
+// paths get {}
+type fakeTransport struct {
+	handlers map[string]fakeHandler
+	// paths of all requests, in order
+	paths []string
+}
+
+func newFakeClient(handlers map[string]fakeHandler) (*Client, *fakeTransport) {
+	transport := &fakeTransport{handlers: handlers}
+	return &Client{HTTPClient: &http.Client{Transport: transport}}, transport
+}
+
+func fakeResponse(statusCode int, header http.Header, body string) *http.Response {
+	return &http.Response{
+		StatusCode:    statusCode,
+		Header:        header,
+		ContentLength: int64(len(body)),
+		Body:          ioutil.NopCloser(strings.NewReader(body)),
+	}
+}
+
+func (t *fakeTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var d []byte
+	if req.Body != nil {
+		d, _ = ioutil.ReadAll(req.Body)
+	}
+	t.paths = append(t.paths, req.URL.Path)
+	var v interface{} = map[string]interface{}{}
+	if h := t.handlers[req.URL.Path]; h != nil {
+		v = h(req, d)
+	}
+	rsp, ok := v.(*http.Response)
+	if !ok {
+		body, isString := v.(string)
+		if !isString {
+			js, _ := json.Marshal(v)
+			body = string(js)
+		}
+		rsp = fakeResponse(http.StatusOK, http.Header{}, body)
+	}
+	rsp.Request = req
+	return rsp, nil
+}
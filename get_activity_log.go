@@ -1,10 +1,17 @@
 package notionapi
 
+import "time"
+
+// number of activities GetRecentEdits asks for in one request
+const activityLogPageSize = 50
+
 // /api/v3/getActivityLog request
 type getActivityLogRequest struct {
 	SpaceID         string `json:"spaceId"`
 	StartingAfterID string `json:"startingAfterId,omitempty"`
 	Limit           int    `json:"limit"`
+	// if set, only activities on this page
+	NavigableBlockID string `json:"navigableBlockId,omitempty"`
 }
 
 // LoadPageChunkResponse is a response to /api/v3/loadPageChunk api
@@ -19,12 +26,28 @@ type GetActivityLogResponse struct {
 // GetActivityLog executes a raw API call /api/v3/getActivityLog.
 // If startingAfterId is "", starts at the most recent log entry.
 func (c *Client) GetActivityLog(spaceID string, startingAfterID string, limit int) (*GetActivityLogResponse, error) {
-	apiURL := "/api/v3/getActivityLog"
 	req := &getActivityLogRequest{
 		SpaceID:         spaceID,
 		StartingAfterID: startingAfterID,
 		Limit:           limit,
 	}
+	return c.getActivityLog(req)
+}
+
+// GetPageActivityLog is like GetActivityLog but only returns activities
+// on a page with a given id
+func (c *Client) GetPageActivityLog(spaceID string, pageID string, startingAfterID string, limit int) (*GetActivityLogResponse, error) {
+	req := &getActivityLogRequest{
+		SpaceID:          spaceID,
+		StartingAfterID:  startingAfterID,
+		Limit:            limit,
+		NavigableBlockID: ToDashID(pageID),
+	}
+	return c.getActivityLog(req)
+}
+
+func (c *Client) getActivityLog(req *getActivityLogRequest) (*GetActivityLogResponse, error) {
+	apiURL := "/api/v3/getActivityLog"
 	var rsp GetActivityLogResponse
 	var err error
 	if rsp.RawJSON, err = doNotionAPI(c, apiURL, req, &rsp); err != nil {
@@ -40,3 +63,88 @@ func (c *Client) GetActivityLog(spaceID string, startingAfterID string, limit in
 	}
 	return &rsp, nil
 }
+
+// EditRecord describes a single edit: who made it, when and to which block
+type EditRecord struct {
+	ActivityID string
+	// e.g. "block-edited", "block-created", "comment-created"
+	Type string
+	Time time.Time
+	// ids of users who made the edit
+	AuthorIDs []string
+	BlockID   string
+	// id of the page the edited block is on
+	PageID string
+	// set if the edit was to a collection or a row of a collection
+	CollectionID    string
+	CollectionRowID string
+}
+
+// Edits returns edits of activities in the response, most recent first
+func (rsp *GetActivityLogResponse) Edits() []*EditRecord {
+	var res []*EditRecord
+	if rsp.RecordMap == nil {
+		return nil
+	}
+	for _, id := range rsp.ActivityIDs {
+		r := rsp.RecordMap.Activities[id]
+		if r == nil || r.Activity == nil {
+			continue
+		}
+		a := r.Activity
+		for i := range a.Edits {
+			e := &a.Edits[i]
+			rec := &EditRecord{
+				ActivityID:      a.ID,
+				Type:            e.Type,
+				Time:            time.Unix(e.Timestamp/1000, (e.Timestamp%1000)*int64(time.Millisecond)),
+				BlockID:         e.BlockID,
+				PageID:          e.NavigableBlockID,
+				CollectionID:    e.CollectionID,
+				CollectionRowID: e.CollectionRowID,
+			}
+			if rec.PageID == "" {
+				rec.PageID = a.NavigableBlockID
+			}
+			if rec.BlockID == "" {
+				rec.BlockID = a.ParentID
+			}
+			for _, author := range e.Authors {
+				if author.Table == TableUser {
+					rec.AuthorIDs = append(rec.AuthorIDs, author.ID)
+				}
+			}
+			res = append(res, rec)
+		}
+	}
+	return res
+}
+
+// GetRecentEdits returns up to max most recent edits in a space or,
+// if pageID is not empty, on a page. It pages through the activity log
+// as needed
+func (c *Client) GetRecentEdits(spaceID string, pageID string, max int) ([]*EditRecord, error) {
+	var res []*EditRecord
+	startingAfterID := ""
+	for len(res) < max {
+		var rsp *GetActivityLogResponse
+		var err error
+		if pageID == "" {
+			rsp, err = c.GetActivityLog(spaceID, startingAfterID, activityLogPageSize)
+		} else {
+			rsp, err = c.GetPageActivityLog(spaceID, pageID, startingAfterID, activityLogPageSize)
+		}
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, rsp.Edits()...)
+		if len(rsp.ActivityIDs) < activityLogPageSize || rsp.NextID == "" || rsp.NextID == startingAfterID {
+			break
+		}
+		startingAfterID = rsp.NextID
+	}
+	if len(res) > max {
+		res = res[:max]
+	}
+	return res, nil
+}
@@ -0,0 +1,105 @@
+package notionapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// pretends to be a server with an activity log of a single activity or,
+// if total is > 0, of total activities with one edit each
+type activityLogServer struct {
+	total    int
+	requests []getActivityLogRequest
+}
+
+func (s *activityLogServer) getActivityLog(req *http.Request, d []byte) interface{} {
+	var ar getActivityLogRequest
+	_ = json.Unmarshal(d, &ar)
+	s.requests = append(s.requests, ar)
+	edit := func(blockID string, ts int64) map[string]interface{} {
+		return map[string]interface{}{
+			"type":      "block-edited",
+			"block_id":  blockID,
+			"timestamp": ts,
+			"authors":   []interface{}{map[string]interface{}{"id": "u1", "table": "notion_user"}},
+		}
+	}
+	if s.total == 0 {
+		return map[string]interface{}{
+			"activityIds": []string{"a1"},
+			"recordMap": map[string]interface{}{
+				"activity": map[string]interface{}{
+					"a1": map[string]interface{}{
+						"role": "reader",
+						"value": map[string]interface{}{
+							"id":                 "a1",
+							"navigable_block_id": ar.NavigableBlockID,
+							"edits":              []interface{}{edit("b2", 1570146091667), edit("b1", 1570146000000)},
+						},
+					},
+				},
+			},
+		}
+	}
+	start := 0
+	if ar.StartingAfterID != "" {
+		start, _ = strconv.Atoi(strings.TrimPrefix(ar.StartingAfterID, "a"))
+		start++
+	}
+	ids := []string{}
+	activities := map[string]interface{}{}
+	for i := start; i < s.total && len(ids) < ar.Limit; i++ {
+		id := fmt.Sprintf("a%d", i)
+		ids = append(ids, id)
+		activities[id] = map[string]interface{}{
+			"role": "reader",
+			"value": map[string]interface{}{
+				"id":    id,
+				"edits": []interface{}{edit(fmt.Sprintf("b%d", i), 1570146000000)},
+			},
+		}
+	}
+	return map[string]interface{}{
+		"activityIds": ids,
+		"recordMap":   map[string]interface{}{"activity": activities},
+	}
+}
+
+func TestGetRecentEdits(t *testing.T) {
+	s := &activityLogServer{}
+	c, _ := newFakeClient(map[string]fakeHandler{"/api/v3/getActivityLog": s.getActivityLog})
+	edits, err := c.GetRecentEdits("space", "94167af6567043279811dc923edd1f04", 10)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(s.requests))
+	assert.Equal(t, "94167af6-5670-4327-9811-dc923edd1f04", s.requests[0].NavigableBlockID)
+	assert.Equal(t, 2, len(edits))
+	e := edits[0]
+	assert.Equal(t, "a1", e.ActivityID)
+	assert.Equal(t, "b2", e.BlockID)
+	assert.Equal(t, "94167af6-5670-4327-9811-dc923edd1f04", e.PageID)
+	assert.Equal(t, []string{"u1"}, e.AuthorIDs)
+	assert.Equal(t, int64(1570146091667), e.Time.UnixNano()/int64(time.Millisecond))
+
+	edits, err = c.GetRecentEdits("space", "", 1)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(edits))
+	assert.Equal(t, "", s.requests[1].NavigableBlockID)
+
+	// the log has 2 pages
+	s = &activityLogServer{total: activityLogPageSize + 10}
+	c, _ = newFakeClient(map[string]fakeHandler{"/api/v3/getActivityLog": s.getActivityLog})
+	edits, err = c.GetRecentEdits("space", "", 1000)
+	assert.NoError(t, err)
+	assert.Equal(t, activityLogPageSize+10, len(edits))
+	assert.Equal(t, 2, len(s.requests))
+	assert.Equal(t, "", s.requests[0].StartingAfterID)
+	assert.Equal(t, fmt.Sprintf("a%d", activityLogPageSize-1), s.requests[1].StartingAfterID)
+	assert.Equal(t, "b59", edits[59].BlockID)
+}
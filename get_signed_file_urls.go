@@ -2,10 +2,15 @@ package notionapi
 
 import (
 	"bytes"
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/hex"
 	"fmt"
 	"io"
+	"mime"
 	"net/http"
 	"net/url"
+	"path"
 	"strings"
 )
 
@@ -71,6 +76,76 @@ func (c *Client) GetSignedFileUrls(urls []string, blockIDs []string) (*GetSigned
 	return &rsp, nil
 }
 
+// IsNotionFileURL returns true if uri is a file uploaded to Notion.
+// Such files can't be accessed without signing the url
+// (see GetSignedFileUrls)
+func IsNotionFileURL(uri string) bool {
+	return strings.Contains(uri, "secure.notion-static.com/")
+}
+
+// how many urls we sign in one GetSignedFileUrls call
+const signFileURLsBatchSize = 64
+
+// SignFileURLs resolves urls of files uploaded to Notion (images,
+// attachments etc.) that are referenced by blocks in the page to signed
+// urls, which can be accessed without credentials for a limited time.
+// Use SignedFileURL to get a signed url
+func (p *Page) SignFileURLs() error {
+	if p.client == nil {
+		return fmt.Errorf("page '%s' has no client", p.ID)
+	}
+	var urls, blockIDs []string
+	seen := map[string]bool{}
+	add := func(uri, blockID string) {
+		if !IsNotionFileURL(uri) || seen[uri] || p.signedURLs[uri] != "" {
+			return
+		}
+		seen[uri] = true
+		urls = append(urls, uri)
+		blockIDs = append(blockIDs, blockID)
+	}
+	for _, id := range getBlockIDsSorted(p.idToBlock) {
+		block := p.idToBlock[id]
+		add(block.Source, block.ID)
+		if block.Type == BlockPage {
+			if fp := block.FormatPage(); fp != nil {
+				add(fp.PageIcon, block.ID)
+				add(fp.PageCover, block.ID)
+			}
+		}
+	}
+	if p.signedURLs == nil {
+		p.signedURLs = map[string]string{}
+	}
+	for len(urls) > 0 {
+		n := len(urls)
+		if n > signFileURLsBatchSize {
+			n = signFileURLsBatchSize
+		}
+		rsp, err := p.client.GetSignedFileUrls(urls[:n], blockIDs[:n])
+		if err != nil {
+			return err
+		}
+		for i, signed := range rsp.SignedUrls {
+			if i < n && signed != "" {
+				p.signedURLs[urls[i]] = signed
+			}
+		}
+		urls = urls[n:]
+		blockIDs = blockIDs[n:]
+	}
+	return nil
+}
+
+// SignedFileURL returns a signed url for a file uploaded to Notion,
+// resolved by SignFileURLs. Returns uri if it wasn't resolved
+func (p *Page) SignedFileURL(uri string) string {
+	if signed := p.signedURLs[uri]; signed != "" {
+		return signed
+	}
+	return uri
+}
+
 // DownloadFileResponse is a result of DownloadFile()
 type DownloadFileResponse struct {
 	URL           string
@@ -111,7 +186,7 @@ func maybeProxyImageURL(uri string) string {
 }
 
 func (c *Client) maybeSignImageURL(uri string, blockID string) string {
-	if !strings.HasPrefix(uri, s3URLPrefix) {
+	if !IsNotionFileURL(uri) {
 		return maybeProxyImageURL(uri)
 	}
 	/* notionapi-py does:
@@ -128,36 +203,186 @@ func (c *Client) maybeSignImageURL(uri string, blockID string) string {
 	return rsp.SignedUrls[0]
 }
 
-func (c *Client) downloadFile(uri string) (*DownloadFileResponse, error) {
+// IsContentTypeAllowed returns true if contentType matches one of allowed
+// content types (see Client.AllowedContentTypes). Empty allowed allows all
+func IsContentTypeAllowed(contentType string, allowed []string) bool {
+	if len(allowed) == 0 {
+		return true
+	}
+	// strip parameters like "; charset=utf-8"
+	contentType = strings.TrimSpace(strings.Split(contentType, ";")[0])
+	contentType = strings.ToLower(contentType)
+	for _, s := range allowed {
+		s = strings.ToLower(s)
+		if strings.HasSuffix(s, "/") {
+			if strings.HasPrefix(contentType, s) {
+				return true
+			}
+			continue
+		}
+		if contentType == s {
+			return true
+		}
+	}
+	return false
+}
+
+// checks MaxFileSize and AllowedContentTypes limits before reading the body
+func (c *Client) checkDownloadAllowed(uri string, resp *http.Response) error {
+	if c.MaxFileSize > 0 && resp.ContentLength > c.MaxFileSize {
+		return newErrDownloadSkipped(uri, "size of %d bytes is bigger than MaxFileSize of %d bytes", resp.ContentLength, c.MaxFileSize)
+	}
+	contentType := resp.Header.Get("Content-Type")
+	if !IsContentTypeAllowed(contentType, c.AllowedContentTypes) {
+		return newErrDownloadSkipped(uri, "content type '%s' is not in AllowedContentTypes", contentType)
+	}
+	return nil
+}
+
+// how many times we try to download a file if the download
+// was truncated or corrupted
+const downloadFileMaxAttempts = 3
+
+// errDownloadStatus is returned by downloadFilePart for non-2xx
+// responses. We don't retry those.
+type errDownloadStatus struct {
+	uri    string
+	status string
+}
+
+func (e *errDownloadStatus) Error() string {
+	return fmt.Sprintf("http GET '%s' failed with status %s", e.uri, e.status)
+}
+
+// verifyFileChecksum verifies md5 of the data if the server told us what
+// it should be, either in Content-MD5 header or in S3 ETag
+func verifyFileChecksum(uri string, header http.Header, d []byte) error {
+	sum := md5.Sum(d)
+	if v := header.Get("Content-MD5"); v != "" {
+		exp, err := base64.StdEncoding.DecodeString(v)
+		if err == nil && !bytes.Equal(exp, sum[:]) {
+			return fmt.Errorf("checksum mismatch for '%s': Content-MD5 is '%s'", uri, v)
+		}
+		return nil
+	}
+	// for S3, ETag of objects not uploaded in multiple parts is
+	// md5 of the content. Multi-part ETags have "-" in them
+	if !strings.Contains(uri, "amazonaws.com") {
+		return nil
+	}
+	etag := strings.Trim(header.Get("ETag"), `"`)
+	if len(etag) != 32 {
+		return nil
+	}
+	if etag != hex.EncodeToString(sum[:]) {
+		return fmt.Errorf("checksum mismatch for '%s': ETag is '%s'", uri, etag)
+	}
+	return nil
+}
+
+// downloadFilePart downloads uri and appends to buf. If buf is not empty,
+// we resume the download with a Range request. Returns headers of the
+// response and expected total size of the file (-1 if not known)
+func (c *Client) downloadFilePart(uri string, buf *bytes.Buffer) (http.Header, int64, error) {
 	req, err := http.NewRequest("GET", uri, nil)
 	if err != nil {
-		//fmt.Printf("DownloadFile: NewRequest() for '%s' failed with '%s'\n", uri, err)
-		return nil, err
+		return nil, -1, err
 	}
-	if c.AuthToken != "" {
-		req.Header.Set("cookie", fmt.Sprintf("token_v2=%v", c.AuthToken))
+	c.setCookies(req)
+	offset := int64(buf.Len())
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
 	}
 	httpClient := c.getHTTPClient()
 	resp, err := httpClient.Do(req)
 	if err != nil {
-		//fmt.Printf("DownloadFile: httpClient.Do() for '%s' failed with '%s'\n", uri, err)
-		return nil, err
+		return nil, -1, err
 	}
-	defer resp.Body.Close()
+	defer closeNoError(resp.Body)
 	if resp.StatusCode >= 400 {
-		//fmt.Printf("DownloadFile: httpClient.Do() for '%s' failed with '%s'\n", uri, resp.Status)
-		return nil, fmt.Errorf("http GET '%s' failed with status %s", uri, resp.Status)
+		return nil, -1, &errDownloadStatus{uri: uri, status: resp.Status}
 	}
-	var buf bytes.Buffer
-	_, err = io.Copy(&buf, resp.Body)
+	if offset > 0 && resp.StatusCode != http.StatusPartialContent {
+		// server doesn't support range requests so we get the whole file
+		buf.Reset()
+		offset = 0
+	}
+	if offset == 0 {
+		if err = c.checkDownloadAllowed(uri, resp); err != nil {
+			return nil, -1, err
+		}
+	}
+	total := int64(-1)
+	if resp.ContentLength >= 0 {
+		total = offset + resp.ContentLength
+	}
+	var r io.Reader = resp.Body
+	if c.MaxFileSize > 0 {
+		// Content-Length is not always sent, so also limit while reading
+		r = io.LimitReader(resp.Body, c.MaxFileSize+1-offset)
+	}
+	_, err = io.Copy(buf, r)
+	if c.MaxFileSize > 0 && int64(buf.Len()) > c.MaxFileSize {
+		return nil, -1, newErrDownloadSkipped(uri, "size is bigger than MaxFileSize of %d bytes", c.MaxFileSize)
+	}
+	return resp.Header, total, err
+}
+
+// downloadFile downloads a file, coalescing concurrent downloads
+// of the same file if DedupRequests is set
+func (c *Client) downloadFile(uri string) (*DownloadFileResponse, error) {
+	if !c.DedupRequests {
+		return c.downloadFileRetry(uri)
+	}
+	key := c.dedupKey("file", uri, fmt.Sprintf("%d\x00%v", c.MaxFileSize, c.AllowedContentTypes))
+	v, err := inflight.do(key, func() (interface{}, error) {
+		return c.downloadFileRetry(uri)
+	})
 	if err != nil {
 		return nil, err
 	}
-	rsp := &DownloadFileResponse{
-		Data:   buf.Bytes(),
-		Header: resp.Header,
+	// callers might set fields of the response so each gets a shallow
+	// copy. Data and Header are shared and must not be modified
+	res := *(v.(*DownloadFileResponse))
+	return &res, nil
+}
+
+// downloadFileRetry downloads a file, resuming truncated downloads and
+// re-trying downloads that fail checksum verification
+func (c *Client) downloadFileRetry(uri string) (*DownloadFileResponse, error) {
+	var buf bytes.Buffer
+	var err error
+	for i := 0; i < downloadFileMaxAttempts; i++ {
+		var header http.Header
+		var total int64
+		header, total, err = c.downloadFilePart(uri, &buf)
+		if err != nil {
+			switch err.(type) {
+			case *ErrDownloadSkipped, *errDownloadStatus:
+				return nil, err
+			}
+			// a network error in the middle of download. we'll resume
+			log(c, "downloadFile: download of '%s' failed after %d bytes with '%s'\n", uri, buf.Len(), err)
+			continue
+		}
+		if total >= 0 && int64(buf.Len()) < total {
+			err = fmt.Errorf("download of '%s' truncated: got %d bytes, expected %d", uri, buf.Len(), total)
+			log(c, "downloadFile: %s\n", err)
+			continue
+		}
+		err = verifyFileChecksum(uri, header, buf.Bytes())
+		if err != nil {
+			log(c, "downloadFile: %s\n", err)
+			buf.Reset()
+			continue
+		}
+		rsp := &DownloadFileResponse{
+			Data:   buf.Bytes(),
+			Header: header,
+		}
+		return rsp, nil
 	}
-	return rsp, nil
+	return nil, err
 }
 
 // DownloadFile downloads a file stored in Notion
@@ -167,10 +392,144 @@ func (c *Client) DownloadFile(uri string, blockID string) (*DownloadFileResponse
 	if strings.Contains(uri, "s3.us-west-2.amazonaws.com") {
 		uri2 := "https://www.notion.so/image/" + url.PathEscape(uri)
 		res, err := c.downloadFile(uri2)
-		if err == nil {
-			return res, nil
+		if err == nil || IsErrDownloadSkipped(err) {
+			return res, err
 		}
 	}
 	uri2 := c.maybeSignImageURL(uri, blockID)
 	return c.downloadFile(uri2)
 }
+
+// FileInfo describes a file opened with DownloadFileReader
+type FileInfo struct {
+	// URL from which we downloaded the file. Can be different from
+	// the url given to DownloadFileReader
+	URL         string
+	Name        string
+	ContentType string
+	// -1 if not known
+	Size   int64
+	Header http.Header
+}
+
+// DownloadFileReader starts downloading a file (e.g. an image or an
+// attachment) and returns a reader for its content, which must be closed.
+// Unlike DownloadFile it doesn't read the whole file into memory.
+// It sends AuthToken and FileToken cookies, which are needed to download
+// files that are not public
+func (c *Client) DownloadFileReader(uri string) (io.ReadCloser, *FileInfo, error) {
+	uri = maybeProxyImageURL(uri)
+	req, err := http.NewRequest("GET", uri, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	req.Header.Set("User-Agent", userAgent)
+	c.setCookies(req)
+	resp, err := c.getHTTPClient().Do(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	if resp.StatusCode >= 400 {
+		closeNoError(resp.Body)
+		return nil, nil, &errDownloadStatus{uri: uri, status: resp.Status}
+	}
+	if err = c.checkDownloadAllowed(uri, resp); err != nil {
+		closeNoError(resp.Body)
+		return nil, nil, err
+	}
+	fi := &FileInfo{
+		URL:         resp.Request.URL.String(),
+		Name:        fileNameFromResponse(resp),
+		ContentType: resp.Header.Get("Content-Type"),
+		Size:        resp.ContentLength,
+		Header:      resp.Header,
+	}
+	return resp.Body, fi, nil
+}
+
+// returns name of the file from Content-Disposition header or
+// from the url
+func fileNameFromResponse(resp *http.Response) string {
+	if cd := resp.Header.Get("Content-Disposition"); cd != "" {
+		if _, params, err := mime.ParseMediaType(cd); err == nil && params["filename"] != "" {
+			return params["filename"]
+		}
+	}
+	p := resp.Request.URL.Path
+	if unescaped, err := url.PathUnescape(p); err == nil {
+		p = unescaped
+	}
+	return path.Base(p)
+}
+
+// query parameters of signed S3 urls. They carry credentials and expiry
+// of the account that signed the url
+var signedURLParams = []string{
+	"X-Amz-Algorithm",
+	"X-Amz-Content-Sha256",
+	"X-Amz-Credential",
+	"X-Amz-Date",
+	"X-Amz-Expires",
+	"X-Amz-Security-Token",
+	"X-Amz-Signature",
+	"X-Amz-SignedHeaders",
+	"AWSAccessKeyId",
+	"Signature",
+	"Expires",
+}
+
+func isSignedURLQuery(q url.Values) bool {
+	if q.Get("X-Amz-Signature") != "" || q.Get("X-Amz-Credential") != "" {
+		return true
+	}
+	return q.Get("AWSAccessKeyId") != "" && q.Get("Signature") != ""
+}
+
+// returns an url embedded in a path of a url of Notion's image proxy
+// (see maybeProxyImageURL) or ""
+func proxiedImageURL(u *url.URL) string {
+	if !strings.HasSuffix(u.Host, "notion.so") || !strings.HasPrefix(u.Path, "/image/") {
+		return ""
+	}
+	return strings.TrimPrefix(u.Path, "/image/")
+}
+
+// IsSignedURL returns true if uri is a signed S3 url (e.g. returned by
+// GetSignedFileUrls) or a url of Notion's image proxy for a signed url
+func IsSignedURL(uri string) bool {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return false
+	}
+	if isSignedURLQuery(u.Query()) {
+		return true
+	}
+	if inner := proxiedImageURL(u); inner != "" {
+		return IsSignedURL(inner)
+	}
+	return false
+}
+
+// StripSignedURL removes query parameters with credentials and expiry
+// from a signed url. The result can't be accessed without signing
+// it again but doesn't leak anything about the account that signed it
+func StripSignedURL(uri string) string {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return uri
+	}
+	if inner := proxiedImageURL(u); inner != "" && IsSignedURL(inner) {
+		u.Path = "/image/" + StripSignedURL(inner)
+		u.RawPath = ""
+		return u.String()
+	}
+	q := u.Query()
+	if !isSignedURLQuery(q) {
+		return uri
+	}
+	for _, name := range signedURLParams {
+		q.Del(name)
+	}
+	u.RawQuery = q.Encode()
+	return u.String()
+}
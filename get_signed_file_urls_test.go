@@ -0,0 +1,78 @@
+package notionapi
+
+import (
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsContentTypeAllowed(t *testing.T) {
+	tests := []struct {
+		contentType string
+		allowed     []string
+		exp         bool
+	}{
+		{"video/mp4", nil, true},
+		{"image/png", []string{"image/"}, true},
+		{"image/png; charset=binary", []string{"image/png"}, true},
+		{"Image/PNG", []string{"image/png"}, true},
+		{"video/mp4", []string{"image/", "application/pdf"}, false},
+		{"", []string{"image/"}, false},
+	}
+	for _, tc := range tests {
+		got := IsContentTypeAllowed(tc.contentType, tc.allowed)
+		assert.Equal(t, tc.exp, got, "content type: '%s'", tc.contentType)
+	}
+}
+
+func TestVerifyFileChecksum(t *testing.T) {
+	d := []byte("hello")
+	// md5 of "hello"
+	md5Hex := "5d41402abc4b2a76b9719d911017c592"
+	h := http.Header{}
+	assert.NoError(t, verifyFileChecksum("https://example.com/a.png", h, d))
+
+	h.Set("ETag", `"`+md5Hex+`"`)
+	assert.NoError(t, verifyFileChecksum("https://s3-us-west-2.amazonaws.com/a.png", h, d))
+	assert.Error(t, verifyFileChecksum("https://s3-us-west-2.amazonaws.com/a.png", h, []byte("hell")))
+	// ETag is only trusted for S3
+	assert.NoError(t, verifyFileChecksum("https://example.com/a.png", h, []byte("hell")))
+
+	h = http.Header{}
+	h.Set("Content-MD5", "XUFAKrxLKna5cZ2REBfFkg==")
+	assert.NoError(t, verifyFileChecksum("https://example.com/a.png", h, d))
+	assert.Error(t, verifyFileChecksum("https://example.com/a.png", h, []byte("hell")))
+}
+
+func TestIsNotionFileURL(t *testing.T) {
+	assert.True(t, IsNotionFileURL("https://s3-us-west-2.amazonaws.com/secure.notion-static.com/e5661303-82e1-43e4-be8e-662d1598cd53/untitled"))
+	assert.False(t, IsNotionFileURL("https://www.notion.so/images/page-cover/met_vincent_van_gogh_cradle.jpg"))
+
+	p := &Page{signedURLs: map[string]string{"a": "signed-a"}}
+	assert.Equal(t, "signed-a", p.SignedFileURL("a"))
+	assert.Equal(t, "b", p.SignedFileURL("b"))
+}
+
+func TestDownloadFileReader(t *testing.T) {
+	cookie := ""
+	c, _ := newFakeClient(map[string]fakeHandler{
+		// files on notion.so are downloaded via /image/ proxy
+		"/image/https://file.notion.so/f/s/a/b/image.png": func(req *http.Request, d []byte) interface{} {
+			cookie = req.Header.Get("cookie")
+			return fakeResponse(http.StatusOK, http.Header{"Content-Type": []string{"image/png"}}, "data")
+		},
+	})
+	c.AuthToken = "auth"
+	c.FileToken = "file"
+	r, fi, err := c.DownloadFileReader("https://file.notion.so/f/s/a/b/image.png")
+	assert.NoError(t, err)
+	defer r.Close()
+	d, _ := ioutil.ReadAll(r)
+	assert.Equal(t, "data", string(d))
+	assert.Equal(t, "image.png", fi.Name)
+	assert.Equal(t, "image/png", fi.ContentType)
+	assert.Equal(t, int64(4), fi.Size)
+	assert.Equal(t, "token_v2=auth; file_token=file", cookie)
+}
@@ -6,6 +6,7 @@ require (
 	github.com/kjk/siser v0.0.0-20190801014033-b3367920d7f2
 	github.com/kjk/u v0.0.0-20191229080709-d1ac8976d53f // indirect
 	github.com/stretchr/testify v1.3.0
+	golang.org/x/net v0.0.0-20191209160850-c0dbc17a3553
 )
 
 go 1.11
@@ -31,6 +31,11 @@ const (
 	AttrDate = "d"
 	// AtttrPage represents a link to a Notion page
 	AttrPage = "p"
+	// AttrUnderline represents underlined text
+	AttrUnderline = "_"
+	// AttrEquation represents an inline TeX equation. Text of the span
+	// is a placeholder
+	AttrEquation = "e"
 )
 
 // TextAttr describes attributes of a span of text
@@ -89,6 +94,14 @@ func AttrGetHighlight(attr TextAttr) string {
 	return attr[1]
 }
 
+func AttrGetEquation(attr TextAttr) string {
+	panicIfAttrNot(attr, "AttrGetEquation", AttrEquation)
+	if len(attr) == 1 {
+		return ""
+	}
+	return attr[1]
+}
+
 func AttrGetDate(attr TextAttr) *Date {
 	panicIfAttrNot(attr, "AttrGetDate", AttrDate)
 	js := []byte(attr[1])
@@ -100,6 +113,77 @@ func AttrGetDate(attr TextAttr) *Date {
 	return d
 }
 
+// SpanFormat is a typed view of attributes of a TextSpan
+type SpanFormat struct {
+	Bold          bool
+	Italic        bool
+	Strikethrough bool
+	Underline     bool
+	Code          bool
+	// Color is a color of text e.g. "red" or of background
+	// e.g. "red_background" (AttrHighlight)
+	Color string
+	// Link is an url (AttrLink)
+	Link string
+	// PageID is id of a mentioned page (AttrPage)
+	PageID string
+	// UserID is id of a mentioned user (AttrUser)
+	UserID string
+	// Date is a mentioned date (AttrDate)
+	Date *Date
+	// DiscussionIDs are ids of discussions about the text (AttrComment)
+	DiscussionIDs []string
+	// Equation is TeX of an inline equation (AttrEquation)
+	Equation string
+}
+
+// Format returns attributes of the span decoded into SpanFormat.
+// Unknown attributes are ignored
+func (t *TextSpan) Format() *SpanFormat {
+	res := &SpanFormat{}
+	for _, attr := range t.Attrs {
+		if len(attr) == 0 {
+			continue
+		}
+		arg := ""
+		if len(attr) > 1 {
+			arg = attr[1]
+		}
+		switch AttrGetType(attr) {
+		case AttrBold:
+			res.Bold = true
+		case AttrItalic:
+			res.Italic = true
+		case AttrStrikeThrought:
+			res.Strikethrough = true
+		case AttrUnderline:
+			res.Underline = true
+		case AttrCode:
+			res.Code = true
+		case AttrHighlight:
+			res.Color = arg
+		case AttrLink:
+			res.Link = arg
+		case AttrPage:
+			res.PageID = arg
+		case AttrUser:
+			res.UserID = arg
+		case AttrDate:
+			var d Date
+			if json.Unmarshal([]byte(arg), &d) == nil {
+				res.Date = &d
+			}
+		case AttrComment:
+			if arg != "" {
+				res.DiscussionIDs = append(res.DiscussionIDs, arg)
+			}
+		case AttrEquation:
+			res.Equation = arg
+		}
+	}
+	return res
+}
+
 func parseTextSpanAttribute(b *TextSpan, a []interface{}) error {
 	if len(a) == 0 {
 		return fmt.Errorf("attribute array is empty")
@@ -3,6 +3,7 @@ package notionapi
 import (
 	"encoding/json"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -361,3 +362,65 @@ func TestParseTextSpan7(t *testing.T) {
 	blocks := parseTextSpans(t, title7)
 	assert.Equal(t, 4, len(blocks))
 }
+
+const titleFormat = `{
+	"title": [
+		[ "styled", [ [ "b" ], [ "_" ], [ "h", "red_background" ], [ "m", "d1" ], [ "a", "https://example.com" ] ] ],
+		[ "⁍", [ [ "e", "x^2" ] ] ],
+		[ "‣", [ [ "d", { "type": "date", "start_date": "2018-07-17" } ] ] ]
+	]
+}`
+
+func TestTextSpanFormat(t *testing.T) {
+	blocks := parseTextSpans(t, titleFormat)
+	assert.Equal(t, 3, len(blocks))
+	f := blocks[0].Format()
+	assert.True(t, f.Bold)
+	assert.True(t, f.Underline)
+	assert.False(t, f.Italic)
+	assert.Equal(t, "red_background", f.Color)
+	assert.Equal(t, []string{"d1"}, f.DiscussionIDs)
+	assert.Equal(t, "https://example.com", f.Link)
+	assert.Equal(t, "x^2", blocks[1].Format().Equation)
+	assert.Equal(t, "2018-07-17", blocks[2].Format().Date.StartDate)
+}
+
+func TestDateStartEnd(t *testing.T) {
+	tz := "America/Los_Angeles"
+	d := &Date{
+		Type:      "datetimerange",
+		StartDate: "2018-07-12",
+		StartTime: "09:00",
+		EndDate:   "2018-07-13",
+		EndTime:   "17:30",
+		TimeZone:  &tz,
+		Reminder:  &Reminder{Unit: "hour", Value: 2},
+	}
+	assert.True(t, d.HasTime())
+	assert.True(t, d.IsRange())
+	assert.Equal(t, "2018-07-12T09:00:00-07:00", d.Start().Format(time.RFC3339))
+	assert.Equal(t, "2018-07-13T17:30:00-07:00", d.End().Format(time.RFC3339))
+	rt, ok := d.ReminderTime()
+	assert.True(t, ok)
+	assert.Equal(t, "2018-07-12T07:00:00-07:00", rt.Format(time.RFC3339))
+
+	d = &Date{Type: "date", StartDate: "2018-07-12", Reminder: &Reminder{Time: "09:00", Unit: "day", Value: 1}}
+	assert.False(t, d.HasTime())
+	assert.Equal(t, d.Start(), d.End())
+	rt, _ = d.ReminderTime()
+	assert.Equal(t, "2018-07-11T09:00:00Z", rt.Format(time.RFC3339))
+	assert.True(t, (&Date{StartDate: "bad"}).Start().IsZero())
+
+	spans := parseTextSpans(t, title5)
+	assert.Equal(t, "2018-07-17", TextSpansDate(spans).StartDate)
+}
+
+func TestFormatDateLocale(t *testing.T) {
+	d := &Date{Type: "datetime", DateFormat: "MMM DD, YYYY", StartDate: "2018-07-02", StartTime: "15:30"}
+	assert.Equal(t, FormatDate(d), FormatDateLocale(d, nil))
+	assert.Equal(t, "Jul 02, 2018 3:30 PM", FormatDateLocale(d, DateLocaleEnglish))
+	assert.Equal(t, "2. Juli 2018 15:30", FormatDateLocale(d, DateLocaleGerman))
+	assert.Equal(t, "2 juillet 2018 15:30", FormatDateLocale(d, DateLocaleFrench))
+	d = &Date{Type: "daterange", DateFormat: "relative", StartDate: "2018-01-31", EndDate: "2018-02-01"}
+	assert.Equal(t, "31 de enero de 2018 → 1 de febrero de 2018", FormatDateLocale(d, DateLocaleSpanish))
+}
@@ -0,0 +1,287 @@
+// Package jsonresume converts a conventionally structured Notion page
+// into https://jsonresume.org/schema/ format.
+//
+// The page is expected to look like:
+//
+//	Title of the page is the name
+//	Label: Software Engineer      (optional key: value lines before the first heading)
+//	Email: jane@example.com
+//	Text before the first heading is the summary
+//
+//	# Experience
+//	## Software Engineer at Acme
+//	2018-01 - 2020-05
+//	Summary of the job
+//	- highlight
+//
+//	# Education
+//	## University of Somewhere
+//	Computer Science
+//	2012 - 2016
+//
+//	# Skills
+//	- Go: concurrency, profiling
+package jsonresume
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+
+	"github.com/ninja-1/notionapi"
+)
+
+// Basics is "basics" section of a resume
+type Basics struct {
+	Name    string `json:"name,omitempty"`
+	Label   string `json:"label,omitempty"`
+	Email   string `json:"email,omitempty"`
+	Phone   string `json:"phone,omitempty"`
+	URL     string `json:"url,omitempty"`
+	Summary string `json:"summary,omitempty"`
+}
+
+// Work is an entry in "work" section of a resume
+type Work struct {
+	Name       string   `json:"name,omitempty"`
+	Position   string   `json:"position,omitempty"`
+	StartDate  string   `json:"startDate,omitempty"`
+	EndDate    string   `json:"endDate,omitempty"`
+	Summary    string   `json:"summary,omitempty"`
+	Highlights []string `json:"highlights,omitempty"`
+}
+
+// Education is an entry in "education" section of a resume
+type Education struct {
+	Institution string   `json:"institution,omitempty"`
+	Area        string   `json:"area,omitempty"`
+	StartDate   string   `json:"startDate,omitempty"`
+	EndDate     string   `json:"endDate,omitempty"`
+	Courses     []string `json:"courses,omitempty"`
+}
+
+// Skill is an entry in "skills" section of a resume
+type Skill struct {
+	Name     string   `json:"name"`
+	Keywords []string `json:"keywords,omitempty"`
+}
+
+// Resume is a resume in JSON Resume format
+type Resume struct {
+	Basics    Basics       `json:"basics"`
+	Work      []*Work      `json:"work,omitempty"`
+	Education []*Education `json:"education,omitempty"`
+	Skills    []*Skill     `json:"skills,omitempty"`
+}
+
+// headings of sections, compared case-insensitively
+var (
+	workHeadings      = []string{"Experience", "Work Experience", "Work", "Employment"}
+	educationHeadings = []string{"Education"}
+	skillsHeadings    = []string{"Skills"}
+)
+
+// "2018-01 - 2020-05", "2018 – present"
+var rxDateRange = regexp.MustCompile(`^\s*(\d{4}(?:-\d{2}(?:-\d{2})?)?)\s*(?:-|–|—|to)\s*(\d{4}(?:-\d{2}(?:-\d{2})?)?|[Pp]resent|[Nn]ow|[Cc]urrent)?\s*$`)
+
+func blockText(block *notionapi.Block) string {
+	text := block.Title
+	if text == "" {
+		text = notionapi.TextSpansToString(block.InlineContent)
+	}
+	return strings.TrimSpace(text)
+}
+
+func isListItem(block *notionapi.Block) bool {
+	return block.Type == notionapi.BlockBulletedList || block.Type == notionapi.BlockNumberedList
+}
+
+// parseDateRange parses "2018-01 - 2020-05". Open-ended ranges
+// ("2018 - present") return empty end date
+func parseDateRange(s string) (start string, end string, ok bool) {
+	m := rxDateRange.FindStringSubmatch(s)
+	if m == nil {
+		return "", "", false
+	}
+	end = m[2]
+	if len(end) > 0 && (end[0] < '0' || end[0] > '9') {
+		end = ""
+	}
+	return m[1], end, true
+}
+
+// parseKeyValue parses "Email: jane@example.com"
+func parseKeyValue(s string) (string, string, bool) {
+	idx := strings.Index(s, ":")
+	if idx <= 0 {
+		return "", "", false
+	}
+	key := strings.TrimSpace(s[:idx])
+	if strings.Contains(key, " ") {
+		return "", "", false
+	}
+	return strings.ToLower(key), strings.TrimSpace(s[idx+1:]), true
+}
+
+// entry is a heading followed by its content
+type entry struct {
+	Title     string
+	StartDate string
+	EndDate   string
+	// non-list text blocks
+	Text []string
+	// list items
+	Items []string
+}
+
+// splitEntries splits blocks of a section into entries. Each heading starts
+// a new entry. Blocks before the first heading are ignored
+func splitEntries(blocks []*notionapi.Block) []*entry {
+	var res []*entry
+	for _, section := range notionapi.SplitBlocks(blocks, 3) {
+		if section.Heading == nil {
+			continue
+		}
+		curr := &entry{Title: blockText(section.Heading)}
+		res = append(res, curr)
+		for _, block := range section.Blocks {
+			text := blockText(block)
+			if text == "" {
+				continue
+			}
+			if isListItem(block) {
+				curr.Items = append(curr.Items, text)
+				continue
+			}
+			if curr.StartDate == "" {
+				if start, end, ok := parseDateRange(text); ok {
+					curr.StartDate = start
+					curr.EndDate = end
+					continue
+				}
+			}
+			curr.Text = append(curr.Text, text)
+		}
+	}
+	return res
+}
+
+func findSection(page *notionapi.Page, headings []string) []*notionapi.Block {
+	for _, h := range headings {
+		if blocks := notionapi.ExtractSection(page, h); blocks != nil {
+			return blocks
+		}
+	}
+	return nil
+}
+
+func workFromBlocks(blocks []*notionapi.Block) []*Work {
+	var res []*Work
+	for _, e := range splitEntries(blocks) {
+		w := &Work{
+			Name:       e.Title,
+			StartDate:  e.StartDate,
+			EndDate:    e.EndDate,
+			Summary:    strings.Join(e.Text, "\n"),
+			Highlights: e.Items,
+		}
+		// "Software Engineer at Acme"
+		if parts := strings.SplitN(e.Title, " at ", 2); len(parts) == 2 {
+			w.Position = strings.TrimSpace(parts[0])
+			w.Name = strings.TrimSpace(parts[1])
+		}
+		res = append(res, w)
+	}
+	return res
+}
+
+func educationFromBlocks(blocks []*notionapi.Block) []*Education {
+	var res []*Education
+	for _, e := range splitEntries(blocks) {
+		ed := &Education{
+			Institution: e.Title,
+			StartDate:   e.StartDate,
+			EndDate:     e.EndDate,
+			Courses:     e.Items,
+		}
+		if len(e.Text) > 0 {
+			ed.Area = e.Text[0]
+		}
+		res = append(res, ed)
+	}
+	return res
+}
+
+// skillsFromBlocks parses list items like "Go: concurrency, profiling"
+func skillsFromBlocks(blocks []*notionapi.Block) []*Skill {
+	var res []*Skill
+	for _, block := range blocks {
+		if !isListItem(block) {
+			continue
+		}
+		text := blockText(block)
+		if text == "" {
+			continue
+		}
+		skill := &Skill{Name: text}
+		if idx := strings.Index(text, ":"); idx > 0 {
+			skill.Name = strings.TrimSpace(text[:idx])
+			for _, kw := range strings.Split(text[idx+1:], ",") {
+				if kw = strings.TrimSpace(kw); kw != "" {
+					skill.Keywords = append(skill.Keywords, kw)
+				}
+			}
+		}
+		res = append(res, skill)
+	}
+	return res
+}
+
+// basicsFromBlocks parses blocks before the first heading
+func basicsFromBlocks(basics *Basics, blocks []*notionapi.Block) {
+	var summary []string
+	for _, block := range blocks {
+		if notionapi.HeadingLevel(block) > 0 {
+			break
+		}
+		text := blockText(block)
+		if text == "" {
+			continue
+		}
+		if key, val, ok := parseKeyValue(text); ok {
+			switch key {
+			case "label", "title", "role":
+				basics.Label = val
+				continue
+			case "email", "e-mail":
+				basics.Email = val
+				continue
+			case "phone":
+				basics.Phone = val
+				continue
+			case "url", "website", "web":
+				basics.URL = val
+				continue
+			}
+		}
+		summary = append(summary, text)
+	}
+	basics.Summary = strings.Join(summary, "\n")
+}
+
+// FromPage converts a page to a resume
+func FromPage(page *notionapi.Page) *Resume {
+	res := &Resume{}
+	root := page.Root()
+	res.Basics.Name = root.Title
+	basicsFromBlocks(&res.Basics, root.Content)
+	res.Work = workFromBlocks(findSection(page, workHeadings))
+	res.Education = educationFromBlocks(findSection(page, educationHeadings))
+	res.Skills = skillsFromBlocks(findSection(page, skillsHeadings))
+	return res
+}
+
+// ToJSON converts a page to JSON Resume
+func ToJSON(page *notionapi.Page) ([]byte, error) {
+	return json.MarshalIndent(FromPage(page), "", "  ")
+}
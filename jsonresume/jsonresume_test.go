@@ -0,0 +1,47 @@
+package jsonresume
+
+import (
+	"testing"
+
+	"github.com/ninja-1/notionapi"
+	"github.com/stretchr/testify/assert"
+)
+
+func mkBlock(typ, text string) *notionapi.Block {
+	return &notionapi.Block{Type: typ, Title: text}
+}
+
+func TestWorkFromBlocks(t *testing.T) {
+	blocks := []*notionapi.Block{
+		mkBlock(notionapi.BlockSubHeader, "Software Engineer at Acme"),
+		mkBlock(notionapi.BlockText, "2018-01 – present"),
+		mkBlock(notionapi.BlockText, "Built things"),
+		mkBlock(notionapi.BlockBulletedList, "Shipped v2"),
+		mkBlock(notionapi.BlockSubHeader, "Freelance"),
+		mkBlock(notionapi.BlockText, "2015 - 2017"),
+	}
+	work := workFromBlocks(blocks)
+	assert.Len(t, work, 2)
+	assert.Equal(t, &Work{
+		Name:       "Acme",
+		Position:   "Software Engineer",
+		StartDate:  "2018-01",
+		Summary:    "Built things",
+		Highlights: []string{"Shipped v2"},
+	}, work[0])
+	assert.Equal(t, "Freelance", work[1].Name)
+	assert.Equal(t, "2017", work[1].EndDate)
+}
+
+func TestSkillsFromBlocks(t *testing.T) {
+	blocks := []*notionapi.Block{
+		mkBlock(notionapi.BlockBulletedList, "Go: concurrency, profiling"),
+		mkBlock(notionapi.BlockText, "not a skill"),
+		mkBlock(notionapi.BlockBulletedList, "SQL"),
+	}
+	skills := skillsFromBlocks(blocks)
+	assert.Equal(t, []*Skill{
+		{Name: "Go", Keywords: []string{"concurrency", "profiling"}},
+		{Name: "SQL"},
+	}, skills)
+}
@@ -0,0 +1,295 @@
+package notionapi
+
+import (
+	"context"
+	"html"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultLinkTitleMaxBodySize = 64 * 1024
+	defaultLinkTitleTimeout     = 5 * time.Second
+	defaultLinkTitleMaxRequests = 100
+	// longer titles are truncated
+	maxLinkTitleLen = 200
+)
+
+var rxHTMLTitle = regexp.MustCompile(`(?is)<title[^>]*>(.*?)</title>`)
+
+// LinkTitleResolver replaces text of bare links (links whose text is
+// their url) with titles of linked pages, which reads better.
+// Titles of Notion pages come from exported pages (see AddPage) or are
+// downloaded with Client (without content of the pages). Titles of other
+// pages are extracted from their HTML if HTTPClient is set.
+// Titles are cached so a resolver can be shared by many pages.
+// It's safe for concurrent use
+type LinkTitleResolver struct {
+	// Client is used to get titles of Notion pages. If not set, only
+	// titles of pages added with AddPage are used
+	Client *Client
+	// HTTPClient is used to get titles of external pages. If not set,
+	// links to external pages are left as they are. They're also left
+	// as they are in builds with notionapi_offline tag
+	HTTPClient *http.Client
+	// MaxBodySize is the maximum number of bytes of an external page we
+	// read to find its title. Defaults to 64 kB
+	MaxBodySize int64
+	// Timeout is a timeout of getting a title of an external page.
+	// Defaults to 5 seconds
+	Timeout time.Duration
+	// MaxRequests limits the number of external pages we request.
+	// Defaults to 100
+	MaxRequests int
+
+	mu sync.Mutex
+	// maps id of a Notion page (no dash) or url of an external page
+	// to a title. Title is "" if we failed to get it
+	titles   map[string]string
+	requests int
+}
+
+// NewLinkTitleResolver returns a resolver that gets titles of Notion
+// pages with client. Set HTTPClient to also resolve external links
+func NewLinkTitleResolver(client *Client) *LinkTitleResolver {
+	return &LinkTitleResolver{
+		Client: client,
+	}
+}
+
+func (r *LinkTitleResolver) getTitle(key string) (string, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	title, ok := r.titles[key]
+	return title, ok
+}
+
+func (r *LinkTitleResolver) setTitle(key string, title string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.titles == nil {
+		r.titles = map[string]string{}
+	}
+	r.titles[key] = title
+}
+
+// AddPage remembers titles of a page and its sub-pages
+func (r *LinkTitleResolver) AddPage(page *Page) {
+	page.ForEachBlock(func(block *Block) {
+		if isPageBlock(block) && block.Title != "" {
+			r.setTitle(ToNoDashID(block.ID), block.Title)
+		}
+	})
+}
+
+// returns url of a link if text of a span is the url, "" otherwise
+func bareLinkURL(ts *TextSpan) string {
+	for _, attr := range ts.Attrs {
+		if AttrGetType(attr) != AttrLink {
+			continue
+		}
+		uri := AttrGetLink(attr)
+		if uri != "" && strings.TrimSpace(ts.Text) == uri {
+			return uri
+		}
+	}
+	return ""
+}
+
+// ResolvePage replaces text of bare links in blocks of a page with titles
+// of linked pages. Links whose title we can't get are not changed.
+// Only failure to get titles of Notion pages is an error
+func (r *LinkTitleResolver) ResolvePage(page *Page) error {
+	var spans []*TextSpan
+	var missingIDs []string
+	page.ForEachBlock(func(block *Block) {
+		for _, ts := range block.InlineContent {
+			uri := bareLinkURL(ts)
+			if uri == "" {
+				continue
+			}
+			spans = append(spans, ts)
+			if !isNotionURL(uri) {
+				continue
+			}
+			id := ExtractNoDashIDFromNotionURL(uri)
+			if id == "" {
+				continue
+			}
+			if _, ok := r.getTitle(id); !ok {
+				missingIDs = append(missingIDs, id)
+			}
+		}
+	})
+	if err := r.resolveNotionTitles(missingIDs); err != nil {
+		return err
+	}
+	for _, ts := range spans {
+		if title := r.Title(bareLinkURL(ts)); title != "" {
+			ts.Text = title
+		}
+	}
+	return nil
+}
+
+// gets titles of Notion pages with given ids in one request
+func (r *LinkTitleResolver) resolveNotionTitles(ids []string) error {
+	if r.Client == nil || len(ids) == 0 {
+		return nil
+	}
+	seen := map[string]bool{}
+	var uniqueIDs []string
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			uniqueIDs = append(uniqueIDs, id)
+		}
+	}
+	rsp, err := r.Client.GetBlockRecords(uniqueIDs)
+	if err != nil {
+		return err
+	}
+	for i, id := range uniqueIDs {
+		title := ""
+		if i < len(rsp.Results) && rsp.Results[i].Block != nil {
+			title = TextSpansToString(rsp.Results[i].Block.GetTitle())
+		}
+		r.setTitle(id, title)
+	}
+	return nil
+}
+
+// Title returns a title of a page with a given url or "" if we don't
+// know it
+func (r *LinkTitleResolver) Title(uri string) string {
+	if isNotionURL(uri) {
+		id := ExtractNoDashIDFromNotionURL(uri)
+		if id == "" {
+			return ""
+		}
+		if title, ok := r.getTitle(id); ok {
+			return title
+		}
+		if err := r.resolveNotionTitles([]string{id}); err != nil {
+			return ""
+		}
+		title, _ := r.getTitle(id)
+		return title
+	}
+	if title, ok := r.getTitle(uri); ok {
+		return title
+	}
+	title := r.externalTitle(uri)
+	r.setTitle(uri, title)
+	return title
+}
+
+// returns true if we can make another request for an external page
+func (r *LinkTitleResolver) takeRequest() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	max := r.MaxRequests
+	if max <= 0 {
+		max = defaultLinkTitleMaxRequests
+	}
+	if r.requests >= max {
+		return false
+	}
+	r.requests++
+	return true
+}
+
+func (r *LinkTitleResolver) doRequest(method string, uri string) (*http.Response, context.CancelFunc, error) {
+	timeout := r.Timeout
+	if timeout <= 0 {
+		timeout = defaultLinkTitleTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	req, err := http.NewRequest(method, uri, nil)
+	if err != nil {
+		cancel()
+		return nil, nil, err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("User-Agent", userAgent)
+	req.Header.Set("Accept", "text/html")
+	rsp, err := r.HTTPClient.Do(req)
+	if err != nil {
+		cancel()
+		return nil, nil, err
+	}
+	return rsp, cancel, nil
+}
+
+func isHTMLResponse(rsp *http.Response) bool {
+	ct := rsp.Header.Get("Content-Type")
+	return ct == "" || strings.Contains(ct, "text/html") || strings.Contains(ct, "application/xhtml")
+}
+
+// gets a title from HTML of an external page. HEAD request first
+// so that we don't download e.g. big files
+func (r *LinkTitleResolver) externalTitle(uri string) string {
+	if Offline || r.HTTPClient == nil {
+		return ""
+	}
+	if !strings.HasPrefix(uri, "http://") && !strings.HasPrefix(uri, "https://") {
+		return ""
+	}
+	if !r.takeRequest() {
+		return ""
+	}
+	rsp, cancel, err := r.doRequest("HEAD", uri)
+	if err != nil {
+		return ""
+	}
+	closeNoError(rsp.Body)
+	cancel()
+	// some servers don't support HEAD
+	if rsp.StatusCode != http.StatusMethodNotAllowed {
+		if rsp.StatusCode != http.StatusOK || !isHTMLResponse(rsp) {
+			return ""
+		}
+	}
+
+	rsp, cancel, err = r.doRequest("GET", uri)
+	if err != nil {
+		return ""
+	}
+	defer cancel()
+	defer closeNoError(rsp.Body)
+	if rsp.StatusCode != http.StatusOK || !isHTMLResponse(rsp) {
+		return ""
+	}
+	max := r.MaxBodySize
+	if max <= 0 {
+		max = defaultLinkTitleMaxBodySize
+	}
+	d, err := ioutil.ReadAll(io.LimitReader(rsp.Body, max))
+	if err != nil {
+		return ""
+	}
+	return extractHTMLTitle(string(d))
+}
+
+// returns text of <title> element of HTML, with whitespace collapsed
+func extractHTMLTitle(s string) string {
+	m := rxHTMLTitle.FindStringSubmatch(s)
+	if m == nil {
+		return ""
+	}
+	title := html.UnescapeString(m[1])
+	title = strings.Join(strings.Fields(title), " ")
+	if len(title) > maxLinkTitleLen {
+		// don't cut in the middle of utf-8 sequence
+		runes := []rune(title)
+		if len(runes) > maxLinkTitleLen {
+			title = string(runes[:maxLinkTitleLen]) + "…"
+		}
+	}
+	return title
+}
@@ -0,0 +1,47 @@
+package notionapi
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLinkTitleResolver(t *testing.T) {
+	// pretends to be notion.so and an external site with a title
+	var methods []string
+	c, _ := newFakeClient(map[string]fakeHandler{
+		"/api/v3/getRecordValues": func(req *http.Request, d []byte) interface{} {
+			return `{"results":[{"role":"reader","value":{"id":"94167af6-5670-4327-9811-dc923edd1f04","type":"page","properties":{"title":[["Test table"]]}}}]}`
+		},
+		"/a": func(req *http.Request, d []byte) interface{} {
+			methods = append(methods, req.Method)
+			header := http.Header{"Content-Type": []string{"text/html; charset=utf-8"}}
+			return fakeResponse(http.StatusOK, header, "<html><head><title>\n  Example &amp; Co\n</title></head></html>")
+		},
+	})
+	r := NewLinkTitleResolver(c)
+	r.HTTPClient = c.HTTPClient
+	internal := "https://www.notion.so/94167af6567043279811dc923edd1f04"
+	spans := []*TextSpan{
+		{Text: internal, Attrs: []TextAttr{{AttrLink, internal}}},
+		{Text: "https://example.com/a", Attrs: []TextAttr{{AttrLink, "https://example.com/a"}}},
+		{Text: "named link", Attrs: []TextAttr{{AttrLink, "https://example.com/b"}}},
+	}
+	root := &Block{ID: "root", Type: BlockPage, InlineContent: spans}
+	p := &Page{ID: "root", idToBlock: map[string]*Block{ToDashID("root"): root}}
+	assert.NoError(t, r.ResolvePage(p))
+	assert.Equal(t, "Test table", spans[0].Text)
+	assert.Equal(t, "named link", spans[2].Text)
+	if Offline {
+		// external pages are not fetched
+		assert.Equal(t, "https://example.com/a", spans[1].Text)
+		return
+	}
+	assert.Equal(t, "Example & Co", spans[1].Text)
+	assert.Equal(t, []string{"HEAD", "GET"}, methods)
+
+	// titles are cached
+	assert.Equal(t, "Example & Co", r.Title("https://example.com/a"))
+	assert.Equal(t, 2, len(methods))
+}
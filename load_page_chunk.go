@@ -9,6 +9,19 @@ type loadPageChunkRequest struct {
 	VerticalColumns bool   `json:"verticalColumns"`
 }
 
+// /api/v3/loadCachedPageChunk request, used for anonymous access
+type loadCachedPageChunkRequest struct {
+	Page            loadCachedPageChunkPage `json:"page"`
+	ChunkNumber     int                     `json:"chunkNumber"`
+	Limit           int                     `json:"limit"`
+	Cursor          cursor                  `json:"cursor"`
+	VerticalColumns bool                    `json:"verticalColumns"`
+}
+
+type loadCachedPageChunkPage struct {
+	ID string `json:"id"`
+}
+
 type cursor struct {
 	Stack [][]stack `json:"stack"`
 }
@@ -60,7 +73,21 @@ func (c *Client) LoadPageChunk(pageID string, chunkNo int, cur *cursor) (*LoadPa
 	}
 	var rsp LoadPageChunkResponse
 	var err error
-	if rsp.RawJSON, err = doNotionAPI(c, apiURL, req, &rsp); err != nil {
+	rsp.RawJSON, err = doNotionAPI(c, apiURL, req, &rsp)
+	if err != nil && c.AuthToken == "" && isErrAPIUnauthorized(err) {
+		// public pages can be accessed anonymously with loadCachedPageChunk
+		dbg(c, "LoadPageChunk: anonymous loadPageChunk failed, trying loadCachedPageChunk\n")
+		req2 := &loadCachedPageChunkRequest{
+			Page:            loadCachedPageChunkPage{ID: ToDashID(pageID)},
+			ChunkNumber:     chunkNo,
+			Limit:           limit,
+			Cursor:          *cur,
+			VerticalColumns: false,
+		}
+		rsp = LoadPageChunkResponse{}
+		rsp.RawJSON, err = doNotionAPI(c, "/api/v3/loadCachedPageChunk", req2, &rsp)
+	}
+	if err != nil {
 		return nil, err
 	}
 	if err = ParseRecordMap(rsp.RecordMap); err != nil {
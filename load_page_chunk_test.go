@@ -2,6 +2,7 @@ package notionapi
 
 import (
 	"encoding/json"
+	"net/http"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -155,3 +156,19 @@ func TestLoadPageChunk1(t *testing.T) {
 
 	}
 }
+
+func TestLoadPageChunkAnonymous(t *testing.T) {
+	// pretends to be a server that only allows anonymous loadCachedPageChunk
+	c, transport := newFakeClient(map[string]fakeHandler{
+		"/api/v3/loadPageChunk": func(req *http.Request, d []byte) interface{} {
+			return fakeResponse(http.StatusUnauthorized, nil, `{}`)
+		},
+		"/api/v3/loadCachedPageChunk": func(req *http.Request, d []byte) interface{} {
+			return `{"recordMap":{},"cursor":{"stack":[]}}`
+		},
+	})
+	c.BaseURL = "https://kjk.notion.site"
+	_, err := c.LoadPageChunk("6682351e44bb4f9ca0e149b703265bdb", 0, nil)
+	require.NoError(t, err)
+	require.Equal(t, []string{"/api/v3/loadPageChunk", "/api/v3/loadCachedPageChunk"}, transport.paths)
+}
@@ -0,0 +1,387 @@
+package notionapi
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+const (
+	// BlockTable is a simple (non-database) table. Its children are BlockTableRow
+	BlockTable = "table"
+	// BlockTableRow is a row of BlockTable
+	BlockTableRow = "table_row"
+)
+
+// MarkdownBlock is a block parsed from Markdown by ParseMarkdown,
+// ready to be created in Notion
+type MarkdownBlock struct {
+	Type       string
+	Properties map[string]interface{}
+	// if not empty, set with UpdateFormatOp
+	Format   map[string]interface{}
+	Children []*MarkdownBlock
+}
+
+var (
+	rxMdHeading   = regexp.MustCompile(`^(#{1,6})\s+(.*?)\s*#*\s*$`)
+	rxMdBullet    = regexp.MustCompile(`^(\s*)[-*+]\s+(.*)$`)
+	rxMdNumbered  = regexp.MustCompile(`^(\s*)\d+[.)]\s+(.*)$`)
+	rxMdTodo      = regexp.MustCompile(`^\[([ xX])\]\s+(.*)$`)
+	rxMdImage     = regexp.MustCompile(`^!\[([^\]]*)\]\(([^)\s]+)(?:\s+"[^"]*")?\)$`)
+	rxMdDivider   = regexp.MustCompile(`^(?:-{3,}|\*{3,}|_{3,})$`)
+	rxMdTableSep  = regexp.MustCompile(`^\|?\s*:?-+:?\s*(\|\s*:?-+:?\s*)*\|?$`)
+	rxMdInlineTok = regexp.MustCompile("\\*\\*(.+?)\\*\\*|__(.+?)__|\\*(.+?)\\*|`([^`]+)`|~~(.+?)~~|\\[([^\\]]+)\\]\\(([^)\\s]+)\\)")
+)
+
+// maps lower-cased language of fenced code blocks to language names used by Notion
+var mdCodeLanguages = map[string]string{
+	"bash":       "Bash",
+	"c":          "C",
+	"cpp":        "C++",
+	"c++":        "C++",
+	"css":        "CSS",
+	"go":         "Go",
+	"golang":     "Go",
+	"html":       "HTML",
+	"java":       "Java",
+	"javascript": "JavaScript",
+	"js":         "JavaScript",
+	"json":       "JSON",
+	"markdown":   "Markdown",
+	"md":         "Markdown",
+	"python":     "Python",
+	"py":         "Python",
+	"ruby":       "Ruby",
+	"rust":       "Rust",
+	"sh":         "Shell",
+	"shell":      "Shell",
+	"sql":        "SQL",
+	"typescript": "TypeScript",
+	"ts":         "TypeScript",
+	"yaml":       "YAML",
+}
+
+// parseMarkdownInline converts inline Markdown (bold, italic, code,
+// strikethrough, links) to Notion's text representation
+func parseMarkdownInline(s string) []interface{} {
+	res := []interface{}{}
+	appendInlineSpans(&res, s, nil)
+	return res
+}
+
+func appendInlineSpans(res *[]interface{}, s string, attrs []interface{}) {
+	addSpan := func(text string, attrs []interface{}) {
+		if text == "" {
+			return
+		}
+		if len(attrs) == 0 {
+			*res = append(*res, []interface{}{text})
+			return
+		}
+		*res = append(*res, []interface{}{text, attrs})
+	}
+	withAttr := func(attr []interface{}) []interface{} {
+		a := append([]interface{}{}, attrs...)
+		return append(a, attr)
+	}
+	for s != "" {
+		m := rxMdInlineTok.FindStringSubmatchIndex(s)
+		if m == nil {
+			addSpan(s, attrs)
+			return
+		}
+		addSpan(s[:m[0]], attrs)
+		group := func(n int) string {
+			if m[2*n] < 0 {
+				return ""
+			}
+			return s[m[2*n]:m[2*n+1]]
+		}
+		switch {
+		case m[2] >= 0:
+			appendInlineSpans(res, group(1), withAttr([]interface{}{AttrBold}))
+		case m[4] >= 0:
+			appendInlineSpans(res, group(2), withAttr([]interface{}{AttrBold}))
+		case m[6] >= 0:
+			appendInlineSpans(res, group(3), withAttr([]interface{}{AttrItalic}))
+		case m[8] >= 0:
+			// no formatting inside code
+			addSpan(group(4), withAttr([]interface{}{AttrCode}))
+		case m[10] >= 0:
+			appendInlineSpans(res, group(5), withAttr([]interface{}{AttrStrikeThrought}))
+		default:
+			appendInlineSpans(res, group(6), withAttr([]interface{}{AttrLink, group(7)}))
+		}
+		s = s[m[1]:]
+	}
+}
+
+func newMarkdownBlock(blockType string, text string) *MarkdownBlock {
+	return &MarkdownBlock{
+		Type: blockType,
+		Properties: map[string]interface{}{
+			"title": parseMarkdownInline(text),
+		},
+	}
+}
+
+func splitMarkdownTableRow(s string) []string {
+	s = strings.TrimSpace(s)
+	s = strings.TrimPrefix(s, "|")
+	s = strings.TrimSuffix(s, "|")
+	parts := strings.Split(s, "|")
+	for i, p := range parts {
+		parts[i] = strings.TrimSpace(p)
+	}
+	return parts
+}
+
+func newMarkdownTable(header []string, rows [][]string) *MarkdownBlock {
+	var colIDs []string
+	for i := range header {
+		colIDs = append(colIDs, fmt.Sprintf("col%d", i))
+	}
+	table := &MarkdownBlock{
+		Type: BlockTable,
+		Format: map[string]interface{}{
+			"table_block_column_order":  colIDs,
+			"table_block_column_header": true,
+		},
+	}
+	for _, cells := range append([][]string{header}, rows...) {
+		props := map[string]interface{}{}
+		for i, colID := range colIDs {
+			if i < len(cells) && cells[i] != "" {
+				props[colID] = parseMarkdownInline(cells[i])
+			}
+		}
+		row := &MarkdownBlock{
+			Type:       BlockTableRow,
+			Properties: props,
+		}
+		table.Children = append(table.Children, row)
+	}
+	return table
+}
+
+// returns width of leading whitespace, tabs count as 4 spaces
+func mdIndent(s string) int {
+	n := 0
+	for _, c := range s {
+		switch c {
+		case ' ':
+			n++
+		case '\t':
+			n += 4
+		default:
+			return n
+		}
+	}
+	return n
+}
+
+// ParseMarkdown converts Markdown to blocks. It supports headings,
+// paragraphs, (nested) bulleted, numbered and todo lists, quotes,
+// fenced code blocks, dividers, images and tables
+func ParseMarkdown(md string) []*MarkdownBlock {
+	md = strings.Replace(md, "\r\n", "\n", -1)
+	lines := strings.Split(md, "\n")
+
+	var res []*MarkdownBlock
+	type listItem struct {
+		indent int
+		block  *MarkdownBlock
+	}
+	var lists []listItem
+	var para []string
+
+	add := func(b *MarkdownBlock) {
+		res = append(res, b)
+	}
+	flushPara := func() {
+		if len(para) > 0 {
+			add(newMarkdownBlock(BlockText, strings.Join(para, " ")))
+			para = nil
+		}
+	}
+
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		trimmed := strings.TrimSpace(line)
+
+		if trimmed == "" {
+			flushPara()
+			continue
+		}
+
+		// list items, possibly nested
+		var listType, text string
+		indent := mdIndent(line)
+		if m := rxMdBullet.FindStringSubmatch(line); m != nil && !rxMdDivider.MatchString(trimmed) {
+			listType, text = BlockBulletedList, m[2]
+		} else if m := rxMdNumbered.FindStringSubmatch(line); m != nil {
+			listType, text = BlockNumberedList, m[2]
+		}
+		if listType != "" {
+			flushPara()
+			b := newMarkdownBlock(listType, text)
+			if m := rxMdTodo.FindStringSubmatch(text); m != nil && listType == BlockBulletedList {
+				b = newMarkdownBlock(BlockTodo, m[2])
+				if m[1] != " " {
+					b.Properties["checked"] = []interface{}{[]interface{}{"Yes"}}
+				}
+			}
+			for len(lists) > 0 && lists[len(lists)-1].indent >= indent {
+				lists = lists[:len(lists)-1]
+			}
+			if len(lists) == 0 {
+				add(b)
+			} else {
+				parent := lists[len(lists)-1].block
+				parent.Children = append(parent.Children, b)
+			}
+			lists = append(lists, listItem{indent: indent, block: b})
+			continue
+		}
+		lists = nil
+
+		if strings.HasPrefix(trimmed, "```") {
+			flushPara()
+			lang := strings.ToLower(strings.TrimSpace(strings.TrimPrefix(trimmed, "```")))
+			var code []string
+			for i++; i < len(lines); i++ {
+				if strings.HasPrefix(strings.TrimSpace(lines[i]), "```") {
+					break
+				}
+				code = append(code, lines[i])
+			}
+			language := mdCodeLanguages[lang]
+			if language == "" {
+				language = "Plain Text"
+			}
+			b := &MarkdownBlock{
+				Type: BlockCode,
+				Properties: map[string]interface{}{
+					"title":    []interface{}{[]interface{}{strings.Join(code, "\n")}},
+					"language": []interface{}{[]interface{}{language}},
+				},
+			}
+			add(b)
+			continue
+		}
+
+		if m := rxMdHeading.FindStringSubmatch(trimmed); m != nil {
+			flushPara()
+			blockType := BlockSubSubHeader
+			switch len(m[1]) {
+			case 1:
+				blockType = BlockHeader
+			case 2:
+				blockType = BlockSubHeader
+			}
+			add(newMarkdownBlock(blockType, m[2]))
+			continue
+		}
+
+		if rxMdDivider.MatchString(trimmed) {
+			flushPara()
+			add(&MarkdownBlock{Type: BlockDivider})
+			continue
+		}
+
+		if strings.HasPrefix(trimmed, ">") {
+			flushPara()
+			var quote []string
+			for ; i < len(lines); i++ {
+				s := strings.TrimSpace(lines[i])
+				if !strings.HasPrefix(s, ">") {
+					i--
+					break
+				}
+				quote = append(quote, strings.TrimSpace(strings.TrimPrefix(s, ">")))
+			}
+			add(newMarkdownBlock(BlockQuote, strings.Join(quote, " ")))
+			continue
+		}
+
+		if m := rxMdImage.FindStringSubmatch(trimmed); m != nil {
+			flushPara()
+			b := &MarkdownBlock{
+				Type: BlockImage,
+				Properties: map[string]interface{}{
+					"source": []interface{}{[]interface{}{m[2]}},
+				},
+				Format: map[string]interface{}{
+					"display_source": m[2],
+				},
+			}
+			if m[1] != "" {
+				b.Properties["caption"] = []interface{}{[]interface{}{m[1]}}
+			}
+			add(b)
+			continue
+		}
+
+		if strings.HasPrefix(trimmed, "|") && i+1 < len(lines) && rxMdTableSep.MatchString(strings.TrimSpace(lines[i+1])) {
+			flushPara()
+			header := splitMarkdownTableRow(trimmed)
+			var rows [][]string
+			for i += 2; i < len(lines); i++ {
+				s := strings.TrimSpace(lines[i])
+				if !strings.HasPrefix(s, "|") {
+					i--
+					break
+				}
+				rows = append(rows, splitMarkdownTableRow(s))
+			}
+			add(newMarkdownTable(header, rows))
+			continue
+		}
+
+		para = append(para, trimmed)
+	}
+	flushPara()
+	return res
+}
+
+// buildMarkdownOps returns operations that create blocks as children of parent,
+// after afterID (at the end if empty)
+func (c *Client) buildMarkdownOps(userID string, parent *Block, afterID string, blocks []*MarkdownBlock) []*Operation {
+	var ops []*Operation
+	for _, mb := range blocks {
+		newBlock, op := c.SetNewRecordOp(userID, parent, mb.Type)
+		newBlock.Properties = mb.Properties
+		ops = append(ops, op)
+		if len(mb.Format) > 0 {
+			ops = append(ops, newBlock.UpdateFormatOp(mb.Format))
+		}
+		ops = append(ops, parent.ListAfterContentOp(newBlock.ID, afterID))
+		afterID = newBlock.ID
+		ops = append(ops, c.buildMarkdownOps(userID, newBlock, "", mb.Children)...)
+	}
+	return ops
+}
+
+// AppendMarkdown converts Markdown to blocks (see ParseMarkdown)
+// and appends them at the end of a page
+func (c *Client) AppendMarkdown(pageID string, md string) error {
+	page, err := c.getBlock(pageID)
+	if err != nil {
+		return err
+	}
+	blocks := ParseMarkdown(md)
+	if len(blocks) == 0 {
+		return nil
+	}
+	userID, err := c.currentUserID()
+	if err != nil {
+		return err
+	}
+	afterID := ""
+	if n := len(page.ContentIDs); n > 0 {
+		afterID = page.ContentIDs[n-1]
+	}
+	ops := c.buildMarkdownOps(userID, page, afterID, blocks)
+	return c.SubmitTransaction(ops)
+}
@@ -0,0 +1,65 @@
+package notionapi
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseMarkdownInline(t *testing.T) {
+	got := parseMarkdownInline("a **b ~~c~~** *d* `e` [f](http://x)")
+	exp := []interface{}{
+		[]interface{}{"a "},
+		[]interface{}{"b ", []interface{}{[]interface{}{AttrBold}}},
+		[]interface{}{"c", []interface{}{[]interface{}{AttrBold}, []interface{}{AttrStrikeThrought}}},
+		[]interface{}{" "},
+		[]interface{}{"d", []interface{}{[]interface{}{AttrItalic}}},
+		[]interface{}{" "},
+		[]interface{}{"e", []interface{}{[]interface{}{AttrCode}}},
+		[]interface{}{" "},
+		[]interface{}{"f", []interface{}{[]interface{}{AttrLink, "http://x"}}},
+	}
+	assert.Equal(t, exp, got)
+}
+
+func TestParseMarkdown(t *testing.T) {
+	md := `# Title
+
+Some
+text
+
+- item
+  - nested
+- [x] done
+1. first
+
+` + "```go\nfmt.Println()\n```" + `
+---
+> quote
+![alt](https://example.com/a.png)
+
+| a | b |
+|---|---|
+| 1 | 2 |
+`
+	blocks := ParseMarkdown(md)
+	var types []string
+	for _, b := range blocks {
+		types = append(types, b.Type)
+	}
+	exp := []string{BlockHeader, BlockText, BlockBulletedList, BlockTodo, BlockNumberedList, BlockCode, BlockDivider, BlockQuote, BlockImage, BlockTable}
+	assert.Equal(t, exp, types)
+	assert.Equal(t, []interface{}{[]interface{}{"Some text"}}, blocks[1].Properties["title"])
+	assert.Equal(t, 1, len(blocks[2].Children))
+	assert.Equal(t, []interface{}{[]interface{}{"Go"}}, blocks[5].Properties["language"])
+	assert.Equal(t, 2, len(blocks[9].Children))
+
+	c := &Client{}
+	parent := &Block{ID: "parent"}
+	ops := c.buildMarkdownOps("user", parent, "", blocks[2:3])
+	// create item, list it, create nested, list it
+	assert.Equal(t, 4, len(ops))
+	assert.Equal(t, CommandListAfter, ops[1].Command)
+	assert.Equal(t, "parent", ops[1].ID)
+	assert.Equal(t, ops[0].ID, ops[3].ID)
+}
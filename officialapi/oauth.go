@@ -0,0 +1,245 @@
+package officialapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sync"
+)
+
+const (
+	// DefaultAuthorizationURL is where users authorize a public integration
+	DefaultAuthorizationURL = "https://api.notion.com/v1/oauth/authorize"
+	// DefaultTokenURL is where authorization codes are exchanged for tokens
+	DefaultTokenURL = "https://api.notion.com/v1/oauth/token"
+	// NotionVersion is the version of the API we send in Notion-Version header
+	NotionVersion = "2022-06-28"
+)
+
+// OAuthConfig describes a public integration, as configured in
+// https://www.notion.so/my-integrations
+type OAuthConfig struct {
+	ClientID     string
+	ClientSecret string
+	// RedirectURI must be one of redirect URIs of the integration
+	RedirectURI string
+
+	// AuthorizationURL and TokenURL default to DefaultAuthorizationURL
+	// and DefaultTokenURL
+	AuthorizationURL string
+	TokenURL         string
+	// HTTPClient is used for token requests. Defaults to http.DefaultClient
+	HTTPClient *http.Client
+}
+
+// Token is an access token of a public integration, returned after
+// a user authorizes it
+type Token struct {
+	AccessToken   string `json:"access_token"`
+	TokenType     string `json:"token_type"`
+	RefreshToken  string `json:"refresh_token,omitempty"`
+	BotID         string `json:"bot_id"`
+	WorkspaceID   string `json:"workspace_id"`
+	WorkspaceName string `json:"workspace_name"`
+	WorkspaceIcon string `json:"workspace_icon"`
+	// Owner is who authorized the integration e.g.
+	// {"type": "user", "user": {...}}
+	Owner json.RawMessage `json:"owner,omitempty"`
+	// DuplicatedTemplateID is set if the user chose to duplicate
+	// a template of the integration
+	DuplicatedTemplateID string `json:"duplicated_template_id,omitempty"`
+}
+
+// ErrOAuth is returned when Notion rejects an OAuth request or a user
+// didn't authorize the integration
+type ErrOAuth struct {
+	StatusCode int
+	// Code is e.g. "invalid_grant" or "access_denied"
+	Code    string
+	Message string
+}
+
+// Error return error string
+func (e *ErrOAuth) Error() string {
+	s := "oauth error '" + e.Code + "'"
+	if e.StatusCode != 0 {
+		s += fmt.Sprintf(" (status code %d)", e.StatusCode)
+	}
+	if e.Message != "" {
+		s += ": " + e.Message
+	}
+	return s
+}
+
+// IsErrOAuth returns true if err is an instance of ErrOAuth
+func IsErrOAuth(err error) bool {
+	_, ok := err.(*ErrOAuth)
+	return ok
+}
+
+// AuthCodeURL returns a URL to which we redirect a user to authorize
+// the integration. state is sent back to RedirectURI and should be
+// checked to prevent CSRF (see HandleCallback)
+func (c *OAuthConfig) AuthCodeURL(state string) string {
+	uri := c.AuthorizationURL
+	if uri == "" {
+		uri = DefaultAuthorizationURL
+	}
+	v := url.Values{}
+	v.Set("client_id", c.ClientID)
+	v.Set("response_type", "code")
+	v.Set("owner", "user")
+	if c.RedirectURI != "" {
+		v.Set("redirect_uri", c.RedirectURI)
+	}
+	if state != "" {
+		v.Set("state", state)
+	}
+	return uri + "?" + v.Encode()
+}
+
+func (c *OAuthConfig) requestToken(req map[string]string) (*Token, error) {
+	uri := c.TokenURL
+	if uri == "" {
+		uri = DefaultTokenURL
+	}
+	js, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+	httpReq, err := http.NewRequest("POST", uri, bytes.NewReader(js))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.SetBasicAuth(c.ClientID, c.ClientSecret)
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("Notion-Version", NotionVersion)
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	rsp, err := httpClient.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer rsp.Body.Close()
+	d, err := ioutil.ReadAll(rsp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if rsp.StatusCode != http.StatusOK {
+		var e struct {
+			Error            string `json:"error"`
+			ErrorDescription string `json:"error_description"`
+			// errors of the API have code and message
+			Code    string `json:"code"`
+			Message string `json:"message"`
+		}
+		_ = json.Unmarshal(d, &e)
+		res := &ErrOAuth{
+			StatusCode: rsp.StatusCode,
+			Code:       e.Error,
+			Message:    e.ErrorDescription,
+		}
+		if res.Code == "" {
+			res.Code = e.Code
+		}
+		if res.Message == "" {
+			res.Message = e.Message
+		}
+		return nil, res
+	}
+	var token Token
+	if err = json.Unmarshal(d, &token); err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+// Exchange exchanges an authorization code, sent to RedirectURI,
+// for a token
+func (c *OAuthConfig) Exchange(code string) (*Token, error) {
+	req := map[string]string{
+		"grant_type": "authorization_code",
+		"code":       code,
+	}
+	if c.RedirectURI != "" {
+		req["redirect_uri"] = c.RedirectURI
+	}
+	return c.requestToken(req)
+}
+
+// Refresh returns a new token for a refresh token
+func (c *OAuthConfig) Refresh(refreshToken string) (*Token, error) {
+	req := map[string]string{
+		"grant_type":    "refresh_token",
+		"refresh_token": refreshToken,
+	}
+	return c.requestToken(req)
+}
+
+// HandleCallback handles a request to RedirectURI: checks that state
+// is the one we sent in AuthCodeURL (it must not be empty) and exchanges
+// the code for a token. Returns ErrOAuth if the user didn't authorize
+// the integration
+func (c *OAuthConfig) HandleCallback(r *http.Request, state string) (*Token, error) {
+	q := r.URL.Query()
+	if errCode := q.Get("error"); errCode != "" {
+		return nil, &ErrOAuth{Code: errCode}
+	}
+	// empty state would disable CSRF protection
+	if state == "" || q.Get("state") != state {
+		return nil, &ErrOAuth{Code: "invalid_state", Message: "state doesn't match"}
+	}
+	code := q.Get("code")
+	if code == "" {
+		return nil, &ErrOAuth{Code: "invalid_request", Message: "missing code"}
+	}
+	return c.Exchange(code)
+}
+
+// TokenStore stores tokens of users of an integration. The key is chosen
+// by the app e.g. id of its user or Token.BotID, which is unique
+// for each authorization
+type TokenStore interface {
+	// GetToken returns nil if there's no token for key
+	GetToken(key string) (*Token, error)
+	SaveToken(key string, token *Token) error
+	DeleteToken(key string) error
+}
+
+// MemoryTokenStore is a TokenStore that keeps tokens in memory.
+// It's safe for concurrent use
+type MemoryTokenStore struct {
+	mu     sync.Mutex
+	tokens map[string]*Token
+}
+
+// GetToken returns a token for key or nil
+func (s *MemoryTokenStore) GetToken(key string) (*Token, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.tokens[key], nil
+}
+
+// SaveToken saves a token for key
+func (s *MemoryTokenStore) SaveToken(key string, token *Token) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.tokens == nil {
+		s.tokens = map[string]*Token{}
+	}
+	s.tokens[key] = token
+	return nil
+}
+
+// DeleteToken deletes a token for key
+func (s *MemoryTokenStore) DeleteToken(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.tokens, key)
+	return nil
+}
@@ -0,0 +1,83 @@
+package officialapi
+
+import (
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type tokenTransport struct {
+	req      *http.Request
+	body     string
+	response string
+	status   int
+}
+
+func (t *tokenTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.req = req
+	d, _ := ioutil.ReadAll(req.Body)
+	t.body = string(d)
+	return &http.Response{
+		StatusCode: t.status,
+		Body:       ioutil.NopCloser(strings.NewReader(t.response)),
+		Request:    req,
+	}, nil
+}
+
+func TestOAuth(t *testing.T) {
+	transport := &tokenTransport{
+		status:   http.StatusOK,
+		response: `{"access_token":"secret_t","token_type":"bearer","bot_id":"b1","workspace_id":"w1","owner":{"type":"user"}}`,
+	}
+	c := &OAuthConfig{
+		ClientID:     "cid",
+		ClientSecret: "csecret",
+		RedirectURI:  "https://example.com/callback",
+		HTTPClient:   &http.Client{Transport: transport},
+	}
+	uri := c.AuthCodeURL("st")
+	require.True(t, strings.HasPrefix(uri, DefaultAuthorizationURL+"?"))
+	require.Contains(t, uri, "client_id=cid")
+	require.Contains(t, uri, "state=st")
+	require.Contains(t, uri, "redirect_uri=https%3A%2F%2Fexample.com%2Fcallback")
+
+	r, _ := http.NewRequest("GET", "/callback?code=c1&state=st", nil)
+	token, err := c.HandleCallback(r, "st")
+	require.NoError(t, err)
+	require.Equal(t, "secret_t", token.AccessToken)
+	require.Equal(t, "w1", token.WorkspaceID)
+	require.Equal(t, DefaultTokenURL, transport.req.URL.String())
+	user, pwd, _ := transport.req.BasicAuth()
+	require.Equal(t, "cid", user)
+	require.Equal(t, "csecret", pwd)
+	require.Contains(t, transport.body, `"code":"c1"`)
+	require.Contains(t, transport.body, `"grant_type":"authorization_code"`)
+
+	r, _ = http.NewRequest("GET", "/callback?code=c1&state=other", nil)
+	_, err = c.HandleCallback(r, "st")
+	require.True(t, IsErrOAuth(err))
+	r, _ = http.NewRequest("GET", "/callback?code=c1", nil)
+	_, err = c.HandleCallback(r, "")
+	require.Equal(t, "invalid_state", err.(*ErrOAuth).Code)
+	r, _ = http.NewRequest("GET", "/callback?error=access_denied&state=st", nil)
+	_, err = c.HandleCallback(r, "st")
+	require.Equal(t, "access_denied", err.(*ErrOAuth).Code)
+
+	transport.status = http.StatusBadRequest
+	transport.response = `{"error":"invalid_grant","error_description":"bad code"}`
+	_, err = c.Exchange("c2")
+	require.True(t, IsErrOAuth(err))
+	require.Equal(t, "invalid_grant", err.(*ErrOAuth).Code)
+
+	var store TokenStore = &MemoryTokenStore{}
+	require.NoError(t, store.SaveToken("u1", token))
+	got, err := store.GetToken("u1")
+	require.NoError(t, err)
+	require.Equal(t, token, got)
+	require.NoError(t, store.DeleteToken("u1"))
+	got, _ = store.GetToken("u1")
+	require.Nil(t, got)
+}
@@ -0,0 +1,143 @@
+// Package officialapi has helpers for the official Notion API
+// (https://developers.notion.com): webhooks (parsing of payloads and
+// verification of their signatures) and OAuth of public integrations
+package officialapi
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// SignatureHeader is a header with a signature of a webhook payload
+const SignatureHeader = "X-Notion-Signature"
+
+// types of webhook events (WebhookEvent.Type)
+const (
+	EventPageCreated            = "page.created"
+	EventPageContentUpdated     = "page.content_updated"
+	EventPagePropertiesUpdated  = "page.properties_updated"
+	EventPageMoved              = "page.moved"
+	EventPageDeleted            = "page.deleted"
+	EventPageUndeleted          = "page.undeleted"
+	EventPageLocked             = "page.locked"
+	EventPageUnlocked           = "page.unlocked"
+	EventDatabaseCreated        = "database.created"
+	EventDatabaseContentUpdated = "database.content_updated"
+	EventDatabaseSchemaUpdated  = "database.schema_updated"
+	EventDatabaseMoved          = "database.moved"
+	EventDatabaseDeleted        = "database.deleted"
+	EventDatabaseUndeleted      = "database.undeleted"
+	EventCommentCreated         = "comment.created"
+	EventCommentUpdated         = "comment.updated"
+	EventCommentDeleted         = "comment.deleted"
+)
+
+// WebhookObject is a reference to an object in a webhook event
+type WebhookObject struct {
+	ID string `json:"id"`
+	// e.g. "page", "database", "comment" or, for authors, "person", "bot"
+	Type string `json:"type"`
+}
+
+// WebhookEvent is a payload of a webhook request
+type WebhookEvent struct {
+	ID             string           `json:"id"`
+	Timestamp      time.Time        `json:"timestamp"`
+	WorkspaceID    string           `json:"workspace_id"`
+	WorkspaceName  string           `json:"workspace_name"`
+	SubscriptionID string           `json:"subscription_id"`
+	IntegrationID  string           `json:"integration_id"`
+	Type           string           `json:"type"`
+	Authors        []*WebhookObject `json:"authors"`
+	// Entity is the object the event is about
+	Entity        *WebhookObject `json:"entity"`
+	AttemptNumber int            `json:"attempt_number"`
+	// Data depends on Type e.g. parent of the entity and ids
+	// of updated blocks
+	Data json.RawMessage `json:"data"`
+
+	RawJSON map[string]interface{} `json:"-"`
+}
+
+// ErrInvalidSignature is returned when a signature of a webhook payload
+// doesn't match
+type ErrInvalidSignature struct {
+	Signature string
+}
+
+// Error return error string
+func (e *ErrInvalidSignature) Error() string {
+	if e.Signature == "" {
+		return "webhook request is not signed"
+	}
+	return "invalid webhook signature '" + e.Signature + "'"
+}
+
+// IsErrInvalidSignature returns true if err is an instance of ErrInvalidSignature
+func IsErrInvalidSignature(err error) bool {
+	_, ok := err.(*ErrInvalidSignature)
+	return ok
+}
+
+// ComputeSignature returns a signature of a webhook payload, as sent
+// in SignatureHeader. verificationToken is the token Notion sends when
+// a webhook subscription is created (see ParseVerificationToken)
+func ComputeSignature(verificationToken string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(verificationToken))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifySignature returns nil if signature of body is valid or
+// ErrInvalidSignature if it's not
+func VerifySignature(verificationToken string, body []byte, signature string) error {
+	expected := ComputeSignature(verificationToken, body)
+	if !hmac.Equal([]byte(expected), []byte(strings.TrimSpace(signature))) {
+		return &ErrInvalidSignature{Signature: signature}
+	}
+	return nil
+}
+
+// ParseVerificationToken returns the verification token if body is
+// the request Notion sends when a webhook subscription is created,
+// or "" if it's not
+func ParseVerificationToken(body []byte) string {
+	var req struct {
+		VerificationToken string `json:"verification_token"`
+	}
+	if err := json.Unmarshal(body, &req); err != nil {
+		return ""
+	}
+	return req.VerificationToken
+}
+
+// ParseWebhookEvent parses a webhook payload
+func ParseWebhookEvent(body []byte) (*WebhookEvent, error) {
+	var ev WebhookEvent
+	if err := json.Unmarshal(body, &ev); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(body, &ev.RawJSON); err != nil {
+		return nil, err
+	}
+	return &ev, nil
+}
+
+// ReadWebhook reads a webhook request, verifies its signature with
+// verificationToken and parses the event
+func ReadWebhook(r *http.Request, verificationToken string) (*WebhookEvent, error) {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return nil, err
+	}
+	if err = VerifySignature(verificationToken, body, r.Header.Get(SignatureHeader)); err != nil {
+		return nil, err
+	}
+	return ParseWebhookEvent(body)
+}
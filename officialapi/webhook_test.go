@@ -0,0 +1,33 @@
+package officialapi
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadWebhook(t *testing.T) {
+	require.Equal(t, "secret_abc", ParseVerificationToken([]byte(`{"verification_token":"secret_abc"}`)))
+	require.Equal(t, "", ParseVerificationToken([]byte(`{"id":"1"}`)))
+
+	body := `{"id":"e1","timestamp":"2024-12-05T23:57:05.379Z","workspace_id":"w1","type":"page.content_updated",` +
+		`"authors":[{"id":"u1","type":"person"}],"entity":{"id":"p1","type":"page"},"data":{"updated_blocks":[]}}`
+	newRequest := func(sig string) *http.Request {
+		r, _ := http.NewRequest("POST", "/webhook", strings.NewReader(body))
+		r.Header.Set(SignatureHeader, sig)
+		return r
+	}
+	ev, err := ReadWebhook(newRequest(ComputeSignature("secret_abc", []byte(body))), "secret_abc")
+	require.NoError(t, err)
+	require.Equal(t, EventPageContentUpdated, ev.Type)
+	require.Equal(t, "p1", ev.Entity.ID)
+	require.Equal(t, "person", ev.Authors[0].Type)
+	require.Equal(t, 2024, ev.Timestamp.Year())
+
+	_, err = ReadWebhook(newRequest(ComputeSignature("other", []byte(body))), "secret_abc")
+	require.True(t, IsErrInvalidSignature(err))
+	_, err = ReadWebhook(newRequest(""), "secret_abc")
+	require.True(t, IsErrInvalidSignature(err))
+}
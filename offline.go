@@ -0,0 +1,13 @@
+//go:build notionapi_offline
+// +build notionapi_offline
+
+package notionapi
+
+// Offline is true when built with notionapi_offline build tag. The package
+// then makes no network requests other than those explicitly requested
+// with Client (API calls and file downloads): tohtml.FetchTwitterOEmbed
+// returns ErrNetworkNotAllowed and rendered HTML doesn't load scripts,
+// CSS or iframes from third parties (gists, videos, maps and other
+// embeds are rendered as links). Use RestrictedTransport to verify
+// which hosts Client talks to
+const Offline = true
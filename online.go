@@ -0,0 +1,7 @@
+//go:build !notionapi_offline
+// +build !notionapi_offline
+
+package notionapi
+
+// Offline is true when built with notionapi_offline build tag (see offline.go)
+const Offline = false
@@ -0,0 +1,132 @@
+package notionapi
+
+// OutlineItem is a section of a page in Page.Outline: a heading and
+// blocks up to the next heading of the same or higher level
+type OutlineItem struct {
+	// Heading is the heading block of the section. For the top item
+	// it's the root block of the page
+	Heading *Block
+	// Level is 1 to 3 (see HeadingLevel) and 0 for the top item
+	Level int
+	// Title is the text of the heading or the title of the page
+	Title string
+	// BlockIDs are ids of blocks in this section, excluding blocks
+	// of sub-sections, in the order of the page. Blocks nested in other
+	// blocks (e.g. in toggles and columns) are included
+	BlockIDs []string
+	// BlockCount is the number of blocks in this section, including
+	// sub-sections and their headings
+	BlockCount int
+	// Children are sub-sections
+	Children []*OutlineItem
+}
+
+// Headings returns heading blocks of the outline in the order of the page
+func (o *OutlineItem) Headings() []*Block {
+	var res []*Block
+	var walk func(*OutlineItem)
+	walk = func(item *OutlineItem) {
+		for _, child := range item.Children {
+			res = append(res, child.Heading)
+			walk(child)
+		}
+	}
+	walk(o)
+	return res
+}
+
+func (o *OutlineItem) countBlocks() int {
+	n := len(o.BlockIDs)
+	for _, child := range o.Children {
+		n += 1 + child.countBlocks()
+	}
+	o.BlockCount = n
+	return n
+}
+
+// Outline returns a tree of sections of the page, based on its headings.
+// Headings nested in other blocks (e.g. in toggles or columns) are part
+// of the outline. Sub-pages are counted as blocks but their content isn't
+func (p *Page) Outline() *OutlineItem {
+	root := p.Root()
+	top := &OutlineItem{
+		Heading: root,
+		Title:   root.Title,
+	}
+	stack := []*OutlineItem{top}
+	p.Walk(&Visitor{
+		Enter: func(block *Block, depth int) bool {
+			if depth == 0 {
+				// the root block
+				return true
+			}
+			level := HeadingLevel(block)
+			if level == 0 {
+				curr := stack[len(stack)-1]
+				curr.BlockIDs = append(curr.BlockIDs, block.ID)
+				return true
+			}
+			for len(stack) > 1 && stack[len(stack)-1].Level >= level {
+				stack = stack[:len(stack)-1]
+			}
+			item := &OutlineItem{
+				Heading: block,
+				Level:   level,
+				Title:   TextSpansToString(block.InlineContent),
+			}
+			parent := stack[len(stack)-1]
+			parent.Children = append(parent.Children, item)
+			stack = append(stack, item)
+			return true
+		},
+	})
+	top.countBlocks()
+	return top
+}
+
+// Section is a part of a page, split at a heading by SplitBlocks
+type Section struct {
+	// Heading is the heading that starts the section. It's nil for
+	// blocks before the first heading
+	Heading *Block
+	// Title is the text of Heading
+	Title string
+	// Blocks are blocks after Heading, up to the next heading
+	// at which we split
+	Blocks []*Block
+}
+
+// SplitBlocks splits blocks into sections at headings of a given level
+// or higher e.g. level 1 splits only at BlockHeader and level 2 at
+// BlockHeader and BlockSubHeader. Headings nested in other blocks
+// don't split. Blocks before the first heading are in a section
+// without Heading, which is omitted if there are no such blocks.
+// Useful for splitting a page into e.g. EPUB chapters or slides
+func SplitBlocks(blocks []*Block, level int) []*Section {
+	var res []*Section
+	curr := &Section{}
+	for _, block := range blocks {
+		l := HeadingLevel(block)
+		if l == 0 || l > level {
+			curr.Blocks = append(curr.Blocks, block)
+			continue
+		}
+		if curr.Heading != nil || len(curr.Blocks) > 0 {
+			res = append(res, curr)
+		}
+		curr = &Section{
+			Heading: block,
+			Title:   TextSpansToString(block.InlineContent),
+		}
+	}
+	if curr.Heading != nil || len(curr.Blocks) > 0 {
+		res = append(res, curr)
+	}
+	return res
+}
+
+// SplitPage splits top-level blocks of a page into sections at headings
+// of a given level or higher (see SplitBlocks)
+func SplitPage(page *Page, level int) []*Section {
+	return SplitBlocks(page.Root().Content, level)
+}
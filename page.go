@@ -4,6 +4,8 @@ import (
 	"errors"
 	"fmt"
 	"sort"
+	"strings"
+	"time"
 )
 
 var (
@@ -30,6 +32,9 @@ type Page struct {
 	// we } TableView representing that collection view_id
 	TableViews []*TableView
 
+	// Stats describes how the page was downloaded
+	Stats PageLoadStats
+
 	idToBlock          map[string]*Block
 	idToUser           map[string]*User
 	idToCollection     map[string]*Collection
@@ -39,9 +44,26 @@ type Page struct {
 
 	blocksToSkip map[string]struct{} // not alive or when server doesn't return "value" for this block id
 
+	// maps url of a file to signed url, see SignFileURLs
+	signedURLs map[string]string
+
 	client *Client
 }
 
+// PageLoadStats describes how a page was downloaded by DownloadPage
+type PageLoadStats struct {
+	// number of chunks returned by loadPageChunk
+	Chunks int
+	// number of API calls made to download the page
+	APICalls int
+	// number of blocks downloaded
+	Blocks int
+	// how long it took to download the page
+	Duration time.Duration
+	// id of the space (workspace) the page belongs to
+	SpaceID string
+}
+
 // BlockByID returns a block by its id
 func (p *Page) BlockByID(id string) *Block {
 	return p.idToBlock[ToDashID(id)]
@@ -77,6 +99,64 @@ func (p *Page) Root() *Block {
 	return p.BlockByID(p.ID)
 }
 
+// PageCover describes a cover image of a page
+type PageCover struct {
+	// URL of the image. Notion's built-in covers are relative
+	// (/images/page-cover/...) so we make them absolute
+	URL string
+	// vertical position of the image, as in format.page_cover_position.
+	// 0 means bottom, 1 means top
+	Position float64
+}
+
+// PageIcon describes an icon of a page, which is either an emoji
+// or an uploaded image
+type PageIcon struct {
+	Emoji string
+	URL   string
+}
+
+// Cover returns a cover image of the page or nil if the page
+// doesn't have a cover
+func (p *Page) Cover() *PageCover {
+	root := p.Root()
+	if root == nil {
+		return nil
+	}
+	fp := root.FormatPage()
+	if fp == nil || fp.PageCover == "" {
+		return nil
+	}
+	uri := fp.PageCover
+	if strings.HasPrefix(uri, "/") {
+		uri = "https://www.notion.so" + uri
+	}
+	return &PageCover{
+		URL:      uri,
+		Position: fp.PageCoverPosition,
+	}
+}
+
+// Icon returns an icon of the page or nil if the page doesn't have an icon
+func (p *Page) Icon() *PageIcon {
+	root := p.Root()
+	if root == nil {
+		return nil
+	}
+	fp := root.FormatPage()
+	if fp == nil || fp.PageIcon == "" {
+		return nil
+	}
+	icon := fp.PageIcon
+	if strings.HasPrefix(icon, "http://") || strings.HasPrefix(icon, "https://") || strings.HasPrefix(icon, "/") {
+		if strings.HasPrefix(icon, "/") {
+			icon = "https://www.notion.so" + icon
+		}
+		return &PageIcon{URL: icon}
+	}
+	return &PageIcon{Emoji: icon}
+}
+
 // SetTitle changes page title
 func (p *Page) SetTitle(s string) error {
 	op := p.Root().SetTitleOp(s)
@@ -143,6 +223,177 @@ func (p *Page) ForEachBlock(cb func(*Block)) {
 	forEachBlockWithParent(seen, blocks, nil, cb)
 }
 
+// FindBlockByID returns a block with a given id (with or without dashes)
+// if it's in the tree of blocks of the page, nil otherwise. Unlike
+// BlockByID, it doesn't return blocks that were downloaded but are not
+// part of the page e.g. content of sub-pages
+func (p *Page) FindBlockByID(id string) *Block {
+	id = ToDashID(id)
+	var res *Block
+	p.Walk(&Visitor{
+		Enter: func(block *Block, depth int) bool {
+			if res != nil {
+				return false
+			}
+			if block.ID == id {
+				res = block
+			}
+			return res == nil
+		},
+	})
+	return res
+}
+
+// Visitor has callbacks called by Walk. Either can be nil
+type Visitor struct {
+	// Enter is called for a block before its children. depth is 0
+	// for blocks passed to Walk. Children are skipped if it returns false
+	Enter func(block *Block, depth int) bool
+	// Exit is called for a block after its children (also when they
+	// were skipped)
+	Exit func(block *Block, depth int)
+}
+
+func walkBlocks(seen map[string]bool, blocks []*Block, depth int, v *Visitor) {
+	for _, block := range blocks {
+		if block == nil || seen[block.ID] {
+			// avoid infinite recursion on malformed pages
+			continue
+		}
+		seen[block.ID] = true
+		descend := true
+		if v.Enter != nil {
+			descend = v.Enter(block, depth)
+		}
+		// sub-pages are visited but their content isn't
+		if depth > 0 && isPageBlock(block) {
+			descend = false
+		}
+		if descend {
+			walkBlocks(seen, block.Content, depth+1, v)
+		}
+		if v.Exit != nil {
+			v.Exit(block, depth)
+		}
+	}
+}
+
+// Walk traverses the tree of blocks in depth-first order, calling
+// v.Enter before and v.Exit after children of a block. Unlike
+// ForEachBlock, it calls callbacks for sub-pages (but doesn't descend
+// into them), doesn't change Block.Parent and skips blocks seen before
+// instead of panicking
+func Walk(blocks []*Block, v *Visitor) {
+	walkBlocks(map[string]bool{}, blocks, 0, v)
+}
+
+// Walk traverses the tree of blocks of the page, starting with the root
+// block (see Walk)
+func (p *Page) Walk(v *Visitor) {
+	Walk([]*Block{p.Root()}, v)
+}
+
+// HeadingLevel returns 1 for BlockHeader, 2 for BlockSubHeader,
+// 3 for BlockSubSubHeader and 0 for blocks that are not headings
+func HeadingLevel(block *Block) int {
+	switch block.Type {
+	case BlockHeader:
+		return 1
+	case BlockSubHeader:
+		return 2
+	case BlockSubSubHeader:
+		return 3
+	}
+	return 0
+}
+
+// ListOrdinal returns the number of a BlockNumberedList item at index
+// idx in blocks i.e. its position in the list started by the first of
+// consecutive numbered list items. Returns 0 if it's not a numbered
+// list item
+func ListOrdinal(blocks []*Block, idx int) int {
+	if idx < 0 || idx >= len(blocks) {
+		return 0
+	}
+	n := 0
+	for i := idx; i >= 0; i-- {
+		block := blocks[i]
+		if block == nil || block.Type != BlockNumberedList {
+			break
+		}
+		n++
+	}
+	return n
+}
+
+// ListOrdinals returns ListOrdinal of each of blocks. Unlike calling
+// ListOrdinal for each block, it's O(n)
+func ListOrdinals(blocks []*Block) []int {
+	res := make([]int, len(blocks))
+	for i, block := range blocks {
+		if block == nil || block.Type != BlockNumberedList {
+			continue
+		}
+		res[i] = 1
+		if i > 0 {
+			res[i] += res[i-1]
+		}
+	}
+	return res
+}
+
+// ListOrdinal returns the number of a BlockNumberedList item within its
+// list, as shown by Notion (see ListOrdinal). Returns 0 if it's not
+// a numbered list item or its parent is not known
+func (b *Block) ListOrdinal() int {
+	if b.Type != BlockNumberedList || b.Parent == nil {
+		return 0
+	}
+	for i, block := range b.Parent.Content {
+		if block == b {
+			return ListOrdinal(b.Parent.Content, i)
+		}
+	}
+	return 0
+}
+
+// ExtractSection returns blocks between a heading whose text is headingText
+// and the next heading of the same or higher level. Comparison of text is
+// case-insensitive. Returns nil if there's no such heading
+func ExtractSection(page *Page, headingText string) []*Block {
+	headingText = strings.TrimSpace(headingText)
+	var heading *Block
+	page.ForEachBlock(func(block *Block) {
+		if heading != nil || HeadingLevel(block) == 0 {
+			return
+		}
+		text := block.Title
+		if text == "" {
+			text = TextSpansToString(block.InlineContent)
+		}
+		if strings.EqualFold(strings.TrimSpace(text), headingText) {
+			heading = block
+		}
+	})
+	if heading == nil || heading.Parent == nil {
+		return nil
+	}
+	level := HeadingLevel(heading)
+	var res []*Block
+	found := false
+	for _, block := range heading.Parent.Content {
+		if !found {
+			found = block == heading
+			continue
+		}
+		if l := HeadingLevel(block); l > 0 && l <= level {
+			break
+		}
+		res = append(res, block)
+	}
+	return res
+}
+
 func panicIf(cond bool, args ...interface{}) {
 	if !cond {
 		return
@@ -234,6 +485,65 @@ func (p *Page) GetSubPages() []string {
 	return res
 }
 
+// SubPage is a page shown in the content of a page: either its child
+// page or a link to another page
+type SubPage struct {
+	// ID is id of the page in dash format
+	ID    string
+	Title string
+	// Block is BlockPage or BlockCollectionViewPage for child pages
+	// and BlockLinkToPage or page blocks of other pages for links
+	Block *Block
+	// IsChild is true for child pages, false for links to other pages
+	IsChild bool
+}
+
+// SubPages returns child pages of the page and pages it links to with
+// BlockLinkToPage, in the order of the page. Each page is returned once.
+// Unlike GetSubPages, it includes links and titles of the pages. Titles
+// of linked pages are "" if they were not downloaded with the page.
+// Mentions of pages in text are not included (see PageLinks)
+func (p *Page) SubPages() []*SubPage {
+	var res []*SubPage
+	seen := map[string]bool{}
+	add := func(sp *SubPage) {
+		if sp.ID == "" || seen[sp.ID] || sp.ID == ToDashID(p.ID) {
+			return
+		}
+		seen[sp.ID] = true
+		res = append(res, sp)
+	}
+	p.Walk(&Visitor{
+		Enter: func(block *Block, depth int) bool {
+			if depth == 0 {
+				return true
+			}
+			if isPageBlock(block) {
+				add(&SubPage{
+					ID:      block.ID,
+					Title:   block.Title,
+					Block:   block,
+					IsChild: p.IsSubPage(block),
+				})
+				return false
+			}
+			if block.Type == BlockLinkToPage {
+				id, _ := block.PropAsString("format.alias_pointer.id")
+				sp := &SubPage{
+					ID:    ToDashID(id),
+					Block: block,
+				}
+				if linked := p.BlockByID(id); linked != nil {
+					sp.Title = linked.Title
+				}
+				add(sp)
+			}
+			return true
+		},
+	})
+	return res
+}
+
 func makeUserName(user *User) string {
 	s := user.GivenName
 	if len(s) > 0 {
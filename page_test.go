@@ -0,0 +1,71 @@
+package notionapi
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPageCoverAndIcon(t *testing.T) {
+	root := &Block{
+		ID:   "root",
+		Type: BlockPage,
+		RawJSON: map[string]interface{}{
+			"format": map[string]interface{}{
+				"page_cover":          "/images/page-cover/rijksmuseum_claesz_1628.jpg",
+				"page_cover_position": 0.352,
+				"page_icon":           "🏕",
+			},
+		},
+	}
+	p := &Page{ID: "root", idToBlock: map[string]*Block{ToDashID("root"): root}}
+	cover := p.Cover()
+	assert.Equal(t, "https://www.notion.so/images/page-cover/rijksmuseum_claesz_1628.jpg", cover.URL)
+	assert.Equal(t, 0.352, cover.Position)
+	assert.Equal(t, &PageIcon{Emoji: "🏕"}, p.Icon())
+}
+
+func TestSubPages(t *testing.T) {
+	child := &Block{ID: "child", Type: BlockPage, Title: "Child", ParentID: "toggle"}
+	toggle := &Block{ID: "toggle", Type: BlockToggle, ParentID: "root", Content: []*Block{child}}
+	alias := &Block{ID: "alias", Type: BlockLinkToPage, ParentID: "root", RawJSON: map[string]interface{}{
+		"format": map[string]interface{}{"alias_pointer": map[string]interface{}{"id": "linked"}},
+	}}
+	other := &Block{ID: "other", Type: BlockPage, Title: "Other", ParentID: "elsewhere"}
+	aliasToChild := &Block{ID: "alias2", Type: BlockLinkToPage, ParentID: "root", RawJSON: map[string]interface{}{
+		"format": map[string]interface{}{"alias_pointer": map[string]interface{}{"id": "child"}},
+	}}
+	root := &Block{ID: "root", Type: BlockPage, Content: []*Block{toggle, alias, other, aliasToChild}}
+	linked := &Block{ID: "linked", Type: BlockPage, Title: "Linked"}
+	p := &Page{ID: "root", idToBlock: map[string]*Block{}}
+	for _, b := range []*Block{root, toggle, child, alias, other, aliasToChild, linked} {
+		p.idToBlock[b.ID] = b
+	}
+
+	pages := p.SubPages()
+	assert.Equal(t, 3, len(pages))
+	assert.Equal(t, "child", pages[0].ID)
+	assert.True(t, pages[0].IsChild)
+	assert.Equal(t, "Linked", pages[1].Title)
+	assert.Equal(t, alias, pages[1].Block)
+	assert.False(t, pages[1].IsChild)
+	assert.Equal(t, "Other", pages[2].Title)
+	assert.False(t, pages[2].IsChild)
+}
+
+func TestListOrdinal(t *testing.T) {
+	parent := &Block{Type: BlockPage}
+	types := []string{BlockNumberedList, BlockNumberedList, BlockText, BlockNumberedList, BlockBulletedList}
+	for _, typ := range types {
+		parent.Content = append(parent.Content, &Block{Type: typ, Parent: parent})
+	}
+	var got []int
+	for i, block := range parent.Content {
+		got = append(got, ListOrdinal(parent.Content, i))
+		assert.Equal(t, got[i], block.ListOrdinal())
+	}
+	assert.Equal(t, []int{1, 2, 0, 1, 0}, got)
+	assert.Equal(t, got, ListOrdinals(parent.Content))
+	assert.Equal(t, 2, parent.Content[1].AsText().Ordinal)
+	assert.Equal(t, 0, ListOrdinal(parent.Content, 10))
+}
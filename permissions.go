@@ -0,0 +1,111 @@
+package notionapi
+
+// Permissions returns permissions of the page: of members of the space
+// (PermissionSpace), of users (PermissionUser) and public (PermissionPublic).
+// Pages without their own permissions inherit permissions of the parent
+// and return nil
+func (p *Page) Permissions() []*Permission {
+	root := p.Root()
+	if root == nil || root.Permissions == nil {
+		return nil
+	}
+	var res []*Permission
+	for i := range *root.Permissions {
+		res = append(res, &(*root.Permissions)[i])
+	}
+	return res
+}
+
+// PublicPermission returns public permission of the page or nil
+// if the page is not shared to the web
+func (p *Page) PublicPermission() *Permission {
+	for _, perm := range p.Permissions() {
+		if perm.Type == PermissionPublic && perm.Role != "" && perm.Role != "none" {
+			return perm
+		}
+	}
+	return nil
+}
+
+func setPermissionItemOp(pageID string, args map[string]interface{}) *Operation {
+	return &Operation{
+		ID:      ToDashID(pageID),
+		Table:   TableBlock,
+		Path:    []string{"permissions"},
+		Command: CommandSetPermissionItem,
+		Args:    args,
+	}
+}
+
+func publicAccessOp(pageID string, role string, allowSearchEngines bool) *Operation {
+	return setPermissionItemOp(pageID, map[string]interface{}{
+		"type":                         PermissionPublic,
+		"role":                         role,
+		"allow_search_engine_indexing": allowSearchEngines,
+	})
+}
+
+func userPermissionOp(pageID string, userID string, role string) *Operation {
+	return setPermissionItemOp(pageID, map[string]interface{}{
+		"type":    PermissionUser,
+		"role":    role,
+		"user_id": userID,
+	})
+}
+
+// SetPublicAccess shares a page to the web (read-only). If allowSearchEngines
+// is true, search engines are allowed to index it
+func (c *Client) SetPublicAccess(pageID string, allowSearchEngines bool) error {
+	op := publicAccessOp(pageID, RoleReader, allowSearchEngines)
+	return c.SubmitTransaction([]*Operation{op})
+}
+
+// RemovePublicAccess stops sharing a page to the web
+func (c *Client) RemovePublicAccess(pageID string) error {
+	op := publicAccessOp(pageID, "none", false)
+	return c.SubmitTransaction([]*Operation{op})
+}
+
+// FindUserByEmail returns a user with a given email or nil if there's
+// no Notion user with this email
+func (c *Client) FindUserByEmail(email string) (*User, error) {
+	req := struct {
+		Email string `json:"email"`
+	}{
+		Email: email,
+	}
+	var rsp struct {
+		Value *struct {
+			Role  string `json:"role"`
+			Value *User  `json:"value"`
+		} `json:"value"`
+	}
+	apiURL := "/api/v3/findUser"
+	if _, err := doNotionAPI(c, apiURL, req, &rsp); err != nil {
+		return nil, err
+	}
+	if rsp.Value == nil {
+		return nil, nil
+	}
+	return rsp.Value.Value, nil
+}
+
+// SharePageWithUser gives a user with a given email a role (RoleReader,
+// RoleEditor etc.) on a page. If there's no Notion user with this email,
+// they're invited (see CreateEmailUser). Returns the user
+func (c *Client) SharePageWithUser(pageID string, email string, role string) (*User, error) {
+	user, err := c.FindUserByEmail(email)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		if user, err = c.CreateEmailUser(email); err != nil {
+			return nil, err
+		}
+	}
+	op := userPermissionOp(pageID, user.ID, role)
+	if err = c.SubmitTransaction([]*Operation{op}); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
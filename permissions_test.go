@@ -0,0 +1,52 @@
+package notionapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPermissions(t *testing.T) {
+	// pretends to be a server that knows one user and records transactions
+	var ops []*Operation
+	c, _ := newFakeClient(map[string]fakeHandler{
+		"/api/v3/findUser": func(req *http.Request, d []byte) interface{} {
+			return map[string]interface{}{
+				"value": map[string]interface{}{
+					"role":  "reader",
+					"value": map[string]interface{}{"id": "6682351e-44bb-4f9c-a0e1-49b703265bdb", "email": "jane@example.com"},
+				},
+			}
+		},
+		"/api/v3/submitTransaction": func(req *http.Request, d []byte) interface{} {
+			var tr submitTransactionRequest
+			_ = json.Unmarshal(d, &tr)
+			ops = append(ops, tr.Operations...)
+			return map[string]interface{}{}
+		},
+	})
+	pageID := "94167af6567043279811dc923edd1f04"
+	assert.NoError(t, c.SetPublicAccess(pageID, true))
+	user, err := c.SharePageWithUser(pageID, "jane@example.com", RoleEditor)
+	assert.NoError(t, err)
+	assert.Equal(t, "6682351e-44bb-4f9c-a0e1-49b703265bdb", user.ID)
+	assert.Equal(t, 2, len(ops))
+	op := ops[0]
+	assert.Equal(t, CommandSetPermissionItem, op.Command)
+	assert.Equal(t, "94167af6-5670-4327-9811-dc923edd1f04", op.ID)
+	args := op.Args.(map[string]interface{})
+	assert.Equal(t, PermissionPublic, args["type"])
+	assert.Equal(t, true, args["allow_search_engine_indexing"])
+	args = ops[1].Args.(map[string]interface{})
+	assert.Equal(t, PermissionUser, args["type"])
+	assert.Equal(t, RoleEditor, args["role"])
+	assert.Equal(t, user.ID, args["user_id"])
+
+	perms := []Permission{{Type: PermissionSpace, Role: RoleEditor}, {Type: PermissionPublic, Role: RoleReader}}
+	p := &Page{ID: pageID, idToBlock: map[string]*Block{}}
+	p.idToBlock[ToDashID(pageID)] = &Block{ID: ToDashID(pageID), Permissions: &perms}
+	assert.Equal(t, 2, len(p.Permissions()))
+	assert.Equal(t, RoleReader, p.PublicPermission().Role)
+}
@@ -0,0 +1,140 @@
+package notionapi
+
+import (
+	"math"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// ReadingWordsPerMinute is the reading speed used by Page.ReadingTime
+var ReadingWordsPerMinute = 200
+
+// returns text of a block for PlainText or "" if it has no text
+func blockPlainText(block *Block) string {
+	switch block.Type {
+	case BlockCode:
+		return block.Code
+	case BlockEquation:
+		// TeX source isn't readable text
+		return ""
+	}
+	return TextSpansToString(block.InlineContent)
+}
+
+// returns text of blocks and their children, one block per line.
+// Sub-pages contribute only their titles. root is a page block
+// whose text is skipped, can be nil
+func blocksPlainText(blocks []*Block, root *Block) string {
+	var lines []string
+	Walk(blocks, &Visitor{
+		Enter: func(block *Block, depth int) bool {
+			if block == root {
+				return true
+			}
+			s := blockPlainText(block)
+			if isPageBlock(block) {
+				s = block.Title
+			}
+			if s = strings.TrimSpace(s); s != "" {
+				lines = append(lines, s)
+			}
+			return !isPageBlock(block)
+		},
+	})
+	return strings.Join(lines, "\n")
+}
+
+// PlainText returns text of blocks of the page, one block per line,
+// without formatting. Title of the page is not included. Sub-pages
+// contribute only their titles
+func (p *Page) PlainText() string {
+	root := p.Root()
+	return blocksPlainText([]*Block{root}, root)
+}
+
+// returns true for blocks that are not content e.g. at the start
+// of a page
+func isDecorativeBlock(block *Block) bool {
+	switch block.Type {
+	case BlockTableOfContents, BlockBreadcrumb:
+		return true
+	case BlockText:
+		return strings.TrimSpace(TextSpansToString(block.InlineContent)) == ""
+	}
+	return false
+}
+
+// Excerpt returns top-level blocks at the start of the page, up to the
+// first divider and at most maxBlocks of them (no limit if maxBlocks
+// is <= 0). Empty text blocks, table of contents and breadcrumbs are
+// skipped. Use it for summaries e.g. on index pages
+func (p *Page) Excerpt(maxBlocks int) []*Block {
+	var res []*Block
+	for _, block := range p.Root().Content {
+		if block == nil || isDecorativeBlock(block) {
+			continue
+		}
+		if block.Type == BlockDivider {
+			break
+		}
+		if maxBlocks > 0 && len(res) >= maxBlocks {
+			break
+		}
+		res = append(res, block)
+	}
+	return res
+}
+
+// ExcerptText returns text of Excerpt(maxBlocks), one block per line
+// (see PlainText)
+func (p *Page) ExcerptText(maxBlocks int) string {
+	return blocksPlainText(p.Excerpt(maxBlocks), nil)
+}
+
+func isCJK(r rune) bool {
+	return unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul)
+}
+
+// returns the number of words in s. Words are separated by whitespace
+// except in Chinese, Japanese and Korean text, where each character
+// counts as a word
+func countWords(s string) int {
+	n := 0
+	inWord := false
+	for _, r := range s {
+		switch {
+		case isCJK(r):
+			n++
+			inWord = false
+		case unicode.IsSpace(r) || unicode.IsPunct(r) && !inWord:
+			inWord = false
+		default:
+			if !inWord {
+				n++
+				inWord = true
+			}
+		}
+	}
+	return n
+}
+
+// WordCount returns the number of words in PlainText
+func (p *Page) WordCount() int {
+	return countWords(p.PlainText())
+}
+
+// ReadingTime returns an estimated time of reading the page, rounded up
+// to a minute, based on ReadingWordsPerMinute. It's 0 for empty pages
+func (p *Page) ReadingTime() time.Duration {
+	words := p.WordCount()
+	if words == 0 {
+		return 0
+	}
+	wpm := ReadingWordsPerMinute
+	if wpm <= 0 {
+		wpm = 200
+	}
+	minutes := math.Ceil(float64(words) / float64(wpm))
+	return time.Duration(minutes) * time.Minute
+}
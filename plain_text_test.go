@@ -0,0 +1,35 @@
+package notionapi
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPlainTextAndWordCount(t *testing.T) {
+	text := &Block{ID: "text", Type: BlockText, InlineContent: []*TextSpan{{Text: "Hello "}, {Text: "world", Attrs: []TextAttr{{AttrBold}}}}}
+	code := &Block{ID: "code", Type: BlockCode, Code: "fmt.Println(1)"}
+	sub := &Block{ID: "sub", Type: BlockPage, Title: "Sub page", Content: []*Block{{ID: "hidden", Type: BlockText}}}
+	dash := &Block{ID: "dash", Type: BlockText, InlineContent: []*TextSpan{{Text: "it's - 日本語"}}}
+	root := &Block{ID: "root", Type: BlockPage, Title: "Title", Content: []*Block{text, code, sub, dash}}
+	p := &Page{ID: "root", idToBlock: map[string]*Block{"root": root}}
+	assert.Equal(t, "Hello world\nfmt.Println(1)\nSub page\nit's - 日本語", p.PlainText())
+	assert.Equal(t, 9, p.WordCount())
+	assert.Equal(t, time.Minute, p.ReadingTime())
+	assert.Equal(t, 0, countWords(" - "))
+}
+
+func TestExcerpt(t *testing.T) {
+	toc := &Block{ID: "toc", Type: BlockTableOfContents}
+	empty := &Block{ID: "empty", Type: BlockText}
+	t1 := &Block{ID: "t1", Type: BlockText, InlineContent: []*TextSpan{{Text: "First"}}}
+	t2 := &Block{ID: "t2", Type: BlockQuote, InlineContent: []*TextSpan{{Text: "Second"}}}
+	divider := &Block{ID: "divider", Type: BlockDivider}
+	t3 := &Block{ID: "t3", Type: BlockText, InlineContent: []*TextSpan{{Text: "Third"}}}
+	root := &Block{ID: "root", Type: BlockPage, Content: []*Block{toc, empty, t1, t2, divider, t3}}
+	p := &Page{ID: "root", idToBlock: map[string]*Block{"root": root}}
+	assert.Equal(t, []*Block{t1, t2}, p.Excerpt(0))
+	assert.Equal(t, []*Block{t1}, p.Excerpt(1))
+	assert.Equal(t, "First\nSecond", p.ExcerptText(5))
+}
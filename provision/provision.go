@@ -0,0 +1,384 @@
+// Package provision reconciles a Notion workspace with a declarative spec
+// of pages, databases and permissions.
+//
+// Like terraform, it works in 2 steps: Plan compares the spec with what
+// exists in Notion and returns a list of changes, Apply executes them.
+// Provisioning only adds things: pages, databases and properties that are
+// not in the spec are left alone.
+package provision
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/ninja-1/notionapi"
+)
+
+// PropertySpec describes a property (column) of a database
+type PropertySpec struct {
+	Name string `json:"name"`
+	// notionapi.ColumnTypeText etc.
+	Type string `json:"type"`
+	// for notionapi.ColumnTypeSelect and notionapi.ColumnTypeMultiSelect
+	Options []string `json:"options,omitempty"`
+}
+
+// DatabaseSpec describes a database (a full-page collection)
+type DatabaseSpec struct {
+	Title      string          `json:"title"`
+	Properties []*PropertySpec `json:"properties"`
+}
+
+// PermissionSpec describes a permission of a page
+type PermissionSpec struct {
+	// "public" or "user"
+	Type string `json:"type"`
+	// for "user"
+	UserID string `json:"user_id,omitempty"`
+	// notionapi.RoleReader etc.
+	Role string `json:"role"`
+}
+
+// PageSpec describes a page and its sub-pages
+type PageSpec struct {
+	Title       string            `json:"title"`
+	Icon        string            `json:"icon,omitempty"`
+	Permissions []*PermissionSpec `json:"permissions,omitempty"`
+	Pages       []*PageSpec       `json:"pages,omitempty"`
+	Databases   []*DatabaseSpec   `json:"databases,omitempty"`
+}
+
+// Spec describes pages and databases that should exist under a root page
+type Spec struct {
+	Pages     []*PageSpec     `json:"pages,omitempty"`
+	Databases []*DatabaseSpec `json:"databases,omitempty"`
+}
+
+// ParseSpec parses a spec in JSON format
+func ParseSpec(d []byte) (*Spec, error) {
+	var spec Spec
+	if err := json.Unmarshal(d, &spec); err != nil {
+		return nil, err
+	}
+	return &spec, nil
+}
+
+const (
+	// ActionCreate is for things that will be created
+	ActionCreate = "create"
+	// ActionUpdate is for things that exist but will be changed
+	ActionUpdate = "update"
+)
+
+// Change is a single change in the plan
+type Change struct {
+	// ActionCreate or ActionUpdate
+	Action string
+	// "page", "database", "property" or "permission"
+	Kind string
+	// path of titles from the root page e.g. "Projects/Roadmap"
+	Path string
+	// additional information e.g. type of property
+	Detail string
+
+	ops []*notionapi.Operation
+}
+
+// String returns change in a human-readable form
+func (c *Change) String() string {
+	sign := "+"
+	if c.Action == ActionUpdate {
+		sign = "~"
+	}
+	s := fmt.Sprintf("%s %s %s", sign, c.Kind, c.Path)
+	if c.Detail != "" {
+		s += " (" + c.Detail + ")"
+	}
+	return s
+}
+
+// Plan is a list of changes needed to make the workspace match the spec
+type Plan struct {
+	Changes []*Change
+}
+
+// String returns the plan in a human-readable form
+func (p *Plan) String() string {
+	if len(p.Changes) == 0 {
+		return "No changes.\n"
+	}
+	var lines []string
+	nCreate, nUpdate := 0, 0
+	for _, c := range p.Changes {
+		lines = append(lines, c.String())
+		if c.Action == ActionCreate {
+			nCreate++
+		} else {
+			nUpdate++
+		}
+	}
+	lines = append(lines, fmt.Sprintf("Plan: %d to create, %d to update.", nCreate, nUpdate))
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// Provisioner plans and applies specs
+type Provisioner struct {
+	Client *notionapi.Client
+	// UserID is the id of the user the blocks are created by
+	UserID string
+	// DownloadPage is used to get the current state of pages.
+	// Defaults to Client.DownloadPage
+	DownloadPage func(pageID string) (*notionapi.Page, error)
+}
+
+// New returns a new Provisioner
+func New(client *notionapi.Client, userID string) *Provisioner {
+	return &Provisioner{
+		Client:       client,
+		UserID:       userID,
+		DownloadPage: client.DownloadPage,
+	}
+}
+
+func joinPath(parent, title string) string {
+	if parent == "" {
+		return title
+	}
+	return parent + "/" + title
+}
+
+// toSchema converts database spec to schema for notionapi.Client.CreateCollectionOps
+func toSchema(db *DatabaseSpec) *notionapi.Schema {
+	res := &notionapi.Schema{Title: db.Title}
+	for _, ps := range db.Properties {
+		res.Properties = append(res.Properties, toSchemaProperty(ps))
+	}
+	return res
+}
+
+func toSchemaProperty(ps *PropertySpec) *notionapi.SchemaProperty {
+	res := &notionapi.SchemaProperty{
+		Name: ps.Name,
+		Type: ps.Type,
+	}
+	for _, opt := range ps.Options {
+		res.Options = append(res.Options, &notionapi.SelectOption{Value: opt})
+	}
+	return res
+}
+
+func permissionArgs(ps *PermissionSpec) map[string]interface{} {
+	if ps.Type == "user" {
+		return map[string]interface{}{
+			"type":    notionapi.PermissionUser,
+			"role":    ps.Role,
+			"user_id": ps.UserID,
+		}
+	}
+	return map[string]interface{}{
+		"type": notionapi.PermissionPublic,
+		"role": ps.Role,
+	}
+}
+
+func setPermissionOp(block *notionapi.Block, ps *PermissionSpec) *notionapi.Operation {
+	return &notionapi.Operation{
+		ID:      block.ID,
+		Table:   notionapi.TableBlock,
+		Path:    []string{"permissions"},
+		Command: notionapi.CommandSetPermissionItem,
+		Args:    permissionArgs(ps),
+	}
+}
+
+// hasPermission returns true if block already has a permission matching ps
+func hasPermission(block *notionapi.Block, ps *PermissionSpec) bool {
+	if block.Permissions == nil {
+		return false
+	}
+	for _, perm := range *block.Permissions {
+		if perm.Role != ps.Role {
+			continue
+		}
+		if ps.Type == "user" && perm.Type == notionapi.PermissionUser && perm.UserID != nil && *perm.UserID == ps.UserID {
+			return true
+		}
+		if ps.Type != "user" && perm.Type == notionapi.PermissionPublic {
+			return true
+		}
+	}
+	return false
+}
+
+func permissionDetail(ps *PermissionSpec) string {
+	if ps.Type == "user" {
+		return fmt.Sprintf("user %s: %s", ps.UserID, ps.Role)
+	}
+	return "public: " + ps.Role
+}
+
+// planNewPage adds changes that create a page and everything in it
+func (p *Provisioner) planNewPage(plan *Plan, parent *notionapi.Block, afterID string, path string, ps *PageSpec) {
+	block, op := p.Client.SetNewRecordOp(p.UserID, parent, notionapi.BlockPage)
+	path = joinPath(path, ps.Title)
+	ops := []*notionapi.Operation{op, block.SetTitleOp(ps.Title)}
+	if ps.Icon != "" {
+		ops = append(ops, block.UpdateFormatOp(map[string]interface{}{"page_icon": ps.Icon}))
+	}
+	ops = append(ops, parent.ListAfterContentOp(block.ID, afterID))
+	plan.Changes = append(plan.Changes, &Change{Action: ActionCreate, Kind: "page", Path: path, ops: ops})
+	for _, perm := range ps.Permissions {
+		ops := []*notionapi.Operation{setPermissionOp(block, perm)}
+		plan.Changes = append(plan.Changes, &Change{Action: ActionCreate, Kind: "permission", Path: path, Detail: permissionDetail(perm), ops: ops})
+	}
+	p.planNewChildren(plan, block, path, ps.Pages, ps.Databases)
+}
+
+func (p *Provisioner) planNewDatabase(plan *Plan, parent *notionapi.Block, afterID string, path string, db *DatabaseSpec) {
+	_, ops := p.Client.CreateCollectionOps(p.UserID, parent, afterID, toSchema(db), nil)
+	detail := fmt.Sprintf("%d properties", len(db.Properties))
+	plan.Changes = append(plan.Changes, &Change{Action: ActionCreate, Kind: "database", Path: joinPath(path, db.Title), Detail: detail, ops: ops})
+}
+
+func (p *Provisioner) planNewChildren(plan *Plan, parent *notionapi.Block, path string, pages []*PageSpec, dbs []*DatabaseSpec) {
+	// new blocks are added at the end, in spec order
+	for _, ps := range pages {
+		p.planNewPage(plan, parent, "", path, ps)
+	}
+	for _, db := range dbs {
+		p.planNewDatabase(plan, parent, "", path, db)
+	}
+}
+
+// planDatabase adds changes for properties that are in the spec but not in the database
+func (p *Provisioner) planDatabase(plan *Plan, block *notionapi.Block, path string, db *DatabaseSpec) error {
+	page, err := p.DownloadPage(block.ID)
+	if err != nil {
+		return err
+	}
+	if len(page.TableViews) == 0 || page.TableViews[0].Collection == nil {
+		return fmt.Errorf("'%s' is not a database", path)
+	}
+	collection := page.TableViews[0].Collection
+	existing := map[string]bool{}
+	for _, col := range collection.Schema {
+		existing[strings.ToLower(col.Name)] = true
+	}
+	for _, ps := range db.Properties {
+		if existing[strings.ToLower(ps.Name)] || ps.Type == notionapi.ColumnTypeTitle {
+			continue
+		}
+		op := &notionapi.Operation{
+			ID:      collection.ID,
+			Table:   notionapi.TableCollection,
+			Path:    []string{"schema", notionapi.NewPropertyID()},
+			Command: notionapi.CommandSet,
+			Args:    toSchemaProperty(ps).ToColumnSchema(),
+		}
+		c := &Change{
+			Action: ActionCreate,
+			Kind:   "property",
+			Path:   joinPath(path, ps.Name),
+			Detail: ps.Type,
+			ops:    []*notionapi.Operation{op},
+		}
+		plan.Changes = append(plan.Changes, c)
+	}
+	return nil
+}
+
+// databaseTitle returns title of a database (collection_view_page) block
+func (p *Provisioner) databaseTitle(block *notionapi.Block) (string, error) {
+	page, err := p.DownloadPage(block.ID)
+	if err != nil {
+		return "", err
+	}
+	if len(page.TableViews) == 0 || page.TableViews[0].Collection == nil {
+		return "", nil
+	}
+	return page.TableViews[0].Collection.GetName(), nil
+}
+
+// planExisting compares an existing page with the spec of its children
+func (p *Provisioner) planExisting(plan *Plan, pageID string, path string, pages []*PageSpec, dbs []*DatabaseSpec) error {
+	page, err := p.DownloadPage(pageID)
+	if err != nil {
+		return err
+	}
+	root := page.Root()
+	subPages := map[string]*notionapi.Block{}
+	databases := map[string]*notionapi.Block{}
+	for _, child := range root.Content {
+		switch child.Type {
+		case notionapi.BlockPage:
+			subPages[child.Title] = child
+		case notionapi.BlockCollectionViewPage:
+			if len(dbs) == 0 {
+				continue
+			}
+			title, err := p.databaseTitle(child)
+			if err != nil {
+				return err
+			}
+			databases[title] = child
+		}
+	}
+	for _, ps := range pages {
+		block := subPages[ps.Title]
+		if block == nil {
+			p.planNewPage(plan, root, "", path, ps)
+			continue
+		}
+		childPath := joinPath(path, ps.Title)
+		if ps.Icon != "" {
+			if fp := block.FormatPage(); fp == nil || fp.PageIcon != ps.Icon {
+				op := block.UpdateFormatOp(map[string]interface{}{"page_icon": ps.Icon})
+				plan.Changes = append(plan.Changes, &Change{Action: ActionUpdate, Kind: "page", Path: childPath, Detail: "icon " + ps.Icon, ops: []*notionapi.Operation{op}})
+			}
+		}
+		for _, perm := range ps.Permissions {
+			if hasPermission(block, perm) {
+				continue
+			}
+			op := setPermissionOp(block, perm)
+			plan.Changes = append(plan.Changes, &Change{Action: ActionUpdate, Kind: "permission", Path: childPath, Detail: permissionDetail(perm), ops: []*notionapi.Operation{op}})
+		}
+		if err := p.planExisting(plan, block.ID, childPath, ps.Pages, ps.Databases); err != nil {
+			return err
+		}
+	}
+	for _, db := range dbs {
+		block := databases[db.Title]
+		if block == nil {
+			p.planNewDatabase(plan, root, "", path, db)
+			continue
+		}
+		if err := p.planDatabase(plan, block, joinPath(path, db.Title), db); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Plan returns changes needed to make children of rootPageID match the spec
+func (p *Provisioner) Plan(rootPageID string, spec *Spec) (*Plan, error) {
+	plan := &Plan{}
+	err := p.planExisting(plan, rootPageID, "", spec.Pages, spec.Databases)
+	if err != nil {
+		return nil, err
+	}
+	return plan, nil
+}
+
+// Apply executes changes in the plan in a single transaction
+func (p *Provisioner) Apply(plan *Plan) error {
+	var ops []*notionapi.Operation
+	for _, c := range plan.Changes {
+		ops = append(ops, c.ops...)
+	}
+	if len(ops) == 0 {
+		return nil
+	}
+	return p.Client.SubmitTransaction(ops)
+}
@@ -0,0 +1,88 @@
+package provision
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	"github.com/ninja-1/notionapi"
+	"github.com/ninja-1/notionapi/caching_downloader"
+	"github.com/stretchr/testify/require"
+)
+
+type recordingTransport struct {
+	apiCalls []string
+}
+
+func (t *recordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.apiCalls = append(t.apiCalls, req.URL.Path)
+	rsp := &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       ioutil.NopCloser(bytes.NewBufferString("{}")),
+		Request:    req,
+	}
+	return rsp, nil
+}
+
+const testSpec = `{
+  "pages": [
+    {
+      "title": "Projects",
+      "icon": "🚀",
+      "permissions": [ { "type": "public", "role": "reader" } ],
+      "databases": [
+        {
+          "title": "Tasks",
+          "properties": [ { "name": "Status", "type": "select", "options": ["Todo", "Done"] } ]
+        }
+      ]
+    }
+  ]
+}`
+
+// https://www.notion.so/Test-headers-6682351e44bb4f9ca0e149b703265bdb
+func TestPlanAndApply(t *testing.T) {
+	d := caching_downloader.NewTestDownloader(t)
+
+	transport := &recordingTransport{}
+	client := &notionapi.Client{HTTPClient: &http.Client{Transport: transport}}
+	p := New(client, "user1")
+	p.DownloadPage = d.DownloadPage
+
+	spec, err := ParseSpec([]byte(testSpec))
+	require.NoError(t, err)
+	plan, err := p.Plan("6682351e44bb4f9ca0e149b703265bdb", spec)
+	require.NoError(t, err)
+	exp := `+ page Projects
++ permission Projects (public: reader)
++ database Projects/Tasks (1 properties)
+Plan: 3 to create, 0 to update.
+`
+	require.Equal(t, exp, plan.String())
+
+	require.NoError(t, p.Apply(plan))
+	require.Equal(t, []string{"/api/v3/submitTransaction"}, transport.apiCalls)
+
+	require.Equal(t, "No changes.\n", (&Plan{}).String())
+}
+
+func TestSchemaForDatabase(t *testing.T) {
+	db := &DatabaseSpec{
+		Title:      "Tasks",
+		Properties: []*PropertySpec{{Name: "Status", Type: notionapi.ColumnTypeSelect, Options: []string{"a", "b"}}},
+	}
+	_, ops := (&notionapi.Client{}).CreateCollectionOps("user", &notionapi.Block{ID: "parent"}, "", toSchema(db), nil)
+	args := ops[1].Args.(map[string]interface{})
+	require.Equal(t, [][]string{{"Tasks"}}, args["name"])
+	schema := args["schema"].(map[string]*notionapi.ColumnSchema)
+	require.Equal(t, 2, len(schema))
+	require.Equal(t, "Name", schema["title"].Name)
+	for id, col := range schema {
+		if id != "title" {
+			require.Equal(t, "Status", col.Name)
+			require.Equal(t, "gray", col.Options[1].Color)
+		}
+	}
+}
@@ -4,7 +4,8 @@ import "fmt"
 
 // AggregateQuery describes an aggregate query
 type AggregateQuery struct {
-	// e.g. "count"
+	// e.g. RollupCount. Rollup* constants other than RollupShowOriginal
+	// and RollupShowUnique
 	AggregationType string `json:"aggregation_type"`
 	ID              string `json:"id"`
 	Property        string `json:"property"`
@@ -45,6 +46,8 @@ type Query struct {
 type loader struct {
 	Type  string `json:"type"`  // e.g. "table"
 	Limit int    `json:"limit"` // Notion uses 70 by default
+	// position of the first row to return
+	Offset int `json:"offset,omitempty"`
 	// from User.TimeZone
 	UserTimeZone string `json:"userTimeZone"`
 	// from User.Locale
@@ -67,11 +70,23 @@ type AggregationResult struct {
 	Value float64 `json:"value"`
 }
 
+// GroupResult is a result for one group of rows when a query
+// has GroupBy set
+type GroupResult struct {
+	// value of the property the rows are grouped by. Depending on
+	// the type of the property, it's a string or an object
+	Value              interface{}          `json:"value"`
+	BlockIDS           []string             `json:"blockIds"`
+	Total              int                  `json:"total"`
+	AggregationResults []*AggregationResult `json:"aggregationResults"`
+}
+
 // QueryCollectionResult is part of response for /api/v3/queryCollection
 type QueryCollectionResult struct {
 	Type               string               `json:"type"`
 	BlockIDS           []string             `json:"blockIds"`
 	AggregationResults []*AggregationResult `json:"aggregationResults"`
+	GroupResults       []*GroupResult       `json:"groupResults"`
 	Total              int                  `json:"total"`
 }
 
@@ -80,16 +95,49 @@ type QueryCollectionResponse struct {
 	RecordMap *RecordMap             `json:"recordMap"`
 	Result    *QueryCollectionResult `json:"result"`
 	RawJSON   map[string]interface{} `json:"-"`
+	// number of API requests made to load the rows
+	Requests int `json:"-"`
+}
+
+// QueryCollectionOptions controls loading of rows of a collection
+// by QueryCollectionWithOptions
+type QueryCollectionOptions struct {
+	// PageSize is the number of rows loaded with one request.
+	// If 0, we load the first 256 rows and then all remaining rows
+	PageSize int
+	// MaxRows, if > 0, is the maximum number of rows we load
+	MaxRows int
+	// OnRows, if set, is called with rows as they're loaded, one page
+	// at a time. Returning an error stops loading and is returned
+	OnRows func(rows []*Block) error
 }
 
 // QueryCollection executes a raw API call /api/v3/queryCollection
 func (c *Client) QueryCollection(collectionID, collectionViewID string, q *Query, user *User) (*QueryCollectionResponse, error) {
+	return c.QueryCollectionWithOptions(collectionID, collectionViewID, q, user, nil)
+}
+
+// QueryCollectionWithOptions executes /api/v3/queryCollection and loads
+// rows in pages. Each request asks for rows after the ones we already have.
+// The response has all loaded rows and records for them
+func (c *Client) QueryCollectionWithOptions(collectionID, collectionViewID string, q *Query, user *User, opts *QueryCollectionOptions) (*QueryCollectionResponse, error) {
+	if opts == nil {
+		opts = &QueryCollectionOptions{}
+	}
 
 	// Notion has this as 70 and re-does the query if user scrolls to see more
 	// of the table. We start with a bigger number because we want all the data
 	// // and there seems to be no downside
 	const startLimit = 256
 
+	limit := startLimit
+	if opts.PageSize > 0 {
+		limit = opts.PageSize
+	}
+	if opts.MaxRows > 0 && limit > opts.MaxRows {
+		limit = opts.MaxRows
+	}
+
 	req := &queryCollectionRequest{
 		CollectionID:     collectionID,
 		CollectionViewID: collectionViewID,
@@ -97,13 +145,213 @@ func (c *Client) QueryCollection(collectionID, collectionViewID string, q *Query
 	}
 	req.Loader = &loader{
 		Type:         "table",
-		Limit:        startLimit,
+		Limit:        limit,
 		UserLocale:   user.Locale,
 		UserTimeZone: user.TimeZone,
 		// don't know what this is, Notion sets it to true
 		LoadContentCover: true,
 	}
 
+	apiURL := "/api/v3/queryCollection"
+	var res *QueryCollectionResponse
+	seen := map[string]bool{}
+	requests := 0
+	for {
+		var rsp QueryCollectionResponse
+		var err error
+		requests++
+		rsp.RawJSON, err = doNotionAPI(c, apiURL, req, &rsp)
+		if err != nil {
+			if res != nil {
+				return nil, fmt.Errorf("Client.QueryCollection() fetch of rows after %d failed: %s", req.Loader.Offset, err)
+			}
+			return nil, err
+		}
+		if err := ParseRecordMap(rsp.RecordMap); err != nil {
+			return nil, err
+		}
+		if rsp.Result == nil {
+			rsp.Result = &QueryCollectionResult{}
+		}
+		var newIDs []string
+		for _, id := range rsp.Result.BlockIDS {
+			if !seen[id] {
+				seen[id] = true
+				newIDs = append(newIDs, id)
+			}
+		}
+		if res == nil {
+			res = &rsp
+			if res.RecordMap == nil {
+				res.RecordMap = &RecordMap{}
+			}
+		} else {
+			res.Result.BlockIDS = append(res.Result.BlockIDS, newIDs...)
+			res.Result.Total = rsp.Result.Total
+			mergeRecordMap(res.RecordMap, rsp.RecordMap)
+		}
+		if opts.OnRows != nil && len(newIDs) > 0 {
+			var rows []*Block
+			for _, id := range newIDs {
+				if rec := rsp.RecordMap.Blocks[id]; rec != nil && rec.Block != nil {
+					rows = append(rows, rec.Block)
+				}
+			}
+			if err := opts.OnRows(rows); err != nil {
+				return nil, err
+			}
+		}
+		n := len(res.Result.BlockIDS)
+		total := rsp.Result.Total
+		if opts.MaxRows > 0 && total > opts.MaxRows {
+			total = opts.MaxRows
+		}
+		// no new rows means the server won't give us more
+		if n >= total || len(newIDs) == 0 {
+			break
+		}
+		req.Loader.Offset = n
+		if opts.PageSize > 0 {
+			limit = opts.PageSize
+		} else {
+			// fetch everything if a collection has more rows
+			// than we originally asked for
+			limit = total - n
+		}
+		if limit > total-n {
+			limit = total - n
+		}
+		req.Loader.Limit = limit
+	}
+	if opts.MaxRows > 0 && len(res.Result.BlockIDS) > opts.MaxRows {
+		res.Result.BlockIDS = res.Result.BlockIDS[:opts.MaxRows]
+	}
+	res.Requests = requests
+	return res, nil
+}
+
+// mergeRecordMap adds records from src to dst
+func mergeRecordMap(dst, src *RecordMap) {
+	if src == nil {
+		return
+	}
+	merge := func(dst *map[string]*Record, src map[string]*Record) {
+		if len(src) == 0 {
+			return
+		}
+		if *dst == nil {
+			*dst = map[string]*Record{}
+		}
+		for id, rec := range src {
+			(*dst)[id] = rec
+		}
+	}
+	merge(&dst.Activities, src.Activities)
+	merge(&dst.Blocks, src.Blocks)
+	merge(&dst.Spaces, src.Spaces)
+	merge(&dst.Users, src.Users)
+	merge(&dst.Collections, src.Collections)
+	merge(&dst.CollectionViews, src.CollectionViews)
+	merge(&dst.Comments, src.Comments)
+	merge(&dst.Discussions, src.Discussions)
+}
+
+// AggregationValue is a computed value of an aggregation
+type AggregationValue struct {
+	// id of the property
+	Property string
+	// e.g. RollupSum
+	AggregationType string
+	Value           float64
+}
+
+// AggregationGroup has values of aggregations for a group of rows
+type AggregationGroup struct {
+	// value of the property the rows are grouped by
+	Value interface{}
+	// number of rows in the group
+	Total  int
+	Values []*AggregationValue
+}
+
+// CollectionAggregation is the result of Client.AggregateCollection
+type CollectionAggregation struct {
+	// number of all rows
+	Total  int
+	Values []*AggregationValue
+	// only if grouped by a property
+	Groups []*AggregationGroup
+}
+
+func aggregationValues(aggs []*AggregateQuery, results []*AggregationResult) []*AggregationValue {
+	var res []*AggregationValue
+	for _, r := range results {
+		for _, agg := range aggs {
+			if agg.ID != r.ID {
+				continue
+			}
+			v := &AggregationValue{
+				Property:        agg.Property,
+				AggregationType: agg.AggregationType,
+				Value:           r.Value,
+			}
+			res = append(res, v)
+			break
+		}
+	}
+	return res
+}
+
+func collectionAggregationFromResult(aggs []*AggregateQuery, r *QueryCollectionResult) *CollectionAggregation {
+	res := &CollectionAggregation{
+		Total:  r.Total,
+		Values: aggregationValues(aggs, r.AggregationResults),
+	}
+	for _, gr := range r.GroupResults {
+		g := &AggregationGroup{
+			Value:  gr.Value,
+			Total:  gr.Total,
+			Values: aggregationValues(aggs, gr.AggregationResults),
+		}
+		res.Groups = append(res.Groups, g)
+	}
+	return res
+}
+
+// AggregateCollection computes aggregations (count, sum, average etc.) of
+// properties of a collection on the server, without downloading the rows.
+// If groupBy is not empty, it's an id of a property and aggregations are
+// also computed for each group of rows with the same value of the property.
+// Aggregations without ID get one assigned. aggs are not modified
+func (c *Client) AggregateCollection(collectionID, collectionViewID string, aggs []*AggregateQuery, groupBy string) (*CollectionAggregation, error) {
+	aggsCopy := make([]*AggregateQuery, len(aggs))
+	for i, agg := range aggs {
+		a := *agg
+		if a.ID == "" {
+			a.ID = fmt.Sprintf("agg%d", i)
+		}
+		if a.ViewType == "" {
+			a.ViewType = CollectionViewTypeTable
+		}
+		aggsCopy[i] = &a
+	}
+	aggs = aggsCopy
+	q := &Query{
+		Aggregate: aggs,
+	}
+	if groupBy != "" {
+		q.GroupBy = groupBy
+	}
+	req := &queryCollectionRequest{
+		CollectionID:     collectionID,
+		CollectionViewID: collectionViewID,
+		Query:            q,
+		// we only want aggregations, not rows
+		Loader: &loader{
+			Type:  "table",
+			Limit: 1,
+		},
+	}
 	apiURL := "/api/v3/queryCollection"
 	var rsp QueryCollectionResponse
 	var err error
@@ -111,20 +359,8 @@ func (c *Client) QueryCollection(collectionID, collectionViewID string, q *Query
 	if err != nil {
 		return nil, err
 	}
-
-	// fetch everything if a collection has more rows
-	// than we originally asked for
-	actualTotal := rsp.Result.Total
-	if actualTotal > startLimit {
-		rsp = QueryCollectionResponse{}
-		req.Loader.Limit = actualTotal
-		rsp.RawJSON, err = doNotionAPI(c, apiURL, req, &rsp)
-		if err != nil {
-			return nil, fmt.Errorf("Client.QueryCollection() 2nd fetch failed: %s", err)
-		}
-	}
-	if err := ParseRecordMap(rsp.RecordMap); err != nil {
-		return nil, err
+	if rsp.Result == nil {
+		return nil, fmt.Errorf("no result for collection '%s'", collectionID)
 	}
-	return &rsp, nil
+	return collectionAggregationFromResult(aggs, rsp.Result), nil
 }
@@ -0,0 +1,110 @@
+package notionapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// pretends to be a server with a collection of 5 rows
+type queryCollectionServer struct {
+	limits  []int
+	offsets []int
+	queries []*Query
+}
+
+func (s *queryCollectionServer) queryCollection(req *http.Request, d []byte) interface{} {
+	var qr queryCollectionRequest
+	_ = json.Unmarshal(d, &qr)
+	s.limits = append(s.limits, qr.Loader.Limit)
+	s.offsets = append(s.offsets, qr.Loader.Offset)
+	s.queries = append(s.queries, qr.Query)
+	var ids []string
+	blocks := map[string]interface{}{}
+	for i := qr.Loader.Offset; i < 5 && i < qr.Loader.Offset+qr.Loader.Limit; i++ {
+		id := fmt.Sprintf("row%d", i)
+		ids = append(ids, id)
+		blocks[id] = map[string]interface{}{
+			"role":  "reader",
+			"value": map[string]interface{}{"id": id, "type": BlockPage},
+		}
+	}
+	return map[string]interface{}{
+		"result":    map[string]interface{}{"type": "table", "blockIds": ids, "total": 5},
+		"recordMap": map[string]interface{}{"block": blocks},
+	}
+}
+
+func TestCollectionAggregationFromResult(t *testing.T) {
+	aggs := []*AggregateQuery{
+		{ID: "a", Property: "title", AggregationType: RollupCount},
+		{ID: "b", Property: "num", AggregationType: RollupSum},
+	}
+	js := `{
+	"total": 3,
+	"aggregationResults": [{"id": "a", "value": 3}, {"id": "b", "value": 7.5}],
+	"groupResults": [
+		{"value": "Done", "total": 2, "aggregationResults": [{"id": "b", "value": 5}]}
+	]
+}`
+	var r QueryCollectionResult
+	err := json.Unmarshal([]byte(js), &r)
+	assert.NoError(t, err)
+	res := collectionAggregationFromResult(aggs, &r)
+	assert.Equal(t, 3, res.Total)
+	assert.Equal(t, 2, len(res.Values))
+	assert.Equal(t, &AggregationValue{Property: "num", AggregationType: RollupSum, Value: 7.5}, res.Values[1])
+	assert.Equal(t, 1, len(res.Groups))
+	assert.Equal(t, "Done", res.Groups[0].Value)
+	assert.Equal(t, 5.0, res.Groups[0].Values[0].Value)
+
+	// ids and view types are set on a copy of aggs
+	server := &queryCollectionServer{}
+	c, _ := newFakeClient(map[string]fakeHandler{"/api/v3/queryCollection": server.queryCollection})
+	aggs = []*AggregateQuery{{Property: "num", AggregationType: RollupSum}}
+	res, err = c.AggregateCollection("col", "view", aggs, "")
+	assert.NoError(t, err)
+	assert.Equal(t, 5, res.Total)
+	assert.Equal(t, "", aggs[0].ID)
+	assert.Equal(t, "", aggs[0].ViewType)
+	sent := server.queries[0].Aggregate[0]
+	assert.Equal(t, "agg0", sent.ID)
+	assert.Equal(t, CollectionViewTypeTable, sent.ViewType)
+}
+
+func TestQueryCollectionPaging(t *testing.T) {
+	server := &queryCollectionServer{}
+	c, _ := newFakeClient(map[string]fakeHandler{"/api/v3/queryCollection": server.queryCollection})
+	var pages [][]string
+	opts := &QueryCollectionOptions{
+		PageSize: 2,
+		OnRows: func(rows []*Block) error {
+			var ids []string
+			for _, b := range rows {
+				ids = append(ids, b.ID)
+			}
+			pages = append(pages, ids)
+			return nil
+		},
+	}
+	rsp, err := c.QueryCollectionWithOptions("col", "view", &Query{}, &User{}, opts)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"row0", "row1", "row2", "row3", "row4"}, rsp.Result.BlockIDS)
+	assert.Equal(t, 5, len(rsp.RecordMap.Blocks))
+	assert.Equal(t, 3, rsp.Requests)
+	assert.Equal(t, []int{2, 2, 1}, server.limits)
+	assert.Equal(t, []int{0, 2, 4}, server.offsets)
+	assert.Equal(t, [][]string{{"row0", "row1"}, {"row2", "row3"}, {"row4"}}, pages)
+
+	server.limits = nil
+	server.offsets = nil
+	opts = &QueryCollectionOptions{MaxRows: 3}
+	rsp, err = c.QueryCollectionWithOptions("col", "view", &Query{}, &User{}, opts)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, len(rsp.Result.BlockIDS))
+	assert.Equal(t, 1, rsp.Requests)
+	assert.Equal(t, []int{3}, server.limits)
+}
@@ -0,0 +1,64 @@
+package notionapi
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// RawResponse is a line written by DownloadPageRaw
+type RawResponse struct {
+	// Endpoint is e.g. "/api/v3/loadPageChunk"
+	Endpoint   string          `json:"endpoint"`
+	StatusCode int             `json:"status_code"`
+	Request    json.RawMessage `json:"request,omitempty"`
+	Response   json.RawMessage `json:"response,omitempty"`
+	// ResponseText is set instead of Response if the response is not
+	// valid JSON
+	ResponseText string `json:"response_text,omitempty"`
+}
+
+// returns d if it's valid json, nil otherwise
+func validJSON(d []byte) json.RawMessage {
+	if len(d) == 0 || !json.Valid(d) {
+		return nil
+	}
+	return json.RawMessage(d)
+}
+
+// DownloadPageRaw downloads a page like DownloadPage and writes every
+// response of the API to w, as they are received, as newline-delimited
+// JSON (one RawResponse per line). It's meant for debugging e.g. when
+// reporting bugs in decoding or adding support for new blocks.
+// Responses written before a failure are kept in w
+func (c *Client) DownloadPageRaw(pageID string, w io.Writer) error {
+	client := *c
+	// a de-duplicated request might be done by a different client
+	client.DedupRequests = false
+	var writeErr error
+	client.onRawResponse = func(apiURL string, req []byte, rsp []byte, statusCode int) {
+		if writeErr != nil {
+			return
+		}
+		v := RawResponse{
+			Endpoint:   apiURL,
+			StatusCode: statusCode,
+			Request:    validJSON(req),
+			Response:   validJSON(rsp),
+		}
+		if v.Response == nil {
+			v.ResponseText = string(rsp)
+		}
+		d, err := json.Marshal(v)
+		if err != nil {
+			writeErr = err
+			return
+		}
+		d = append(d, '\n')
+		_, writeErr = w.Write(d)
+	}
+	_, err := client.DownloadPage(pageID)
+	if writeErr != nil {
+		return writeErr
+	}
+	return err
+}
@@ -0,0 +1,41 @@
+package notionapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDownloadPageRaw(t *testing.T) {
+	// pretends to be a server with an empty page
+	id := "6682351e-44bb-4f9c-a0e1-49b703265bdb"
+	block := `{"role":"reader","value":{"id":"` + id + `","type":"page","alive":true}}`
+	c, _ := newFakeClient(map[string]fakeHandler{
+		"/api/v3/getRecordValues": func(req *http.Request, d []byte) interface{} {
+			return `{"results":[` + block + `]}`
+		},
+		"/api/v3/loadPageChunk": func(req *http.Request, d []byte) interface{} {
+			return `{"recordMap":{"block":{"` + id + `":` + block + `}},"cursor":{"stack":[]}}`
+		},
+	})
+	var buf strings.Builder
+	err := c.DownloadPageRaw("6682351e44bb4f9ca0e149b703265bdb", &buf)
+	assert.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	assert.True(t, len(lines) > 0)
+	var first RawResponse
+	assert.NoError(t, json.Unmarshal([]byte(lines[0]), &first))
+	assert.Equal(t, "/api/v3/getRecordValues", first.Endpoint)
+	assert.Equal(t, http.StatusOK, first.StatusCode)
+	assert.Contains(t, string(first.Request), "6682351e-44bb-4f9c-a0e1-49b703265bdb")
+	assert.Contains(t, string(first.Response), `"results"`)
+	for _, line := range lines {
+		var rr RawResponse
+		assert.NoError(t, json.Unmarshal([]byte(line), &rr))
+		assert.NotEmpty(t, rr.Endpoint)
+	}
+	assert.Nil(t, c.onRawResponse)
+}
@@ -0,0 +1,92 @@
+package notionapi
+
+import (
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// ErrNetworkNotAllowed is returned for network requests that are not
+// allowed, by RestrictedTransport or when built with notionapi_offline tag
+type ErrNetworkNotAllowed struct {
+	URL string
+}
+
+// Error return error string
+func (e *ErrNetworkNotAllowed) Error() string {
+	return "network request to '" + e.URL + "' is not allowed"
+}
+
+// IsErrNetworkNotAllowed returns true if err is an instance of ErrNetworkNotAllowed
+func IsErrNetworkNotAllowed(err error) bool {
+	_, ok := err.(*ErrNetworkNotAllowed)
+	return ok
+}
+
+// RestrictedTransport is http.RoundTripper that only allows requests
+// to AllowedHosts and records all requests. Use it as Transport of
+// Client.HTTPClient to make sure and verify that we only talk to
+// hosts we expect e.g.:
+//
+//	t := &notionapi.RestrictedTransport{AllowedHosts: []string{"www.notion.so"}}
+//	client.HTTPClient = &http.Client{Transport: t}
+type RestrictedTransport struct {
+	// Transport makes allowed requests. If nil, http.DefaultTransport
+	// is used
+	Transport http.RoundTripper
+	// AllowedHosts are allowed hosts. "*.example.com" allows sub-domains
+	// of example.com
+	AllowedHosts []string
+
+	mu       sync.Mutex
+	requests []string
+	blocked  []string
+}
+
+func (t *RestrictedTransport) isAllowed(host string) bool {
+	host = strings.ToLower(host)
+	for _, allowed := range t.AllowedHosts {
+		allowed = strings.ToLower(allowed)
+		if host == allowed {
+			return true
+		}
+		if strings.HasPrefix(allowed, "*.") && strings.HasSuffix(host, allowed[1:]) {
+			return true
+		}
+	}
+	return false
+}
+
+// RoundTrip makes a request if its host is allowed
+func (t *RestrictedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	uri := req.URL.String()
+	allowed := t.isAllowed(req.URL.Hostname())
+	t.mu.Lock()
+	t.requests = append(t.requests, uri)
+	if !allowed {
+		t.blocked = append(t.blocked, uri)
+	}
+	t.mu.Unlock()
+	if !allowed {
+		return nil, &ErrNetworkNotAllowed{URL: uri}
+	}
+	transport := t.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	return transport.RoundTrip(req)
+}
+
+// Requests returns urls of all requests, including blocked
+func (t *RestrictedTransport) Requests() []string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]string(nil), t.requests...)
+}
+
+// Blocked returns urls of requests that were not allowed
+func (t *RestrictedTransport) Blocked() []string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]string(nil), t.blocked...)
+}
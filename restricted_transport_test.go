@@ -0,0 +1,22 @@
+package notionapi
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRestrictedTransport(t *testing.T) {
+	transport := &RestrictedTransport{
+		Transport:    &fakeTransport{},
+		AllowedHosts: []string{"*.notion.so"},
+	}
+	c := &Client{HTTPClient: &http.Client{Transport: transport}}
+	_, _ = c.GetRecordValues([]RecordRequest{{Table: TableBlock, ID: "94167af6-5670-4327-9811-dc923edd1f04"}})
+	hc := &http.Client{Transport: transport}
+	_, err := hc.Get("https://publish.twitter.com/oembed")
+	assert.Error(t, err)
+	assert.Equal(t, 2, len(transport.Requests()))
+	assert.Equal(t, []string{"https://publish.twitter.com/oembed"}, transport.Blocked())
+}
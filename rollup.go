@@ -0,0 +1,181 @@
+package notionapi
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// values of ColumnSchema.Aggregation for rollups. Except for
+// RollupShowOriginal and RollupShowUnique, they're also values of
+// AggregateQuery.AggregationType
+const (
+	RollupShowOriginal    = "show_original"
+	RollupShowUnique      = "show_unique"
+	RollupCount           = "count"
+	RollupCountValues     = "count_values"
+	RollupCountUnique     = "unique"
+	RollupEmpty           = "empty"
+	RollupNotEmpty        = "not_empty"
+	RollupPercentEmpty    = "percent_empty"
+	RollupPercentNotEmpty = "percent_not_empty"
+	RollupSum             = "sum"
+	RollupAverage         = "average"
+	RollupMedian          = "median"
+	RollupMin             = "min"
+	RollupMax             = "max"
+	RollupRange           = "range"
+)
+
+// RelationIDs returns ids of pages in a relation property of a row
+func RelationIDs(row *Block, propertyID string) []string {
+	var res []string
+	for _, ts := range row.GetProperty(propertyID) {
+		for _, attr := range ts.Attrs {
+			if AttrGetType(attr) == AttrPage {
+				res = append(res, AttrGetPageID(attr))
+			}
+		}
+	}
+	return res
+}
+
+func formatRollupNumber(f float64) string {
+	// avoid showing floating point noise like 0.30000000000000004
+	f = math.Round(f*1e6) / 1e6
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+func formatRollupPercent(n, total int) string {
+	if total == 0 {
+		return "0%"
+	}
+	return formatRollupNumber(float64(n)*100/float64(total)) + "%"
+}
+
+func uniqueStrings(a []string) []string {
+	var res []string
+	seen := map[string]bool{}
+	for _, s := range a {
+		if !seen[s] {
+			seen[s] = true
+			res = append(res, s)
+		}
+	}
+	return res
+}
+
+// values of multi-select are comma-separated
+func rollupValues(typ string, val string) []string {
+	if typ == ColumnTypeMultiSelect {
+		var res []string
+		for _, s := range strings.Split(val, ",") {
+			if s = strings.TrimSpace(s); s != "" {
+				res = append(res, s)
+			}
+		}
+		return res
+	}
+	return []string{val}
+}
+
+// aggregateNumbers computes numeric aggregation of values. Values that
+// are not numbers are skipped
+func aggregateNumbers(aggregation string, values []string) string {
+	var nums []float64
+	for _, v := range values {
+		f, err := strconv.ParseFloat(strings.TrimPrefix(v, "$"), 64)
+		if err == nil {
+			nums = append(nums, f)
+		}
+	}
+	if len(nums) == 0 {
+		if aggregation == RollupSum {
+			return "0"
+		}
+		return ""
+	}
+	sort.Float64s(nums)
+	sum := 0.0
+	for _, f := range nums {
+		sum += f
+	}
+	n := len(nums)
+	switch aggregation {
+	case RollupSum:
+		return formatRollupNumber(sum)
+	case RollupAverage:
+		return formatRollupNumber(sum / float64(n))
+	case RollupMedian:
+		if n%2 == 1 {
+			return formatRollupNumber(nums[n/2])
+		}
+		return formatRollupNumber((nums[n/2-1] + nums[n/2]) / 2)
+	case RollupMin:
+		return formatRollupNumber(nums[0])
+	case RollupMax:
+		return formatRollupNumber(nums[n-1])
+	case RollupRange:
+		return formatRollupNumber(nums[n-1] - nums[0])
+	}
+	return ""
+}
+
+// ComputeRollup computes the value of a rollup property described
+// by schema for a row. getBlock returns a related page by id or nil if
+// it's not available. If any related page is not available, we can't
+// compute the value and return "".
+// Notion doesn't store values of rollups in rows so we compute them
+// from the values of TargetProperty of pages in RelationProperty
+func ComputeRollup(schema *ColumnSchema, row *Block, getBlock func(id string) *Block) (string, error) {
+	if schema.Type != ColumnTypeRollup {
+		return "", fmt.Errorf("property '%s' is of type '%s', not '%s'", schema.Name, schema.Type, ColumnTypeRollup)
+	}
+	var values []string
+	nEmpty := 0
+	for _, id := range RelationIDs(row, schema.RelationProperty) {
+		related := getBlock(id)
+		if related == nil {
+			return "", nil
+		}
+		val := TextSpansToString(related.GetProperty(schema.TargetProperty))
+		if val == "" {
+			nEmpty++
+			continue
+		}
+		values = append(values, rollupValues(schema.TargetPropertyType, val)...)
+	}
+	total := len(values) + nEmpty
+
+	switch schema.Aggregation {
+	case "", RollupShowOriginal:
+		return strings.Join(values, ", "), nil
+	case RollupShowUnique:
+		return strings.Join(uniqueStrings(values), ", "), nil
+	case RollupCount:
+		return strconv.Itoa(total), nil
+	case RollupCountValues:
+		return strconv.Itoa(len(values)), nil
+	case RollupCountUnique:
+		return strconv.Itoa(len(uniqueStrings(values))), nil
+	case RollupEmpty:
+		return strconv.Itoa(nEmpty), nil
+	case RollupNotEmpty:
+		return strconv.Itoa(total - nEmpty), nil
+	case RollupPercentEmpty:
+		return formatRollupPercent(nEmpty, total), nil
+	case RollupPercentNotEmpty:
+		return formatRollupPercent(total-nEmpty, total), nil
+	case RollupSum, RollupAverage, RollupMedian, RollupMin, RollupMax, RollupRange:
+		return aggregateNumbers(schema.Aggregation, values), nil
+	}
+	return "", fmt.Errorf("unsupported rollup aggregation '%s' of property '%s'", schema.Aggregation, schema.Name)
+}
+
+// RollupValue computes the value of a rollup property of a row
+// using related pages that were downloaded with the page
+func (p *Page) RollupValue(row *Block, schema *ColumnSchema) (string, error) {
+	return ComputeRollup(schema, row, p.BlockByID)
+}
@@ -0,0 +1,69 @@
+package notionapi
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestComputeRollup(t *testing.T) {
+	newRow := func(id string, props map[string]interface{}) *Block {
+		return &Block{ID: id, Properties: props}
+	}
+	related := map[string]*Block{
+		"a": newRow("a", map[string]interface{}{"n": textValue("1"), "title": textValue("A")}),
+		"b": newRow("b", map[string]interface{}{"n": textValue("2.5"), "title": textValue("B")}),
+		"c": newRow("c", map[string]interface{}{"title": textValue("A")}),
+	}
+	getBlock := func(id string) *Block {
+		return related[id]
+	}
+	row := newRow("row", map[string]interface{}{
+		"rel": mentions(AttrPage, []string{"a", "b", "c"}),
+	})
+	tests := []struct {
+		target      string
+		aggregation string
+		exp         string
+	}{
+		{"title", RollupShowOriginal, "A, B, A"},
+		{"title", RollupShowUnique, "A, B"},
+		{"title", RollupCountUnique, "2"},
+		{"n", RollupCount, "3"},
+		{"n", RollupCountValues, "2"},
+		{"n", RollupEmpty, "1"},
+		{"n", RollupPercentNotEmpty, "66.666667%"},
+		{"n", RollupSum, "3.5"},
+		{"n", RollupAverage, "1.75"},
+		{"n", RollupRange, "1.5"},
+	}
+	for _, test := range tests {
+		schema := &ColumnSchema{
+			Name:             "Rollup",
+			Type:             ColumnTypeRollup,
+			RelationProperty: "rel",
+			TargetProperty:   test.target,
+			Aggregation:      test.aggregation,
+		}
+		got, err := ComputeRollup(schema, row, getBlock)
+		assert.NoError(t, err)
+		assert.Equal(t, test.exp, got, test.aggregation)
+	}
+
+	_, err := ComputeRollup(&ColumnSchema{Type: ColumnTypeRollup, Aggregation: "foo"}, row, getBlock)
+	assert.Error(t, err)
+
+	// we don't know the value if a related page is not available
+	row = newRow("row", map[string]interface{}{
+		"rel": mentions(AttrPage, []string{"a", "b", "c", "missing"}),
+	})
+	schema := &ColumnSchema{
+		Type:             ColumnTypeRollup,
+		RelationProperty: "rel",
+		TargetProperty:   "n",
+		Aggregation:      RollupCount,
+	}
+	got, err := ComputeRollup(schema, row, getBlock)
+	assert.NoError(t, err)
+	assert.Equal(t, "", got)
+}
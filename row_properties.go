@@ -0,0 +1,206 @@
+package notionapi
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+func textValue(s string) interface{} {
+	return []interface{}{[]interface{}{s}}
+}
+
+// mentions returns a value with mentions e.g. of pages (AttrPage)
+// or users (AttrUser), separated with ","
+func mentions(attr string, ids []string) interface{} {
+	var res []interface{}
+	for i, id := range ids {
+		if i > 0 {
+			res = append(res, []interface{}{","})
+		}
+		mention := []interface{}{"‣", []interface{}{[]interface{}{attr, id}}}
+		res = append(res, mention)
+	}
+	return res
+}
+
+func toStrings(v interface{}) ([]string, bool) {
+	switch vt := v.(type) {
+	case string:
+		return []string{vt}, true
+	case []string:
+		return vt, true
+	}
+	return nil, false
+}
+
+func dateValue(v interface{}) (interface{}, error) {
+	date := map[string]interface{}{
+		"type": "date",
+	}
+	switch vt := v.(type) {
+	case time.Time:
+		date["start_date"] = vt.Format("2006-01-02")
+		if vt.Hour() != 0 || vt.Minute() != 0 {
+			date["type"] = "datetime"
+			date["start_time"] = vt.Format("15:04")
+			if loc := vt.Location(); loc != time.UTC && loc != time.Local {
+				date["time_zone"] = loc.String()
+			}
+		}
+	case string:
+		if _, err := time.Parse("2006-01-02", vt); err != nil {
+			return nil, fmt.Errorf("date '%s' is not in YYYY-MM-DD format", vt)
+		}
+		date["start_date"] = vt
+	case *Date:
+		res := []interface{}{
+			[]interface{}{"‣", []interface{}{[]interface{}{AttrDate, vt}}},
+		}
+		return res, nil
+	default:
+		return nil, fmt.Errorf("unsupported value of type %T for a date", v)
+	}
+	res := []interface{}{
+		[]interface{}{"‣", []interface{}{[]interface{}{AttrDate, date}}},
+	}
+	return res, nil
+}
+
+func hasOption(cs *ColumnSchema, value string) bool {
+	for _, opt := range cs.Options {
+		if opt.Value == value {
+			return true
+		}
+	}
+	return false
+}
+
+// encodePropertyValue converts a Go value to the value of a row property
+// as stored by Notion, based on the type of the property
+func encodePropertyValue(cs *ColumnSchema, v interface{}) (interface{}, error) {
+	if v == nil {
+		return nil, nil
+	}
+	switch cs.Type {
+	case ColumnTypeTitle, ColumnTypeText, ColumnTypeURL, ColumnTypeEmail, ColumnTypePhoneNumber:
+		s, ok := v.(string)
+		if !ok {
+			return nil, fmt.Errorf("property '%s' of type '%s' needs a string, got %T", cs.Name, cs.Type, v)
+		}
+		return textValue(s), nil
+	case ColumnTypeNumber:
+		switch vt := v.(type) {
+		case int:
+			return textValue(strconv.Itoa(vt)), nil
+		case int64:
+			return textValue(strconv.FormatInt(vt, 10)), nil
+		case float64:
+			return textValue(strconv.FormatFloat(vt, 'f', -1, 64)), nil
+		case string:
+			if _, err := strconv.ParseFloat(vt, 64); err != nil {
+				return nil, fmt.Errorf("'%s' is not a number", vt)
+			}
+			return textValue(vt), nil
+		}
+	case ColumnTypeCheckbox:
+		if b, ok := v.(bool); ok {
+			if b {
+				return textValue("Yes"), nil
+			}
+			return textValue("No"), nil
+		}
+	case ColumnTypeSelect, ColumnTypeMultiSelect:
+		values, ok := toStrings(v)
+		if !ok {
+			break
+		}
+		if cs.Type == ColumnTypeSelect && len(values) > 1 {
+			return nil, fmt.Errorf("property '%s' of type '%s' can only have one value", cs.Name, cs.Type)
+		}
+		for _, val := range values {
+			if !hasOption(cs, val) {
+				return nil, fmt.Errorf("'%s' is not an option of property '%s'", val, cs.Name)
+			}
+		}
+		return textValue(strings.Join(values, ",")), nil
+	case ColumnTypeDate:
+		return dateValue(v)
+	case ColumnTypeRelation:
+		if ids, ok := toStrings(v); ok {
+			var dashIDs []string
+			for _, id := range ids {
+				dashIDs = append(dashIDs, ToDashID(id))
+			}
+			return mentions(AttrPage, dashIDs), nil
+		}
+	case ColumnTypePerson:
+		if ids, ok := toStrings(v); ok {
+			return mentions(AttrUser, ids), nil
+		}
+	default:
+		return nil, fmt.Errorf("setting properties of type '%s' is not supported", cs.Type)
+	}
+	return nil, fmt.Errorf("unsupported value of type %T for property '%s' of type '%s'", v, cs.Name, cs.Type)
+}
+
+// buildSetRowPropertiesOps returns operations that set properties of a row
+func buildSetRowPropertiesOps(row *Block, col *Collection, props map[string]interface{}) ([]*Operation, error) {
+	var names []string
+	for name := range props {
+		names = append(names, name)
+	}
+	// for deterministic order of operations
+	sort.Strings(names)
+	var ops []*Operation
+	for _, name := range names {
+		v := props[name]
+		id := findPropertyID(col, name)
+		if id == "" {
+			return nil, fmt.Errorf("property '%s' doesn't exist", name)
+		}
+		val, err := encodePropertyValue(col.Schema[id], v)
+		if err != nil {
+			return nil, err
+		}
+		ops = append(ops, row.buildOp(CommandSet, []string{"properties", id}, val))
+	}
+	return ops, nil
+}
+
+// SetRowProperties sets properties of a row of a collection (database).
+// props maps name of the property to its value. The value depends on
+// the type of the property:
+//   - text, title, url, email, phone number: string
+//   - number: int, int64, float64 or string
+//   - checkbox: bool
+//   - select: string
+//   - multi select: []string
+//   - date: time.Time, *Date or "YYYY-MM-DD" string
+//   - relation: []string with ids of pages
+//   - person: []string with ids of users
+//
+// A nil value clears the property
+func (c *Client) SetRowProperties(rowID string, props map[string]interface{}) error {
+	row, err := c.getBlock(rowID)
+	if err != nil {
+		return err
+	}
+	if row.ParentTable != TableCollection {
+		return fmt.Errorf("block '%s' is not a row of a collection", rowID)
+	}
+	col, err := c.getCollection(row.ParentID)
+	if err != nil {
+		return err
+	}
+	ops, err := buildSetRowPropertiesOps(row, col, props)
+	if err != nil {
+		return err
+	}
+	if len(ops) == 0 {
+		return nil
+	}
+	return c.SubmitTransaction(ops)
+}
@@ -0,0 +1,51 @@
+package notionapi
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncodePropertyValue(t *testing.T) {
+	col := &Collection{
+		ID: "col",
+		Schema: map[string]*ColumnSchema{
+			"title": {Name: "Name", Type: ColumnTypeTitle},
+			"stat":  {Name: "Status", Type: ColumnTypeSelect, Options: []*CollectionColumnOption{{Value: "Done"}}},
+			"done":  {Name: "Done", Type: ColumnTypeCheckbox},
+			"due":   {Name: "Due", Type: ColumnTypeDate},
+			"num":   {Name: "Points", Type: ColumnTypeNumber},
+			"rel":   {Name: "Related", Type: ColumnTypeRelation},
+		},
+	}
+	row := &Block{ID: "row"}
+	ops, err := buildSetRowPropertiesOps(row, col, map[string]interface{}{"status": "Done"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"properties", "stat"}, ops[0].Path)
+	assert.Equal(t, textValue("Done"), ops[0].Args)
+
+	_, err = buildSetRowPropertiesOps(row, col, map[string]interface{}{"Status": "Unknown"})
+	assert.Error(t, err)
+	_, err = buildSetRowPropertiesOps(row, col, map[string]interface{}{"No such": "x"})
+	assert.Error(t, err)
+
+	v, err := encodePropertyValue(col.Schema["done"], true)
+	assert.NoError(t, err)
+	assert.Equal(t, textValue("Yes"), v)
+	v, err = encodePropertyValue(col.Schema["num"], 1.5)
+	assert.NoError(t, err)
+	assert.Equal(t, textValue("1.5"), v)
+	_, err = encodePropertyValue(col.Schema["num"], "abc")
+	assert.Error(t, err)
+
+	v, err = encodePropertyValue(col.Schema["due"], "2020-05-01")
+	assert.NoError(t, err)
+	d, _ := json.Marshal(v)
+	assert.Equal(t, `[["‣",[["d",{"start_date":"2020-05-01","type":"date"}]]]]`, string(d))
+
+	v, err = encodePropertyValue(col.Schema["rel"], []string{"6682351e44bb4f9ca0e149b703265bdb"})
+	assert.NoError(t, err)
+	d, _ = json.Marshal(v)
+	assert.Equal(t, `[["‣",[["p","6682351e-44bb-4f9c-a0e1-49b703265bdb"]]]]`, string(d))
+}
@@ -0,0 +1,168 @@
+package notionapi
+
+import (
+	"fmt"
+	"strings"
+)
+
+// getCollection returns a collection record with a given id
+func (c *Client) getCollection(collectionID string) (*Collection, error) {
+	records := []RecordRequest{
+		{
+			Table: TableCollection,
+			ID:    ToDashID(collectionID),
+		},
+	}
+	rsp, err := c.GetRecordValues(records)
+	if err != nil {
+		return nil, err
+	}
+	if len(rsp.Results) == 0 || rsp.Results[0].Collection == nil {
+		return nil, fmt.Errorf("collection '%s' doesn't exist", collectionID)
+	}
+	return rsp.Results[0].Collection, nil
+}
+
+// findPropertyID returns id of a property with a given name (or id).
+// Name is compared case-insensitively
+func findPropertyID(col *Collection, name string) string {
+	if _, ok := col.Schema[name]; ok {
+		return name
+	}
+	for id, cs := range col.Schema {
+		if strings.EqualFold(cs.Name, name) {
+			return id
+		}
+	}
+	return ""
+}
+
+func (col *Collection) schemaOp(command string, path []string, args interface{}) *Operation {
+	return &Operation{
+		ID:      col.ID,
+		Table:   TableCollection,
+		Path:    append([]string{"schema"}, path...),
+		Command: command,
+		Args:    args,
+	}
+}
+
+func addPropertyOps(col *Collection, prop *SchemaProperty) (string, []*Operation, error) {
+	if findPropertyID(col, prop.Name) != "" {
+		return "", nil, fmt.Errorf("property '%s' already exists", prop.Name)
+	}
+	if prop.Type == ColumnTypeTitle {
+		return "", nil, fmt.Errorf("collection can only have one property of type '%s'", ColumnTypeTitle)
+	}
+	id := NewPropertyID()
+	op := col.schemaOp(CommandSet, []string{id}, prop.ToColumnSchema())
+	return id, []*Operation{op}, nil
+}
+
+func renamePropertyOps(col *Collection, name string, newName string) ([]*Operation, error) {
+	id := findPropertyID(col, name)
+	if id == "" {
+		return nil, fmt.Errorf("property '%s' doesn't exist", name)
+	}
+	if other := findPropertyID(col, newName); other != "" && other != id {
+		return nil, fmt.Errorf("property '%s' already exists", newName)
+	}
+	op := col.schemaOp(CommandSet, []string{id, "name"}, newName)
+	return []*Operation{op}, nil
+}
+
+// removePropertyOps returns operations that remove a property from the schema
+// and its values from rows
+func removePropertyOps(col *Collection, name string, rows []*Block) ([]*Operation, error) {
+	id := findPropertyID(col, name)
+	if id == "" {
+		return nil, fmt.Errorf("property '%s' doesn't exist", name)
+	}
+	if col.Schema[id].Type == ColumnTypeTitle {
+		return nil, fmt.Errorf("can't remove '%s' property of type '%s'", name, ColumnTypeTitle)
+	}
+	ops := []*Operation{
+		col.schemaOp(CommandUpdate, nil, map[string]interface{}{id: nil}),
+	}
+	for _, row := range rows {
+		if _, ok := row.Properties[id]; !ok {
+			continue
+		}
+		op := row.buildOp(CommandUpdate, []string{"properties"}, map[string]interface{}{id: nil})
+		ops = append(ops, op)
+	}
+	return ops, nil
+}
+
+// getCollectionRows returns all rows of a collection
+func (c *Client) getCollectionRows(col *Collection) ([]*Block, error) {
+	if col.ParentTable != TableBlock {
+		return nil, fmt.Errorf("unsupported parent table '%s' of collection '%s'", col.ParentTable, col.ID)
+	}
+	parent, err := c.getBlock(col.ParentID)
+	if err != nil {
+		return nil, err
+	}
+	if len(parent.ViewIDs) == 0 {
+		return nil, fmt.Errorf("collection '%s' has no views", col.ID)
+	}
+	rsp, err := c.QueryCollection(col.ID, parent.ViewIDs[0], &Query{}, &User{})
+	if err != nil {
+		return nil, err
+	}
+	var res []*Block
+	for _, id := range rsp.Result.BlockIDS {
+		r := rsp.RecordMap.Blocks[id]
+		if r != nil && r.Block != nil {
+			res = append(res, r.Block)
+		}
+	}
+	return res, nil
+}
+
+// AddCollectionProperty adds a property to the schema of a collection.
+// Returns id of the new property
+func (c *Client) AddCollectionProperty(collectionID string, prop *SchemaProperty) (string, error) {
+	col, err := c.getCollection(collectionID)
+	if err != nil {
+		return "", err
+	}
+	id, ops, err := addPropertyOps(col, prop)
+	if err != nil {
+		return "", err
+	}
+	return id, c.SubmitTransaction(ops)
+}
+
+// RenameCollectionProperty renames a property of a collection.
+// Values of properties in rows are keyed by property id, not name,
+// so rows don't need to change
+func (c *Client) RenameCollectionProperty(collectionID string, name string, newName string) error {
+	col, err := c.getCollection(collectionID)
+	if err != nil {
+		return err
+	}
+	ops, err := renamePropertyOps(col, name, newName)
+	if err != nil {
+		return err
+	}
+	return c.SubmitTransaction(ops)
+}
+
+// RemoveCollectionProperty removes a property from the schema of a collection
+// and removes its values from all rows
+func (c *Client) RemoveCollectionProperty(collectionID string, name string) error {
+	col, err := c.getCollection(collectionID)
+	if err != nil {
+		return err
+	}
+	rows, err := c.getCollectionRows(col)
+	if err != nil {
+		return err
+	}
+	ops, err := removePropertyOps(col, name, rows)
+	if err != nil {
+		return err
+	}
+	return c.SubmitTransaction(ops)
+}
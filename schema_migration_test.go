@@ -0,0 +1,39 @@
+package notionapi
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSchemaMigrationOps(t *testing.T) {
+	col := &Collection{
+		ID: "col",
+		Schema: map[string]*ColumnSchema{
+			"title": {Name: "Name", Type: ColumnTypeTitle},
+			"abcd":  {Name: "Status", Type: ColumnTypeSelect},
+		},
+	}
+	_, _, err := addPropertyOps(col, &SchemaProperty{Name: "status", Type: ColumnTypeText})
+	assert.Error(t, err)
+	id, ops, err := addPropertyOps(col, &SchemaProperty{Name: "Notes", Type: ColumnTypeText})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"schema", id}, ops[0].Path)
+
+	_, err = renamePropertyOps(col, "Status", "name")
+	assert.Error(t, err)
+	ops, err = renamePropertyOps(col, "Status", "State")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"schema", "abcd", "name"}, ops[0].Path)
+
+	_, err = removePropertyOps(col, "Name", nil)
+	assert.Error(t, err)
+	rows := []*Block{
+		{ID: "r1", Properties: map[string]interface{}{"abcd": "x"}},
+		{ID: "r2", Properties: map[string]interface{}{}},
+	}
+	ops, err = removePropertyOps(col, "Status", rows)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(ops))
+	assert.Equal(t, "r1", ops[1].ID)
+}
@@ -0,0 +1,254 @@
+// Package statuspage renders a status page (current status banner,
+// list of components and incident history) from Notion databases
+// of components and incidents
+package statuspage
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/ninja-1/notionapi"
+	"github.com/ninja-1/notionapi/tohtml"
+)
+
+// Level describes how severe a status is. Higher is worse
+type Level int
+
+const (
+	// LevelOperational is when everything works
+	LevelOperational Level = iota
+	// LevelMaintenance is for scheduled maintenance
+	LevelMaintenance
+	// LevelDegraded is for degraded performance
+	LevelDegraded
+	// LevelPartialOutage is when some things don't work
+	LevelPartialOutage
+	// LevelMajorOutage is when nothing works
+	LevelMajorOutage
+)
+
+// maps lower-cased status names used in Notion to a level
+var knownStatuses = map[string]Level{
+	"operational":          LevelOperational,
+	"ok":                   LevelOperational,
+	"up":                   LevelOperational,
+	"maintenance":          LevelMaintenance,
+	"under maintenance":    LevelMaintenance,
+	"degraded":             LevelDegraded,
+	"degraded performance": LevelDegraded,
+	"partial outage":       LevelPartialOutage,
+	"major outage":         LevelMajorOutage,
+	"outage":               LevelMajorOutage,
+	"down":                 LevelMajorOutage,
+}
+
+var levelNames = map[Level]string{
+	LevelOperational:   "All Systems Operational",
+	LevelMaintenance:   "Under Maintenance",
+	LevelDegraded:      "Degraded Performance",
+	LevelPartialOutage: "Partial Outage",
+	LevelMajorOutage:   "Major Outage",
+}
+
+// css class for a level
+var levelClasses = map[Level]string{
+	LevelOperational:   "operational",
+	LevelMaintenance:   "maintenance",
+	LevelDegraded:      "degraded",
+	LevelPartialOutage: "partial-outage",
+	LevelMajorOutage:   "major-outage",
+}
+
+// ParseLevel returns a level for a status name like "Partial Outage".
+// Unknown statuses are LevelOperational
+func ParseLevel(status string) Level {
+	s := strings.ToLower(strings.TrimSpace(status))
+	return knownStatuses[s]
+}
+
+// String returns a human-readable description of a level
+func (l Level) String() string {
+	return levelNames[l]
+}
+
+// Component is a row in the components database
+type Component struct {
+	Name   string
+	Status string
+	Level  Level
+}
+
+// Incident is a row in the incidents database
+type Incident struct {
+	Title string
+	// e.g. "Investigating", "Monitoring", "Resolved"
+	Status string
+	// "2020-05-01"
+	Date string
+	// impact of the incident e.g. "Major Outage"
+	Impact string
+	Level  Level
+	Row    *notionapi.Block
+}
+
+// IsResolved returns true if the incident has been resolved
+func (i *Incident) IsResolved() bool {
+	s := strings.ToLower(i.Status)
+	return s == "resolved" || s == "completed" || s == "done"
+}
+
+// Generator generates a status page from a database of components
+// and a database of incidents. Either can be nil
+type Generator struct {
+	Components *notionapi.TableView
+	Incidents  *notionapi.TableView
+
+	// names of columns. Default to "Status", "Date" and "Impact"
+	StatusColumn string
+	DateColumn   string
+	ImpactColumn string
+
+	// Title is the title of the status page. Defaults to "Status"
+	Title string
+	// MaxIncidents limits number of incidents in the history. 0 means no limit
+	MaxIncidents int
+}
+
+// New returns a Generator for databases of components and incidents
+func New(components, incidents *notionapi.TableView) *Generator {
+	return &Generator{
+		Components:   components,
+		Incidents:    incidents,
+		StatusColumn: "Status",
+		DateColumn:   "Date",
+		ImpactColumn: "Impact",
+		Title:        "Status",
+	}
+}
+
+// ComponentList returns components in the order of the database
+func (g *Generator) ComponentList() ([]*Component, error) {
+	tv := g.Components
+	if tv == nil {
+		return nil, nil
+	}
+	statusCol := tv.ColumnIndexByName(g.StatusColumn)
+	if statusCol < 0 {
+		return nil, fmt.Errorf("components database has no '%s' column", g.StatusColumn)
+	}
+	var res []*Component
+	for row, tr := range tv.Rows {
+		status := tv.CellText(row, statusCol)
+		c := &Component{
+			Name:   tr.Page.Title,
+			Status: status,
+			Level:  ParseLevel(status),
+		}
+		res = append(res, c)
+	}
+	return res, nil
+}
+
+// IncidentList returns incidents, newest first
+func (g *Generator) IncidentList() ([]*Incident, error) {
+	tv := g.Incidents
+	if tv == nil {
+		return nil, nil
+	}
+	statusCol := tv.ColumnIndexByName(g.StatusColumn)
+	if statusCol < 0 {
+		return nil, fmt.Errorf("incidents database has no '%s' column", g.StatusColumn)
+	}
+	dateCol := tv.ColumnIndexByName(g.DateColumn)
+	impactCol := tv.ColumnIndexByName(g.ImpactColumn)
+	var res []*Incident
+	for row, tr := range tv.Rows {
+		i := &Incident{
+			Title:  tr.Page.Title,
+			Status: tv.CellText(row, statusCol),
+			Date:   tv.CellText(row, dateCol),
+			Impact: tv.CellText(row, impactCol),
+			Row:    tr.Page,
+		}
+		i.Level = ParseLevel(i.Impact)
+		res = append(res, i)
+	}
+	sort.SliceStable(res, func(i, j int) bool {
+		return res[i].Date > res[j].Date
+	})
+	return res, nil
+}
+
+// CurrentLevel returns the worst level of components and unresolved incidents
+func CurrentLevel(components []*Component, incidents []*Incident) Level {
+	res := LevelOperational
+	for _, c := range components {
+		if c.Level > res {
+			res = c.Level
+		}
+	}
+	for _, i := range incidents {
+		if !i.IsResolved() && i.Level > res {
+			res = i.Level
+		}
+	}
+	return res
+}
+
+// ToHTML returns status page as HTML fragment
+func (g *Generator) ToHTML() ([]byte, error) {
+	components, err := g.ComponentList()
+	if err != nil {
+		return nil, err
+	}
+	incidents, err := g.IncidentList()
+	if err != nil {
+		return nil, err
+	}
+	level := CurrentLevel(components, incidents)
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, `<div class="status-page"><h1>%s</h1>`, tohtml.EscapeHTML(g.Title))
+	fmt.Fprintf(&buf, `<div class="status-banner %s">%s</div>`, levelClasses[level], tohtml.EscapeHTML(level.String()))
+
+	if len(components) > 0 {
+		buf.WriteString(`<ul class="status-components">`)
+		for _, c := range components {
+			status := c.Status
+			if status == "" {
+				status = "Operational"
+			}
+			fmt.Fprintf(&buf, `<li class="%s"><span class="status-component-name">%s</span><span class="status-component-status">%s</span></li>`, levelClasses[c.Level], tohtml.EscapeHTML(c.Name), tohtml.EscapeHTML(status))
+		}
+		buf.WriteString(`</ul>`)
+	}
+
+	if g.Incidents != nil {
+		buf.WriteString(`<section class="status-incidents"><h2>Incident History</h2>`)
+		if len(incidents) == 0 {
+			buf.WriteString(`<p>No incidents reported.</p>`)
+		}
+		if g.MaxIncidents > 0 && len(incidents) > g.MaxIncidents {
+			incidents = incidents[:g.MaxIncidents]
+		}
+		for _, i := range incidents {
+			cls := levelClasses[i.Level]
+			if i.IsResolved() {
+				cls += " resolved"
+			}
+			fmt.Fprintf(&buf, `<div class="status-incident %s"><h3>%s</h3>`, cls, tohtml.EscapeHTML(i.Title))
+			if i.Status != "" {
+				fmt.Fprintf(&buf, `<span class="status-incident-status">%s</span>`, tohtml.EscapeHTML(i.Status))
+			}
+			if i.Date != "" {
+				fmt.Fprintf(&buf, `<time datetime="%s">%s</time>`, tohtml.EscapeHTML(i.Date), tohtml.EscapeHTML(i.Date))
+			}
+			buf.WriteString(`</div>`)
+		}
+		buf.WriteString(`</section>`)
+	}
+	buf.WriteString(`</div>`)
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,24 @@
+package statuspage
+
+import (
+	"testing"
+
+	"github.com/ninja-1/notionapi/caching_downloader"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStatusPageFromTable(t *testing.T) {
+	p := caching_downloader.LoadTestPage(t, "94167af6567043279811dc923edd1f04")
+	tv := p.TableViews[0]
+	g := New(tv, tv)
+	g.StatusColumn = "Tags"
+	g.DateColumn = "Numbers"
+	html, err := g.ToHTML()
+	require.NoError(t, err)
+	require.Contains(t, string(html), `<div class="status-banner `)
+	require.Contains(t, string(html), `Incident History`)
+
+	g.StatusColumn = "no such column"
+	_, err = g.ToHTML()
+	require.Error(t, err)
+}
@@ -8,6 +8,8 @@ const (
 	CommandUpdate     = "update"
 	CommandListAfter  = "listAfter"
 	CommandListRemove = "listRemove"
+	// CommandSetPermissionItem adds or changes a permission of a block
+	CommandSetPermissionItem = "setPermissionItem"
 )
 
 type submitTransactionRequest struct {
@@ -0,0 +1,141 @@
+package notionapi_test
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/ninja-1/notionapi"
+	"github.com/ninja-1/notionapi/caching_downloader"
+	"github.com/stretchr/testify/require"
+)
+
+// Tests that use pages cached in caching_downloader/testdata. They're in
+// notionapi_test package because caching_downloader imports notionapi
+
+// https://www.notion.so/Test-headers-6682351e44bb4f9ca0e149b703265bdb
+func TestExtractSection(t *testing.T) {
+	p := caching_downloader.LoadTestPage(t, "6682351e44bb4f9ca0e149b703265bdb")
+	var headings []*notionapi.Block
+	p.ForEachBlock(func(block *notionapi.Block) {
+		if notionapi.HeadingLevel(block) > 0 {
+			headings = append(headings, block)
+		}
+	})
+	require.Equal(t, 3, len(headings))
+
+	// h1 section ends at the end of the page
+	blocks := notionapi.ExtractSection(p, strings.ToUpper(notionapi.TextSpansToString(headings[0].InlineContent)))
+	require.Equal(t, 5, len(blocks))
+	// h3 section is followed by 2 text blocks
+	blocks = notionapi.ExtractSection(p, notionapi.TextSpansToString(headings[2].InlineContent))
+	require.Equal(t, 2, len(blocks))
+	require.Nil(t, notionapi.ExtractSection(p, "no such heading"))
+}
+
+// https://www.notion.so/Test-headers-6682351e44bb4f9ca0e149b703265bdb
+func TestOutline(t *testing.T) {
+	p := caching_downloader.LoadTestPage(t, "6682351e44bb4f9ca0e149b703265bdb")
+	o := p.Outline()
+	require.Equal(t, 0, o.Level)
+	require.Equal(t, 6, o.BlockCount)
+	require.Equal(t, 3, len(o.Headings()))
+	require.Equal(t, 1, len(o.Children))
+	h1 := o.Children[0]
+	require.Equal(t, notionapi.BlockHeader, h1.Heading.Type)
+	require.Equal(t, 5, h1.BlockCount)
+	require.Equal(t, 0, len(h1.BlockIDs))
+	h2 := h1.Children[0]
+	require.Equal(t, 2, h2.Level)
+	require.Equal(t, 4, h2.BlockCount)
+	require.Equal(t, 1, len(h2.BlockIDs))
+	h3 := h2.Children[0]
+	require.Equal(t, 3, h3.Level)
+	require.Equal(t, 2, len(h3.BlockIDs))
+	require.Equal(t, notionapi.TextSpansToString(h3.Heading.InlineContent), h3.Title)
+}
+
+// https://www.notion.so/Test-headers-6682351e44bb4f9ca0e149b703265bdb
+func TestSplitPage(t *testing.T) {
+	p := caching_downloader.LoadTestPage(t, "6682351e44bb4f9ca0e149b703265bdb")
+	sections := notionapi.SplitPage(p, 1)
+	require.Equal(t, 1, len(sections))
+	require.Equal(t, 5, len(sections[0].Blocks))
+
+	sections = notionapi.SplitPage(p, 3)
+	require.Equal(t, 3, len(sections))
+	require.Equal(t, notionapi.BlockSubSubHeader, sections[2].Heading.Type)
+	require.Equal(t, notionapi.TextSpansToString(sections[2].Heading.InlineContent), sections[2].Title)
+	require.Equal(t, []int{0, 1, 2}, []int{len(sections[0].Blocks), len(sections[1].Blocks), len(sections[2].Blocks)})
+}
+
+func TestDriftReport(t *testing.T) {
+	r := notionapi.NewDriftReport()
+	p := caching_downloader.LoadTestPage(t, "6682351e44bb4f9ca0e149b703265bdb")
+	r.AddPage(p)
+	for _, e := range r.Entries() {
+		require.NotEqual(t, notionapi.DriftBlockType, e.Kind)
+	}
+
+	r.AddBlock(&notionapi.Block{ID: "b1", Type: "table_row"})
+	r.AddBlock(&notionapi.Block{ID: "b2", Type: "table_row"})
+	r.AddBlock(&notionapi.Block{
+		ID:   "b3",
+		Type: notionapi.BlockText,
+		RawJSON: map[string]interface{}{
+			"id":     "b3",
+			"format": map[string]interface{}{"block_color": "red", "block_width": 10.0},
+		},
+		InlineContent: []*notionapi.TextSpan{
+			{Text: "x", Attrs: [][]string{{"b"}, {"x"}}},
+		},
+	})
+	entries := r.Entries()
+	e := entries[0]
+	require.Equal(t, notionapi.DriftBlockType, e.Kind)
+	require.Equal(t, "table_row", e.Name)
+	require.Equal(t, 2, e.Count)
+	require.Equal(t, []string{"b1", "b2"}, e.ExampleBlockIDs)
+
+	var kinds []string
+	for _, e := range entries {
+		if e.BlockType == notionapi.BlockText {
+			kinds = append(kinds, e.Kind+" "+e.Name)
+		}
+	}
+	require.Contains(t, kinds, "format_field block_width")
+	require.Contains(t, kinds, "text_attr x")
+	require.Contains(t, r.String(), "block_type table_row: 2 times in 1 pages")
+}
+
+func TestWalk(t *testing.T) {
+	p := caching_downloader.LoadTestPage(t, "6682351e44bb4f9ca0e149b703265bdb")
+	var entered, exited []string
+	p.Walk(&notionapi.Visitor{
+		Enter: func(block *notionapi.Block, depth int) bool {
+			entered = append(entered, strconv.Itoa(depth)+" "+block.Type)
+			return true
+		},
+		Exit: func(block *notionapi.Block, depth int) {
+			exited = append(exited, block.Type)
+		},
+	})
+	require.Equal(t, 7, len(entered))
+	require.Equal(t, "0 page", entered[0])
+	require.Equal(t, "1 header", entered[1])
+	require.Equal(t, 7, len(exited))
+	require.Equal(t, "page", exited[6])
+
+	n := 0
+	p.Walk(&notionapi.Visitor{
+		Enter: func(block *notionapi.Block, depth int) bool {
+			n++
+			return false
+		},
+	})
+	require.Equal(t, 1, n)
+
+	block := p.Root().Content[2]
+	require.Equal(t, block, p.FindBlockByID(notionapi.ToNoDashID(block.ID)))
+	require.Nil(t, p.FindBlockByID("00000000000000000000000000000000"))
+}
@@ -0,0 +1,141 @@
+// Package toanki converts Notion pages to flashcards that can be
+// imported into Anki (https://apps.ankiweb.net/)
+package toanki
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/ninja-1/notionapi"
+	"github.com/ninja-1/notionapi/tohtml"
+)
+
+// Card is a flashcard. Front and Back are HTML
+type Card struct {
+	Front string
+	Back  string
+	Tags  []string
+	// block (toggle or a database row) the card was created from
+	Block *notionapi.Block
+}
+
+// Converter creates flashcards from a page.
+// Every toggle block becomes a card with toggle's title as a question
+// and its content as an answer.
+// If QuestionColumn and AnswerColumn are set, every row of databases
+// in the page also becomes a card
+type Converter struct {
+	Page *notionapi.Page
+
+	// Tags are added to every card
+	Tags []string
+
+	// names of database columns with question and answer
+	QuestionColumn string
+	AnswerColumn   string
+
+	// if true, we don't create cards from toggle blocks
+	SkipToggles bool
+
+	html *tohtml.Converter
+}
+
+// NewConverter returns a new Converter
+func NewConverter(page *notionapi.Page) *Converter {
+	return &Converter{
+		Page: page,
+	}
+}
+
+func (c *Converter) blocksHTML(blocks []*notionapi.Block) string {
+	c.html.PushNewBuffer()
+	for _, block := range blocks {
+		c.html.RenderBlock(block)
+	}
+	return c.html.PopBuffer().String()
+}
+
+func (c *Converter) tableCards(tv *notionapi.TableView) []*Card {
+	qCol := tv.ColumnIndexByName(c.QuestionColumn)
+	aCol := tv.ColumnIndexByName(c.AnswerColumn)
+	if qCol < 0 || aCol < 0 {
+		return nil
+	}
+	var res []*Card
+	for row, tr := range tv.Rows {
+		front := c.html.GetInlineContent(tv.CellContent(row, qCol))
+		if front == "" {
+			continue
+		}
+		card := &Card{
+			Front: front,
+			Back:  c.html.GetInlineContent(tv.CellContent(row, aCol)),
+			Tags:  c.Tags,
+			Block: tr.Page,
+		}
+		res = append(res, card)
+	}
+	return res
+}
+
+// Cards returns flashcards created from the page
+func (c *Converter) Cards() []*Card {
+	c.html = tohtml.NewConverter(c.Page)
+	var res []*Card
+	if !c.SkipToggles {
+		c.Page.ForEachBlock(func(block *notionapi.Block) {
+			if block.Type != notionapi.BlockToggle || len(block.Content) == 0 {
+				return
+			}
+			card := &Card{
+				Front: c.html.GetInlineContent(block.InlineContent),
+				Back:  c.blocksHTML(block.Content),
+				Tags:  c.Tags,
+				Block: block,
+			}
+			res = append(res, card)
+		})
+	}
+	if c.QuestionColumn != "" && c.AnswerColumn != "" {
+		for _, tv := range c.Page.TableViews {
+			res = append(res, c.tableCards(tv)...)
+		}
+	}
+	return res
+}
+
+// quotes a field if it has characters that would break TSV
+func tsvField(s string) string {
+	if !strings.ContainsAny(s, "\t\n\r\"") {
+		return s
+	}
+	return `"` + strings.Replace(s, `"`, `""`, -1) + `"`
+}
+
+// ToTSV returns cards as tab-separated text file that can be imported
+// into Anki with File > Import
+func (c *Converter) ToTSV() []byte {
+	cards := c.Cards()
+	var buf bytes.Buffer
+	buf.WriteString("#separator:tab\n#html:true\n#tags column:3\n")
+	for _, card := range cards {
+		tags := make([]string, len(card.Tags))
+		for i, tag := range card.Tags {
+			// tags are separated by space in Anki
+			tags[i] = strings.Replace(tag, " ", "_", -1)
+		}
+		buf.WriteString(tsvField(card.Front))
+		buf.WriteByte('\t')
+		buf.WriteString(tsvField(card.Back))
+		buf.WriteByte('\t')
+		buf.WriteString(tsvField(strings.Join(tags, " ")))
+		buf.WriteByte('\n')
+	}
+	return buf.Bytes()
+}
+
+// ToTSV converts toggle blocks in the page to flashcards in Anki's
+// tab-separated import format
+func ToTSV(page *notionapi.Page) []byte {
+	return NewConverter(page).ToTSV()
+}
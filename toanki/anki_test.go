@@ -0,0 +1,27 @@
+package toanki
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ninja-1/notionapi/caching_downloader"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTsvField(t *testing.T) {
+	assert.Equal(t, "plain", tsvField("plain"))
+	assert.Equal(t, `"a<br>""b""`+"\n"+`"`, tsvField("a<br>\"b\"\n"))
+}
+
+// https://www.notion.so/Test-table-94167af6567043279811dc923edd1f04
+// rows of a database as flashcards
+func TestAnkiCardsFromTable(t *testing.T) {
+	p := caching_downloader.LoadTestPage(t, "94167af6567043279811dc923edd1f04")
+	conv := NewConverter(p)
+	conv.QuestionColumn = "Name"
+	conv.AnswerColumn = "numbers"
+	cards := conv.Cards()
+	require.NotEmpty(t, cards)
+	require.True(t, strings.HasPrefix(string(conv.ToTSV()), "#separator:tab\n"))
+}
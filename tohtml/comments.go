@@ -0,0 +1,121 @@
+package tohtml
+
+import (
+	"strings"
+
+	"github.com/ninja-1/notionapi"
+)
+
+// CommentsMode says how to render discussions (comments) on blocks
+type CommentsMode int
+
+const (
+	// CommentsNone doesn't render comments
+	CommentsNone CommentsMode = iota
+	// CommentsFootnotes renders a reference after the block and
+	// the comments at the end of the page
+	CommentsFootnotes
+	// CommentsSideNotes renders comments in <aside> after the block
+	CommentsSideNotes
+)
+
+type footnote struct {
+	no         int
+	discussion *notionapi.Discussion
+}
+
+// returns discussions on a block we should render
+func (c *Converter) blockDiscussions(block *notionapi.Block) []*notionapi.Discussion {
+	var res []*notionapi.Discussion
+	for _, d := range block.Discussions() {
+		if d.Resolved && !c.RenderResolvedComments {
+			continue
+		}
+		if len(d.CommentsAll(block.Page)) == 0 {
+			continue
+		}
+		res = append(res, d)
+	}
+	return res
+}
+
+func (c *Converter) userName(userID string) string {
+	u := c.Page.UserByID(userID)
+	if u == nil && c.Users != nil {
+		u = c.Users.UserByID(userID)
+	}
+	if u == nil {
+		return ""
+	}
+	return strings.TrimSpace(u.GivenName + " " + u.FamilyName)
+}
+
+// userNameByID returns name of a user from the page or from Users
+func (c *Converter) userNameByID(page *notionapi.Page, userID string) string {
+	if page != nil && page.UserByID(userID) != nil {
+		return notionapi.GetUserNameByID(page, userID)
+	}
+	if c.Users != nil {
+		return c.Users.NameByID(userID)
+	}
+	if page == nil {
+		return userID
+	}
+	return notionapi.GetUserNameByID(page, userID)
+}
+
+func (c *Converter) renderDiscussion(d *notionapi.Discussion) {
+	for _, comment := range d.CommentsAll(c.Page) {
+		c.Printf(`<div class="comment">`)
+		if name := c.userName(comment.CreatedBy); name != "" {
+			c.Printf(`<span class="comment-author">%s</span> `, EscapeHTML(name))
+		}
+		c.Printf(`<span class="comment-text">`)
+		c.RenderInlines(comment.GetText())
+		c.Printf(`</span>`)
+		c.Printf(`</div>`)
+	}
+}
+
+// renders discussions on a block, according to RenderComments
+func (c *Converter) renderBlockComments(block *notionapi.Block) {
+	if c.RenderComments == CommentsNone || c.Page == nil {
+		return
+	}
+	discussions := c.blockDiscussions(block)
+	if len(discussions) == 0 {
+		return
+	}
+	switch c.RenderComments {
+	case CommentsFootnotes:
+		for _, d := range discussions {
+			no := len(c.footnotes) + 1
+			c.footnotes = append(c.footnotes, &footnote{no: no, discussion: d})
+			c.Printf(`<sup class="comment-ref" id="comment-ref-%d"><a href="#comment-%d">[%d]</a></sup>`, no, no, no)
+		}
+	case CommentsSideNotes:
+		c.Printf(`<aside class="comments">`)
+		for _, d := range discussions {
+			c.Printf(`<div class="discussion">`)
+			c.renderDiscussion(d)
+			c.Printf(`</div>`)
+		}
+		c.Printf(`</aside>`)
+	}
+}
+
+// renders comments collected as footnotes
+func (c *Converter) renderFootnotes() {
+	if len(c.footnotes) == 0 {
+		return
+	}
+	c.Printf(`<section class="comments"><ol>`)
+	for _, fn := range c.footnotes {
+		c.Printf(`<li id="comment-%d">`, fn.no)
+		c.renderDiscussion(fn.discussion)
+		c.Printf(` <a href="#comment-ref-%d" class="comment-backref">↩</a>`, fn.no)
+		c.Printf(`</li>`)
+	}
+	c.Printf(`</ol></section>`)
+	c.footnotes = nil
+}
@@ -0,0 +1,39 @@
+package tohtml
+
+import (
+	"fmt"
+	"math"
+)
+
+// in StrictCSP mode column widths are approximated with classes
+// column-grow-1 ... column-grow-20 (in 5% steps)
+const columnGrowSteps = 20
+
+// iframeSizeAttrs returns attributes that set the size of an iframe.
+// In StrictCSP mode they're width and height attributes instead of style
+func (c *Converter) iframeSizeAttrs(width, height float64, fullWidth bool) string {
+	if !c.StrictCSP {
+		return ` style="` + iframeStyle(width, height, fullWidth) + `"`
+	}
+	res := ` width="100%"`
+	if !fullWidth && width > 0 {
+		res = fmt.Sprintf(` width="%d"`, int(width))
+	}
+	if height > 0 {
+		res += fmt.Sprintf(` height="%d"`, int(height))
+	}
+	return res
+}
+
+// columnGrowClass returns a class that approximates a column ratio
+// without inline styles
+func columnGrowClass(ratio float64) string {
+	n := int(math.Round(ratio * columnGrowSteps))
+	if n < 1 {
+		n = 1
+	}
+	if n > columnGrowSteps {
+		n = columnGrowSteps
+	}
+	return fmt.Sprintf("column-grow-%d", n)
+}
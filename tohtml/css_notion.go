@@ -152,6 +152,50 @@ img {
 	padding-right: 0;
 }
 
+/* stack columns on narrow screens. See data-column-count and data-column-ratio */
+@media only screen and (max-width: 640px) {
+	.column-list {
+		flex-direction: column;
+	}
+
+	.column-list > .column {
+		flex: none !important;
+		width: 100% !important;
+		padding: 0;
+	}
+}
+
+.visually-hidden {
+	position: absolute;
+	width: 1px;
+	height: 1px;
+	overflow: hidden;
+	clip: rect(0 0 0 0);
+	white-space: nowrap;
+}
+
+.header-permalink {
+	opacity: 0;
+	text-decoration: none;
+}
+
+h1:hover .header-permalink,
+h2:hover .header-permalink,
+h3:hover .header-permalink,
+.header-permalink:focus {
+	opacity: 1;
+}
+
+.table_of_contents-list {
+	list-style: none;
+	padding-left: 1.5em;
+	margin: 0;
+}
+
+nav > .table_of_contents-list {
+	padding-left: 0;
+}
+
 .table_of_contents-item {
 	display: block;
 	font-size: 0.875rem;
@@ -575,4 +619,245 @@ const CSSPlus = `
 .breadcrumbs {
 
 }
+
+.bookmark.source {
+	display: flex;
+	text-decoration: none;
+	color: inherit;
+	border: 1px solid rgba(55, 53, 47, 0.16);
+	border-radius: 3px;
+	overflow: hidden;
+}
+
+.bookmark-info {
+	flex: 4 1 180px;
+	padding: 12px 14px 14px;
+	overflow: hidden;
+}
+
+.bookmark-title {
+	font-size: 14px;
+	white-space: nowrap;
+	overflow: hidden;
+	text-overflow: ellipsis;
+	margin-bottom: 2px;
+}
+
+.bookmark-description {
+	font-size: 12px;
+	opacity: 0.8;
+	height: 32px;
+	overflow: hidden;
+}
+
+.bookmark-icon {
+	width: 16px;
+	height: 16px;
+	margin-right: 6px;
+	vertical-align: middle;
+}
+
+.bookmark-image {
+	flex: 1 1 180px;
+	max-height: 120px;
+	object-fit: cover;
+}
+
+.comment-ref {
+	font-size: 0.75em;
+}
+
+aside.comments, section.comments {
+	font-size: 0.875em;
+	color: rgba(55, 53, 47, 0.6);
+}
+
+aside.comments {
+	border-left: 3px solid rgba(255, 212, 0, 0.8);
+	padding-left: 0.75em;
+	margin: 0.25em 0;
+}
+
+section.comments {
+	border-top: 1px solid rgba(55, 53, 47, 0.09);
+	margin-top: 2em;
+}
+
+.comment-author {
+	font-weight: 600;
+}
+
+.page-hero {
+	position: relative;
+}
+
+.page-hero-cover {
+	height: 30vh;
+	background-size: cover;
+	background-repeat: no-repeat;
+	margin-bottom: 1em;
+}
+
+.page-hero-icon {
+	font-size: 78px;
+	line-height: 1.1;
+}
+
+.page-hero-with-cover .page-hero-icon {
+	margin-top: -50px;
+	position: relative;
+}
+
+.page-hero-icon img.icon {
+	width: 78px;
+	height: 78px;
+	border-radius: 3px;
+}
+
+table.properties {
+	border-collapse: collapse;
+	margin-bottom: 1.5em;
+}
+
+table.properties th {
+	font-weight: normal;
+	text-align: left;
+	color: rgba(55, 53, 47, 0.6);
+	padding: 4px 16px 4px 0;
+	white-space: nowrap;
+	vertical-align: top;
+}
+
+table.properties td {
+	padding: 4px 0;
+}
+
+/* StrictCSP output, without inline styles */
+.callout-icon {
+	font-size: 1.5em;
+}
+
+.callout-text {
+	width: 100%;
+}
+
+figure.callout {
+	white-space: pre-wrap;
+	display: flex;
+}
+
+.page-hero-cover-image {
+	width: 100%;
+	height: 100%;
+	object-fit: cover;
+}
+
+.file-card-icon {
+	width: 1em;
+	height: 1em;
+	margin-right: 0.5em;
+	vertical-align: text-bottom;
+}
+
+table.collection-content-list {
+	width: 100%;
+}
+
+.column-grow-1 {
+	flex: 1 1 0;
+}
+
+.column-grow-2 {
+	flex: 2 1 0;
+}
+
+.column-grow-3 {
+	flex: 3 1 0;
+}
+
+.column-grow-4 {
+	flex: 4 1 0;
+}
+
+.column-grow-5 {
+	flex: 5 1 0;
+}
+
+.column-grow-6 {
+	flex: 6 1 0;
+}
+
+.column-grow-7 {
+	flex: 7 1 0;
+}
+
+.column-grow-8 {
+	flex: 8 1 0;
+}
+
+.column-grow-9 {
+	flex: 9 1 0;
+}
+
+.column-grow-10 {
+	flex: 10 1 0;
+}
+
+.column-grow-11 {
+	flex: 11 1 0;
+}
+
+.column-grow-12 {
+	flex: 12 1 0;
+}
+
+.column-grow-13 {
+	flex: 13 1 0;
+}
+
+.column-grow-14 {
+	flex: 14 1 0;
+}
+
+.column-grow-15 {
+	flex: 15 1 0;
+}
+
+.column-grow-16 {
+	flex: 16 1 0;
+}
+
+.column-grow-17 {
+	flex: 17 1 0;
+}
+
+.column-grow-18 {
+	flex: 18 1 0;
+}
+
+.column-grow-19 {
+	flex: 19 1 0;
+}
+
+.column-grow-20 {
+	flex: 20 1 0;
+}
+
+/* placeholders of third-party embeds. See ClickToLoadEmbeds */
+.embed-placeholder {
+	border: 1px solid rgba(55, 53, 47, 0.16);
+	border-radius: 3px;
+	padding: 1em;
+	background: rgba(55, 53, 47, 0.024);
+}
+
+.embed-placeholder-provider {
+	font-weight: 600;
+	text-transform: capitalize;
+	margin-bottom: 0.5em;
+}
+
+.embed-placeholder-link {
+	word-break: break-all;
+}
 `
@@ -0,0 +1,115 @@
+package tohtml
+
+import (
+	"github.com/ninja-1/notionapi"
+)
+
+// DocsMarkup configures markup of heading permalinks and table of contents
+// for documentation sites. Classes can be set to match the theme
+// of a docs site generator (see DocusaurusMarkup and DocsifyMarkup)
+type DocsMarkup struct {
+	// class of permalink <a> added to headings
+	PermalinkClass string
+	// visible content of the permalink e.g. "#"
+	PermalinkSymbol string
+	// prefix of a label of the permalink for screen readers. The label is
+	// PermalinkLabel followed by the text of the heading
+	PermalinkLabel string
+	// class that hides an element visually but not from screen readers
+	VisuallyHiddenClass string
+
+	// classes of <ul>, <li> and <a> elements in table of contents.
+	// Nested headings are in nested <ul>
+	TOCListClass string
+	TOCItemClass string
+	TOCLinkClass string
+}
+
+var (
+	// DefaultDocsMarkup uses classes of our CSS
+	DefaultDocsMarkup = &DocsMarkup{
+		PermalinkClass:      "header-permalink",
+		PermalinkSymbol:     "#",
+		PermalinkLabel:      "Permalink to ",
+		VisuallyHiddenClass: "visually-hidden",
+		TOCListClass:        "table_of_contents-list",
+		TOCItemClass:        "table_of_contents-item",
+		TOCLinkClass:        "table_of_contents-link",
+	}
+	// DocusaurusMarkup uses classes of Docusaurus themes
+	DocusaurusMarkup = &DocsMarkup{
+		PermalinkClass:      "hash-link",
+		PermalinkSymbol:     "#",
+		PermalinkLabel:      "Direct link to ",
+		VisuallyHiddenClass: "sr-only",
+		TOCListClass:        "table-of-contents",
+		TOCLinkClass:        "table-of-contents__link",
+	}
+	// DocsifyMarkup uses classes of docsify themes
+	DocsifyMarkup = &DocsMarkup{
+		PermalinkClass:      "anchor",
+		PermalinkSymbol:     "#",
+		PermalinkLabel:      "Permalink to ",
+		VisuallyHiddenClass: "sr-only",
+		TOCListClass:        "app-sub-sidebar",
+		TOCLinkClass:        "section-link",
+	}
+)
+
+// returns ` class="cls"` or "" if cls is empty
+func classAttr(cls string) string {
+	if cls == "" {
+		return ""
+	}
+	return ` class="` + EscapeHTML(cls) + `"`
+}
+
+// renderPermalink renders a link to a heading with a label
+// for screen readers
+func (c *Converter) renderPermalink(block *notionapi.Block) {
+	m := c.DocsMarkup
+	label := m.PermalinkLabel + notionapi.TextSpansToString(block.InlineContent)
+	c.Printf(` <a%s href="#%s">`, classAttr(m.PermalinkClass), c.headingID(block))
+	if m.VisuallyHiddenClass != "" {
+		c.Printf(`<span%s>%s</span>`, classAttr(m.VisuallyHiddenClass), EscapeHTML(label))
+	}
+	c.Printf(`<span aria-hidden="true">%s</span>`, EscapeHTML(m.PermalinkSymbol))
+	c.Printf(`</a>`)
+}
+
+// renderDocsTOC renders table of contents as nested lists of links,
+// which are navigable with a keyboard and by screen readers
+func (c *Converter) renderDocsTOC(block *notionapi.Block, headings []*notionapi.Block) {
+	m := c.DocsMarkup
+	c.Printf(`<nav id="%s" class="table_of_contents" aria-label="Table of contents">`, block.ID)
+	// levels of headings of currently open lists
+	var levels []int
+	for _, h := range headings {
+		level := notionapi.HeadingLevel(h)
+		n := len(levels)
+		if n > 0 && level > levels[n-1] {
+			// nested list inside the previous <li>
+			c.Printf(`<ul%s>`, classAttr(m.TOCListClass))
+			levels = append(levels, level)
+		} else {
+			for n > 1 && level < levels[n-1] {
+				c.Printf(`</li></ul>`)
+				levels = levels[:n-1]
+				n--
+			}
+			if n == 0 {
+				c.Printf(`<ul%s>`, classAttr(m.TOCListClass))
+				levels = append(levels, level)
+			} else {
+				c.Printf(`</li>`)
+			}
+		}
+		c.Printf(`<li%s>`, classAttr(m.TOCItemClass))
+		s := c.GetInlineContent(h.InlineContent)
+		c.Printf(`<a%s href="#%s">%s</a>`, classAttr(m.TOCLinkClass), c.headingID(h), s)
+	}
+	for range levels {
+		c.Printf(`</li></ul>`)
+	}
+	c.Printf(`</nav>`)
+}
@@ -0,0 +1,267 @@
+package tohtml
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/ninja-1/notionapi"
+)
+
+const (
+	// sandbox for embeds that need to run scripts of the embedded site
+	sandboxScripts = "allow-scripts allow-same-origin allow-popups allow-forms"
+	// sandbox for embeds that also present full-screen content
+	sandboxPresentation = "allow-scripts allow-same-origin allow-popups allow-presentation"
+)
+
+// EmbedProvider describes how to embed content of a well-known provider
+// (Figma, CodePen etc.) as an iframe
+type EmbedProvider struct {
+	Name string
+	// Match returns true if uri can be embedded by this provider
+	Match func(uri *url.URL) bool
+	// EmbedURL returns url for an iframe
+	EmbedURL func(uri *url.URL) string
+	// value of iframe's sandbox attribute
+	Sandbox string
+}
+
+func hostIs(u *url.URL, domain string) bool {
+	host := strings.ToLower(u.Hostname())
+	return host == domain || strings.HasSuffix(host, "."+domain)
+}
+
+// EmbedProviders is a list of providers we know how to embed as iframes
+var EmbedProviders = []*EmbedProvider{
+	{
+		Name: "figma",
+		Match: func(u *url.URL) bool {
+			return hostIs(u, "figma.com")
+		},
+		EmbedURL: func(u *url.URL) string {
+			return "https://www.figma.com/embed?embed_host=notion&url=" + url.QueryEscape(u.String())
+		},
+		Sandbox: sandboxScripts,
+	},
+	{
+		Name: "codepen",
+		Match: func(u *url.URL) bool {
+			return hostIs(u, "codepen.io") && strings.Contains(u.Path, "/pen/")
+		},
+		EmbedURL: func(u *url.URL) string {
+			// https://codepen.io/${user}/pen/${id} => https://codepen.io/${user}/embed/${id}
+			path := strings.Replace(u.Path, "/pen/", "/embed/", 1)
+			return "https://codepen.io" + path + "?default-tab=result"
+		},
+		Sandbox: sandboxScripts,
+	},
+	{
+		Name: "replit",
+		Match: func(u *url.URL) bool {
+			return hostIs(u, "replit.com") || hostIs(u, "repl.it")
+		},
+		EmbedURL: func(u *url.URL) string {
+			res := *u
+			q := res.Query()
+			q.Set("embed", "true")
+			res.RawQuery = q.Encode()
+			return res.String()
+		},
+		Sandbox: sandboxScripts,
+	},
+	{
+		Name: "loom",
+		Match: func(u *url.URL) bool {
+			return hostIs(u, "loom.com") && strings.HasPrefix(u.Path, "/share/")
+		},
+		EmbedURL: func(u *url.URL) string {
+			// https://www.loom.com/share/${id} => https://www.loom.com/embed/${id}
+			return "https://www.loom.com/embed/" + strings.TrimPrefix(u.Path, "/share/")
+		},
+		Sandbox: sandboxPresentation,
+	},
+	{
+		Name: "youtube",
+		Match: func(u *url.URL) bool {
+			return youtubeVideoID(u) != ""
+		},
+		EmbedURL: func(u *url.URL) string {
+			return "https://www.youtube-nocookie.com/embed/" + youtubeVideoID(u)
+		},
+		Sandbox: sandboxPresentation,
+	},
+	{
+		Name: "typeform",
+		Match: func(u *url.URL) bool {
+			return hostIs(u, "typeform.com") && strings.HasPrefix(u.Path, "/to/")
+		},
+		EmbedURL: func(u *url.URL) string {
+			return u.String()
+		},
+		Sandbox: sandboxScripts,
+	},
+}
+
+// returns id of a YouTube video from its url or "" if it's not a video
+func youtubeVideoID(u *url.URL) string {
+	switch {
+	case hostIs(u, "youtu.be"):
+		return strings.Trim(u.Path, "/")
+	case hostIs(u, "youtube.com") && u.Path == "/watch":
+		return u.Query().Get("v")
+	case hostIs(u, "youtube.com") && strings.HasPrefix(u.Path, "/embed/"):
+		return strings.TrimPrefix(u.Path, "/embed/")
+	}
+	return ""
+}
+
+// FindEmbedProvider returns a provider for a given url or nil
+// if we don't know how to embed it
+func FindEmbedProvider(uri string) *EmbedProvider {
+	u, err := url.Parse(uri)
+	if err != nil || u.Host == "" {
+		return nil
+	}
+	for _, p := range EmbedProviders {
+		if p.Match(u) {
+			return p
+		}
+	}
+	return nil
+}
+
+// returns width, height and full width of embed blocks
+func getEmbedSize(block *notionapi.Block) (float64, float64, bool) {
+	switch block.Type {
+	case notionapi.BlockFigma:
+		if f := block.FormatFigma(); f != nil {
+			return f.BlockWidth, f.BlockHeight, f.BlockFullWidth || f.BlockPageWidth
+		}
+	case notionapi.BlockCodepen:
+		if f := block.FormatCodepen(); f != nil {
+			return f.BlockWidth, f.BlockHeight, f.BlockFullWidth || f.BlockPageWidth
+		}
+	case notionapi.BlockEmbed:
+		if f := block.FormatEmbed(); f != nil {
+			return f.BlockWidth, f.BlockHeight, f.BlockFullWidth || f.BlockPageWidth
+		}
+	}
+	return 0, 0, true
+}
+
+// isEmbedAllowed returns true if uri can be embedded according
+// to Converter.EmbedAllowedDomains
+func (c *Converter) isEmbedAllowed(uri string) bool {
+	if c.EmbedAllowedDomains == nil {
+		return true
+	}
+	u, err := url.Parse(uri)
+	if err != nil || u.Host == "" {
+		return false
+	}
+	if u.Scheme != "https" && u.Scheme != "http" {
+		return false
+	}
+	for _, domain := range c.EmbedAllowedDomains {
+		domain = strings.TrimPrefix(strings.ToLower(domain), ".")
+		if domain != "" && hostIs(u, domain) {
+			return true
+		}
+	}
+	return false
+}
+
+// embedURL returns url of an iframe or script of an embed rewritten
+// with RewriteURL and false if it can't be embedded. We check the
+// rewritten url so that RewriteURL can't bypass EmbedAllowedDomains.
+// When built with notionapi_offline tag, nothing is embedded
+func (c *Converter) embedURL(uri string) (string, bool) {
+	if notionapi.Offline {
+		return "", false
+	}
+	uri = c.RewrittenURL(uri, URLKindEmbed)
+	return uri, c.isEmbedAllowed(uri)
+}
+
+// renderProviderEmbed renders an embed of a well-known provider as iframe.
+// Returns false if we don't know how to embed block.Source
+func (c *Converter) renderProviderEmbed(block *notionapi.Block) bool {
+	if c.NotionCompat {
+		return false
+	}
+	p := FindEmbedProvider(block.Source)
+	if p == nil {
+		return false
+	}
+	u, _ := url.Parse(block.Source)
+	embedURL, ok := c.embedURL(p.EmbedURL(u))
+	if !ok {
+		return false
+	}
+	src := EscapeHTML(embedURL)
+	width, height, fullWidth := getEmbedSize(block)
+	if height == 0 {
+		height = 450
+	}
+	c.Printf(`<figure id="%s" class="embed embed-%s">`, block.ID, p.Name)
+	{
+		if c.ClickToLoadEmbeds {
+			c.renderEmbedPlaceholder(p.Name, src, block.Source, width, height)
+		} else {
+			size := c.iframeSizeAttrs(width, height, fullWidth)
+			c.Printf(`<iframe src="%s"%s sandbox="%s" frameborder="0" loading="lazy" allowfullscreen=""></iframe>`, src, size, p.Sandbox)
+		}
+		c.RenderCaption(block)
+		c.renderTranscript(block)
+	}
+	c.Printf(`</figure>`)
+	return true
+}
+
+// renderEmbedPlaceholder renders a static card instead of an iframe
+// of a third party (see Converter.ClickToLoadEmbeds). loadURL is
+// (already escaped) url of the iframe and sourceURL is the original url
+func (c *Converter) renderEmbedPlaceholder(provider string, loadURL string, sourceURL string, width, height float64) {
+	c.Printf(`<div class="embed-placeholder" data-click-to-load="%s" data-provider="%s"`, loadURL, provider)
+	if width > 0 {
+		c.Printf(` data-width="%d"`, int(width))
+	}
+	if height > 0 {
+		c.Printf(` data-height="%d"`, int(height))
+	}
+	c.Printf(`>`)
+	c.Printf(`<div class="embed-placeholder-provider">%s</div>`, EscapeHTML(provider))
+	c.A(sourceURL, sourceURL, "embed-placeholder-link")
+	c.Printf(`</div>`)
+}
+
+// FetchTwitterOEmbed returns HTML for a tweet from Twitter's oEmbed API.
+// It can be used as Converter.FetchTweetOEmbed.
+// The script tag is omitted (see Converter.IncludeTweetScript).
+// When built with notionapi_offline tag, it doesn't make the request
+func FetchTwitterOEmbed(tweetURL string) (string, error) {
+	uri := "https://publish.twitter.com/oembed?omit_script=true&url=" + url.QueryEscape(tweetURL)
+	if notionapi.Offline {
+		return "", &notionapi.ErrNetworkNotAllowed{URL: uri}
+	}
+	httpClient := &http.Client{Timeout: 30 * time.Second}
+	rsp, err := httpClient.Get(uri)
+	if err != nil {
+		return "", err
+	}
+	defer rsp.Body.Close()
+	if rsp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("http GET '%s' failed with status %s", uri, rsp.Status)
+	}
+	var res struct {
+		HTML string `json:"html"`
+	}
+	if err = json.NewDecoder(rsp.Body).Decode(&res); err != nil {
+		return "", err
+	}
+	return res.HTML, nil
+}
@@ -0,0 +1,120 @@
+package tohtml
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/ninja-1/notionapi"
+)
+
+// HeadingSlug returns a readable anchor for a heading with a given text
+// e.g. "getting-started" for "Getting started!"
+func HeadingSlug(text string) string {
+	var sb strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(text) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if dash && sb.Len() > 0 {
+				sb.WriteByte('-')
+			}
+			sb.WriteRune(r)
+			dash = false
+			continue
+		}
+		dash = true
+	}
+	if sb.Len() == 0 {
+		return "section"
+	}
+	return sb.String()
+}
+
+// levenshtein returns edit distance between a and b
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min3(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
+
+func min3(a, b, c int) int {
+	if b < a {
+		a = b
+	}
+	if c < a {
+		a = c
+	}
+	return a
+}
+
+// isSlightChange returns true if slug differs from a previous anchor
+// in at most a third of characters
+func isSlightChange(prevAnchor string, slug string) bool {
+	n := len([]rune(prevAnchor))
+	if m := len([]rune(slug)); m > n {
+		n = m
+	}
+	return levenshtein(prevAnchor, slug)*3 <= n
+}
+
+// HeadingAnchors returns readable anchors (see HeadingSlug), unique within
+// the page, for headings of a page keyed by block id. They can be used
+// as Converter.HeadingID.
+// previous are anchors of the previously published version of the page
+// (can be nil). If the text of a heading changed only slightly, it keeps
+// its previous anchor so that links to it don't break
+func HeadingAnchors(page *notionapi.Page, previous map[string]string) map[string]string {
+	headings := page.Outline().Headings()
+	res := map[string]string{}
+	taken := map[string]bool{}
+	// previous anchors first, so that new ones don't take them
+	for _, h := range headings {
+		prev := previous[h.ID]
+		if prev == "" || taken[prev] {
+			continue
+		}
+		slug := HeadingSlug(notionapi.TextSpansToString(h.InlineContent))
+		if isSlightChange(prev, slug) {
+			res[h.ID] = prev
+			taken[prev] = true
+		}
+	}
+	for _, h := range headings {
+		if res[h.ID] != "" {
+			continue
+		}
+		slug := HeadingSlug(notionapi.TextSpansToString(h.InlineContent))
+		anchor := slug
+		for i := 2; taken[anchor]; i++ {
+			anchor = slug + "-" + strconv.Itoa(i)
+		}
+		res[h.ID] = anchor
+		taken[anchor] = true
+	}
+	return res
+}
+
+// headingID returns id of a heading block, used as its anchor
+func (c *Converter) headingID(block *notionapi.Block) string {
+	if c.HeadingID != nil {
+		if id := c.HeadingID(block); id != "" {
+			return EscapeHTML(id)
+		}
+	}
+	return block.ID
+}
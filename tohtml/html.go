@@ -3,10 +3,8 @@ package tohtml
 import (
 	"bytes"
 	"fmt"
-	"html"
-	"os"
-	"os/exec"
 	"path"
+	"sort"
 	"strconv"
 	"strings"
 
@@ -109,6 +107,11 @@ func (c *Converter) tableTitleCellURL(tv *notionapi.TableView, row, col int) str
 	if c.TableTitleCellURLOverride != nil {
 		return c.TableTitleCellURLOverride(tv, row, col)
 	}
+	if c.RowPageURL != nil {
+		if uri := c.RowPageURL(tv.Rows[row].Page); uri != "" {
+			return uri
+		}
+	}
 	title := ""
 	titleSpans := tv.CellContent(row, col)
 	if len(titleSpans) == 0 {
@@ -178,11 +181,20 @@ func getDownloadedFileName(uri string, block *notionapi.Block) string {
 	return name
 }
 
-func getFileOrSourceURL(block *notionapi.Block) string {
+// fileURL returns url of a file uploaded to Notion: either a local
+// path of the downloaded file or, if SignFileURLs is set, a signed url
+func (c *Converter) fileURL(uri string, kind URLKind, block *notionapi.Block) string {
+	if c.SignFileURLs && notionapi.IsNotionFileURL(uri) && c.Page != nil {
+		return c.RewrittenURL(c.Page.SignedFileURL(uri), kind)
+	}
+	return c.RewrittenURL(getDownloadedFileName(uri, block), kind)
+}
+
+func (c *Converter) fileOrSourceURL(block *notionapi.Block, kind URLKind) string {
 	if len(block.FileIDs) > 0 {
-		return getDownloadedFileName(block.Source, block)
+		return c.fileURL(block.Source, kind, block)
 	}
-	return block.Source
+	return c.RewrittenURL(block.Source, kind)
 }
 
 func htmlFileName(title string) string {
@@ -257,21 +269,56 @@ type Converter struct {
 	// to h1/h2/h3
 	AddHeaderAnchor bool
 
+	// HeadingID, if set, returns id of a heading (h1/h2/h3), used in links
+	// to it. If not set or it returns "", the id is id of the block.
+	// See HeadingAnchors
+	HeadingID func(block *notionapi.Block) string
+
+	// DocsMarkup, if set, adds permalinks with labels for screen readers
+	// to headings (instead of AddHeaderAnchor) and renders table of
+	// contents as nested lists, with classes that can match a docs theme
+	DocsMarkup *DocsMarkup
+
 	// allows over-riding rendering of specific blocks
 	// return false for default rendering
 	RenderBlockOverride BlockRenderFunc
 
 	// RewriteURL allows re-writing URLs e.g. to convert inter-notion URLs
-	// to destination URLs
-	RewriteURL func(url string) string
+	// to destination URLs. It's called for every href and src we render,
+	// kind tells what the url is for
+	RewriteURL func(url string, kind URLKind) string
+
+	// DateLocale, if set, formats dates with month names and conventions
+	// of a language (e.g. notionapi.DateLocaleGerman) instead of English
+	DateLocale *notionapi.DateLocale
+	// FormatDateOverride, if set, formats dates instead of DateLocale
+	FormatDateOverride func(d *notionapi.Date) string
 
 	// Returns URL for a title cell (that links to a page)
 	TableTitleCellURLOverride func(tv *notionapi.TableView, row, col int) string
+	// Users, if set, is used to resolve names of users e.g. in mentions.
+	// Sharing one directory when rendering many pages allows resolving
+	// users that are not loaded with a page
+	Users *notionapi.UserDirectory
+
+	// RowPageURL, if set, returns URL of a page of a row of a collection,
+	// used as a link in the title column. If it returns "", we use
+	// the default URL
+	RowPageURL func(row *notionapi.Block) string
 
 	// if true, generates stand-alone HTML with inline CSS
 	// otherwise it's just the inner part going inside the body
 	FullHTML bool
 
+	// if true (and FullHTML is true), adds OpenGraph and article <meta>
+	// tags for sharing: title, description from the start of the page
+	// (see notionapi.Page.ExcerptText), cover as an image and times
+	// of creation and last edit
+	RenderMetaTags bool
+	// PageURL, if set, is the public url of the page, used in og:url
+	// and to resolve relative url of the cover
+	PageURL string
+
 	// we need this to properly render ordered and numbered lists
 	CurrBlocks   []*notionapi.Block
 	CurrBlockIdx int
@@ -282,9 +329,186 @@ type Converter struct {
 	// RenderBlockOverride
 	Data interface{}
 
+	// if true, renders a transcript in <details> below BlockAudio and
+	// BlockVideo. By default a transcript is a toggle block directly
+	// following the audio/video block whose title starts with "Transcript"
+	// or a caption link whose text is "Transcript"
+	RenderTranscripts bool
+
+	// if true, renders the (normally hidden) template content
+	// of BlockTemplateButton below the button
+	RenderTemplateButtonContent bool
+
+	// FindTranscript allows over-riding how we find a transcript for
+	// BlockAudio and BlockVideo. Children of returned block are rendered
+	// as transcript. Return nil if there's no transcript
+	FindTranscript func(block *notionapi.Block) *notionapi.Block
+
+	// if true, renders BlockTweet as Twitter's blockquote-based embed
+	// instead of a link
+	RenderTweetEmbed bool
+
+	// if true (and RenderTweetEmbed is true), adds <script> tag that
+	// loads Twitter's widgets.js, which turns the blockquote into a widget
+	IncludeTweetScript bool
+
+	// ClickToLoadEmbeds, if true, renders third-party content (iframes
+	// of EmbedProviders e.g. YouTube or Figma, maps and tweets) as static
+	// placeholder cards, so that nothing is loaded from third parties
+	// without user's consent. A card (div.embed-placeholder) has
+	// data-click-to-load attribute with the url to load (e.g. in an iframe)
+	// and links to the original url
+	ClickToLoadEmbeds bool
+	// EmbedVideos, if true, renders videos of EmbedProviders (e.g. YouTube)
+	// as iframes. By default they're rendered as links. Ignored when
+	// ClickToLoadEmbeds is set, which renders them as placeholders
+	EmbedVideos bool
+
+	// EmbedAllowedDomains, if not nil, limits iframes (and scripts of
+	// gists) to urls of those domains and their sub-domains. Embeds of
+	// other domains are rendered as links. An empty, non-nil list renders
+	// all embeds as links. Note that urls of iframes of EmbedProviders can
+	// differ from the original e.g. YouTube videos are embedded from
+	// youtube-nocookie.com. Urls are checked after RewriteURL
+	EmbedAllowedDomains []string
+
+	// if set, added as nonce attribute to <script> tags we generate,
+	// for Content-Security-Policy
+	ScriptNonce string
+
+	// StrictCSP, if true, guarantees output without inline styles, inline
+	// event handlers and scripts, so that it can be served with
+	// Content-Security-Policy without 'unsafe-inline'. Gists are rendered
+	// as links, tweets without widgets.js, equations as TeX source instead
+	// of KaTeX and sizes of images, embeds and columns are set with
+	// attributes and classes. With FullHTML, CSS is
+	// linked from StylesheetURL or, if not set, inlined only if ScriptNonce
+	// is set (as its nonce)
+	StrictCSP bool
+	// Indent, if not empty, formats HTML with block elements on separate
+	// lines, indented with Indent (e.g. two spaces) for each level of
+	// nesting. Text and inline elements are not changed. It's meant for
+	// reading and diffing HTML and doesn't change how the page looks: CSS
+	// sets white-space: pre-wrap for body so inside body lines are broken
+	// inside tags (e.g. "</p\n  ><p>") instead of adding text
+	Indent string
+	// Compact, if true, doesn't write newlines between elements
+	// (by default we write them after table rows). Ignored if Indent is set
+	Compact bool
+
+	// Sanitize, if true, makes output safe to show for pages with untrusted
+	// content: urls with schemes other than AllowedURLSchemes (e.g.
+	// javascript: or data:) are removed, gists are rendered as links
+	// and HTML from FetchTweetOEmbed is not used
+	Sanitize bool
+	// AllowedURLSchemes are url schemes allowed in Sanitize mode.
+	// If not set, we use DefaultAllowedURLSchemes. Relative urls
+	// are always allowed
+	AllowedURLSchemes []string
+	// StylesheetURL, if set, is the url of CSS (see CSS) that FullHTML
+	// links to instead of inlining it
+	StylesheetURL string
+
+	// FetchTweetOEmbed allows providing HTML for a tweet e.g. from
+	// Twitter's oEmbed API (see FetchTwitterOEmbed). If it returns an error
+	// or empty string, we render the blockquote ourselves
+	FetchTweetOEmbed func(tweetURL string) (string, error)
+
+	// RenderComments says if and how to render discussions on blocks.
+	// Comments must be downloaded (see Client.DownloadDiscussions)
+	RenderComments CommentsMode
+
+	// if true, also renders resolved discussions
+	RenderResolvedComments bool
+
+	// if true, renders the header of the root page as a hero with
+	// page cover as a background and page icon over it
+	RenderPageHero bool
+
+	// if true and the page is a row of a collection (database),
+	// renders its properties as a table at the top of the page
+	RenderRowProperties bool
+
+	// if true, files uploaded to Notion (images, attachments etc.) link to
+	// signed urls, resolved with Page.SignFileURLs, instead of local
+	// files. Signed urls can be accessed without credentials but are only
+	// valid for a limited time
+	SignFileURLs bool
+
+	// if true, signed urls (see notionapi.IsSignedURL) are never written
+	// to HTML because they carry credentials and expiry of the account
+	// that signed them. They're replaced by RewriteSignedURL or, if it's
+	// not set, stripped of signing parameters. Stripped urls don't work
+	// without signing so files must be served another way, e.g. by
+	// a proxy or from downloaded copies
+	RedactSignedURLs bool
+	// RewriteSignedURL returns an url to use instead of a signed url
+	// e.g. an url of a proxy or of a downloaded copy of the file
+	RewriteSignedURL func(uri string) string
+
+	// MaxDepth is the maximum nesting of blocks we render. Blocks nested
+	// deeper are not rendered and ToHTML returns an error.
+	// If 0, DefaultMaxDepth is used
+	MaxDepth int
+
+	// BreakLongWords, if > 0, inserts <wbr> into words (URLs, identifiers)
+	// longer than BreakLongWords characters so that they don't overflow
+	// narrow containers. See BreakLongWords function
+	BreakLongWords int
+	// SoftHyphens, if true, uses soft hyphens instead of <wbr> when breaking
+	// between letters, so that a hyphen is shown at the break
+	SoftHyphens bool
+
+	// MaxCollectionRows, if > 0, limits the number of rendered rows of
+	// collection views (inline databases). If there are more rows, we show
+	// a "View N more" link to CollectionViewAllURL
+	MaxCollectionRows int
+	// CollectionViewAllURL returns url of a page with all rows of
+	// a collection view block. If not set or it returns "", we only show
+	// the number of rows that were not rendered
+	CollectionViewAllURL func(block *notionapi.Block) string
+
+	// CollectionViewSelector, if set, returns index in block.TableViews
+	// of the view of a collection (database) to render. By default
+	// we render the first one. See CollectionViewIndex
+	CollectionViewSelector func(block *notionapi.Block) int
+
+	// ImageAlt, if set, returns alt text for an image block
+	ImageAlt func(block *notionapi.Block) string
+
+	// Lang is the language of the page e.g. "en". For FullHTML
+	// it's set as lang attribute of <html> element
+	Lang string
+	// DetectLanguage, if set, is called with text of each block and returns
+	// its language (e.g. "ja") or "" if not known. If it's different from
+	// the language of the parent block (or Lang), the block gets a lang
+	// attribute. DetectScriptLanguage is a simple detector
+	DetectLanguage func(text string) string
+
+	langStack []string
+
 	didImportKatexCSS bool
-	bufs              []*bytes.Buffer
-}
+	didAddTweetScript bool
+	// ListOrdinal of blocks, by the first block of CurrBlocks
+	listOrdinals map[*notionapi.Block][]int
+	// ids of blocks currently being rendered, to detect cycles
+	renderStack map[string]bool
+	depth       int
+	// first error encountered during rendering
+	renderErr error
+	// discussions to render at the end of the page
+	footnotes []*footnote
+	// data stashed with SetBlockData, by block id
+	blockData map[string]map[string]interface{}
+	// ids of blocks already rendered as transcripts
+	transcriptBlocks map[string]bool
+	bufs             []*bytes.Buffer
+	// a buffer that is no longer used, re-used by PushNewBuffer
+	freeBuf *bytes.Buffer
+}
+
+// DefaultMaxDepth is the default value of Converter.MaxDepth
+const DefaultMaxDepth = 128
 
 // NewConverter returns customizable HTML renderer
 func NewConverter(page *notionapi.Page) *Converter {
@@ -304,6 +528,13 @@ func (c *Converter) PageByID(pageID string) *notionapi.Page {
 // PushNewBuffer creates a new buffer and sets Buf to it
 func (c *Converter) PushNewBuffer() {
 	c.bufs = append(c.bufs, c.Buf)
+	if buf := c.freeBuf; buf != nil {
+		// re-use buffer we own to avoid allocations
+		buf.Reset()
+		c.Buf = buf
+		c.freeBuf = nil
+		return
+	}
 	c.Buf = &bytes.Buffer{}
 }
 
@@ -317,11 +548,11 @@ func (c *Converter) PopBuffer() *bytes.Buffer {
 }
 
 func (c *Converter) Printf(format string, args ...interface{}) {
-	s := format
-	if len(args) > 0 {
-		s = fmt.Sprintf(format, args...)
+	if len(args) == 0 {
+		c.Buf.WriteString(format)
+		return
 	}
-	c.Buf.WriteString(s)
+	fmt.Fprintf(c.Buf, format, args...)
 }
 
 // A writes <a></a> element to output
@@ -329,7 +560,7 @@ func (c *Converter) A(uri, text, cls string) {
 	// TODO: Notion seems to encode url but it's probably not correct
 	// (it encodes "&" as "&amp;")
 	// at best should only encoede as url
-	uri = EscapeHTML(uri)
+	uri = EscapeHTML(c.sanitizeURL(uri))
 	text = EscapeHTML(text)
 	if cls != "" {
 		cls = fmt.Sprintf(` class="%s"`, cls)
@@ -359,6 +590,27 @@ func (c *Converter) NextBlock() *notionapi.Block {
 	return c.CurrBlocks[nextIdx]
 }
 
+// ListOrdinal returns the number of the current block if it's
+// a BlockNumberedList item, 0 otherwise. Unlike ListNo, it's already
+// set when RenderBlockOverride is called
+func (c *Converter) ListOrdinal() int {
+	if c.CurrBlockIdx < 0 || c.CurrBlockIdx >= len(c.CurrBlocks) {
+		return 0
+	}
+	// computed once for all blocks of a list so that rendering
+	// a list of n items is O(n)
+	key := c.CurrBlocks[0]
+	ordinals := c.listOrdinals[key]
+	if len(ordinals) != len(c.CurrBlocks) {
+		ordinals = notionapi.ListOrdinals(c.CurrBlocks)
+		if c.listOrdinals == nil {
+			c.listOrdinals = map[*notionapi.Block][]int{}
+		}
+		c.listOrdinals[key] = ordinals
+	}
+	return ordinals[c.CurrBlockIdx]
+}
+
 // IsPrevBlockOfType returns true if previous block is of a given type
 func (c *Converter) IsPrevBlockOfType(t string) bool {
 	b := c.PrevBlock()
@@ -379,23 +631,48 @@ func (c *Converter) IsNextBlockOfType(t string) bool {
 
 // FormatDate formats the data
 func (c *Converter) FormatDate(d *notionapi.Date) string {
-	// TODO: allow over-riding date formatting
-	s := notionapi.FormatDate(d)
-	return fmt.Sprintf(`<time>@%s</time>`, s)
-}
+	var s string
+	if c.FormatDateOverride != nil {
+		s = c.FormatDateOverride(d)
+	} else {
+		s = notionapi.FormatDateLocale(d, c.DateLocale)
+	}
+	return fmt.Sprintf(`<time>@%s</time>`, EscapeHTML(s))
+}
+
+// URLKind tells RewriteURL what a url is for
+type URLKind string
+
+const (
+	// URLKindLink is a link to a web page e.g. inline link or a bookmark
+	URLKindLink URLKind = "link"
+	// URLKindPage is a link to a Notion page e.g. a mention, a sub-page
+	// or a breadcrumb
+	URLKindPage URLKind = "page"
+	// URLKindRow is a link to a page of a database row
+	URLKindRow URLKind = "row"
+	// URLKindImage is src of an image, page cover or icon
+	URLKindImage URLKind = "image"
+	// URLKindFile is a link to a file, audio, video or pdf
+	URLKindFile URLKind = "file"
+	// URLKindEmbed is src of an iframe or script of an embed
+	URLKindEmbed URLKind = "embed"
+)
 
 // RewrittenURL optionally transforms the url via the
 // function provided by the user
-func (c *Converter) RewrittenURL(uri string) string {
-	if c.RewriteURL != nil {
-		return c.RewriteURL(uri)
+func (c *Converter) RewrittenURL(uri string, kind URLKind) string {
+	if c.RewriteURL != nil && uri != "" {
+		uri = c.RewriteURL(uri, kind)
 	}
-	return uri
+	return c.sanitizeURL(uri)
 }
 
 // RenderInline renders inline block
 func (c *Converter) RenderInline(b *notionapi.TextSpan) {
-	var start, end string
+	// end tags are written in reverse order
+	var endsArr [4]string
+	ends := endsArr[:0]
 	text := b.Text
 	for i := range b.Attrs {
 		attr := b.Attrs[len(b.Attrs)-i-1]
@@ -403,20 +680,27 @@ func (c *Converter) RenderInline(b *notionapi.TextSpan) {
 		case notionapi.AttrHighlight:
 			// TODO: possibly needs to change b.Highlight
 			hl := notionapi.AttrGetHighlight(attr)
-			start += fmt.Sprintf(`<mark class="highlight-%s">`, hl)
-			end = `</mark>` + end
+			fmt.Fprintf(c.Buf, `<mark class="highlight-%s">`, EscapeHTML(hl))
+			ends = append(ends, `</mark>`)
 		case notionapi.AttrBold:
-			start += `<strong>`
-			end = `</strong>` + end
+			c.Buf.WriteString(`<strong>`)
+			ends = append(ends, `</strong>`)
 		case notionapi.AttrItalic:
-			start += `<em>`
-			end = `</em>` + end
+			c.Buf.WriteString(`<em>`)
+			ends = append(ends, `</em>`)
 		case notionapi.AttrStrikeThrought:
-			start += `<del>`
-			end = `</del>` + end
+			c.Buf.WriteString(`<del>`)
+			ends = append(ends, `</del>`)
+		case notionapi.AttrUnderline:
+			c.Buf.WriteString(`<u>`)
+			ends = append(ends, `</u>`)
 		case notionapi.AttrCode:
-			start += `<code>`
-			end = `</code>` + end
+			c.Buf.WriteString(`<code>`)
+			ends = append(ends, `</code>`)
+		case notionapi.AttrEquation:
+			tex := notionapi.AttrGetEquation(attr)
+			fmt.Fprintf(c.Buf, `<span class="equation-inline">%s</span>`, EscapeHTML(tex))
+			text = ""
 		case notionapi.AttrPage:
 			pageID := notionapi.AttrGetPageID(attr)
 			pageTitle := ""
@@ -430,31 +714,38 @@ func (c *Converter) RenderInline(b *notionapi.TextSpan) {
 				urlName = strings.Replace(urlName, " ", "-", -1)
 				relURL = urlName + "-" + relURL
 			}
-			uri := c.RewrittenURL("https://www.notion.so/" + relURL)
-			start += fmt.Sprintf(`<a href="%s">%s</a>`, uri, EscapeHTML(pageTitle))
+			uri := c.RewrittenURL("https://www.notion.so/"+relURL, URLKindPage)
+			fmt.Fprintf(c.Buf, `<a href="%s">%s</a>`, EscapeHTML(uri), EscapeHTML(pageTitle))
 			text = ""
 		case notionapi.AttrLink:
-			uri := c.RewrittenURL(notionapi.AttrGetLink(attr))
+			uri := c.RewrittenURL(notionapi.AttrGetLink(attr), URLKindLink)
 			if uri == "" {
-				start += `<a>`
+				c.Buf.WriteString(`<a>`)
 			} else {
 				// TODO: notion escapes url but it seems to be wrong
 				uri = EscapeHTML(uri)
-				start += fmt.Sprintf(`<a href="%s">`, uri)
+				fmt.Fprintf(c.Buf, `<a href="%s">`, uri)
 			}
-			end = `</a>` + end
+			ends = append(ends, `</a>`)
 		case notionapi.AttrUser:
 			userID := notionapi.AttrGetUserID(attr)
-			userName := notionapi.GetUserNameByID(c.Page, userID)
-			start += fmt.Sprintf(`<span class="user">@%s</span>`, userName)
+			userName := c.userNameByID(c.Page, userID)
+			fmt.Fprintf(c.Buf, `<span class="user">@%s</span>`, EscapeHTML(userName))
 			text = ""
 		case notionapi.AttrDate:
 			date := notionapi.AttrGetDate(attr)
-			start += c.FormatDate(date)
+			c.Buf.WriteString(c.FormatDate(date))
 			text = ""
 		}
 	}
-	c.Printf(start + EscapeHTML(text) + end)
+	if c.BreakLongWords > 0 {
+		c.Buf.WriteString(BreakLongWords(text, c.BreakLongWords, c.SoftHyphens))
+	} else {
+		htmlEscaper.WriteString(c.Buf, text)
+	}
+	for i := len(ends) - 1; i >= 0; i-- {
+		c.Buf.WriteString(ends[i])
+	}
 }
 
 // RenderInlines renders inline blocks
@@ -474,7 +765,10 @@ func (c *Converter) GetInlineContent(blocks []*notionapi.TextSpan) string {
 	for _, block := range blocks {
 		c.RenderInline(block)
 	}
-	return c.PopBuffer().String()
+	buf := c.PopBuffer()
+	s := buf.String()
+	c.freeBuf = buf
+	return s
 }
 
 // RenderCode renders BlockCode
@@ -494,14 +788,19 @@ func (c *Converter) RenderCode(block *notionapi.Block) {
 	c.Printf("</pre>")
 }
 
+// escapes like html.EscapeString but, like Notion, with &#x27;
+// and &quot;
+var htmlEscaper = strings.NewReplacer(
+	`&`, "&amp;",
+	`'`, "&#x27;",
+	`<`, "&lt;",
+	`>`, "&gt;",
+	`"`, "&quot;",
+)
+
 // EscapeHTML escapes HTML in the same way as Notion.
 func EscapeHTML(s string) string {
-	s = html.EscapeString(s)
-	// don't get why this is needed but it happens in
-	// https://www.notion.so/Blendle-s-Employee-Handbook-3b617da409454a52bc3a920ba8832bf7
-	s = strings.Replace(s, "&#39;", "&#x27;", -1)
-	s = strings.Replace(s, "&#34;", "&quot;", -1)
-	return s
+	return htmlEscaper.Replace(s)
 }
 
 func isURL(uri string) bool {
@@ -514,7 +813,48 @@ func isURL(uri string) bool {
 	return false
 }
 
+func (c *Converter) renderPageHero(block *notionapi.Block) {
+	cover := c.Page.Cover()
+	cls := "page-hero"
+	if cover != nil {
+		cls += " page-hero-with-cover"
+	}
+	c.Printf(`<header class="%s">`, cls)
+	{
+		if cover != nil {
+			coverURL := c.RewrittenURL(FilePathFromPageCoverURL(cover.URL, block), URLKindImage)
+			position := (1 - cover.Position) * 100
+			if c.StrictCSP {
+				c.Printf(`<div class="page-hero-cover"><img class="page-hero-cover-image" src="%s" alt="" data-position="%v"/></div>`, EscapeHTML(coverURL), position)
+			} else {
+				// ' would end url() early
+				coverURL = strings.Replace(coverURL, "'", "%27", -1)
+				style := fmt.Sprintf(`background-image:url('%s');background-position:center %v%%`, coverURL, position)
+				c.Printf(`<div class="page-hero-cover" style="%s"></div>`, EscapeHTML(style))
+			}
+		}
+		if icon := c.Page.Icon(); icon != nil {
+			c.Printf(`<div class="page-hero-icon">`)
+			if icon.URL != "" {
+				uri := c.fileURL(icon.URL, URLKindImage, block)
+				c.Printf(`<img class="icon" src="%s"/>`, EscapeHTML(uri))
+			} else {
+				c.Printf(`<span class="icon">%s</span>`, EscapeHTML(icon.Emoji))
+			}
+			c.Printf(`</div>`)
+		}
+		c.Printf(`<h1 class="page-title">`)
+		c.RenderInlines(block.InlineContent)
+		c.Printf(`</h1>`)
+	}
+	c.Printf(`</header>`)
+}
+
 func (c *Converter) renderPageHeader(block *notionapi.Block) {
+	if c.RenderPageHero && !c.NotionCompat {
+		c.renderPageHero(block)
+		return
+	}
 	c.Printf(`<header>`)
 	{
 		formatPage := block.FormatPage()
@@ -523,9 +863,14 @@ func (c *Converter) renderPageHeader(block *notionapi.Block) {
 		if pageCover != "" {
 			position := (1 - formatPage.PageCoverPosition) * 100
 			coverURL := FilePathFromPageCoverURL(pageCover, block)
+			coverURL = c.RewrittenURL(coverURL, URLKindImage)
 			// TODO: Notion incorrectly escapes them
 			coverURL = EscapeHTML(coverURL)
-			c.Printf(`<img class="page-cover-image" src="%s" style="object-position:center %v%%"/>`, coverURL, position)
+			if c.StrictCSP {
+				c.Printf(`<img class="page-cover-image" src="%s" data-position="%v"/>`, coverURL, position)
+			} else {
+				c.Printf(`<img class="page-cover-image" src="%s" style="object-position:center %v%%"/>`, coverURL, position)
+			}
 		}
 		pageIcon, _ := block.PropAsString("format.page_icon")
 		if pageIcon != "" {
@@ -536,10 +881,10 @@ func (c *Converter) renderPageHeader(block *notionapi.Block) {
 			}
 			c.Printf(`<div class="page-header-icon %s">`, clsCover)
 			if isURL(pageIcon) {
-				fileName := getDownloadedFileName(pageIcon, block)
-				c.Printf(`<img class="icon" src="%s"/>`, fileName)
+				fileName := c.fileURL(pageIcon, URLKindImage, block)
+				c.Printf(`<img class="icon" src="%s"/>`, EscapeHTML(fileName))
 			} else {
-				c.Printf(`<span class="icon">%s</span>`, pageIcon)
+				c.Printf(`<span class="icon">%s</span>`, EscapeHTML(pageIcon))
 			}
 			c.Printf(`</div>`)
 		}
@@ -561,32 +906,32 @@ func (c *Converter) RenderCollectionViewPage(block *notionapi.Block) {
 	name := col.GetName()
 	c.Printf(`<figure id="%s" class="link-to-page">`, block.ID)
 	{
-		filePath := filePathForCollection(c.Page, col)
-		c.Printf(`<a href="%s">`, filePath)
+		filePath := c.RewrittenURL(filePathForCollection(c.Page, col), URLKindPage)
+		c.Printf(`<a href="%s">`, EscapeHTML(filePath))
 		{
-			uri := getCollectionDownloadedFileName(c.Page, col, icon)
-			c.Printf(`<img class="icon" src="%s"/>`, uri)
+			uri := c.RewrittenURL(getCollectionDownloadedFileName(c.Page, col, icon), URLKindImage)
+			c.Printf(`<img class="icon" src="%s"/>`, EscapeHTML(uri))
 		}
 		// TODO: should name be inlines?
-		c.Printf(`%s</a>`, name)
+		c.Printf(`%s</a>`, EscapeHTML(name))
 	}
 	c.Printf(`</figure>`)
 }
 
 func (c *Converter) renderLinkToPageNotion(block *notionapi.Block) {
-	uri := filePathForPage(block)
+	uri := c.RewrittenURL(filePathForPage(block), URLKindPage)
 	cls := GetBlockColorClass(block) + " link-to-page"
 	cls = CleanAttributeValue(cls)
 	c.Printf(`<figure id="%s" class="%s">`, block.ID, cls)
 	{
-		c.Printf(`<a href="%s">`, uri)
+		c.Printf(`<a href="%s">`, EscapeHTML(uri))
 		pageIcon, ok := block.PropAsString("format.page_icon")
 		if ok {
 			if isURL(pageIcon) {
-				fileName := getDownloadedFileName(pageIcon, block)
-				c.Printf(`<img class="icon" src="%s"/>`, fileName)
+				fileName := c.fileURL(pageIcon, URLKindImage, block)
+				c.Printf(`<img class="icon" src="%s"/>`, EscapeHTML(fileName))
 			} else {
-				c.Printf(`<span class="icon">%s</span>`, pageIcon)
+				c.Printf(`<span class="icon">%s</span>`, EscapeHTML(pageIcon))
 			}
 		}
 		// TODO: possibly r.RenderInlines(block.InlineContent)
@@ -602,20 +947,20 @@ func (c *Converter) renderLinkToPage(block *notionapi.Block) {
 		return
 	}
 
-	uri := filePathForPage(block)
+	uri := c.RewrittenURL(filePathForPage(block), URLKindPage)
 	cls := GetBlockColorClass(block) + " link-to-page"
 	cls = CleanAttributeValue(cls)
 	c.Printf(`<div id="%s" class="%s">`, block.ID, cls)
 	{
 
-		c.Printf(`<a href="%s">`, uri)
+		c.Printf(`<a href="%s">`, EscapeHTML(uri))
 		pageIcon, ok := block.PropAsString("format.page_icon")
 		if ok {
 			if isURL(pageIcon) {
-				fileName := getDownloadedFileName(pageIcon, block)
-				c.Printf(`<img class="icon" src="%s"/>`, fileName)
+				fileName := c.fileURL(pageIcon, URLKindImage, block)
+				c.Printf(`<img class="icon" src="%s"/>`, EscapeHTML(fileName))
 			} else {
-				c.Printf(`<span class="icon">%s</span>`, pageIcon)
+				c.Printf(`<span class="icon">%s</span>`, EscapeHTML(pageIcon))
 			}
 		}
 		// TODO: possibly r.RenderInlines(block.InlineContent)
@@ -625,15 +970,34 @@ func (c *Converter) renderLinkToPage(block *notionapi.Block) {
 	c.Printf(`</div>`)
 }
 
+// renderStylesheet renders CSS for FullHTML
+func (c *Converter) renderStylesheet() {
+	switch {
+	case c.StylesheetURL != "":
+		c.Printf(`<link rel="stylesheet" href="%s"/>`, EscapeHTML(c.StylesheetURL))
+	case !c.StrictCSP:
+		c.Printf("<style>%s</style>", CSS)
+	case c.ScriptNonce != "":
+		c.Printf("<style%s>%s</style>", c.nonceAttr(), CSS)
+	}
+}
+
 func (c *Converter) renderRootPage(block *notionapi.Block) {
 	if c.FullHTML {
-		c.Printf(`<html>`)
+		if c.Lang != "" {
+			c.Printf(`<html lang="%s">`, EscapeHTML(c.Lang))
+		} else {
+			c.Printf(`<html>`)
+		}
 		{
 			c.Printf(`<head>`)
 			{
 				c.Printf(`<meta http-equiv="Content-Type" content="text/html; charset=utf-8"/>`)
 				c.Printf(`<title>%s</title>`, EscapeHTML(block.Title))
-				c.Printf("<style>%s\t\n</style>", CSS)
+				if c.RenderMetaTags {
+					c.renderMetaTags(block)
+				}
+				c.renderStylesheet()
 			}
 			c.Printf(`</head>`)
 		}
@@ -649,11 +1013,15 @@ func (c *Converter) renderRootPage(block *notionapi.Block) {
 	}
 	c.Printf(`<article id="%s" class="page %s">`, block.ID, clsFont)
 	c.renderPageHeader(block)
+	if c.RenderRowProperties {
+		c.renderRowProperties(block)
+	}
 	{
 		c.Printf(`<div class="page-body">`)
 		c.RenderChildren(block)
 		c.Printf(`</div>`)
 	}
+	c.renderFootnotes()
 	c.Printf(`</article>`)
 
 	if c.FullHTML {
@@ -705,37 +1073,11 @@ func (c *Converter) RenderText(block *notionapi.Block) {
 	c.Printf(`</div>`)
 }
 
-func equationToHTML(katexPath string, equation string) (string, error) {
-	cmd := exec.Command(katexPath, "-d")
-	stdin, err := cmd.StdinPipe()
-	if err != nil {
-		return "", err
-	}
-	var out bytes.Buffer
-	cmd.Stdout = &out
-	err = cmd.Start()
-	if err != nil {
-		return "", err
-	}
-	_, err = stdin.Write([]byte(equation))
-	if err != nil {
-		_ = cmd.Process.Kill()
-		return "", err
-	}
-	err = stdin.Close()
-	if err != nil {
-		return "", err
-	}
-	if err = cmd.Wait(); err != nil {
-		return "", err
-	}
-	res := out.String()
-	return res, nil
-}
-
 // RenderEquation renders BlockEquation
 func (c *Converter) RenderEquation(block *notionapi.Block) {
-	if !c.UseKatexToRenderEquation {
+	// KaTeX output relies on inline styles so in StrictCSP mode we render
+	// TeX source as text
+	if !c.UseKatexToRenderEquation || c.StrictCSP {
 		c.Printf(`<figure id="%s" class="equation">`, block.ID)
 		c.RenderInlines(block.InlineContent)
 		c.Printf(`</figure>`)
@@ -753,7 +1095,7 @@ func (c *Converter) RenderEquation(block *notionapi.Block) {
 
 	c.Printf(`<figure id="%s" class="equation">`, block.ID)
 	{
-		if !c.didImportKatexCSS {
+		if !c.didImportKatexCSS && !notionapi.Offline {
 			c.Printf(`<style>@import url('https://cdnjs.cloudflare.com/ajax/libs/KaTeX/0.10.0/katex.min.css')</style>`)
 			c.didImportKatexCSS = true
 		}
@@ -770,11 +1112,7 @@ func (c *Converter) RenderEquation(block *notionapi.Block) {
 // RenderNumberedList renders BlockNumberedList
 func (c *Converter) RenderNumberedList(block *notionapi.Block) {
 	isPrevSame := c.IsPrevBlockOfType(notionapi.BlockNumberedList)
-	if isPrevSame {
-		c.ListNo++
-	} else {
-		c.ListNo = 1
-	}
+	c.ListNo = c.ListOrdinal()
 
 	cls := GetBlockColorClass(block) + " numbered-list"
 	cls = CleanAttributeValue(cls)
@@ -823,10 +1161,12 @@ func (c *Converter) RenderBulletedList(block *notionapi.Block) {
 // RenderHeaderLevel renders BlockHeader, SubHeader and SubSubHeader
 func (c *Converter) RenderHeaderLevel(block *notionapi.Block, level int) {
 	cls := GetBlockColorClass(block)
-	c.Printf(`<h%d id="%s" class="%s">`, level, block.ID, cls)
+	c.Printf(`<h%d id="%s" class="%s">`, level, c.headingID(block), cls)
 	c.RenderInlines(block.InlineContent)
-	if c.AddHeaderAnchor {
-		c.Printf(`<a class="header-anchor" href="#%s" aria-hidden="true"><svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 8 8"><path d="M5.88.03c-.18.01-.36.03-.53.09-.27.1-.53.25-.75.47a.5.5 0 1 0 .69.69c.11-.11.24-.17.38-.22.35-.12.78-.07 1.06.22.39.39.39 1.04 0 1.44l-1.5 1.5c-.44.44-.8.48-1.06.47-.26-.01-.41-.13-.41-.13a.5.5 0 1 0-.5.88s.34.22.84.25c.5.03 1.2-.16 1.81-.78l1.5-1.5c.78-.78.78-2.04 0-2.81-.28-.28-.61-.45-.97-.53-.18-.04-.38-.04-.56-.03zm-2 2.31c-.5-.02-1.19.15-1.78.75l-1.5 1.5c-.78.78-.78 2.04 0 2.81.56.56 1.36.72 2.06.47.27-.1.53-.25.75-.47a.5.5 0 1 0-.69-.69c-.11.11-.24.17-.38.22-.35.12-.78.07-1.06-.22-.39-.39-.39-1.04 0-1.44l1.5-1.5c.4-.4.75-.45 1.03-.44.28.01.47.09.47.09a.5.5 0 1 0 .44-.88s-.34-.2-.84-.22z"></path></svg></a>`, block.ID)
+	if c.DocsMarkup != nil {
+		c.renderPermalink(block)
+	} else if c.AddHeaderAnchor {
+		c.Printf(`<a class="header-anchor" href="#%s" aria-hidden="true"><svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 8 8"><path d="M5.88.03c-.18.01-.36.03-.53.09-.27.1-.53.25-.75.47a.5.5 0 1 0 .69.69c.11-.11.24-.17.38-.22.35-.12.78-.07 1.06.22.39.39.39 1.04 0 1.44l-1.5 1.5c-.44.44-.8.48-1.06.47-.26-.01-.41-.13-.41-.13a.5.5 0 1 0-.5.88s.34.22.84.25c.5.03 1.2-.16 1.81-.78l1.5-1.5c.78-.78.78-2.04 0-2.81-.28-.28-.61-.45-.97-.53-.18-.04-.38-.04-.56-.03zm-2 2.31c-.5-.02-1.19.15-1.78.75l-1.5 1.5c-.78.78-.78 2.04 0 2.81.56.56 1.36.72 2.06.47.27-.1.53-.25.75-.47a.5.5 0 1 0-.69-.69c-.11.11-.24.17-.38.22-.35.12-.78.07-1.06-.22-.39-.39-.39-1.04 0-1.44l1.5-1.5c.4-.4.75-.45 1.03-.44.28.01.47.09.47.09a.5.5 0 1 0 .44-.88s-.34-.2-.84-.22z"></path></svg></a>`, c.headingID(block))
 	}
 	c.Printf(`</h%d>`, level)
 }
@@ -922,17 +1262,23 @@ func CleanAttributeValue(v string) string {
 func (c *Converter) RenderCallout(block *notionapi.Block) {
 	cls := GetBlockColorClass(block) + " callout"
 	cls = CleanAttributeValue(cls)
-	c.Printf(`<figure class="%s" style="white-space:pre-wrap;display:flex" id="%s">`, cls, block.ID)
-	{
+	if c.StrictCSP {
+		c.Printf(`<figure class="%s" id="%s">`, cls, block.ID)
+		c.Printf(`<div class="callout-icon">`)
+	} else {
+		c.Printf(`<figure class="%s" style="white-space:pre-wrap;display:flex" id="%s">`, cls, block.ID)
 		c.Printf(`<div style="font-size:1.5em">`)
-		{
-			pageIcon, _ := block.PropAsString("format.page_icon")
-			c.Printf(`<span class="icon">%s</span>`, pageIcon)
-		}
+	}
+	{
+		c.Printf(`<span class="icon">%s</span>`, EscapeHTML(block.AsCallout().Icon))
 		c.Printf(`</div>`)
 
 		{
-			c.Printf("%s", `<div style="width:100%">`)
+			if c.StrictCSP {
+				c.Printf(`<div class="callout-text">`)
+			} else {
+				c.Printf("%s", `<div style="width:100%">`)
+			}
 			c.RenderInlines(block.InlineContent)
 			c.Printf(`</div>`)
 		}
@@ -940,41 +1286,6 @@ func (c *Converter) RenderCallout(block *notionapi.Block) {
 	c.Printf(`</figure>`)
 }
 
-func isHeaderBlock(block *notionapi.Block) bool {
-	switch block.Type {
-	case notionapi.BlockHeader, notionapi.BlockSubHeader, notionapi.BlockSubSubHeader:
-		return true
-	}
-	return false
-}
-
-func getHeaderBlocks(blocks []*notionapi.Block, seen map[string]bool) []*notionapi.Block {
-	var res []*notionapi.Block
-	for i, b := range blocks {
-		id := b.ID
-		if seen[id] {
-			// avoid infinite recursion in processing a page
-			// it happens in e.g. 3df846eaf0404fe6b012208773063a04
-			logf("getHeaderBlocks: already seen block %s of type %s in page %s. Array pos %d out of %d\n", b.ID, b.Type, b.Page.ID, i, len(blocks))
-			continue
-		}
-		seen[id] = true
-		if isHeaderBlock(b) {
-			res = append(res, b)
-			continue
-		}
-		if b.Type == notionapi.BlockPage || b.Type == notionapi.BlockCollectionViewPage {
-			continue
-		}
-		if len(b.Content) == 0 {
-			continue
-		}
-		sub := getHeaderBlocks(b.Content, seen)
-		res = append(res, sub...)
-	}
-	return res
-}
-
 func cmpBlockTypes(prev, curr string) int {
 	if prev == curr {
 		return 0
@@ -1006,19 +1317,21 @@ func adjustIndent(blocks []*notionapi.Block, i int) int {
 
 // RenderTableOfContents renders BlockTableOfContents
 func (c *Converter) RenderTableOfContents(block *notionapi.Block) {
+	blocks := c.Page.Outline().Headings()
+	if c.DocsMarkup != nil {
+		c.renderDocsTOC(block, blocks)
+		return
+	}
 	cls := GetBlockColorClass(block) + " table_of_contents"
 	cls = CleanAttributeValue(cls)
 	c.Printf(`<nav id="%s" class="%s">`, block.ID, cls)
-	root := c.Page.Root()
-	seen := map[string]bool{}
-	blocks := getHeaderBlocks(root.Content, seen)
 	indent := 0
 	for i, b := range blocks {
 		indent += adjustIndent(blocks, i)
 		s := c.GetInlineContent(b.InlineContent)
 		c.Printf(`<div class="table_of_contents-item table_of_contents-indent-%d">`, indent)
 		{
-			c.Printf(`<a class="table_of_contents-link" href="#%s">%s</a>`, b.ID, s)
+			c.Printf(`<a class="table_of_contents-link" href="#%s">%s</a>`, c.headingID(b), s)
 		}
 		c.Printf(`</div>`)
 	}
@@ -1041,8 +1354,52 @@ func (c *Converter) RenderCaption(block *notionapi.Block) {
 	c.Printf(`</figcaption>`)
 }
 
-// RenderBookmark renders BlockBookmark
+// RenderBookmark renders BlockBookmark as a preview card with title,
+// description, icon and cover image
 func (c *Converter) RenderBookmark(block *notionapi.Block) {
+	if c.NotionCompat {
+		c.renderBookmarkNotion(block)
+		return
+	}
+	uri := block.Link
+	title := block.Title
+	if title == "" {
+		title = uri
+	}
+	f := block.AsBookmark().Format
+	icon, cover := f.Icon, f.Cover
+	cls := GetBlockColorClass(block) + " bookmark source"
+	cls = CleanAttributeValue(cls)
+	c.Printf(`<figure id="%s">`, block.ID)
+	{
+		c.Printf(`<a class="%s" href="%s">`, cls, EscapeHTML(c.RewrittenURL(uri, URLKindLink)))
+		{
+			c.Printf(`<div class="bookmark-info">`)
+			{
+				c.Printf(`<div class="bookmark-text">`)
+				c.Printf(`<div class="bookmark-title">%s</div>`, EscapeHTML(title))
+				if block.Description != "" {
+					c.Printf(`<div class="bookmark-description">%s</div>`, EscapeHTML(block.Description))
+				}
+				c.Printf(`</div>`)
+				c.Printf(`<div class="bookmark-href">`)
+				if icon != "" {
+					c.Printf(`<img class="icon bookmark-icon" src="%s"/>`, EscapeHTML(c.RewrittenURL(icon, URLKindImage)))
+				}
+				c.Printf(`%s</div>`, EscapeHTML(uri))
+			}
+			c.Printf(`</div>`)
+			if cover != "" {
+				c.Printf(`<img class="bookmark-image" src="%s"/>`, EscapeHTML(c.RewrittenURL(cover, URLKindImage)))
+			}
+		}
+		c.Printf(`</a>`)
+		c.RenderCaption(block)
+	}
+	c.Printf(`</figure>`)
+}
+
+func (c *Converter) renderBookmarkNotion(block *notionapi.Block) {
 	c.Printf(`<figure id="%s">`, block.ID)
 	{
 		cls := GetBlockColorClass(block) + " bookmark source"
@@ -1061,6 +1418,76 @@ func (c *Converter) RenderBookmark(block *notionapi.Block) {
 	c.Printf(`</figure>`)
 }
 
+func isTranscriptTitle(s string) bool {
+	s = strings.ToLower(strings.TrimSpace(s))
+	return strings.HasPrefix(s, "transcript")
+}
+
+// finds a transcript toggle block following audio/video block
+func (c *Converter) findTranscriptBlock(block *notionapi.Block) *notionapi.Block {
+	if c.FindTranscript != nil {
+		return c.FindTranscript(block)
+	}
+	next := c.NextBlock()
+	if next == nil || next.Type != notionapi.BlockToggle {
+		return nil
+	}
+	title := notionapi.TextSpansToString(next.InlineContent)
+	if !isTranscriptTitle(title) {
+		return nil
+	}
+	return next
+}
+
+// finds a caption link to a transcript, returns "" if not found
+func (c *Converter) findTranscriptLink(block *notionapi.Block) string {
+	for _, ts := range block.GetCaption() {
+		if !isTranscriptTitle(ts.Text) {
+			continue
+		}
+		for _, attr := range ts.Attrs {
+			switch notionapi.AttrGetType(attr) {
+			case notionapi.AttrLink:
+				return c.RewrittenURL(notionapi.AttrGetLink(attr), URLKindLink)
+			case notionapi.AttrPage:
+				pageID := notionapi.ToNoDashID(notionapi.AttrGetPageID(attr))
+				return c.RewrittenURL("https://www.notion.so/"+pageID, URLKindPage)
+			}
+		}
+	}
+	return ""
+}
+
+func (c *Converter) renderTranscript(block *notionapi.Block) {
+	if !c.RenderTranscripts {
+		return
+	}
+	transcript := c.findTranscriptBlock(block)
+	uri := ""
+	if transcript == nil {
+		uri = c.findTranscriptLink(block)
+		if uri == "" {
+			return
+		}
+	}
+	c.Printf(`<details class="transcript">`)
+	{
+		c.Printf(`<summary>Transcript</summary>`)
+		if transcript != nil {
+			if c.transcriptBlocks == nil {
+				c.transcriptBlocks = map[string]bool{}
+			}
+			c.transcriptBlocks[transcript.ID] = true
+			c.Printf(`<div class="transcript-content">`)
+			c.RenderChildren(transcript)
+			c.Printf(`</div>`)
+		} else {
+			c.A(uri, "Read transcript", "transcript-link")
+		}
+	}
+	c.Printf(`</details>`)
+}
+
 // RenderAudio renders BlockAudio
 func (c *Converter) RenderAudio(block *notionapi.Block) {
 	c.Printf(`<figure id="%s">`, block.ID)
@@ -1068,10 +1495,7 @@ func (c *Converter) RenderAudio(block *notionapi.Block) {
 		c.Printf(`<div class="source">`)
 		{
 			source := block.Source
-			fileName := source
-			if len(block.FileIDs) > 0 {
-				fileName = getDownloadedFileName(source, block)
-			}
+			fileName := c.fileOrSourceURL(block, URLKindFile)
 			if source == "" {
 				c.Printf(`<a></a>`)
 			} else {
@@ -1080,21 +1504,24 @@ func (c *Converter) RenderAudio(block *notionapi.Block) {
 		}
 		c.Printf(`</div>`)
 		c.RenderCaption(block)
+		c.renderTranscript(block)
 	}
 	c.Printf(`</figure>`)
 }
 
 // RenderVideo renders BlockVideo
 func (c *Converter) RenderVideo(block *notionapi.Block) {
+	// videos uploaded to Notion are files, not embeds
+	useProvider := c.ClickToLoadEmbeds || c.EmbedVideos
+	if useProvider && len(block.FileIDs) == 0 && c.renderProviderEmbed(block) {
+		return
+	}
 	c.Printf(`<figure id="%s">`, block.ID)
 	{
 		c.Printf(`<div class="source">`)
 		{
 			source := block.Source
-			fileName := source
-			if len(block.FileIDs) > 0 {
-				fileName = getDownloadedFileName(source, block)
-			}
+			fileName := c.fileOrSourceURL(block, URLKindFile)
 			if source == "" {
 				c.Printf(`<a></a>`)
 			} else {
@@ -1103,6 +1530,7 @@ func (c *Converter) RenderVideo(block *notionapi.Block) {
 		}
 		c.Printf(`</div>`)
 		c.RenderCaption(block)
+		c.renderTranscript(block)
 	}
 	c.Printf(`</figure>`)
 }
@@ -1113,7 +1541,7 @@ func (c *Converter) renderEmbed(block *notionapi.Block) {
 		c.Printf(`<div class="source">`)
 		{
 			uri := block.Source
-			c.A(uri, uri, "")
+			c.A(c.RewrittenURL(uri, URLKindLink), uri, "")
 		}
 		c.Printf(`</div>`)
 		c.RenderCaption(block)
@@ -1123,11 +1551,14 @@ func (c *Converter) renderEmbed(block *notionapi.Block) {
 
 // RenderEmbed renders BlockEmbed
 func (c *Converter) RenderEmbed(block *notionapi.Block) {
+	if c.renderProviderEmbed(block) {
+		return
+	}
 	c.Printf(`<figure id="%s">`, block.ID)
 	{
 		c.Printf(`<div class="source">`)
 		{
-			uri := getFileOrSourceURL(block)
+			uri := c.fileOrSourceURL(block, URLKindLink)
 			text := block.Source
 			c.A(uri, text, "")
 		}
@@ -1137,42 +1568,136 @@ func (c *Converter) RenderEmbed(block *notionapi.Block) {
 	c.Printf(`</figure>`)
 }
 
+func (c *Converter) nonceAttr() string {
+	if c.ScriptNonce == "" {
+		return ""
+	}
+	return fmt.Sprintf(` nonce="%s"`, CleanAttributeValue(c.ScriptNonce))
+}
+
+func (c *Converter) renderTweetScript() {
+	if !c.IncludeTweetScript || c.didAddTweetScript || c.StrictCSP || notionapi.Offline {
+		return
+	}
+	c.Printf(`<script async src="https://platform.twitter.com/widgets.js" charset="utf-8"%s></script>`, c.nonceAttr())
+	c.didAddTweetScript = true
+}
+
 // RenderTweet renders BlockTweet
 func (c *Converter) RenderTweet(block *notionapi.Block) {
-	c.renderEmbed(block)
+	if c.NotionCompat || !c.RenderTweetEmbed {
+		c.renderEmbed(block)
+		return
+	}
+	uri := block.Source
+	c.Printf(`<figure id="%s" class="tweet">`, block.ID)
+	if c.ClickToLoadEmbeds {
+		c.renderEmbedPlaceholder("twitter", EscapeHTML(uri), uri, 0, 0)
+		c.RenderCaption(block)
+		c.Printf(`</figure>`)
+		return
+	}
+	{
+		html := ""
+		// oEmbed html includes <script>
+		if c.FetchTweetOEmbed != nil && !c.StrictCSP && !c.Sanitize {
+			var err error
+			html, err = c.FetchTweetOEmbed(uri)
+			if err != nil {
+				html = ""
+			}
+		}
+		if html != "" {
+			c.Printf("%s", html)
+		} else {
+			c.Printf(`<blockquote class="twitter-tweet">`)
+			c.A(uri, uri, "")
+			c.Printf(`</blockquote>`)
+		}
+		c.renderTweetScript()
+		c.RenderCaption(block)
+	}
+	c.Printf(`</figure>`)
 }
 
 // RenderGist renders BlockGist
 func (c *Converter) RenderGist(block *notionapi.Block) {
-	if c.NotionCompat {
+	if c.NotionCompat || c.StrictCSP || c.Sanitize {
 		c.renderEmbed(block)
-	} else {
-		uri := block.Source + ".js"
-		// TODO: support caption
-		// TODO: maybe support comments
-		// TODO: quote uri
-		c.Printf(`<script src="%s", class="notion-embed-gist"></script>`, uri)
+		return
 	}
+	uri, ok := c.embedURL(block.Source + ".js")
+	if !ok {
+		c.renderEmbed(block)
+		return
+	}
+	// TODO: support caption
+	// TODO: maybe support comments
+	c.Printf(`<script src="%s", class="notion-embed-gist"></script>`, EscapeHTML(uri))
 }
 
 // RenderCodepen renders BlockCodepen
 func (c *Converter) RenderCodepen(block *notionapi.Block) {
+	if c.renderProviderEmbed(block) {
+		return
+	}
 	c.renderEmbed(block)
 }
 
+// returns style attribute value for an iframe of a given size
+func iframeStyle(width, height float64, fullWidth bool) string {
+	var parts []string
+	if fullWidth || width == 0 {
+		parts = append(parts, "width:100%")
+	} else {
+		parts = append(parts, fmt.Sprintf("width:%dpx", int(width)))
+	}
+	if height > 0 {
+		parts = append(parts, fmt.Sprintf("height:%dpx", int(height)))
+	}
+	return strings.Join(parts, ";")
+}
+
 // RenderMaps renders BlockMaps
 func (c *Converter) RenderMaps(block *notionapi.Block) {
-	c.renderEmbed(block)
+	f := block.FormatMaps()
+	uri, ok := "", false
+	if !c.NotionCompat && f != nil && f.DisplaySource != "" {
+		uri, ok = c.embedURL(f.DisplaySource)
+	}
+	if !ok {
+		// no embeddable url, fallback to a link to the map
+		c.renderEmbed(block)
+		return
+	}
+	c.Printf(`<figure id="%s" class="maps">`, block.ID)
+	{
+		uri = EscapeHTML(uri)
+		if c.ClickToLoadEmbeds {
+			c.renderEmbedPlaceholder("maps", uri, block.Source, f.BlockWidth, f.BlockHeight)
+		} else {
+			size := c.iframeSizeAttrs(f.BlockWidth, f.BlockHeight, f.BlockFullWidth || f.BlockPageWidth)
+			c.Printf(`<iframe src="%s"%s frameborder="0" loading="lazy" allowfullscreen=""></iframe>`, uri, size)
+		}
+		c.Printf(`<div class="source">`)
+		c.A(c.RewrittenURL(block.Source, URLKindLink), block.Source, "")
+		c.Printf(`</div>`)
+		c.RenderCaption(block)
+	}
+	c.Printf(`</figure>`)
 }
 
 // RenderFigma renders BlockFigma
 func (c *Converter) RenderFigma(block *notionapi.Block) {
+	if c.renderProviderEmbed(block) {
+		return
+	}
 	c.Printf(`<figure id="%s">`, block.ID)
 	{
 		c.Printf(`<div class="source">`)
 		{
 			uri := block.Source
-			c.Printf(`<a href="%s">%s</a>`, uri, uri)
+			c.A(c.RewrittenURL(uri, URLKindLink), uri, "")
 		}
 
 		c.Printf(`</div>`)
@@ -1187,7 +1712,7 @@ func (c *Converter) RenderFile(block *notionapi.Block) {
 	{
 		c.Printf(`<div class="source">`)
 		{
-			uri := getDownloadedFileName(block.Source, block)
+			uri := c.fileURL(block.Source, URLKindFile, block)
 			c.A(uri, block.Source, "")
 		}
 		c.Printf(`</div>`)
@@ -1196,20 +1721,48 @@ func (c *Converter) RenderFile(block *notionapi.Block) {
 	c.Printf(`</figure>`)
 }
 
-// RenderDrive renders BlockDrive
+// getFileCardInfo returns name, icon and url of a file for BlockDrive
+// and BlockDropbox. For Drive they come from format.drive_properties,
+// otherwise we use title and source
+func getFileCardInfo(block *notionapi.Block) (string, string, string) {
+	var name, icon, uri string
+	if block.Type == notionapi.BlockDrive {
+		if f := block.FormatDrive(); f != nil && f.DriveProperties != nil {
+			dp := f.DriveProperties
+			name, icon, uri = dp.Title, dp.Icon, dp.URL
+		}
+	}
+	if uri == "" {
+		uri = block.Source
+	}
+	if name == "" {
+		name = notionapi.TextSpansToString(block.InlineContent)
+	}
+	if name == "" {
+		name = uri
+	}
+	return name, icon, uri
+}
+
+// RenderDrive renders BlockDrive and BlockDropbox as a file card
 func (c *Converter) RenderDrive(block *notionapi.Block) {
+	name, icon, uri := getFileCardInfo(block)
+	uri = c.RewrittenURL(uri, URLKindLink)
+	cls := "bookmark source file-card file-card-" + block.Type
 	c.Printf(`<figure id="%s">`, block.ID)
 	{
-		c.Printf(`<div class="bookmark source">`)
+		c.Printf(`<div class="%s">`, cls)
 		{
-			icon, _ := block.PropAsString("format.drive_properties.icon")
-			c.Printf(`<img style="width:1em;height:1em;margin-right:0.5em;vertical-align:text-bottom" src="%s"/>`, icon)
-
-			docURL, _ := block.PropAsString("format.drive_properties.url")
-			title, _ := block.PropAsString("format.drive_properties.title")
-			c.Printf(`<a href="%s">%s</a>`, docURL, title)
+			if icon != "" {
+				if c.StrictCSP {
+					c.Printf(`<img class="file-card-icon" src="%s"/>`, EscapeHTML(c.RewrittenURL(icon, URLKindImage)))
+				} else {
+					c.Printf(`<img style="width:1em;height:1em;margin-right:0.5em;vertical-align:text-bottom" src="%s"/>`, EscapeHTML(c.RewrittenURL(icon, URLKindImage)))
+				}
+			}
+			c.A(uri, name, "")
 			c.Printf(`<br/>`)
-			c.Printf(`<a class="bookmark-href" href="%s">%s</a>`, docURL, docURL)
+			c.A(uri, uri, "bookmark-href")
 		}
 		c.Printf(`</div>`)
 		c.RenderCaption(block)
@@ -1222,7 +1775,7 @@ func (c *Converter) RenderPDF(block *notionapi.Block) {
 	c.Printf(`<figure id="%s">`, block.ID)
 	{
 		c.Printf(`<div class="source">`)
-		uri := getDownloadedFileName(block.Source, block)
+		uri := c.fileURL(block.Source, URLKindFile, block)
 		c.A(uri, block.Source, "")
 		c.Printf(`</div>`)
 		c.RenderCaption(block)
@@ -1238,14 +1791,35 @@ func getImageStyle(block *notionapi.Block) string {
 	return fmt.Sprintf(`style="width:%dpx" `, int(f.BlockWidth))
 }
 
+// like getImageStyle but sets the width with an attribute
+func getImageWidthAttr(block *notionapi.Block) string {
+	f := block.FormatImage()
+	if f == nil || f.BlockWidth == 0 {
+		return ""
+	}
+	return fmt.Sprintf(`width="%d" `, int(f.BlockWidth))
+}
+
 // RenderImage renders BlockImage
 func (c *Converter) RenderImage(block *notionapi.Block) {
 	c.Printf(`<figure id="%s" class="image">`, block.ID)
 	{
-		uri := getFileOrSourceURL(block)
+		uri := c.fileOrSourceURL(block, URLKindImage)
 		style := getImageStyle(block)
+		if c.StrictCSP {
+			style = getImageWidthAttr(block)
+		}
+		uri = EscapeHTML(uri)
 		c.Printf(`<a href="%s">`, uri)
-		c.Printf(`<img %ssrc="%s"/>`, style, uri)
+		alt := ""
+		if c.ImageAlt != nil {
+			alt = c.ImageAlt(block)
+		}
+		if alt != "" {
+			c.Printf(`<img %ssrc="%s" alt="%s"/>`, style, uri, EscapeHTML(alt))
+		} else {
+			c.Printf(`<img %ssrc="%s"/>`, style, uri)
+		}
 		c.Printf(`</a>`)
 
 		c.RenderCaption(block)
@@ -1261,7 +1835,9 @@ func (c *Converter) RenderColumnList(block *notionapi.Block) {
 		maybePanic("has no columns")
 		return
 	}
-	c.Printf(`<div id="%s" class="column-list">`, block.ID)
+	// data-column-count allows custom CSS to stack columns differently
+	// depending on their number
+	c.Printf(`<div id="%s" class="column-list" data-column-count="%d">`, block.ID, nColumns)
 	c.RenderChildren(block)
 	c.Printf(`</div>`)
 }
@@ -1269,16 +1845,80 @@ func (c *Converter) RenderColumnList(block *notionapi.Block) {
 // RenderColumn renders BlockColumn
 // Its parent is BlockColumnList
 func (c *Converter) RenderColumn(block *notionapi.Block) {
-	var colRatio float64 = 50
-	fc := block.FormatColumn()
-	if fc != nil {
-		colRatio = fc.ColumnRatio * 100
+	if c.StrictCSP {
+		ratio := ColumnRatio(block)
+		c.Printf(`<div id="%s" class="column %s" data-column-ratio="%s">`, block.ID, columnGrowClass(ratio), strconv.FormatFloat(ratio, 'f', 4, 64))
+		c.RenderChildren(block)
+		c.Printf("</div>")
+		return
 	}
-	c.Printf(`<div id="%s" style="width:%v%%" class="column">`, block.ID, colRatio)
+	if c.NotionCompat {
+		var colRatio float64 = 50
+		fc := block.FormatColumn()
+		if fc != nil {
+			colRatio = fc.ColumnRatio * 100
+		}
+		c.Printf(`<div id="%s" style="width:%v%%" class="column" data-column-ratio="%s">`, block.ID, colRatio, strconv.FormatFloat(ColumnRatio(block), 'f', 4, 64))
+		c.RenderChildren(block)
+		c.Printf("</div>")
+		return
+	}
+	// flex-grow with flex-basis of 0 keeps the proportions
+	// regardless of padding between columns
+	ratio := strconv.FormatFloat(ColumnRatio(block), 'f', 4, 64)
+	c.Printf(`<div id="%s" style="flex:%s 1 0;--column-ratio:%s" class="column" data-column-ratio="%s">`, block.ID, ratio, ratio, ratio)
 	c.RenderChildren(block)
 	c.Printf("</div>")
 }
 
+func getColumnRatio(block *notionapi.Block) float64 {
+	col := block.AsColumn()
+	if col == nil {
+		return 0
+	}
+	return col.Ratio
+}
+
+// ColumnRatio returns a width of BlockColumn as a fraction of the width
+// of its BlockColumnList. Ratios in column_ratio don't always add up to 1
+// and are missing for columns that were never resized so we normalize them
+func ColumnRatio(block *notionapi.Block) float64 {
+	columns := []*notionapi.Block{block}
+	if block.Parent != nil && len(block.Parent.Content) > 0 {
+		columns = block.Parent.Content
+	}
+	n := float64(len(columns))
+	var total float64
+	for _, col := range columns {
+		r := getColumnRatio(col)
+		if r <= 0 {
+			r = 1 / n
+		}
+		total += r
+	}
+	r := getColumnRatio(block)
+	if r <= 0 {
+		r = 1 / n
+	}
+	return r / total
+}
+
+// RenderTemplateButton renders BlockTemplateButton
+func (c *Converter) RenderTemplateButton(block *notionapi.Block) {
+	c.Printf(`<div id="%s" class="template-button">`, block.ID)
+	{
+		c.Printf(`<button type="button" disabled="">`)
+		c.RenderInlines(block.InlineContent)
+		c.Printf(`</button>`)
+		if c.RenderTemplateButtonContent && len(block.Content) > 0 {
+			c.Printf(`<div class="template-button-content">`)
+			c.RenderChildren(block)
+			c.Printf(`</div>`)
+		}
+	}
+	c.Printf(`</div>`)
+}
+
 func (c *Converter) findParentPageID(page *notionapi.Page, id string) string {
 	// we traverse blocks upwards until we find a block of type Page
 	currID := id
@@ -1323,13 +1963,12 @@ func (c *Converter) RenderBreadcrumb(block *notionapi.Block) {
 		title := page.Root().Title
 		pageID := notionapi.ToNoDashID(page.Root().ID)
 		uri := "https://www.notion.so/" + pageID
-		uri = c.RewriteURL(uri)
-		uri = EscapeHTML(uri)
-		c.Printf(`<div><a href="%s">%s</a></div>`, uri, title)
+		uri = EscapeHTML(c.RewrittenURL(uri, URLKindPage))
+		c.Printf(`<div><a href="%s">%s</a></div>`, uri, EscapeHTML(title))
 		c.Printf("<div>/</div>")
 	}
 	title := c.Page.Root().Title
-	c.Printf(`<div>%s</div>`, title)
+	c.Printf(`<div>%s</div>`, EscapeHTML(title))
 	c.Printf(`</div>`)
 }
 
@@ -1377,11 +2016,7 @@ func (c *Converter) renderTableCell(tv *notionapi.TableView, row, col int) {
 	// the value comes from page and their schema has to be fished out
 
 	if schema == nil {
-		colNameCls := EscapeHTML(colName)
-		if colVal == "" {
-			colVal = "&nbsp;"
-		}
-		c.Printf(`<td class="cell-%s">%s</td>`, colNameCls, colVal)
+		c.writeTableCell(colName, colVal)
 		return
 	}
 
@@ -1392,29 +2027,114 @@ func (c *Converter) renderTableCell(tv *notionapi.TableView, row, col int) {
 			// row here is a page. For cosmetic reasons we don't want
 			// to link to empty pages.
 		} else {
-			uri := c.tableTitleCellURL(tv, row, col)
+			uri := EscapeHTML(c.RewrittenURL(c.tableTitleCellURL(tv, row, col), URLKindRow))
 			if colVal == "" {
 				colVal = "Untitled"
 			}
-			colVal = fmt.Sprintf(`<a href="%s">%s</a>`, uri, colVal)
+			colVal = `<a href="` + uri + `">` + colVal + `</a>`
 		}
-	} else if typ == notionapi.ColumnTypeMultiSelect {
+	} else {
+		colVal = c.formatPropertyValue(tv.Page, schema, rowPage, colVal)
+	}
+
+	c.writeTableCell(colName, colVal)
+}
+
+// writes <td> of a column with colVal as (already escaped) content
+func (c *Converter) writeTableCell(colName string, colVal string) {
+	if colVal == "" {
+		colVal = "&nbsp;"
+	}
+	c.Buf.WriteString(`<td class="cell-`)
+	htmlEscaper.WriteString(c.Buf, colName)
+	c.Buf.WriteString(`">`)
+	c.Buf.WriteString(colVal)
+	c.Buf.WriteString(`</td>`)
+}
+
+// returns ids of properties of a collection in the order
+// in which Notion shows them on a page
+func collectionPropertyIDs(col *notionapi.Collection) []string {
+	var res []string
+	seen := map[string]bool{}
+	if col.Format != nil {
+		for _, pp := range col.Format.PageProperties {
+			seen[pp.Property] = true
+			if pp.Visible && col.Schema[pp.Property] != nil {
+				res = append(res, pp.Property)
+			}
+		}
+	}
+	// properties not in PageProperties are visible
+	var rest []string
+	for id := range col.Schema {
+		if !seen[id] {
+			rest = append(rest, id)
+		}
+	}
+	sort.Slice(rest, func(i, j int) bool {
+		n1, n2 := col.Schema[rest[i]].Name, col.Schema[rest[j]].Name
+		if n1 != n2 {
+			return n1 < n2
+		}
+		// ids are unique so the order doesn't depend on the order of a map
+		return rest[i] < rest[j]
+	})
+	return append(res, rest...)
+}
+
+// renders properties of a page that is a row in a collection
+func (c *Converter) renderRowProperties(block *notionapi.Block) {
+	if block.ParentTable != notionapi.TableCollection {
+		return
+	}
+	col := c.Page.CollectionByID(block.ParentID)
+	if col == nil {
+		return
+	}
+	ids := collectionPropertyIDs(col)
+	if len(ids) == 0 {
+		return
+	}
+	c.Printf(`<table class="properties"><tbody>`)
+	for _, id := range ids {
+		schema := col.Schema[id]
+		if schema.Type == notionapi.ColumnTypeTitle {
+			// already shown as page title
+			continue
+		}
+		colVal := c.GetInlineContent(block.GetProperty(id))
+		colVal = c.formatPropertyValue(c.Page, schema, block, colVal)
+		c.Printf(`<tr class="property-row property-row-%s">`, EscapeHTML(schema.Type))
+		c.Printf(`<th>%s</th><td>%s</td>`, EscapeHTML(schema.Name), colVal)
+		c.Printf(`</tr>`)
+	}
+	c.Printf(`</tbody></table>`)
+}
+
+// formatPropertyValue formats colVal, which is HTML of a value of
+// a property of rowPage described by schema. Relations are already
+// links to related pages
+func (c *Converter) formatPropertyValue(page *notionapi.Page, schema *notionapi.ColumnSchema, rowPage *notionapi.Block, colVal string) string {
+	typ := schema.Type
+	if typ == notionapi.ColumnTypeMultiSelect {
 		vals := strings.Split(colVal, ",")
-		s := ""
+		var sb strings.Builder
 		for idx := range vals {
 			val := vals[idx]
 			if val == "" {
 				continue
 			}
-			v := EscapeHTML(val)
 			col := getMultiSelectoColor(schema.Options, val)
 			if col == "" {
-				s += fmt.Sprintf(`<span class="selected-value">%s</span>`, v)
+				sb.WriteString(`<span class="selected-value">`)
 			} else {
-				s += fmt.Sprintf(`<span class="selected-value block-color-%s_background">%s</span>`, col, v)
+				sb.WriteString(`<span class="selected-value block-color-` + col + `_background">`)
 			}
+			htmlEscaper.WriteString(&sb, val)
+			sb.WriteString(`</span>`)
 		}
-		colVal = s
+		colVal = sb.String()
 	} else if typ == notionapi.ColumnTypeCreatedTime {
 		// TODO: better formatting. Notion seems to be using
 		// relative formatting like "Today 3:03pm"
@@ -1428,21 +2148,19 @@ func (c *Converter) renderTableCell(tv *notionapi.TableView, row, col int) {
 		colVal = fmtNumber(colVal, schema.NumberFormat)
 	} else if typ == notionapi.ColumnTypeLastEditedBy {
 		uid := rowPage.LastEditedBy
-		colVal = notionapi.GetUserNameByID(tv.Page, uid)
+		colVal = c.userNameByID(page, uid)
 	} else if typ == notionapi.ColumnTypeCreatedBy {
 		uid := rowPage.CreatedBy
-		colVal = notionapi.GetUserNameByID(tv.Page, uid)
-	} else if schema.Type == notionapi.ColumnTypeRelation {
-		// TODO: not sure how to format relations
-		//colVal = c.GetInlineContent(textSpans)
-		colVal = ""
-	}
-
-	colNameCls := EscapeHTML(colName)
-	if colVal == "" {
-		colVal = "&nbsp;"
+		colVal = c.userNameByID(page, uid)
+	} else if typ == notionapi.ColumnTypeRollup && colVal == "" && page != nil {
+		// Notion doesn't store values of rollups so we compute them
+		v, err := page.RollupValue(rowPage, schema)
+		if err != nil {
+			logf("%s\n", err)
+		}
+		colVal = EscapeHTML(v)
 	}
-	c.Printf(`<td class="cell-%s">%s</td>`, colNameCls, colVal)
+	return colVal
 }
 
 func fmtNumber(v string, numFmt string) string {
@@ -1473,7 +2191,8 @@ func (c *Converter) renderTableRow(tv *notionapi.TableView, row int) {
 	for col := 0; col < nCols; col++ {
 		c.renderTableCell(tv, row, col)
 	}
-	c.Printf("</tr>\n")
+	c.Printf("</tr>")
+	c.newline()
 }
 
 // RenderCollectionView renders BlockCollectionView
@@ -1483,12 +2202,11 @@ func (c *Converter) RenderCollectionView(block *notionapi.Block) {
 		pageID = notionapi.ToNoDashID(c.Page.ID)
 	}
 
-	if len(block.TableViews) == 0 {
+	tv := c.TableViewForBlock(block)
+	if tv == nil {
 		logf("missing block.CollectionViews for block %s %s in page %s\n", block.ID, block.Type, pageID)
 		return
 	}
-	// render only the first one
-	tv := block.TableViews[0]
 
 	nCols := tv.ColumnCount()
 	if nCols == 0 {
@@ -1501,8 +2219,10 @@ func (c *Converter) RenderCollectionView(block *notionapi.Block) {
 	c.Printf(`<div id="%s" class="collection-content">`, block.ID)
 	{
 		name := tv.Collection.GetName()
-		c.Printf(`<h4 class="collection-title">%s</h4>`, name)
-		if isList {
+		c.Printf(`<h4 class="collection-title">%s</h4>`, EscapeHTML(name))
+		if isList && c.StrictCSP {
+			c.Printf(`<table class="collection-content collection-content-list">`)
+		} else if isList {
 			c.Printf("%s", `<table class="collection-content" style="width: 100%">`)
 		} else {
 			c.Printf(`<table class="collection-content">`)
@@ -1521,20 +2241,130 @@ func (c *Converter) RenderCollectionView(block *notionapi.Block) {
 			c.Printf(`</thead>`)
 		}
 
-		c.Printf(`<tbody>`)
-		{
-			nRows := tv.RowCount()
-			for row := 0; row < nRows; row++ {
-				c.renderTableRow(tv, row)
+		nRows := tv.RowCount()
+		nMore := 0
+		// rows that were not downloaded
+		if tv.Total > nRows {
+			nMore = tv.Total - nRows
+		}
+		if c.MaxCollectionRows > 0 && nRows > c.MaxCollectionRows {
+			nMore += nRows - c.MaxCollectionRows
+			nRows = c.MaxCollectionRows
+		}
+		if len(tv.Groups) > 0 {
+			c.renderTableGroups(tv, nRows)
+		} else {
+			c.Printf(`<tbody>`)
+			{
+				for row := 0; row < nRows; row++ {
+					c.renderTableRow(tv, row)
+				}
 			}
+			c.Printf(`</tbody>`)
 		}
-		c.Printf(`</tbody>`)
 
 		c.Printf(`</table>`)
+		if nMore > 0 {
+			c.renderViewMore(block, nMore)
+		}
 	}
 	c.Printf(`</div>`)
 }
 
+// renderTableGroups renders rows of a grouped collection view, each group
+// with a header with the value of the property. At most maxRows rows
+// are rendered
+func (c *Converter) renderTableGroups(tv *notionapi.TableView, maxRows int) {
+	rowIdx := map[*notionapi.TableRow]int{}
+	for i, tr := range tv.Rows {
+		rowIdx[tr] = i
+	}
+	nCols := tv.ColumnCount()
+	groupName := "No value"
+	if schema := tv.Collection.Schema[tv.GroupBy]; schema != nil {
+		groupName = "No " + schema.Name
+	}
+	nRendered := 0
+	for _, g := range tv.Groups {
+		if nRendered >= maxRows {
+			break
+		}
+		c.Printf(`<tbody class="collection-group">`)
+		c.Printf(`<tr class="collection-group-header"><th colspan="%d">`, nCols)
+		name := g.Value
+		if name == "" {
+			name = groupName
+		}
+		if g.Option != nil && g.Option.Color != "" {
+			c.Printf(`<span class="selected-value block-color-%s_background">%s</span>`, g.Option.Color, EscapeHTML(name))
+		} else {
+			c.Printf(`<span class="selected-value">%s</span>`, EscapeHTML(name))
+		}
+		c.Printf(` <span class="collection-group-count">%d</span>`, len(g.Rows))
+		c.Printf(`</th></tr>`)
+		for _, tr := range g.Rows {
+			if nRendered >= maxRows {
+				break
+			}
+			idx, ok := rowIdx[tr]
+			if !ok {
+				continue
+			}
+			c.renderTableRow(tv, idx)
+			nRendered++
+		}
+		c.Printf(`</tbody>`)
+	}
+}
+
+// CollectionViewIndex returns index in block.TableViews of a collection
+// view with a given id or name, or -1 if there's no such view
+func CollectionViewIndex(block *notionapi.Block, idOrName string) int {
+	for i, tv := range block.TableViews {
+		cv := tv.CollectionView
+		if cv == nil {
+			continue
+		}
+		if notionapi.ToNoDashID(cv.ID) == notionapi.ToNoDashID(idOrName) && cv.ID != "" {
+			return i
+		}
+		if strings.EqualFold(cv.Name, idOrName) {
+			return i
+		}
+	}
+	return -1
+}
+
+// TableViewForBlock returns a view of a collection block that we render,
+// selected with CollectionViewSelector
+func (c *Converter) TableViewForBlock(block *notionapi.Block) *notionapi.TableView {
+	if len(block.TableViews) == 0 {
+		return nil
+	}
+	idx := 0
+	if c.CollectionViewSelector != nil {
+		idx = c.CollectionViewSelector(block)
+		if idx < 0 || idx >= len(block.TableViews) {
+			idx = 0
+		}
+	}
+	return block.TableViews[idx]
+}
+
+// renderViewMore renders a link to all rows of a collection view
+// when only MaxCollectionRows rows were rendered
+func (c *Converter) renderViewMore(block *notionapi.Block, nMore int) {
+	uri := ""
+	if c.CollectionViewAllURL != nil {
+		uri = c.RewrittenURL(c.CollectionViewAllURL(block), URLKindPage)
+	}
+	if uri == "" {
+		c.Printf(`<div class="collection-view-more">%d more</div>`, nMore)
+		return
+	}
+	c.Printf(`<div class="collection-view-more"><a href="%s">View %d more</a></div>`, EscapeHTML(uri), nMore)
+}
+
 // DefaultRenderFunc returns a defult rendering function for a type of
 // a given block
 func (c *Converter) DefaultRenderFunc(blockType string) func(*notionapi.Block) {
@@ -1593,7 +2423,7 @@ func (c *Converter) DefaultRenderFunc(blockType string) func(*notionapi.Block) {
 		return c.RenderAudio
 	case notionapi.BlockFile:
 		return c.RenderFile
-	case notionapi.BlockDrive:
+	case notionapi.BlockDrive, notionapi.BlockDropbox:
 		return c.RenderDrive
 	case notionapi.BlockFigma:
 		return c.RenderFigma
@@ -1605,8 +2435,8 @@ func (c *Converter) DefaultRenderFunc(blockType string) func(*notionapi.Block) {
 		return c.RenderTableOfContents
 	case notionapi.BlockBreadcrumb:
 		return c.RenderBreadcrumb
-	case notionapi.BlockFactory:
-		return nil
+	case notionapi.BlockTemplateButton:
+		return c.RenderTemplateButton
 	default:
 		maybePanic("DefaultRenderFunc: unsupported block type '%s' in %s\n", blockType, c.Page.NotionURL())
 	}
@@ -1652,12 +2482,72 @@ func (c *Converter) RenderChildren(block *notionapi.Block) {
 	}
 }
 
+// SetBlockData stashes data computed for a block during rendering
+// (e.g. generated anchor or resolved path of an asset), typically in
+// RenderBlockOverride. After rendering it's available via RenderMetadata
+func (c *Converter) SetBlockData(block *notionapi.Block, key string, v interface{}) {
+	if c.blockData == nil {
+		c.blockData = map[string]map[string]interface{}{}
+	}
+	m := c.blockData[block.ID]
+	if m == nil {
+		m = map[string]interface{}{}
+		c.blockData[block.ID] = m
+	}
+	m[key] = v
+}
+
+// GetBlockData returns data for a block stashed with SetBlockData
+func (c *Converter) GetBlockData(block *notionapi.Block, key string) (interface{}, bool) {
+	v, ok := c.blockData[block.ID][key]
+	return v, ok
+}
+
+// RenderMetadata returns data stashed with SetBlockData during last
+// rendering, keyed by block id
+func (c *Converter) RenderMetadata() map[string]map[string]interface{} {
+	return c.blockData
+}
+
+func (c *Converter) setRenderError(err error) {
+	if c.renderErr == nil {
+		c.renderErr = err
+	}
+}
+
 // RenderBlock renders a block to html
 func (c *Converter) RenderBlock(block *notionapi.Block) {
 	if block == nil {
 		// a missing block is possible
 		return
 	}
+	if c.transcriptBlocks[block.ID] {
+		// already rendered as a transcript of audio/video
+		return
+	}
+	maxDepth := c.MaxDepth
+	if maxDepth <= 0 {
+		maxDepth = DefaultMaxDepth
+	}
+	if c.depth >= maxDepth {
+		c.setRenderError(fmt.Errorf("block '%s' is nested deeper than MaxDepth of %d", block.ID, maxDepth))
+		return
+	}
+	if c.renderStack[block.ID] {
+		// e.g. a synced block referencing its ancestor
+		c.setRenderError(fmt.Errorf("block '%s' contains itself", block.ID))
+		return
+	}
+	if c.renderStack == nil {
+		c.renderStack = map[string]bool{}
+	}
+	c.renderStack[block.ID] = true
+	c.depth++
+	defer func() {
+		c.depth--
+		delete(c.renderStack, block.ID)
+	}()
+
 	if c.RenderBlockOverride != nil {
 		handled := c.RenderBlockOverride(block)
 		if handled {
@@ -1666,50 +2556,148 @@ func (c *Converter) RenderBlock(block *notionapi.Block) {
 	}
 	def := c.DefaultRenderFunc(block.Type)
 	if def != nil {
-		def(block)
+		if c.DetectLanguage != nil {
+			c.renderWithLang(block, def)
+		} else {
+			def(block)
+		}
+		c.renderBlockComments(block)
 	}
 }
 
-func (c *Converter) detectKatex() error {
-	katexPath := c.KatexPath
-	if katexPath != "" {
-		if _, err := os.Stat(c.KatexPath); err == nil {
-			return nil
+// prepare is called before rendering
+func (c *Converter) prepare() error {
+	if c.NotionCompat {
+		c.UseKatexToRenderEquation = true
+	}
+	if c.UseKatexToRenderEquation {
+		if err := c.detectKatex(); err != nil {
+			return err
 		}
 	}
-	katexPath, err := exec.LookPath("katex")
-	if err != nil {
-		if c.KatexPath != "" {
-			return fmt.Errorf("UseKatexToRenderEquation is set but KatexPath ('%s') doesn't exist", c.KatexPath)
+
+	if c.SignFileURLs {
+		if err := c.Page.SignFileURLs(); err != nil {
+			return err
 		}
-		return fmt.Errorf("UseKatexToRenderEquation is set but couldn't locate katex binary (see https://katex.org/). You can install Katex with `npm install -g katex`. You can provide the path to katex binary via KatexPath. ")
 	}
-	c.KatexPath = katexPath
+	c.renderErr = nil
+	c.blockData = nil
 	return nil
 }
 
-// ToHTML renders a page to html
-func (c *Converter) ToHTML() ([]byte, error) {
-	if c.NotionCompat {
-		c.UseKatexToRenderEquation = true
+// newline writes a newline between elements, unless Compact or Indent
+// (which decides about newlines) is set
+func (c *Converter) newline() {
+	if !c.Compact && c.Indent == "" {
+		c.Printf("\n")
 	}
-	if c.UseKatexToRenderEquation {
-		if err := c.detectKatex(); err != nil {
-			return nil, err
-		}
+}
+
+// finish returns the result of rendering to buf
+func (c *Converter) finish(buf *bytes.Buffer) ([]byte, error) {
+	if c.renderErr != nil {
+		return nil, c.renderErr
+	}
+	d := buf.Bytes()
+	if c.RedactSignedURLs {
+		d = c.redactSignedURLs(d)
+	}
+	if c.Indent != "" {
+		d = prettyHTML(d, c.Indent)
 	}
+	return d, nil
+}
 
+// ToHTML renders a page to html
+func (c *Converter) ToHTML() ([]byte, error) {
+	if err := c.prepare(); err != nil {
+		return nil, err
+	}
 	c.PushNewBuffer()
 	c.RenderBlock(c.Page.Root())
-	buf := c.PopBuffer()
-	return buf.Bytes(), nil
+	return c.finish(c.PopBuffer())
+}
+
+// ExcerptHTML renders blocks at the start of the page (see
+// notionapi.Page.Excerpt) e.g. for index pages. Unlike ToHTML, it
+// returns a fragment, without the title of the page
+func (c *Converter) ExcerptHTML(maxBlocks int) ([]byte, error) {
+	return c.renderFragment(c.Page.Excerpt(maxBlocks))
+}
+
+// renders blocks (and their children) as HTML fragment
+func (c *Converter) renderFragment(blocks []*notionapi.Block) ([]byte, error) {
+	if err := c.prepare(); err != nil {
+		return nil, err
+	}
+	c.PushNewBuffer()
+	c.CurrBlocks = blocks
+	for i, block := range blocks {
+		c.CurrBlockIdx = i
+		c.RenderBlock(block)
+	}
+	return c.finish(c.PopBuffer())
+}
+
+// returns a block of the page or an error if it's not there
+func (c *Converter) findBlock(blockID string) (*notionapi.Block, error) {
+	block := c.Page.FindBlockByID(blockID)
+	if block == nil {
+		return nil, fmt.Errorf("block '%s' is not in page '%s'", blockID, c.Page.ID)
+	}
+	return block, nil
+}
+
+// RenderSubtree renders a block of the page with its children as HTML
+// fragment, like ExcerptHTML. Use it to embed a part of a page
+// (e.g. a toggle or a column) in another document
+func (c *Converter) RenderSubtree(blockID string) ([]byte, error) {
+	block, err := c.findBlock(blockID)
+	if err != nil {
+		return nil, err
+	}
+	return c.renderFragment([]*notionapi.Block{block})
+}
+
+// RenderRange renders blocks from startBlockID to endBlockID (inclusive)
+// with their children as HTML fragment, like ExcerptHTML. The blocks must
+// have the same parent e.g. a heading and the last block of its section
+// (see notionapi.ExtractSection)
+func (c *Converter) RenderRange(startBlockID, endBlockID string) ([]byte, error) {
+	start, err := c.findBlock(startBlockID)
+	if err != nil {
+		return nil, err
+	}
+	end, err := c.findBlock(endBlockID)
+	if err != nil {
+		return nil, err
+	}
+	if start.Parent == nil || start.Parent != end.Parent {
+		return nil, fmt.Errorf("blocks '%s' and '%s' don't have the same parent", startBlockID, endBlockID)
+	}
+	blocks := start.Parent.Content
+	startIdx, endIdx := -1, -1
+	for i, block := range blocks {
+		if block == start {
+			startIdx = i
+		}
+		if block == end {
+			endIdx = i
+		}
+	}
+	if startIdx < 0 || endIdx < startIdx {
+		return nil, fmt.Errorf("block '%s' is not before block '%s'", startBlockID, endBlockID)
+	}
+	return c.renderFragment(blocks[startIdx : endIdx+1])
 }
 
 // ToHTML converts a page to HTML
 func ToHTML(page *notionapi.Page) []byte {
 	r := NewConverter(page)
-	// the only error that can happen is katex binary
-	// not existing. Since we don't ask
+	// the only errors that can happen are katex binary
+	// not existing (we don't ask for katex) and pathological
+	// block structures, in which case we return nil
 	res, _ := r.ToHTML()
 	return res
 }
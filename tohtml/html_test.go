@@ -1,9 +1,16 @@
 package tohtml
 
 import (
+	"bytes"
+	"net/url"
+	"regexp"
+	"strings"
 	"testing"
 
+	"github.com/ninja-1/notionapi"
+	"github.com/ninja-1/notionapi/caching_downloader"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestHTMLFileNameForPage(t *testing.T) {
@@ -15,3 +22,693 @@ func TestHTMLFileNameForPage(t *testing.T) {
 		assert.Equal(t, test[1], got)
 	}
 }
+
+func TestFindEmbedProvider(t *testing.T) {
+	tests := []struct {
+		uri      string
+		name     string
+		embedURL string
+	}{
+		{"https://www.figma.com/file/abc/Design", "figma", "https://www.figma.com/embed?embed_host=notion&url=https%3A%2F%2Fwww.figma.com%2Ffile%2Fabc%2FDesign"},
+		{"https://codepen.io/kjk/pen/xyz", "codepen", "https://codepen.io/kjk/embed/xyz?default-tab=result"},
+		{"https://www.loom.com/share/123", "loom", "https://www.loom.com/embed/123"},
+		{"https://replit.com/@kjk/test", "replit", "https://replit.com/@kjk/test?embed=true"},
+		{"https://foo.typeform.com/to/abc", "typeform", "https://foo.typeform.com/to/abc"},
+		{"https://www.youtube.com/watch?v=dQw4w9WgXcQ", "youtube", "https://www.youtube-nocookie.com/embed/dQw4w9WgXcQ"},
+		{"https://youtu.be/dQw4w9WgXcQ", "youtube", "https://www.youtube-nocookie.com/embed/dQw4w9WgXcQ"},
+		{"https://www.youtube.com/channel/abc", "", ""},
+		{"https://example.com/foo", "", ""},
+		{"not a url", "", ""},
+	}
+	for _, tc := range tests {
+		p := FindEmbedProvider(tc.uri)
+		if tc.name == "" {
+			assert.Nil(t, p)
+			continue
+		}
+		assert.Equal(t, tc.name, p.Name)
+		u, _ := url.Parse(tc.uri)
+		assert.Equal(t, tc.embedURL, p.EmbedURL(u))
+	}
+}
+
+func TestRenderCycle(t *testing.T) {
+	parent := &notionapi.Block{ID: "parent", Type: notionapi.BlockToggle}
+	child := &notionapi.Block{ID: "child", Type: notionapi.BlockToggle}
+	parent.Content = []*notionapi.Block{child}
+	child.Content = []*notionapi.Block{parent}
+	c := NewConverter(nil)
+	c.PushNewBuffer()
+	c.RenderBlock(parent)
+	assert.Error(t, c.renderErr)
+
+	child.Content = nil
+	c.renderErr = nil
+	c.MaxDepth = 1
+	c.RenderBlock(parent)
+	assert.Error(t, c.renderErr)
+
+	c.renderErr = nil
+	c.MaxDepth = 0
+	c.RenderBlock(parent)
+	assert.NoError(t, c.renderErr)
+}
+
+func TestRenderTweet(t *testing.T) {
+	block := &notionapi.Block{
+		ID:     "tweet",
+		Type:   notionapi.BlockTweet,
+		Source: "https://twitter.com/notionhq/status/1",
+	}
+	c := NewConverter(nil)
+	c.RenderTweetEmbed = true
+	c.IncludeTweetScript = true
+	c.ScriptNonce = "abc"
+	c.PushNewBuffer()
+	c.RenderTweet(block)
+	c.RenderTweet(block)
+	s := c.PopBuffer().String()
+	assert.Contains(t, s, `<blockquote class="twitter-tweet">`)
+	if !notionapi.Offline {
+		assert.Equal(t, 1, strings.Count(s, `nonce="abc"`))
+	}
+}
+
+func TestCollectionPropertyIDs(t *testing.T) {
+	col := &notionapi.Collection{
+		Schema: map[string]*notionapi.ColumnSchema{
+			"title": {Name: "Name", Type: notionapi.ColumnTypeTitle},
+			"a":     {Name: "Tags", Type: notionapi.ColumnTypeMultiSelect},
+			"b":     {Name: "Date", Type: notionapi.ColumnTypeDate},
+			"c":     {Name: "Hidden", Type: notionapi.ColumnTypeText},
+		},
+		Format: &notionapi.CollectionFormat{
+			PageProperties: []*notionapi.CollectionPageProperty{
+				{Property: "a", Visible: true},
+				{Property: "c", Visible: false},
+			},
+		},
+	}
+	assert.Equal(t, []string{"a", "b", "title"}, collectionPropertyIDs(col))
+}
+
+func TestColumnRatio(t *testing.T) {
+	newColumn := func(ratio float64) *notionapi.Block {
+		b := &notionapi.Block{Type: notionapi.BlockColumn}
+		if ratio > 0 {
+			b.RawJSON = map[string]interface{}{
+				"format": map[string]interface{}{"column_ratio": ratio},
+			}
+		}
+		return b
+	}
+	list := &notionapi.Block{Type: notionapi.BlockColumnList}
+	list.Content = []*notionapi.Block{newColumn(0.25), newColumn(0.5), newColumn(0)}
+	for _, col := range list.Content {
+		col.Parent = list
+	}
+	assert.InDelta(t, 0.25/1.0833, ColumnRatio(list.Content[0]), 0.001)
+	assert.InDelta(t, 0.5/1.0833, ColumnRatio(list.Content[1]), 0.001)
+	assert.InDelta(t, 0.3333/1.0833, ColumnRatio(list.Content[2]), 0.001)
+
+	c := &Converter{Buf: &bytes.Buffer{}}
+	c.RenderColumnList(list)
+	s := c.Buf.String()
+	assert.Contains(t, s, `class="column-list" data-column-count="3"`)
+	assert.Contains(t, s, `data-column-ratio="0.4615"`)
+}
+
+func TestBlockData(t *testing.T) {
+	block := &notionapi.Block{ID: "b1", Type: notionapi.BlockText}
+	c := NewConverter(nil)
+	c.RenderBlockOverride = func(block *notionapi.Block) bool {
+		c.SetBlockData(block, "anchor", "intro")
+		return false
+	}
+	c.PushNewBuffer()
+	c.RenderBlock(block)
+	v, ok := c.GetBlockData(block, "anchor")
+	assert.True(t, ok)
+	assert.Equal(t, "intro", v)
+	assert.Equal(t, "intro", c.RenderMetadata()["b1"]["anchor"])
+}
+
+func TestBreakLongWords(t *testing.T) {
+	tests := []struct {
+		s   string
+		exp string
+	}{
+		{"short words stay", "short words stay"},
+		{"see https://example.com/some/path", "see https://<wbr>example.<wbr>com/some/<wbr>path"},
+		{"abcdefghij", "abcdefgh<wbr>ij"},
+		{"a<b", "a&lt;b"},
+		// CJK characters are break opportunities already
+		{"日本語の文章はとても長いです。", "日本語の文章はとても長いです。"},
+	}
+	for _, tc := range tests {
+		got := BreakLongWords(tc.s, 8, false)
+		assert.Equal(t, tc.exp, got, "s: %s", tc.s)
+	}
+	assert.Equal(t, "abcdefgh&shy;ij", BreakLongWords("abcdefghij", 8, true))
+}
+
+func TestDetectScriptLanguage(t *testing.T) {
+	assert.Equal(t, "", DetectScriptLanguage("Hello world"))
+	assert.Equal(t, "ja", DetectScriptLanguage("日本語の文章です"))
+	assert.Equal(t, "zh", DetectScriptLanguage("中文文本"))
+	assert.Equal(t, "ko", DetectScriptLanguage("한국어 텍스트"))
+	assert.Equal(t, "ru", DetectScriptLanguage("Привет, мир"))
+	assert.Equal(t, "", DetectScriptLanguage("Hello world, один"))
+}
+
+func TestRenderWithLang(t *testing.T) {
+	c := &Converter{
+		Buf:            &bytes.Buffer{},
+		Lang:           "en",
+		DetectLanguage: DetectScriptLanguage,
+	}
+	render := func(block *notionapi.Block) {
+		c.Printf(`<p id="%s">%s</p>`, block.ID, block.Title)
+	}
+	c.renderWithLang(&notionapi.Block{ID: "b1", Title: "Hello"}, render)
+	c.renderWithLang(&notionapi.Block{ID: "b2", Title: "こんにちは"}, render)
+	assert.Equal(t, `<p id="b1">Hello</p><p lang="ja" id="b2">こんにちは</p>`, c.Buf.String())
+}
+
+func TestImageAlt(t *testing.T) {
+	c := &Converter{Buf: &bytes.Buffer{}}
+	block := &notionapi.Block{ID: "img", Type: notionapi.BlockImage, Source: "https://example.com/a.png"}
+	c.RenderImage(block)
+	assert.NotContains(t, c.Buf.String(), "alt=")
+
+	c.Buf.Reset()
+	c.ImageAlt = func(block *notionapi.Block) string {
+		return `text "in" image`
+	}
+	c.RenderImage(block)
+	assert.Contains(t, c.Buf.String(), `alt="text &quot;in&quot; image"`)
+}
+
+func TestRenderGroupedCollectionView(t *testing.T) {
+	schema := &notionapi.ColumnSchema{
+		Name:    "Status",
+		Type:    notionapi.ColumnTypeSelect,
+		Options: []*notionapi.CollectionColumnOption{{Value: "Done", Color: "green"}},
+	}
+	tv := &notionapi.TableView{
+		CollectionView: &notionapi.CollectionView{ID: "view", Type: notionapi.CollectionViewTypeBoard},
+		Collection: &notionapi.Collection{
+			Schema: map[string]*notionapi.ColumnSchema{"stat": schema},
+		},
+		GroupBy: "stat",
+	}
+	tv.Columns = []*notionapi.ColumnInfo{{TableView: tv, Property: &notionapi.TableProperty{Property: "stat"}}}
+	for _, id := range []string{"r1", "r2"} {
+		tr := &notionapi.TableRow{TableView: tv, Page: &notionapi.Block{ID: id}, Columns: [][]*notionapi.TextSpan{nil}}
+		tv.Rows = append(tv.Rows, tr)
+	}
+	tv.Groups = []*notionapi.TableGroup{
+		{Value: "", Rows: tv.Rows[:1]},
+		{Value: "Done", Option: schema.Options[0], Rows: tv.Rows[1:]},
+	}
+	block := &notionapi.Block{ID: "block", TableViews: []*notionapi.TableView{tv}}
+
+	c := &Converter{Buf: &bytes.Buffer{}}
+	c.RenderCollectionView(block)
+	s := c.Buf.String()
+	assert.Equal(t, 2, strings.Count(s, `<tbody class="collection-group">`))
+	assert.Contains(t, s, `<span class="selected-value">No Status</span>`)
+	assert.Contains(t, s, `<span class="selected-value block-color-green_background">Done</span>`)
+	assert.True(t, strings.Index(s, `id="r1"`) < strings.Index(s, `id="r2"`))
+
+	c = &Converter{Buf: &bytes.Buffer{}, MaxCollectionRows: 1}
+	c.RenderCollectionView(block)
+	s = c.Buf.String()
+	assert.Equal(t, 1, strings.Count(s, `<tbody class="collection-group">`))
+	assert.Contains(t, s, `1 more`)
+}
+
+func TestCollectionViewSelector(t *testing.T) {
+	block := &notionapi.Block{
+		TableViews: []*notionapi.TableView{
+			{CollectionView: &notionapi.CollectionView{ID: "6682351e-44bb-4f9c-a0e1-49b703265bdb", Name: "All"}},
+			{CollectionView: &notionapi.CollectionView{ID: "94167af6-5670-4327-9811-dc923edd1f04", Name: "Board"}},
+		},
+	}
+	assert.Equal(t, 1, CollectionViewIndex(block, "board"))
+	assert.Equal(t, 1, CollectionViewIndex(block, "94167af6567043279811dc923edd1f04"))
+	assert.Equal(t, -1, CollectionViewIndex(block, "Calendar"))
+
+	c := &Converter{}
+	assert.Equal(t, block.TableViews[0], c.TableViewForBlock(block))
+	c.CollectionViewSelector = func(block *notionapi.Block) int {
+		return CollectionViewIndex(block, "Board")
+	}
+	assert.Equal(t, block.TableViews[1], c.TableViewForBlock(block))
+	c.CollectionViewSelector = func(block *notionapi.Block) int {
+		return CollectionViewIndex(block, "Calendar")
+	}
+	assert.Equal(t, block.TableViews[0], c.TableViewForBlock(block))
+}
+
+func TestDocsMarkup(t *testing.T) {
+	newHeading := func(id string, typ string, text string) *notionapi.Block {
+		return &notionapi.Block{ID: id, Type: typ, InlineContent: []*notionapi.TextSpan{{Text: text}}}
+	}
+	headings := []*notionapi.Block{
+		newHeading("h1", notionapi.BlockHeader, "Intro"),
+		newHeading("h2", notionapi.BlockSubHeader, "Setup"),
+		newHeading("h3", notionapi.BlockSubHeader, "Usage"),
+		newHeading("h4", notionapi.BlockHeader, "API"),
+	}
+	c := &Converter{Buf: &bytes.Buffer{}, DocsMarkup: DocusaurusMarkup}
+	c.renderDocsTOC(&notionapi.Block{ID: "toc"}, headings)
+	exp := `<nav id="toc" class="table_of_contents" aria-label="Table of contents">` +
+		`<ul class="table-of-contents"><li><a class="table-of-contents__link" href="#h1">Intro</a>` +
+		`<ul class="table-of-contents"><li><a class="table-of-contents__link" href="#h2">Setup</a></li>` +
+		`<li><a class="table-of-contents__link" href="#h3">Usage</a></li></ul></li>` +
+		`<li><a class="table-of-contents__link" href="#h4">API</a></li></ul></nav>`
+	assert.Equal(t, exp, c.Buf.String())
+
+	c = &Converter{Buf: &bytes.Buffer{}, DocsMarkup: DefaultDocsMarkup}
+	c.RenderHeaderLevel(headings[1], 2)
+	s := c.Buf.String()
+	assert.Contains(t, s, `<a class="header-permalink" href="#h2"><span class="visually-hidden">Permalink to Setup</span><span aria-hidden="true">#</span></a>`)
+}
+
+func TestRowPageURL(t *testing.T) {
+	tv := &notionapi.TableView{
+		CollectionView: &notionapi.CollectionView{ID: "view"},
+		Collection: &notionapi.Collection{
+			Schema: map[string]*notionapi.ColumnSchema{"title": {Name: "Name", Type: notionapi.ColumnTypeTitle}},
+		},
+	}
+	tv.Columns = []*notionapi.ColumnInfo{{TableView: tv, Property: &notionapi.TableProperty{Property: "title"}, Schema: tv.Collection.Schema["title"]}}
+	row := &notionapi.Block{ID: "r1", ContentIDs: []string{"c1"}}
+	title := []*notionapi.TextSpan{{Text: "Row"}}
+	tv.Rows = []*notionapi.TableRow{{TableView: tv, Page: row, Columns: [][]*notionapi.TextSpan{title}}}
+
+	c := &Converter{Buf: &bytes.Buffer{}}
+	c.RowPageURL = func(row *notionapi.Block) string {
+		return row.ID + ".html"
+	}
+	c.renderTableCell(tv, 0, 0)
+	assert.Equal(t, `<td class="cell-title"><a href="r1.html">Row</a></td>`, c.Buf.String())
+}
+
+func TestStrictCSP(t *testing.T) {
+	list := &notionapi.Block{ID: "list", Type: notionapi.BlockColumnList}
+	for _, ratio := range []float64{0.25, 0.75} {
+		col := &notionapi.Block{ID: "col", Type: notionapi.BlockColumn, Parent: list}
+		col.RawJSON = map[string]interface{}{
+			"format": map[string]interface{}{"column_ratio": ratio},
+		}
+		list.Content = append(list.Content, col)
+	}
+	callout := &notionapi.Block{ID: "callout", Type: notionapi.BlockCallout}
+	gist := &notionapi.Block{ID: "gist", Type: notionapi.BlockGist, Source: "https://gist.github.com/kjk/1"}
+	tweet := &notionapi.Block{ID: "tweet", Type: notionapi.BlockTweet, Source: "https://twitter.com/kjk/status/1"}
+	equation := &notionapi.Block{ID: "equation", Type: notionapi.BlockEquation, InlineContent: []*notionapi.TextSpan{{Text: "x^2 < y"}}}
+
+	c := &Converter{Buf: &bytes.Buffer{}, StrictCSP: true, RenderTweetEmbed: true, IncludeTweetScript: true, UseKatexToRenderEquation: true, KatexPath: "katex"}
+	c.RenderColumnList(list)
+	c.RenderCallout(callout)
+	c.RenderGist(gist)
+	c.RenderTweet(tweet)
+	c.RenderEquation(equation)
+	s := c.Buf.String()
+	assert.NotContains(t, s, "style=")
+	assert.NotContains(t, s, "<script")
+	assert.Contains(t, s, `<figure id="equation" class="equation">x^2 &lt; y</figure>`)
+	assert.Contains(t, s, `class="column column-grow-5"`)
+	assert.Contains(t, s, `class="column column-grow-15"`)
+	assert.Contains(t, s, `<div class="callout-icon">`)
+	assert.Contains(t, s, `<a href="https://gist.github.com/kjk/1">`)
+
+	c = &Converter{Buf: &bytes.Buffer{}}
+	c.RenderCallout(callout)
+	c.RenderGist(gist)
+	s = c.Buf.String()
+	assert.Contains(t, s, "style=")
+	if !notionapi.Offline {
+		assert.Contains(t, s, "<script")
+	}
+}
+
+func TestClickToLoadEmbeds(t *testing.T) {
+	if notionapi.Offline {
+		t.Skip("embeds are rendered as links in offline builds")
+	}
+	video := &notionapi.Block{ID: "video", Type: notionapi.BlockVideo, Source: "https://youtu.be/dQw4w9WgXcQ"}
+	tweet := &notionapi.Block{ID: "tweet", Type: notionapi.BlockTweet, Source: "https://twitter.com/kjk/status/1"}
+	c := &Converter{Buf: &bytes.Buffer{}, ClickToLoadEmbeds: true, RenderTweetEmbed: true, IncludeTweetScript: true}
+	c.RenderVideo(video)
+	c.RenderTweet(tweet)
+	s := c.Buf.String()
+	assert.NotContains(t, s, "<iframe")
+	assert.NotContains(t, s, "<script")
+	assert.Contains(t, s, `<div class="embed-placeholder" data-click-to-load="https://www.youtube-nocookie.com/embed/dQw4w9WgXcQ" data-provider="youtube" data-height="450">`)
+	assert.Contains(t, s, `<a class="embed-placeholder-link" href="https://youtu.be/dQw4w9WgXcQ">`)
+	assert.Contains(t, s, `data-click-to-load="https://twitter.com/kjk/status/1" data-provider="twitter"`)
+
+	// videos are links unless embeds are enabled
+	c = &Converter{Buf: &bytes.Buffer{}}
+	c.RenderVideo(video)
+	assert.NotContains(t, c.Buf.String(), "<iframe")
+	assert.Contains(t, c.Buf.String(), `<a href="https://youtu.be/dQw4w9WgXcQ">`)
+
+	transcript := &notionapi.Block{ID: "transcript", Type: notionapi.BlockToggle, Content: []*notionapi.Block{
+		{ID: "t1", Type: notionapi.BlockText, InlineContent: []*notionapi.TextSpan{{Text: "Hello"}}},
+	}}
+	c = &Converter{Buf: &bytes.Buffer{}, EmbedVideos: true, RenderTranscripts: true}
+	c.FindTranscript = func(block *notionapi.Block) *notionapi.Block {
+		return transcript
+	}
+	c.RenderVideo(video)
+	s = c.Buf.String()
+	assert.Contains(t, s, `<iframe src="https://www.youtube-nocookie.com/embed/dQw4w9WgXcQ"`)
+	assert.Contains(t, s, `<details class="transcript">`)
+}
+
+func TestEmbedAllowedDomains(t *testing.T) {
+	if notionapi.Offline {
+		t.Skip("embeds are rendered as links in offline builds")
+	}
+	video := &notionapi.Block{ID: "video", Type: notionapi.BlockVideo, Source: "https://youtu.be/dQw4w9WgXcQ"}
+	figma := &notionapi.Block{ID: "figma", Type: notionapi.BlockFigma, Source: "https://www.figma.com/file/1"}
+	gist := &notionapi.Block{ID: "gist", Type: notionapi.BlockGist, Source: "https://gist.github.com/kjk/1"}
+	c := &Converter{Buf: &bytes.Buffer{}, EmbedVideos: true, EmbedAllowedDomains: []string{"youtube-nocookie.com"}}
+	c.RenderVideo(video)
+	c.RenderFigma(figma)
+	c.RenderGist(gist)
+	s := c.Buf.String()
+	assert.Contains(t, s, `<iframe src="https://www.youtube-nocookie.com/embed/dQw4w9WgXcQ"`)
+	assert.Equal(t, 1, strings.Count(s, "<iframe"))
+	assert.NotContains(t, s, "<script")
+	assert.Contains(t, s, `<a href="https://www.figma.com/file/1">`)
+	assert.Contains(t, s, `<a href="https://gist.github.com/kjk/1">`)
+
+	c = &Converter{Buf: &bytes.Buffer{}, EmbedVideos: true, EmbedAllowedDomains: []string{}}
+	c.RenderVideo(video)
+	assert.NotContains(t, c.Buf.String(), "<iframe")
+
+	// the allowlist applies to urls after RewriteURL
+	maps := &notionapi.Block{ID: "maps", Type: notionapi.BlockMaps, Source: "https://maps.google.com/x"}
+	maps.RawJSON = map[string]interface{}{"format": map[string]interface{}{"display_source": "https://www.google.com/maps/embed?x"}}
+	c = &Converter{Buf: &bytes.Buffer{}, EmbedVideos: true, EmbedAllowedDomains: []string{"youtube-nocookie.com", "google.com", "github.com"}}
+	c.RewriteURL = func(uri string, kind URLKind) string {
+		if kind == URLKindEmbed {
+			return "https://evil.example.com/embed"
+		}
+		return uri
+	}
+	c.RenderVideo(video)
+	c.RenderGist(gist)
+	c.RenderMaps(maps)
+	s = c.Buf.String()
+	assert.NotContains(t, s, "<iframe")
+	assert.NotContains(t, s, "<script")
+	assert.NotContains(t, s, "evil.example.com")
+}
+
+func TestRewriteURLKinds(t *testing.T) {
+	if notionapi.Offline {
+		t.Skip("embeds are rendered as links in offline builds")
+	}
+	video := &notionapi.Block{ID: "video", Type: notionapi.BlockVideo, Source: "https://youtu.be/dQw4w9WgXcQ"}
+	image := &notionapi.Block{ID: "image", Type: notionapi.BlockImage, Source: "https://example.com/a.png"}
+	figma := &notionapi.Block{ID: "figma", Type: notionapi.BlockFigma, Source: "https://example.com/figma"}
+	kinds := map[string]URLKind{}
+	c := &Converter{Buf: &bytes.Buffer{}, EmbedVideos: true}
+	c.RewriteURL = func(uri string, kind URLKind) string {
+		kinds[uri] = kind
+		return "/rewritten/" + string(kind)
+	}
+	c.RenderVideo(video)
+	c.RenderImage(image)
+	c.RenderFigma(figma)
+	s := c.Buf.String()
+	assert.Equal(t, URLKindEmbed, kinds["https://www.youtube-nocookie.com/embed/dQw4w9WgXcQ"])
+	assert.Equal(t, URLKindImage, kinds["https://example.com/a.png"])
+	assert.Equal(t, URLKindLink, kinds["https://example.com/figma"])
+	assert.Contains(t, s, `<iframe src="/rewritten/embed"`)
+	assert.Contains(t, s, `<img src="/rewritten/image"/>`)
+	assert.Contains(t, s, `<a href="/rewritten/link">`)
+}
+
+func TestSanitize(t *testing.T) {
+	assert.Equal(t, "javascript", urlScheme(" Java\tScript:alert(1)"))
+	assert.Equal(t, "", urlScheme("/a:b"))
+	assert.True(t, IsURLSchemeAllowed("page.html", DefaultAllowedURLSchemes))
+	assert.False(t, IsURLSchemeAllowed("data:text/html,x", DefaultAllowedURLSchemes))
+
+	spans := []*notionapi.TextSpan{
+		{Text: "x", Attrs: []notionapi.TextAttr{{notionapi.AttrLink, "javascript:alert(1)"}}},
+		{Text: "<script>", Attrs: []notionapi.TextAttr{{notionapi.AttrLink, "https://example.com/?a=1&b=2"}}},
+	}
+	image := &notionapi.Block{ID: "image", Type: notionapi.BlockImage, Source: "data:image/svg+xml,<svg onload=alert(1)>"}
+	gist := &notionapi.Block{ID: "gist", Type: notionapi.BlockGist, Source: "https://evil.example.com/x"}
+	c := &Converter{Buf: &bytes.Buffer{}, Sanitize: true}
+	c.RenderInlines(spans)
+	c.RenderImage(image)
+	c.RenderGist(gist)
+	s := c.Buf.String()
+	assert.NotContains(t, s, "javascript:")
+	assert.NotContains(t, s, "data:")
+	assert.NotContains(t, s, "<script")
+	assert.Contains(t, s, `<a href="https://evil.example.com/x">`)
+	assert.Contains(t, s, `<a>x</a>`)
+	assert.Contains(t, s, `<a href="https://example.com/?a=1&amp;b=2">&lt;script&gt;</a>`)
+
+	c = &Converter{Buf: &bytes.Buffer{}}
+	c.RenderInlines(spans[:1])
+	assert.Contains(t, c.Buf.String(), "javascript:")
+
+	users := notionapi.NewUserDirectory()
+	users.Add(&notionapi.User{ID: "u1", GivenName: "<img src=x onerror=alert(1)>"})
+	c = &Converter{Buf: &bytes.Buffer{}, Sanitize: true, Users: users}
+	c.RenderInlines([]*notionapi.TextSpan{
+		{Text: "x", Attrs: []notionapi.TextAttr{{notionapi.AttrHighlight, `red"><script>alert(1)</script>`}}},
+		{Text: "‣", Attrs: []notionapi.TextAttr{{notionapi.AttrUser, "u1"}}},
+	})
+	s = c.Buf.String()
+	assert.NotContains(t, s, "<script")
+	assert.NotContains(t, s, "<img")
+	assert.Contains(t, s, `<mark class="highlight-red&quot;&gt;&lt;script&gt;alert(1)&lt;/script&gt;">`)
+}
+
+func TestPrettyHTML(t *testing.T) {
+	d := `<div class="a"><p>Hello <b>world</b></p><pre><code>x
+  y</code></pre><ul><li>a</li><li><p>b</p></li></ul><img src="a.png"/></div>`
+	// a fragment ends up in body so lines are broken inside tags
+	exp := `<div class="a"
+  ><p>Hello <b>world</b></p
+  ><pre><code>x
+  y</code></pre
+  ><ul
+    ><li>a</li
+    ><li
+      ><p>b</p
+    ></li
+  ></ul><img src="a.png"
+/></div>
+`
+	assert.Equal(t, exp, string(prettyHTML([]byte(d), "  ")))
+
+	d = `<!DOCTYPE html><html><head><title>T</title></head><body><p>a</p><!-- c --><p>b</p></body></html>`
+	exp = `<!DOCTYPE html>
+<html>
+  <head>
+    <title>T</title>
+  </head>
+  <body
+    ><p>a</p
+    ><!-- c --><p>b</p
+  ></body
+></html>
+`
+	assert.Equal(t, exp, string(prettyHTML([]byte(d), "  ")))
+
+	c := &Converter{Buf: &bytes.Buffer{}, Compact: true}
+	c.newline()
+	assert.Equal(t, "", c.Buf.String())
+}
+
+func BenchmarkRenderInlines(b *testing.B) {
+	spans := []*notionapi.TextSpan{
+		{Text: "Plain text with <special> & \"quoted\" characters. "},
+		{Text: "bold", Attrs: []notionapi.TextAttr{{notionapi.AttrBold}}},
+		{Text: " and ", Attrs: nil},
+		{Text: "a link", Attrs: []notionapi.TextAttr{{notionapi.AttrItalic}, {notionapi.AttrLink, "https://example.com/?a=1&b=2"}}},
+	}
+	c := &Converter{Buf: &bytes.Buffer{}}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		c.Buf.Reset()
+		c.GetInlineContent(spans)
+	}
+}
+
+func TestHeadingSlug(t *testing.T) {
+	assert.Equal(t, "getting-started", HeadingSlug("Getting started!"))
+	assert.Equal(t, "1-2-über-uns", HeadingSlug("  1. 2) Über uns"))
+	assert.Equal(t, "section", HeadingSlug("???"))
+	assert.True(t, isSlightChange("getting-started", "getting-started-now"))
+	assert.False(t, isSlightChange("getting-started", "installation"))
+}
+
+func TestRedactSignedURLs(t *testing.T) {
+	signed := "https://s3.us-west-2.amazonaws.com/secure.notion-static.com/1/a.png?X-Amz-Algorithm=AWS4-HMAC-SHA256&X-Amz-Credential=AKIA%2F20240101&X-Amz-Signature=abc&X-Amz-Expires=3600&id=1"
+	proxied := "https://www.notion.so/image/" + url.PathEscape(signed) + "?table=block&id=1"
+	d := `<img src="` + EscapeHTML(signed) + `"/><a href="` + EscapeHTML(proxied) + `">x</a><a href="https://example.com/?a=1&amp;b=2">y</a>`
+
+	c := &Converter{}
+	s := string(c.redactSignedURLs([]byte(d)))
+	assert.NotContains(t, s, "X-Amz")
+	assert.NotContains(t, s, "AKIA")
+	assert.Contains(t, s, `<img src="https://s3.us-west-2.amazonaws.com/secure.notion-static.com/1/a.png?id=1"/>`)
+	assert.Contains(t, s, `href="https://example.com/?a=1&amp;b=2"`)
+
+	c = &Converter{RewriteSignedURL: func(uri string) string { return "/assets/a.png" }}
+	s = string(c.redactSignedURLs([]byte(d)))
+	assert.Contains(t, s, `<img src="/assets/a.png"/>`)
+	assert.Contains(t, s, `<a href="/assets/a.png">`)
+}
+
+func TestRenderInlineUnderlineAndEquation(t *testing.T) {
+	c := &Converter{Buf: &bytes.Buffer{}}
+	c.RenderInlines([]*notionapi.TextSpan{
+		{Text: "under", Attrs: []notionapi.TextAttr{{notionapi.AttrUnderline}}},
+		{Text: "⁍", Attrs: []notionapi.TextAttr{{notionapi.AttrEquation, "a<b"}}},
+	})
+	assert.Equal(t, `<u>under</u><span class="equation-inline">a&lt;b</span>`, c.Buf.String())
+}
+
+func TestFormatDateOverride(t *testing.T) {
+	d := &notionapi.Date{Type: "date", StartDate: "2018-07-02"}
+	c := &Converter{DateLocale: notionapi.DateLocaleGerman}
+	assert.Equal(t, "<time>@2. Juli 2018</time>", c.FormatDate(d))
+	c.FormatDateOverride = func(d *notionapi.Date) string { return "<" + d.StartDate + ">" }
+	assert.Equal(t, "<time>@&lt;2018-07-02&gt;</time>", c.FormatDate(d))
+}
+
+func TestFormatRelationProperty(t *testing.T) {
+	c := &Converter{Buf: &bytes.Buffer{}, Page: &notionapi.Page{}}
+	row := &notionapi.Block{ID: "row", Properties: map[string]interface{}{
+		"rel": []interface{}{
+			[]interface{}{"‣", []interface{}{[]interface{}{"p", "6682351e-44bb-4f9c-a0e1-49b703265bdb"}}},
+		},
+	}}
+	schema := &notionapi.ColumnSchema{Name: "Related", Type: notionapi.ColumnTypeRelation}
+	colVal := c.GetInlineContent(row.GetProperty("rel"))
+	got := c.formatPropertyValue(c.Page, schema, row, colVal)
+	assert.Contains(t, got, `<a href="https://www.notion.so/6682351e44bb4f9ca0e149b703265bdb">`)
+}
+
+func TestIndentedHTMLWithCSS(t *testing.T) {
+	p := caching_downloader.LoadTestPage(t, "6682351e44bb4f9ca0e149b703265bdb")
+	render := func(indent string) string {
+		c := NewConverter(p)
+		c.FullHTML = true
+		c.Indent = indent
+		d, err := c.ToHTML()
+		require.NoError(t, err)
+		return string(d)
+	}
+	s, plain := render("  "), render("")
+	assert.Contains(t, s, CSS)
+	assert.Contains(t, s, "white-space: pre-wrap")
+	assert.Contains(t, s, "\n  <head>\n")
+	// body preserves whitespace so lines are broken inside tags
+	assert.Contains(t, s, "\n      ><div class=\"page-body\"\n        ><h1 ")
+	h2 := `<h2 id="e736dec2-817e-452c-8256-f5215a7cdf0e" class="">This is a sub-<mark class="highlight-teal">header</mark></h2`
+	assert.Contains(t, s, "\n        >"+h2+"\n")
+	// without whitespace inside tags, body is the same as not indented
+	body := s[strings.Index(s, "<body"):]
+	body = regexp.MustCompile(`\n *(/?>)`).ReplaceAllString(body, "$1")
+	assert.Equal(t, plain[strings.Index(plain, "<body"):]+"\n", body)
+}
+
+func TestExcerptHTML(t *testing.T) {
+	p := caching_downloader.LoadTestPage(t, "6682351e44bb4f9ca0e149b703265bdb")
+	d, err := NewConverter(p).ExcerptHTML(2)
+	require.NoError(t, err)
+	s := string(d)
+	require.NotContains(t, s, "<article")
+	require.Equal(t, 1, strings.Count(s, "<h1"))
+	require.Equal(t, 1, strings.Count(s, "<h2"))
+	require.Equal(t, 0, strings.Count(s, "<h3"))
+}
+
+func TestMetaTags(t *testing.T) {
+	p := caching_downloader.LoadTestPage(t, "6682351e44bb4f9ca0e149b703265bdb")
+	c := NewConverter(p)
+	c.FullHTML = true
+	c.RenderMetaTags = true
+	c.PageURL = "https://example.com/test-headers"
+	d, err := c.ToHTML()
+	require.NoError(t, err)
+	s := string(d)
+	require.Contains(t, s, `<meta property="og:title" content="Test headers"/>`)
+	require.Contains(t, s, `<meta property="og:url" content="https://example.com/test-headers"/>`)
+	require.Contains(t, s, `<meta property="og:description" content="`)
+	require.Contains(t, s, `<meta property="article:modified_time" content="`)
+	require.Contains(t, s, `<meta name="twitter:card" content="summary"/>`)
+
+	c = NewConverter(p)
+	c.FullHTML = true
+	d, err = c.ToHTML()
+	require.NoError(t, err)
+	require.NotContains(t, string(d), "og:title")
+}
+
+func TestIndentedHTML(t *testing.T) {
+	p := caching_downloader.LoadTestPage(t, "94167af6567043279811dc923edd1f04")
+	render := func() []byte {
+		c := NewConverter(p)
+		c.FullHTML = true
+		c.Indent = "  "
+		d, err := c.ToHTML()
+		require.NoError(t, err)
+		return d
+	}
+	d := render()
+	require.Equal(t, d, render())
+	require.Contains(t, string(d), "\n  <head>")
+	require.True(t, strings.Count(string(d), "\n") > 20)
+}
+
+func TestRenderSubtreeAndRange(t *testing.T) {
+	p := caching_downloader.LoadTestPage(t, "6682351e44bb4f9ca0e149b703265bdb")
+	blocks := p.Root().Content
+	c := NewConverter(p)
+	d, err := c.RenderSubtree(blocks[1].ID)
+	require.NoError(t, err)
+	s := string(d)
+	require.True(t, strings.HasPrefix(s, "<h2"))
+	require.NotContains(t, s, "<h1")
+
+	d, err = c.RenderRange(blocks[1].ID, blocks[2].ID)
+	require.NoError(t, err)
+	s = string(d)
+	require.Contains(t, s, "<h2")
+	require.Contains(t, s, "With text.")
+	require.NotContains(t, s, "<h3")
+
+	_, err = c.RenderRange(blocks[2].ID, blocks[1].ID)
+	require.Error(t, err)
+	_, err = c.RenderSubtree("00000000-0000-0000-0000-000000000000")
+	require.Error(t, err)
+}
+
+func BenchmarkToHTMLTable(b *testing.B) {
+	p := caching_downloader.LoadTestPage(b, "94167af6567043279811dc923edd1f04")
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, err := NewConverter(p).ToHTML()
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+//go:build !js
+// +build !js
+
+package tohtml
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+)
+
+func equationToHTML(katexPath string, equation string) (string, error) {
+	cmd := exec.Command(katexPath, "-d")
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return "", err
+	}
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	err = cmd.Start()
+	if err != nil {
+		return "", err
+	}
+	_, err = stdin.Write([]byte(equation))
+	if err != nil {
+		_ = cmd.Process.Kill()
+		return "", err
+	}
+	err = stdin.Close()
+	if err != nil {
+		return "", err
+	}
+	if err = cmd.Wait(); err != nil {
+		return "", err
+	}
+	res := out.String()
+	return res, nil
+}
+
+func (c *Converter) detectKatex() error {
+	katexPath := c.KatexPath
+	if katexPath != "" {
+		if _, err := os.Stat(c.KatexPath); err == nil {
+			return nil
+		}
+	}
+	katexPath, err := exec.LookPath("katex")
+	if err != nil {
+		if c.KatexPath != "" {
+			return fmt.Errorf("UseKatexToRenderEquation is set but KatexPath ('%s') doesn't exist", c.KatexPath)
+		}
+		return fmt.Errorf("UseKatexToRenderEquation is set but couldn't locate katex binary (see https://katex.org/). You can install Katex with `npm install -g katex`. You can provide the path to katex binary via KatexPath. ")
+	}
+	c.KatexPath = katexPath
+	return nil
+}
@@ -0,0 +1,17 @@
+//go:build js
+// +build js
+
+package tohtml
+
+import "errors"
+
+// there's no katex binary when running in the browser so we render
+// equations as text
+
+func equationToHTML(katexPath string, equation string) (string, error) {
+	return "", errors.New("katex is not supported under GOOS=js")
+}
+
+func (c *Converter) detectKatex() error {
+	return nil
+}
@@ -0,0 +1,112 @@
+package tohtml
+
+import (
+	"bytes"
+	"unicode"
+
+	"github.com/ninja-1/notionapi"
+)
+
+// scripts that are (mostly) used by a single language
+var scriptLanguages = []struct {
+	table *unicode.RangeTable
+	lang  string
+}{
+	{unicode.Hangul, "ko"},
+	{unicode.Arabic, "ar"},
+	{unicode.Hebrew, "he"},
+	{unicode.Greek, "el"},
+	{unicode.Thai, "th"},
+	{unicode.Devanagari, "hi"},
+	{unicode.Cyrillic, "ru"},
+	{unicode.Han, "zh"},
+}
+
+// DetectScriptLanguage is a simple language detector for DetectLanguage
+// based on the script the text is written in. It only recognizes languages
+// with their own script (e.g. Japanese from kana, Korean from hangul)
+// and returns "" for Latin text
+func DetectScriptLanguage(text string) string {
+	counts := map[string]int{}
+	total := 0
+	for _, r := range text {
+		if !unicode.IsLetter(r) {
+			continue
+		}
+		total++
+		if unicode.In(r, unicode.Hiragana, unicode.Katakana) {
+			counts["ja"]++
+			continue
+		}
+		for _, sl := range scriptLanguages {
+			if unicode.Is(sl.table, r) {
+				counts[sl.lang]++
+				break
+			}
+		}
+	}
+	// kanji are Han characters so text with any kana is Japanese
+	if counts["ja"] > 0 {
+		counts["ja"] += counts["zh"]
+		delete(counts, "zh")
+	}
+	lang, max := "ja", counts["ja"]
+	for _, sl := range scriptLanguages {
+		if counts[sl.lang] > max {
+			lang, max = sl.lang, counts[sl.lang]
+		}
+	}
+	// most letters must be in the script
+	if max*2 <= total {
+		return ""
+	}
+	return lang
+}
+
+func blockText(block *notionapi.Block) string {
+	if block.Title != "" {
+		return block.Title
+	}
+	return notionapi.TextSpansToString(block.InlineContent)
+}
+
+func (c *Converter) currentLang() string {
+	if n := len(c.langStack); n > 0 {
+		return c.langStack[n-1]
+	}
+	return c.Lang
+}
+
+// addAttrToFirstTag adds attribute to the first HTML tag in html
+func addAttrToFirstTag(html []byte, name string, val string) []byte {
+	start := bytes.IndexByte(html, '<')
+	if start < 0 || start+1 >= len(html) || html[start+1] == '/' || html[start+1] == '!' {
+		return html
+	}
+	end := start + 1
+	for end < len(html) && html[end] != ' ' && html[end] != '>' && html[end] != '/' {
+		end++
+	}
+	attr := ` ` + name + `="` + EscapeHTML(val) + `"`
+	var res []byte
+	res = append(res, html[:end]...)
+	res = append(res, attr...)
+	res = append(res, html[end:]...)
+	return res
+}
+
+// renderWithLang renders a block and adds lang attribute to its
+// element if the language of its text is different than of its parent
+func (c *Converter) renderWithLang(block *notionapi.Block, render func(*notionapi.Block)) {
+	lang := c.DetectLanguage(blockText(block))
+	if lang == "" || lang == c.currentLang() {
+		render(block)
+		return
+	}
+	c.langStack = append(c.langStack, lang)
+	c.PushNewBuffer()
+	render(block)
+	html := c.PopBuffer().Bytes()
+	c.langStack = c.langStack[:len(c.langStack)-1]
+	c.Buf.Write(addAttrToFirstTag(html, "lang", lang))
+}
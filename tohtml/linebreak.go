@@ -0,0 +1,81 @@
+package tohtml
+
+import (
+	"strings"
+	"unicode"
+)
+
+// after those characters we prefer to break long words (URLs, paths, identifiers)
+const breakAfterChars = "/.-_?&=#:,;\\|+~@"
+
+// CJK characters that can't start a line (closing punctuation, small kana etc.)
+const cjkNoLineStart = "、。，．：；？！」』）】〕〉》〙〗ー々ゝゞぁぃぅぇぉっゃゅょゎァィゥェォッャュョヮヵヶ・"
+
+// CJK characters that can't end a line (opening punctuation)
+const cjkNoLineEnd = "「『（【〔〈《〘〖"
+
+// isCJK returns true for characters between which browsers can break a line
+// without a space (ideographs, kana, hangul)
+func isCJK(r rune) bool {
+	return unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul)
+}
+
+// canBreakBetween returns true if we can insert a break between prev and next
+func canBreakBetween(prev, next rune) bool {
+	if strings.ContainsRune(cjkNoLineEnd, prev) || strings.ContainsRune(cjkNoLineStart, next) {
+		return false
+	}
+	return !unicode.IsSpace(prev) && !unicode.IsSpace(next)
+}
+
+// BreakLongWords HTML-escapes s and inserts <wbr> into runs of more than
+// maxRun characters without a break opportunity. We prefer to break after
+// URL punctuation (/, ., - etc.) and only force a break every maxRun
+// characters if there's none.
+// Whitespace and CJK characters are break opportunities so long CJK
+// sentences are left alone, and we never break where CJK line-breaking
+// rules forbid it (e.g. before 。or after「).
+// If softHyphens is true, forced breaks between letters use &shy; so that
+// a hyphen is shown
+func BreakLongWords(s string, maxRun int, softHyphens bool) string {
+	if maxRun <= 0 {
+		return EscapeHTML(s)
+	}
+	runes := []rune(s)
+	var b strings.Builder
+	// number of characters since last break opportunity
+	run := 0
+	// number of characters since last preferred break
+	sincePunct := 0
+	for i, r := range runes {
+		if i > 0 {
+			prev := runes[i-1]
+			if canBreakBetween(prev, r) && !isCJK(prev) && !isCJK(r) {
+				if run >= maxRun && strings.ContainsRune(breakAfterChars, prev) {
+					b.WriteString("<wbr>")
+					run = 0
+				} else if sincePunct >= maxRun {
+					if softHyphens && unicode.IsLetter(prev) && unicode.IsLetter(r) {
+						b.WriteString("&shy;")
+					} else {
+						b.WriteString("<wbr>")
+					}
+					run = 0
+					sincePunct = 0
+				}
+			}
+		}
+		b.WriteString(EscapeHTML(string(r)))
+		if unicode.IsSpace(r) || isCJK(r) {
+			run = 0
+			sincePunct = 0
+			continue
+		}
+		run++
+		sincePunct++
+		if strings.ContainsRune(breakAfterChars, r) {
+			sincePunct = 0
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,89 @@
+package tohtml
+
+import (
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/ninja-1/notionapi"
+)
+
+const (
+	// number of blocks at the start of a page used for description
+	metaDescriptionBlocks = 3
+	// longer descriptions are truncated
+	metaDescriptionMaxLen = 200
+)
+
+// returns text of the start of the page, as one line of at most
+// metaDescriptionMaxLen characters
+func metaDescription(page *notionapi.Page) string {
+	s := page.ExcerptText(metaDescriptionBlocks)
+	s = strings.Join(strings.Fields(s), " ")
+	runes := []rune(s)
+	if len(runes) <= metaDescriptionMaxLen {
+		return s
+	}
+	s = string(runes[:metaDescriptionMaxLen])
+	// don't cut in the middle of a word
+	if idx := strings.LastIndex(s, " "); idx > metaDescriptionMaxLen/2 {
+		s = s[:idx]
+	}
+	return s + "…"
+}
+
+// returns absolute url of the cover of the page or ""
+func (c *Converter) metaImageURL(block *notionapi.Block) string {
+	cover := c.Page.Cover()
+	if cover == nil {
+		return ""
+	}
+	uri := FilePathFromPageCoverURL(cover.URL, block)
+	if isURL(uri) {
+		return uri
+	}
+	// a downloaded copy, relative to the page
+	if c.PageURL == "" {
+		return ""
+	}
+	base, err := url.Parse(c.PageURL)
+	if err != nil {
+		return ""
+	}
+	ref, err := url.Parse(uri)
+	if err != nil {
+		return ""
+	}
+	return base.ResolveReference(ref).String()
+}
+
+func (c *Converter) renderMetaTag(attr string, name string, content string) {
+	if content == "" {
+		return
+	}
+	c.Printf(`<meta %s="%s" content="%s"/>`, attr, name, EscapeHTML(content))
+}
+
+// renderMetaTags renders OpenGraph and article <meta> tags
+// (see Converter.RenderMetaTags)
+func (c *Converter) renderMetaTags(block *notionapi.Block) {
+	desc := metaDescription(c.Page)
+	image := c.metaImageURL(block)
+	c.renderMetaTag("name", "description", desc)
+	c.renderMetaTag("property", "og:type", "article")
+	c.renderMetaTag("property", "og:title", block.Title)
+	c.renderMetaTag("property", "og:description", desc)
+	c.renderMetaTag("property", "og:url", c.PageURL)
+	c.renderMetaTag("property", "og:image", image)
+	if block.CreatedTime > 0 {
+		c.renderMetaTag("property", "article:published_time", block.CreatedOn().UTC().Format(time.RFC3339))
+	}
+	if block.LastEditedTime > 0 {
+		c.renderMetaTag("property", "article:modified_time", block.LastEditedOn().UTC().Format(time.RFC3339))
+	}
+	card := "summary"
+	if image != "" {
+		card = "summary_large_image"
+	}
+	c.renderMetaTag("name", "twitter:card", card)
+}
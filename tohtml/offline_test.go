@@ -0,0 +1,30 @@
+//go:build notionapi_offline
+// +build notionapi_offline
+
+package tohtml
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/ninja-1/notionapi"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOfflineEmbeds(t *testing.T) {
+	video := &notionapi.Block{ID: "video", Type: notionapi.BlockVideo, Source: "https://youtu.be/dQw4w9WgXcQ"}
+	figma := &notionapi.Block{ID: "figma", Type: notionapi.BlockFigma, Source: "https://www.figma.com/file/1"}
+	gist := &notionapi.Block{ID: "gist", Type: notionapi.BlockGist, Source: "https://gist.github.com/kjk/1"}
+	maps := &notionapi.Block{ID: "maps", Type: notionapi.BlockMaps, Source: "https://maps.google.com/x"}
+	maps.RawJSON = map[string]interface{}{"format": map[string]interface{}{"display_source": "https://www.google.com/maps/embed?x"}}
+	c := &Converter{Buf: &bytes.Buffer{}, EmbedVideos: true}
+	c.RenderVideo(video)
+	c.RenderFigma(figma)
+	c.RenderGist(gist)
+	c.RenderMaps(maps)
+	s := c.Buf.String()
+	assert.NotContains(t, s, "<script src=")
+	assert.NotContains(t, s, "<iframe")
+	assert.Contains(t, s, `<a href="https://gist.github.com/kjk/1">`)
+	assert.Contains(t, s, `<a href="https://www.figma.com/file/1">`)
+}
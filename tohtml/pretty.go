@@ -0,0 +1,174 @@
+package tohtml
+
+import (
+	"bytes"
+	"strings"
+)
+
+// elements that start on a new line when Converter.Indent is set
+var blockElements = map[string]bool{
+	"html": true, "head": true, "body": true, "meta": true, "title": true,
+	"link": true, "style": true, "script": true, "article": true,
+	"header": true, "nav": true, "section": true, "div": true, "p": true,
+	"h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true,
+	"ul": true, "ol": true, "li": true, "details": true, "summary": true,
+	"figure": true, "figcaption": true, "blockquote": true, "pre": true,
+	"hr": true, "table": true, "thead": true, "tbody": true, "tr": true,
+	"th": true, "td": true, "iframe": true,
+}
+
+// elements whose content we copy as is
+var rawElements = map[string]bool{
+	"pre": true, "script": true, "style": true, "textarea": true,
+}
+
+// elements without closing tag
+var voidElements = map[string]bool{
+	"meta": true, "link": true, "hr": true, "br": true, "img": true,
+	"input": true, "wbr": true,
+}
+
+// returns lower-cased name of a tag e.g. "div" for "<div class=x>" and
+// "</div>" and true if it's a closing tag
+func tagName(tag string) (string, bool) {
+	s := strings.TrimPrefix(tag[1:len(tag)-1], "/")
+	isClose := len(s) < len(tag)-2
+	if i := strings.IndexAny(s, " \t\n/"); i >= 0 {
+		s = s[:i]
+	}
+	return strings.ToLower(s), isClose
+}
+
+// prettyHTML formats HTML we render with block elements on separate
+// lines, indented with indent for each level of nesting. Inline elements
+// and text stay on the line of their block and content of <pre>,
+// <script> and <style> is not changed.
+// CSS sets white-space: pre-wrap for body so whitespace text added there
+// would be rendered. Inside body (and in fragments, which end up in
+// someone's body) we break lines inside tags instead, before the closing
+// '>' of the previous tag, and don't break after text or comments.
+// The result depends only on d
+func prettyHTML(d []byte, indent string) []byte {
+	var buf bytes.Buffer
+	s := string(d)
+	depth := 0
+	// for each open block element, true if it has block children
+	var hasBlockChild []bool
+	// whether d is a full document and whether we are inside its body
+	isDocument, inBody := false, false
+	newline := func() {
+		var ws bytes.Buffer
+		if buf.Len() > 0 {
+			ws.WriteByte('\n')
+		}
+		for i := 0; i < depth; i++ {
+			ws.WriteString(indent)
+		}
+		if isDocument && !inBody {
+			buf.Write(ws.Bytes())
+			return
+		}
+		b := buf.Bytes()
+		if !bytes.HasSuffix(b, []byte(">")) || bytes.HasSuffix(b, []byte("-->")) {
+			return
+		}
+		n := len(b) - 1
+		if bytes.HasSuffix(b, []byte("/>")) {
+			n--
+		}
+		tail := string(b[n:])
+		buf.Truncate(n)
+		buf.Write(ws.Bytes())
+		buf.WriteString(tail)
+	}
+	for len(s) > 0 {
+		start := strings.IndexByte(s, '<')
+		if start != 0 {
+			if start < 0 {
+				start = len(s)
+			}
+			buf.WriteString(s[:start])
+			s = s[start:]
+			continue
+		}
+		if strings.HasPrefix(s, "<!") {
+			if strings.HasPrefix(strings.ToLower(s), "<!doctype") {
+				isDocument = true
+			}
+			// doctype or a comment
+			end := strings.Index(s, ">")
+			if strings.HasPrefix(s, "<!--") {
+				if end = strings.Index(s, "-->"); end >= 0 {
+					end += 2
+				}
+			}
+			if end < 0 {
+				end = len(s) - 1
+			}
+			newline()
+			buf.WriteString(s[:end+1])
+			s = s[end+1:]
+			continue
+		}
+		end := strings.IndexByte(s, '>')
+		if end < 0 {
+			buf.WriteString(s)
+			break
+		}
+		tag := s[:end+1]
+		s = s[end+1:]
+		name, isClose := tagName(tag)
+		if !blockElements[name] {
+			buf.WriteString(tag)
+			continue
+		}
+		if name == "html" {
+			isDocument = true
+		}
+		if isClose {
+			depth--
+			n := len(hasBlockChild)
+			if depth < 0 || n == 0 {
+				// unbalanced HTML, give up on indenting
+				depth = 0
+				buf.WriteString(tag)
+				continue
+			}
+			if hasBlockChild[n-1] {
+				newline()
+			}
+			hasBlockChild = hasBlockChild[:n-1]
+			buf.WriteString(tag)
+			continue
+		}
+		if n := len(hasBlockChild); n > 0 {
+			hasBlockChild[n-1] = true
+		}
+		newline()
+		buf.WriteString(tag)
+		if name == "body" {
+			inBody = true
+		}
+		if voidElements[name] || strings.HasSuffix(tag, "/>") {
+			continue
+		}
+		if rawElements[name] {
+			closeTag := "</" + name
+			i := strings.Index(strings.ToLower(s), closeTag)
+			if i < 0 {
+				i = len(s)
+			}
+			buf.WriteString(s[:i])
+			s = s[i:]
+			if end := strings.IndexByte(s, '>'); end >= 0 {
+				buf.WriteString(s[:end+1])
+				s = s[end+1:]
+			}
+			continue
+		}
+		depth++
+		hasBlockChild = append(hasBlockChild, false)
+	}
+	buf.WriteByte('\n')
+	return buf.Bytes()
+}
@@ -0,0 +1,29 @@
+package tohtml
+
+import (
+	"html"
+	"regexp"
+
+	"github.com/ninja-1/notionapi"
+)
+
+// matches urls in HTML, in attributes, text and url() of styles
+var rxURL = regexp.MustCompile(`https?://[^\s"'<>()]+`)
+
+// redactSignedURLs replaces signed urls in rendered HTML. We do it on
+// the whole output so that no signed url slips through, no matter which
+// block or hook rendered it
+func (c *Converter) redactSignedURLs(d []byte) []byte {
+	return rxURL.ReplaceAllFunc(d, func(m []byte) []byte {
+		uri := html.UnescapeString(string(m))
+		if !notionapi.IsSignedURL(uri) {
+			return m
+		}
+		if c.RewriteSignedURL != nil {
+			uri = c.RewriteSignedURL(uri)
+		} else {
+			uri = notionapi.StripSignedURL(uri)
+		}
+		return []byte(EscapeHTML(uri))
+	})
+}
@@ -0,0 +1,58 @@
+package tohtml
+
+import (
+	"strings"
+)
+
+// DefaultAllowedURLSchemes are schemes of urls allowed in Sanitize mode
+// if Converter.AllowedURLSchemes is not set
+var DefaultAllowedURLSchemes = []string{"http", "https", "mailto", "tel"}
+
+// returns scheme of a url in lower case or "" for relative urls.
+// Browsers ignore whitespace and control characters in schemes
+// (e.g. "java\tscript:") so we do too
+func urlScheme(uri string) string {
+	var sb strings.Builder
+	for _, r := range uri {
+		switch {
+		case r == ':':
+			return strings.ToLower(sb.String())
+		case r == '/' || r == '?' || r == '#':
+			return ""
+		case r <= ' ' || r == 0x7f:
+			continue
+		}
+		sb.WriteRune(r)
+	}
+	return ""
+}
+
+// IsURLSchemeAllowed returns true if uri is relative or its scheme
+// is one of schemes
+func IsURLSchemeAllowed(uri string, schemes []string) bool {
+	scheme := urlScheme(uri)
+	if scheme == "" {
+		return true
+	}
+	for _, s := range schemes {
+		if strings.EqualFold(s, scheme) {
+			return true
+		}
+	}
+	return false
+}
+
+// returns "" for urls not allowed in Sanitize mode
+func (c *Converter) sanitizeURL(uri string) string {
+	if !c.Sanitize {
+		return uri
+	}
+	schemes := c.AllowedURLSchemes
+	if schemes == nil {
+		schemes = DefaultAllowedURLSchemes
+	}
+	if !IsURLSchemeAllowed(uri, schemes) {
+		return ""
+	}
+	return uri
+}
@@ -41,10 +41,20 @@ type Converter struct {
 	// to destination URLs
 	RewriteURL func(url string) string
 
+	// DateLocale, if set, formats dates with month names and conventions
+	// of a language (e.g. notionapi.DateLocaleGerman) instead of English
+	DateLocale *notionapi.DateLocale
+	// FormatDateOverride, if set, formats dates instead of DateLocale
+	FormatDateOverride func(d *notionapi.Date) string
+
 	// data provided by they caller, useful when providing
 	// RenderBlockOverride
 	Data interface{}
 
+	// Users, if set, is used to resolve names of mentioned users
+	// that are not loaded with the page
+	Users *notionapi.UserDirectory
+
 	// we need this to properly render ordered and numbered lists
 	CurrBlocks   []*notionapi.Block
 	CurrBlockIdx int
@@ -52,8 +62,30 @@ type Converter struct {
 	Indent string
 	ListNo int
 
+	// if true, links to Notion pages are rendered as [[Page Title]]
+	// wikilinks (as used by Obsidian and Logseq) and the page starts
+	// with YAML front matter with its title and aliases
+	Wikilinks bool
+
+	// WikilinkTarget returns a target of a wikilink to a page with
+	// a given id, typically name of the file without .md extension.
+	// If not set or returns "", we use WikilinkFileName of the title
+	WikilinkTarget func(pageID string) string
+
+	// Aliases are additional aliases of the page written in front matter
+	// when Wikilinks is true
+	Aliases []string
+
+	// Warnings lists blocks that ToMarkdown dropped or couldn't fully
+	// represent in Markdown. Blocks handled by RenderBlockOverride
+	// are not reported
+	Warnings []*notionapi.ConversionWarning
+
 	bufs      []*bytes.Buffer
 	URLPrefix string
+
+	// ListOrdinal of blocks, by the first block of CurrBlocks
+	listOrdinals map[*notionapi.Block][]int
 }
 
 // NewConverter returns customizable Markdown renderer
@@ -160,6 +192,27 @@ func (c *Converter) NextBlock() *notionapi.Block {
 	return c.CurrBlocks[nextIdx]
 }
 
+// ListOrdinal returns the number of the current block if it's
+// a BlockNumberedList item, 0 otherwise. Unlike ListNo, it's already
+// set when RenderBlockOverride is called
+func (c *Converter) ListOrdinal() int {
+	if c.CurrBlockIdx < 0 || c.CurrBlockIdx >= len(c.CurrBlocks) {
+		return 0
+	}
+	// computed once for all blocks of a list so that rendering
+	// a list of n items is O(n)
+	key := c.CurrBlocks[0]
+	ordinals := c.listOrdinals[key]
+	if len(ordinals) != len(c.CurrBlocks) {
+		ordinals = notionapi.ListOrdinals(c.CurrBlocks)
+		if c.listOrdinals == nil {
+			c.listOrdinals = map[*notionapi.Block][]int{}
+		}
+		c.listOrdinals[key] = ordinals
+	}
+	return ordinals[c.CurrBlockIdx]
+}
+
 // IsPrevBlockOfType returns true if previous block is of a given type
 func (c *Converter) IsPrevBlockOfType(t string) bool {
 	b := c.PrevBlock()
@@ -180,9 +233,10 @@ func (c *Converter) IsNextBlockOfType(t string) bool {
 
 // FormatDate formats the date
 func (c *Converter) FormatDate(d *notionapi.Date) string {
-	// TODO: allow over-riding date formatting
-	s := notionapi.FormatDate(d)
-	return s
+	if c.FormatDateOverride != nil {
+		return c.FormatDateOverride(d)
+	}
+	return notionapi.FormatDateLocale(d, c.DateLocale)
 }
 
 func getBeforeWhitespace(text string) (string, string) {
@@ -256,8 +310,16 @@ func (c *Converter) InlineToString(b *notionapi.TextSpan) string {
 		case notionapi.AttrCode:
 			start += "`"
 			end = "`" + end
+		case notionapi.AttrEquation:
+			text = "$" + notionapi.AttrGetEquation(attr) + "$"
 		case notionapi.AttrPage:
 			pageID := notionapi.AttrGetPageID(attr)
+			if c.Wikilinks {
+				// text of page mention is a placeholder
+				start += c.Wikilink(pageID, "")
+				text = ""
+				continue
+			}
 			// TODO: find the page
 			// TODO: needs to download info when recursively scanning
 			// for pages
@@ -269,6 +331,13 @@ func (c *Converter) InlineToString(b *notionapi.TextSpan) string {
 			start += fmt.Sprintf(`[%s](%s)`, pageTitle, uri)
 		case notionapi.AttrLink:
 			uri := notionapi.AttrGetLink(attr)
+			if c.Wikilinks && strings.Contains(uri, "notion.so") {
+				if pageID := notionapi.ExtractNoDashIDFromNotionURL(uri); pageID != "" {
+					before, text, after = shuffleWhitespace(text)
+					text = before + c.Wikilink(pageID, text) + after
+					continue
+				}
+			}
 			if c.RewriteURL != nil {
 				uri = c.RewriteURL(uri)
 			}
@@ -277,7 +346,11 @@ func (c *Converter) InlineToString(b *notionapi.TextSpan) string {
 			text = fmt.Sprintf(`%s[%s](%s)%s`, before, text, uri, after)
 		case notionapi.AttrUser:
 			userID := notionapi.AttrGetUserID(attr)
-			text = fmt.Sprintf(`@%s`, notionapi.GetUserNameByID(c.Page, userID))
+			name := notionapi.GetUserNameByID(c.Page, userID)
+			if name == userID && c.Users != nil {
+				name = c.Users.NameByID(userID)
+			}
+			text = fmt.Sprintf(`@%s`, name)
 		case notionapi.AttrDate:
 			date := notionapi.AttrGetDate(attr)
 			text = c.FormatDate(date)
@@ -326,6 +399,9 @@ func (c *Converter) RenderCode(block *notionapi.Block) {
 }
 
 func (c *Converter) renderRootPage(block *notionapi.Block) {
+	if c.Wikilinks {
+		c.renderFrontMatter(block)
+	}
 	title := c.GetInlineContent(block.InlineContent, false)
 	c.Printf("# " + title)
 	c.Newline()
@@ -345,6 +421,11 @@ func (c *Converter) RenderPage(block *notionapi.Block) {
 		c.renderRootPage(block)
 		return
 	}
+	if c.Wikilinks {
+		c.Printf("%s", c.Wikilink(block.ID, block.Title))
+		c.Eol()
+		return
+	}
 	title := c.GetInlineContent(block.InlineContent, false)
 	uri := ""
 	if c.RewriteURL != nil {
@@ -381,12 +462,7 @@ func (c *Converter) RenderNumberedList(block *notionapi.Block) {
 	c.incIndent()
 	defer c.decIndent()
 
-	isPrevSame := c.IsPrevBlockOfType(notionapi.BlockNumberedList)
-	if isPrevSame {
-		c.ListNo++
-	} else {
-		c.ListNo = 1
-	}
+	c.ListNo = c.ListOrdinal()
 	c.WriteString(fmt.Sprintf("%d. ", c.ListNo))
 	c.RenderInlines(block.InlineContent, false)
 	c.Eol()
@@ -579,11 +655,17 @@ func (c *Converter) RenderFile(block *notionapi.Block) {
 	c.renderCaption(block)
 }
 
-// RenderDrive renders BlockDrive
+// RenderDrive renders BlockDrive and BlockDropbox
 func (c *Converter) RenderDrive(block *notionapi.Block) {
 	docURL, _ := block.PropAsString("format.drive_properties.url")
 	title, _ := block.PropAsString("format.drive_properties.title")
-	c.Printf("[%s](%s)\n", title, docURL)
+	if docURL == "" {
+		docURL = block.Source
+	}
+	if title == "" {
+		title = docURL
+	}
+	c.Printf("[%s](%s)\n", escapeMarkdownLinkText(title), docURL)
 	c.renderCaption(block)
 }
 
@@ -710,7 +792,7 @@ func (c *Converter) DefaultRenderFunc(blockType string) func(*notionapi.Block) {
 		return c.RenderAudio
 	case notionapi.BlockFile:
 		return c.RenderFile
-	case notionapi.BlockDrive:
+	case notionapi.BlockDrive, notionapi.BlockDropbox:
 		return c.RenderDrive
 	case notionapi.BlockFigma:
 		return c.RenderFigma
@@ -807,9 +889,54 @@ func (c *Converter) RenderBlock(block *notionapi.Block) {
 		c.AddNewlineBeforeBlock(block)
 		def(block)
 	}
+	c.addWarning(block, def != nil)
+}
+
+// returns a reason why a block rendered with a default function
+// loses information in Markdown or ""
+func lossyReason(block *notionapi.Block) string {
+	switch block.Type {
+	case notionapi.BlockCollectionView, notionapi.BlockCallout:
+		return "not implemented"
+	case notionapi.BlockColumnList:
+		return "columns are rendered one after another"
+	case notionapi.BlockVideo, notionapi.BlockAudio, notionapi.BlockFile,
+		notionapi.BlockPDF, notionapi.BlockDrive, notionapi.BlockDropbox:
+		return "rendered as a link"
+	case notionapi.BlockEmbed, notionapi.BlockGist, notionapi.BlockMaps,
+		notionapi.BlockCodepen, notionapi.BlockTweet, notionapi.BlockFigma:
+		return "embed rendered as a link"
+	case notionapi.BlockToggle:
+		return "toggle is always expanded"
+	}
+	if color, _ := block.PropAsString("format.block_color"); color != "" {
+		return "block color is not supported"
+	}
+	return ""
+}
+
+// addWarning records a warning if a block was rendered lossily or,
+// if rendered is false, dropped
+func (c *Converter) addWarning(block *notionapi.Block, rendered bool) {
+	w := &notionapi.ConversionWarning{
+		Format:    notionapi.FormatMarkdown,
+		Kind:      notionapi.WarningDropped,
+		BlockID:   block.ID,
+		BlockType: block.Type,
+		Message:   "block type is not supported",
+	}
+	if rendered {
+		w.Kind = notionapi.WarningLossy
+		w.Message = lossyReason(block)
+		if w.Message == "" {
+			return
+		}
+	}
+	c.Warnings = append(c.Warnings, w)
 }
 
 func (c *Converter) ToMarkdown() []byte {
+	c.Warnings = nil
 	c.PushNewBuffer()
 
 	c.RenderBlock(c.Page.Root())
@@ -3,7 +3,10 @@ package tomarkdown
 import (
 	"testing"
 
+	"github.com/ninja-1/notionapi"
+	"github.com/ninja-1/notionapi/caching_downloader"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestMarkdownFileNameForPage(t *testing.T) {
@@ -21,3 +24,31 @@ func TestMarkdownFileNameForPage(t *testing.T) {
 		assert.Equal(t, test[2], got)
 	}
 }
+
+func TestWikilinkFileName(t *testing.T) {
+	assert.Equal(t, "Notes- 2020-01.md", WikilinkFileName("Notes: 2020/01"))
+	assert.Equal(t, "Untitled.md", WikilinkFileName(""))
+}
+
+func TestConversionWarnings(t *testing.T) {
+	p := caching_downloader.LoadTestPage(t, "94167af6567043279811dc923edd1f04")
+	c := NewConverter(p)
+	c.ToMarkdown()
+	require.NotEmpty(t, c.Warnings)
+	w := c.Warnings[0]
+	require.Equal(t, notionapi.FormatMarkdown, w.Format)
+	require.Equal(t, notionapi.WarningLossy, w.Kind)
+	require.Equal(t, notionapi.BlockCollectionView, w.BlockType)
+
+	ws := p.PlainTextWarnings()
+	require.NotEmpty(t, ws)
+	require.Equal(t, notionapi.WarningDropped, ws[0].Kind)
+
+	p = caching_downloader.LoadTestPage(t, "6682351e44bb4f9ca0e149b703265bdb")
+	c = NewConverter(p)
+	c.ToMarkdown()
+	// a colored heading
+	require.Equal(t, 1, len(c.Warnings))
+	require.Equal(t, notionapi.BlockSubSubHeader, c.Warnings[0].BlockType)
+	require.Empty(t, p.PlainTextWarnings())
+}
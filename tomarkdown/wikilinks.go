@@ -0,0 +1,79 @@
+package tomarkdown
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ninja-1/notionapi"
+)
+
+// characters that are not allowed in file names or wikilinks in
+// Obsidian and Logseq
+const wikilinkBadChars = `/\:*?"<>|#^[]`
+
+// WikilinkFileName returns a name of markdown file for a page with
+// a given title, that can be referred to with [[title]] wikilink
+func WikilinkFileName(title string) string {
+	s := strings.Map(func(r rune) rune {
+		if strings.ContainsRune(wikilinkBadChars, r) {
+			return '-'
+		}
+		return r
+	}, title)
+	s = strings.TrimSpace(s)
+	if s == "" {
+		s = "Untitled"
+	}
+	return s + ".md"
+}
+
+// returns title of a page with a given id, if we know it
+func (c *Converter) pageTitle(pageID string) string {
+	if c.Page != nil {
+		if block := c.Page.BlockByID(pageID); block != nil && block.Title != "" {
+			return block.Title
+		}
+	}
+	return "Untitled"
+}
+
+// Wikilink returns [[target]] or [[target|title]] wikilink to a page
+func (c *Converter) Wikilink(pageID string, title string) string {
+	if title == "" {
+		title = c.pageTitle(pageID)
+	}
+	target := ""
+	if c.WikilinkTarget != nil {
+		target = c.WikilinkTarget(pageID)
+	}
+	if target == "" {
+		target = strings.TrimSuffix(WikilinkFileName(title), ".md")
+	}
+	if target == title {
+		return fmt.Sprintf("[[%s]]", title)
+	}
+	return fmt.Sprintf("[[%s|%s]]", target, title)
+}
+
+func yamlQuote(s string) string {
+	s = strings.Replace(s, `\`, `\\`, -1)
+	s = strings.Replace(s, `"`, `\"`, -1)
+	return `"` + s + `"`
+}
+
+// renders YAML front matter with title and aliases of a page
+func (c *Converter) renderFrontMatter(block *notionapi.Block) {
+	title := block.Title
+	c.Printf("---\n")
+	c.Printf("title: %s\n", yamlQuote(title))
+	aliases := []string{title}
+	aliases = append(aliases, c.Aliases...)
+	c.Printf("aliases:\n")
+	for _, alias := range aliases {
+		if alias != "" {
+			c.Printf("  - %s\n", yamlQuote(alias))
+		}
+	}
+	c.Printf("notion_id: %s\n", notionapi.ToNoDashID(block.ID))
+	c.Printf("---\n")
+}
@@ -0,0 +1,213 @@
+// Package toslides converts Notion pages to slide decks that can be
+// presented with Reveal.js (https://revealjs.com/) or Marp (https://marp.app/)
+package toslides
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/ninja-1/notionapi"
+	"github.com/ninja-1/notionapi/tohtml"
+	"github.com/ninja-1/notionapi/tomarkdown"
+)
+
+const (
+	// DefaultRevealURL is where we load Reveal.js from
+	DefaultRevealURL = "https://cdn.jsdelivr.net/npm/reveal.js@4.6.1"
+	// DefaultRevealTheme is a theme of Reveal.js
+	DefaultRevealTheme = "white"
+)
+
+// Slide is a slide of a deck
+type Slide struct {
+	// Heading is the heading that starts the slide. It's nil for
+	// the title slide and slides that start at dividers
+	Heading *notionapi.Block
+	// Title is the text of Heading or the title of the page for
+	// the title slide
+	Title string
+	// Blocks are the content of the slide
+	Blocks []*notionapi.Block
+}
+
+// Converter creates slides from a page. Headings of HeadingLevel or
+// higher start new slides. The first slide has the title of the page
+// and blocks before the first heading
+type Converter struct {
+	Page *notionapi.Page
+
+	// HeadingLevel is the lowest level of headings that start a slide:
+	// 1 for BlockHeader only, 2 (default) for BlockHeader and BlockSubHeader
+	HeadingLevel int
+	// if true, BlockDivider also starts a new slide, without a title
+	SplitAtDividers bool
+
+	// RevealURL is where Reveal.js is loaded from. Defaults to
+	// DefaultRevealURL
+	RevealURL string
+	// RevealTheme is a theme of Reveal.js e.g. "black". Defaults
+	// to DefaultRevealTheme
+	RevealTheme string
+	// MarpTheme is a theme of Marp e.g. "gaia". Defaults to "default"
+	MarpTheme string
+}
+
+// NewConverter returns a new Converter
+func NewConverter(page *notionapi.Page) *Converter {
+	return &Converter{
+		Page: page,
+	}
+}
+
+// splits blocks of a slide at dividers. Dividers are dropped
+func splitAtDividers(slide *Slide) []*Slide {
+	res := []*Slide{{Heading: slide.Heading, Title: slide.Title}}
+	for _, block := range slide.Blocks {
+		if block.Type == notionapi.BlockDivider {
+			res = append(res, &Slide{})
+			continue
+		}
+		curr := res[len(res)-1]
+		curr.Blocks = append(curr.Blocks, block)
+	}
+	return res
+}
+
+// Slides returns slides of the page
+func (c *Converter) Slides() []*Slide {
+	level := c.HeadingLevel
+	if level <= 0 {
+		level = 2
+	}
+	root := c.Page.Root()
+	sections := notionapi.SplitBlocks(root.Content, level)
+	var slides []*Slide
+	if len(sections) == 0 || sections[0].Heading != nil {
+		slides = append(slides, &Slide{Title: root.Title})
+	}
+	for i, section := range sections {
+		slide := &Slide{
+			Heading: section.Heading,
+			Title:   section.Title,
+			Blocks:  section.Blocks,
+		}
+		if i == 0 && section.Heading == nil {
+			slide.Title = root.Title
+		}
+		slides = append(slides, slide)
+	}
+	if !c.SplitAtDividers {
+		return slides
+	}
+	var res []*Slide
+	for _, slide := range slides {
+		for _, s := range splitAtDividers(slide) {
+			// skip empty slides e.g. from a divider at the end
+			if s.Title != "" || len(s.Blocks) > 0 {
+				res = append(res, s)
+			}
+		}
+	}
+	return res
+}
+
+func (c *Converter) slideHTML(html *tohtml.Converter, slide *Slide) string {
+	html.PushNewBuffer()
+	if slide.Heading != nil {
+		tag := fmt.Sprintf("h%d", notionapi.HeadingLevel(slide.Heading))
+		html.Printf(`<%s>%s</%s>`, tag, html.GetInlineContent(slide.Heading.InlineContent), tag)
+	} else if slide.Title != "" {
+		html.Printf(`<h1>%s</h1>`, tohtml.EscapeHTML(slide.Title))
+	}
+	html.CurrBlocks = slide.Blocks
+	for i, block := range slide.Blocks {
+		html.CurrBlockIdx = i
+		html.RenderBlock(block)
+	}
+	return html.PopBuffer().String()
+}
+
+// ToRevealHTML returns a standalone HTML page with slides for Reveal.js
+func (c *Converter) ToRevealHTML() []byte {
+	revealURL := c.RevealURL
+	if revealURL == "" {
+		revealURL = DefaultRevealURL
+	}
+	revealURL = strings.TrimSuffix(revealURL, "/")
+	theme := c.RevealTheme
+	if theme == "" {
+		theme = DefaultRevealTheme
+	}
+	html := tohtml.NewConverter(c.Page)
+	var buf bytes.Buffer
+	buf.WriteString(`<!DOCTYPE html><html><head><meta charset="utf-8"/>`)
+	buf.WriteString(`<meta name="viewport" content="width=device-width, initial-scale=1.0"/>`)
+	buf.WriteString(fmt.Sprintf(`<title>%s</title>`, tohtml.EscapeHTML(c.Page.Root().Title)))
+	buf.WriteString(fmt.Sprintf(`<link rel="stylesheet" href="%s/dist/reveal.css"/>`, revealURL))
+	buf.WriteString(fmt.Sprintf(`<link rel="stylesheet" href="%s/dist/theme/%s.css"/>`, revealURL, theme))
+	buf.WriteString(`</head><body><div class="reveal"><div class="slides">`)
+	for _, slide := range c.Slides() {
+		buf.WriteString("\n<section>")
+		buf.WriteString(c.slideHTML(html, slide))
+		buf.WriteString("</section>")
+	}
+	buf.WriteString("\n</div></div>")
+	buf.WriteString(fmt.Sprintf(`<script src="%s/dist/reveal.js"></script>`, revealURL))
+	buf.WriteString(`<script>Reveal.initialize({hash: true});</script>`)
+	buf.WriteString("</body></html>\n")
+	return buf.Bytes()
+}
+
+func (c *Converter) slideMarkdown(md *tomarkdown.Converter, slide *Slide) string {
+	md.PushNewBuffer()
+	if slide.Heading != nil {
+		prefix := strings.Repeat("#", notionapi.HeadingLevel(slide.Heading))
+		md.Printf("%s %s\n", prefix, md.GetInlineContent(slide.Heading.InlineContent, true))
+	} else if slide.Title != "" {
+		md.Printf("# %s\n", slide.Title)
+	}
+	md.CurrBlocks = slide.Blocks
+	for i, block := range slide.Blocks {
+		// Marp treats horizontal rules (---, *** and ___) as slide
+		// separators so dividers inside a slide are dropped
+		if block.Type == notionapi.BlockDivider {
+			continue
+		}
+		md.CurrBlockIdx = i
+		md.RenderBlock(block)
+	}
+	return strings.TrimSpace(md.PopBuffer().String())
+}
+
+// ToMarp returns Markdown with slides for Marp
+func (c *Converter) ToMarp() []byte {
+	theme := c.MarpTheme
+	if theme == "" {
+		theme = "default"
+	}
+	md := tomarkdown.NewConverter(c.Page)
+	var buf bytes.Buffer
+	buf.WriteString("---\nmarp: true\ntheme: " + theme + "\n---\n")
+	for i, slide := range c.Slides() {
+		if i > 0 {
+			buf.WriteString("\n---\n")
+		}
+		buf.WriteString("\n")
+		buf.WriteString(c.slideMarkdown(md, slide))
+		buf.WriteString("\n")
+	}
+	return buf.Bytes()
+}
+
+// ToRevealHTML converts a page to Reveal.js slides, starting a new slide
+// at every BlockHeader and BlockSubHeader
+func ToRevealHTML(page *notionapi.Page) []byte {
+	return NewConverter(page).ToRevealHTML()
+}
+
+// ToMarp converts a page to Marp slides, starting a new slide
+// at every BlockHeader and BlockSubHeader
+func ToMarp(page *notionapi.Page) []byte {
+	return NewConverter(page).ToMarp()
+}
@@ -0,0 +1,51 @@
+package toslides
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ninja-1/notionapi"
+	"github.com/ninja-1/notionapi/caching_downloader"
+	"github.com/stretchr/testify/require"
+)
+
+// https://www.notion.so/Test-headers-6682351e44bb4f9ca0e149b703265bdb
+func TestSlides(t *testing.T) {
+	p := caching_downloader.LoadTestPage(t, "6682351e44bb4f9ca0e149b703265bdb")
+	conv := NewConverter(p)
+	slides := conv.Slides()
+	require.Equal(t, 3, len(slides))
+	require.Equal(t, p.Root().Title, slides[0].Title)
+	require.Equal(t, notionapi.BlockSubHeader, slides[2].Heading.Type)
+	require.Equal(t, 4, len(slides[2].Blocks))
+
+	s := string(conv.ToRevealHTML())
+	require.Equal(t, 3, strings.Count(s, "<section>"))
+	require.Contains(t, s, "Reveal.initialize")
+	s = string(conv.ToMarp())
+	require.True(t, strings.HasPrefix(s, "---\nmarp: true\n"))
+	require.Equal(t, 3, strings.Count(s, "\n---\n"))
+	require.Contains(t, s, "\n## ")
+}
+
+// a page with a divider inside the section of the first heading
+func TestSlidesWithDivider(t *testing.T) {
+	p := caching_downloader.LoadTestPage(t, "5fb2d1c04b6e4a3f9d8e7c6b5a493827")
+	conv := NewConverter(p)
+	slides := conv.Slides()
+	require.Equal(t, 3, len(slides))
+	require.Equal(t, "First", slides[1].Title)
+	require.Equal(t, notionapi.BlockDivider, slides[1].Blocks[1].Type)
+
+	s := string(conv.ToMarp())
+	// the end of front matter and separators between 3 slides
+	require.Equal(t, 3, strings.Count(s, "\n---\n"))
+	require.Contains(t, s, "Before divider")
+	require.Contains(t, s, "After divider")
+	require.Contains(t, string(conv.ToRevealHTML()), "<hr")
+
+	conv.SplitAtDividers = true
+	slides = conv.Slides()
+	require.Equal(t, 4, len(slides))
+	require.Equal(t, "", slides[2].Title)
+}
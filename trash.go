@@ -0,0 +1,93 @@
+package notionapi
+
+type deleteBlocksRequest struct {
+	BlockIDs          []string `json:"blockIds"`
+	PermanentlyDelete bool     `json:"permanentlyDelete"`
+}
+
+// getBlock returns a single block record with a given id
+func (c *Client) getBlock(id string) (*Block, error) {
+	rsp, err := c.GetBlockRecords([]string{id})
+	if err != nil {
+		return nil, err
+	}
+	if len(rsp.Results) == 0 || rsp.Results[0].Block == nil {
+		return nil, newErrPageNotFound(id)
+	}
+	return rsp.Results[0].Block, nil
+}
+
+// buildSetAliveOps returns operations that move a block to trash (alive is false)
+// or restore it from trash (alive is true). The block is also removed from
+// (or re-added to) the list of children of its parent.
+func buildSetAliveOps(block *Block, alive bool) []*Operation {
+	ops := []*Operation{
+		block.buildOp(CommandUpdate, []string{}, map[string]interface{}{
+			"alive": alive,
+		}),
+	}
+	// rows of a collection are not in a list of its parent
+	if block.ParentID == "" || block.ParentTable == TableCollection {
+		return ops
+	}
+	table := block.ParentTable
+	path := []string{"content"}
+	if table == TableSpace {
+		path = []string{"pages"}
+	}
+	command := CommandListRemove
+	if alive {
+		command = CommandListAfter
+	}
+	op := &Operation{
+		ID:      block.ParentID,
+		Table:   table,
+		Path:    path,
+		Command: command,
+		Args: map[string]string{
+			"id": block.ID,
+		},
+	}
+	ops = append(ops, op)
+	return ops
+}
+
+func (c *Client) setPageAlive(id string, alive bool) (*Block, error) {
+	block, err := c.getBlock(id)
+	if err != nil {
+		return nil, err
+	}
+	ops := buildSetAliveOps(block, alive)
+	return block, c.SubmitTransaction(ops)
+}
+
+// DeletePage moves a page (or any other block) with a given id to trash.
+// It can be restored with RestorePage
+func (c *Client) DeletePage(id string) error {
+	_, err := c.setPageAlive(id, false)
+	return err
+}
+
+// RestorePage restores a page (or any other block) with a given id from trash.
+// The page is added as the last child of its parent
+func (c *Client) RestorePage(id string) error {
+	_, err := c.setPageAlive(id, true)
+	return err
+}
+
+// DeletePermanently moves a page to trash and then permanently deletes it.
+// This can't be undone
+func (c *Client) DeletePermanently(id string) error {
+	block, err := c.setPageAlive(id, false)
+	if err != nil {
+		return err
+	}
+	req := &deleteBlocksRequest{
+		BlockIDs:          []string{block.ID},
+		PermanentlyDelete: true,
+	}
+	var rsp map[string]interface{}
+	apiURL := "/api/v3/deleteBlocks"
+	_, err = doNotionAPI(c, apiURL, req, &rsp)
+	return err
+}
@@ -0,0 +1,27 @@
+package notionapi
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildSetAliveOps(t *testing.T) {
+	b := &Block{ID: "b1", ParentID: "p1", ParentTable: TableBlock}
+	ops := buildSetAliveOps(b, false)
+	assert.Len(t, ops, 2)
+	assert.Equal(t, map[string]interface{}{"alive": false}, ops[0].Args)
+	assert.Equal(t, "p1", ops[1].ID)
+	assert.Equal(t, CommandListRemove, ops[1].Command)
+	assert.Equal(t, []string{"content"}, ops[1].Path)
+
+	b.ParentTable = TableSpace
+	ops = buildSetAliveOps(b, true)
+	assert.Equal(t, CommandListAfter, ops[1].Command)
+	assert.Equal(t, TableSpace, ops[1].Table)
+	assert.Equal(t, []string{"pages"}, ops[1].Path)
+
+	b.ParentTable = TableCollection
+	ops = buildSetAliveOps(b, false)
+	assert.Len(t, ops, 1)
+}
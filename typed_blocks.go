@@ -0,0 +1,255 @@
+package notionapi
+
+// Typed views of blocks. Block has fields for all block types and
+// format is an untyped map. The views have fields relevant to a given
+// type, with format decoded. Unlike Block.Format* functions, As*
+// functions don't panic: they return nil if the block is of a different
+// type and leave format empty if it can't be decoded.
+// The views embed *Block so that all of its fields are still available
+
+// decodes format of a block into v. Returns false if there is no
+// format or it has unexpected values
+func (b *Block) decodeFormat(v interface{}) bool {
+	formatRaw := jsonGetMap(b.RawJSON, "format")
+	if len(formatRaw) == 0 {
+		return false
+	}
+	return jsonUnmarshalFromMap(formatRaw, v) == nil
+}
+
+// TextBlock is a view of BlockText, BlockQuote, BlockToggle,
+// BlockBulletedList and BlockNumberedList
+type TextBlock struct {
+	*Block
+	Text  []*TextSpan
+	Color string
+	// Ordinal is the number of a BlockNumberedList item (see
+	// Block.ListOrdinal), 0 for other blocks
+	Ordinal int
+}
+
+// AsText returns a typed view of a block with text or nil
+func (b *Block) AsText() *TextBlock {
+	switch b.Type {
+	case BlockText, BlockQuote, BlockToggle, BlockBulletedList, BlockNumberedList:
+	default:
+		return nil
+	}
+	res := &TextBlock{Block: b, Text: b.InlineContent, Ordinal: b.ListOrdinal()}
+	res.Color, _ = b.PropAsString("format.block_color")
+	return res
+}
+
+// HeadingBlock is a view of BlockHeader, BlockSubHeader
+// and BlockSubSubHeader
+type HeadingBlock struct {
+	*Block
+	// Level is 1 to 3
+	Level int
+	Text  []*TextSpan
+	Color string
+	// Toggleable is true for headings that can be collapsed
+	Toggleable bool
+}
+
+// AsHeading returns a typed view of a heading or nil
+func (b *Block) AsHeading() *HeadingBlock {
+	level := HeadingLevel(b)
+	if level == 0 {
+		return nil
+	}
+	res := &HeadingBlock{Block: b, Level: level, Text: b.InlineContent}
+	res.Color, _ = b.PropAsString("format.block_color")
+	if v, ok := b.Prop("format.toggleable"); ok {
+		res.Toggleable, _ = v.(bool)
+	}
+	return res
+}
+
+// TodoBlock is a view of BlockTodo
+type TodoBlock struct {
+	*Block
+	Text    []*TextSpan
+	Checked bool
+}
+
+// AsTodo returns a typed view of BlockTodo or nil
+func (b *Block) AsTodo() *TodoBlock {
+	if b.Type != BlockTodo {
+		return nil
+	}
+	return &TodoBlock{Block: b, Text: b.InlineContent, Checked: b.IsChecked}
+}
+
+// CalloutBlock is a view of BlockCallout
+type CalloutBlock struct {
+	*Block
+	Text []*TextSpan
+	// Icon is an emoji or url of an image
+	Icon  string
+	Color string
+}
+
+// AsCallout returns a typed view of BlockCallout or nil
+func (b *Block) AsCallout() *CalloutBlock {
+	if b.Type != BlockCallout {
+		return nil
+	}
+	res := &CalloutBlock{Block: b, Text: b.InlineContent}
+	res.Icon, _ = b.PropAsString("format.page_icon")
+	res.Color, _ = b.PropAsString("format.block_color")
+	return res
+}
+
+// CodeBlock is a view of BlockCode
+type CodeBlock struct {
+	*Block
+	Code     string
+	Language string
+	Caption  []*TextSpan
+	Format   FormatCode
+}
+
+// AsCode returns a typed view of BlockCode or nil
+func (b *Block) AsCode() *CodeBlock {
+	if b.Type != BlockCode {
+		return nil
+	}
+	res := &CodeBlock{Block: b, Code: b.Code, Language: b.CodeLanguage, Caption: b.GetCaption()}
+	b.decodeFormat(&res.Format)
+	return res
+}
+
+// ImageBlock is a view of BlockImage
+type ImageBlock struct {
+	*Block
+	// URL is an url of the image that is always accessible
+	// (see Block.ImageURL)
+	URL     string
+	Caption []*TextSpan
+	Format  FormatImage
+}
+
+// AsImage returns a typed view of BlockImage or nil
+func (b *Block) AsImage() *ImageBlock {
+	if b.Type != BlockImage {
+		return nil
+	}
+	res := &ImageBlock{Block: b, URL: b.ImageURL, Caption: b.GetCaption()}
+	b.decodeFormat(&res.Format)
+	return res
+}
+
+// BookmarkBlock is a view of BlockBookmark
+type BookmarkBlock struct {
+	*Block
+	URL         string
+	Title       string
+	Description string
+	Caption     []*TextSpan
+	Format      FormatBookmark
+}
+
+// AsBookmark returns a typed view of BlockBookmark or nil
+func (b *Block) AsBookmark() *BookmarkBlock {
+	if b.Type != BlockBookmark {
+		return nil
+	}
+	res := &BookmarkBlock{
+		Block:       b,
+		URL:         b.Link,
+		Title:       b.Title,
+		Description: b.Description,
+		Caption:     b.GetCaption(),
+	}
+	b.decodeFormat(&res.Format)
+	return res
+}
+
+// EmbedBlock is a view of embeds: BlockEmbed, BlockVideo, BlockMaps,
+// BlockFigma, BlockCodepen, BlockGist, BlockTweet, BlockAudio and BlockPDF
+type EmbedBlock struct {
+	*Block
+	// URL is the url of embedded content
+	URL     string
+	Caption []*TextSpan
+	// Format has values common to formats of embeds
+	Format FormatEmbed
+}
+
+// AsEmbed returns a typed view of an embed or nil
+func (b *Block) AsEmbed() *EmbedBlock {
+	switch b.Type {
+	case BlockEmbed, BlockVideo, BlockMaps, BlockFigma, BlockCodepen, BlockGist, BlockTweet, BlockAudio, BlockPDF:
+	default:
+		return nil
+	}
+	res := &EmbedBlock{Block: b, URL: b.Source, Caption: b.GetCaption()}
+	b.decodeFormat(&res.Format)
+	if res.Format.DisplaySource != "" {
+		res.URL = res.Format.DisplaySource
+	}
+	return res
+}
+
+// FileBlock is a view of BlockFile
+type FileBlock struct {
+	*Block
+	URL  string
+	Name string
+	// Size is e.g. "1.2MB"
+	Size    string
+	Caption []*TextSpan
+}
+
+// AsFile returns a typed view of BlockFile or nil
+func (b *Block) AsFile() *FileBlock {
+	if b.Type != BlockFile {
+		return nil
+	}
+	res := &FileBlock{
+		Block:   b,
+		URL:     b.Source,
+		Name:    TextSpansToString(b.InlineContent),
+		Size:    b.FileSize,
+		Caption: b.GetCaption(),
+	}
+	return res
+}
+
+// ColumnBlock is a view of BlockColumn
+type ColumnBlock struct {
+	*Block
+	// Ratio is e.g. 0.5 for a half-sized column. It's 0 if not known
+	Ratio float64
+}
+
+// AsColumn returns a typed view of BlockColumn or nil
+func (b *Block) AsColumn() *ColumnBlock {
+	if b.Type != BlockColumn {
+		return nil
+	}
+	res := &ColumnBlock{Block: b}
+	var format FormatColumn
+	if b.decodeFormat(&format) {
+		res.Ratio = format.ColumnRatio
+	}
+	return res
+}
+
+// PageBlock is a view of BlockPage and BlockCollectionViewPage
+type PageBlock struct {
+	*Block
+	Title  string
+	Format FormatPage
+}
+
+// AsPage returns a typed view of a page block or nil
+func (b *Block) AsPage() *PageBlock {
+	if !isPageBlock(b) {
+		return nil
+	}
+	res := &PageBlock{Block: b, Title: b.Title}
+	b.decodeFormat(&res.Format)
+	return res
+}
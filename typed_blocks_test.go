@@ -0,0 +1,44 @@
+package notionapi
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTypedBlocks(t *testing.T) {
+	img := &Block{
+		Type:     BlockImage,
+		ImageURL: "https://example.com/a.png",
+		RawJSON: map[string]interface{}{
+			"format": map[string]interface{}{"block_width": 320.0},
+		},
+	}
+	assert.Nil(t, img.AsCode())
+	assert.Equal(t, "https://example.com/a.png", img.AsImage().URL)
+	assert.Equal(t, 320.0, img.AsImage().Format.BlockWidth)
+
+	code := &Block{
+		Type:         BlockCode,
+		Code:         "x := 1",
+		CodeLanguage: "Go",
+		RawJSON: map[string]interface{}{
+			// unexpected values don't panic
+			"format": map[string]interface{}{"code_wrap": "yes"},
+		},
+	}
+	assert.Equal(t, "Go", code.AsCode().Language)
+	assert.False(t, code.AsCode().Format.CodeWrap)
+
+	col := &Block{Type: BlockColumn, RawJSON: map[string]interface{}{
+		"format": map[string]interface{}{"column_ratio": 0.25},
+	}}
+	assert.Equal(t, 0.25, col.AsColumn().Ratio)
+	assert.Nil(t, col.AsHeading())
+
+	h := &Block{Type: BlockSubHeader, RawJSON: map[string]interface{}{
+		"format": map[string]interface{}{"toggleable": true},
+	}}
+	assert.Equal(t, 2, h.AsHeading().Level)
+	assert.True(t, h.AsHeading().Toggleable)
+}
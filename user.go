@@ -1,5 +1,10 @@
 package notionapi
 
+import (
+	"errors"
+	"sync"
+)
+
 // User represents a Notion user
 type User struct {
 	Email                      string `json:"email"`
@@ -16,3 +21,131 @@ type User struct {
 
 	RawJSON map[string]interface{} `json:"-"`
 }
+
+// UserDirectory is a directory of users that can be shared by code
+// rendering many pages, e.g. to resolve user mentions. It's safe for
+// concurrent use
+type UserDirectory struct {
+	mu    sync.RWMutex
+	users map[string]*User
+}
+
+// NewUserDirectory returns an empty UserDirectory
+func NewUserDirectory() *UserDirectory {
+	return &UserDirectory{
+		users: map[string]*User{},
+	}
+}
+
+// Add adds a user to the directory
+func (d *UserDirectory) Add(user *User) {
+	if user == nil || user.ID == "" {
+		return
+	}
+	d.mu.Lock()
+	d.users[ToNoDashID(user.ID)] = user
+	d.mu.Unlock()
+}
+
+// AddPage adds users loaded with a page
+func (d *UserDirectory) AddPage(page *Page) {
+	for _, r := range page.UserRecords {
+		d.Add(r.User)
+	}
+	for _, u := range page.idToUser {
+		d.Add(u)
+	}
+}
+
+// AddCurrentUser adds the user the client is logged in as
+func (d *UserDirectory) AddCurrentUser(c *Client) error {
+	user, err := c.GetMe()
+	if err != nil {
+		return err
+	}
+	d.Add(user)
+	return nil
+}
+
+// UserByID returns a user with a given id or nil if not in the directory
+func (d *UserDirectory) UserByID(id string) *User {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return d.users[ToNoDashID(id)]
+}
+
+// NameByID returns full name of a user with a given id. Returns the id
+// if the user is not in the directory
+func (d *UserDirectory) NameByID(id string) string {
+	u := d.UserByID(id)
+	if u == nil {
+		return id
+	}
+	return makeUserName(u)
+}
+
+// Len returns number of users in the directory
+func (d *UserDirectory) Len() int {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return len(d.users)
+}
+
+// Name returns full name of the user
+func (u *User) Name() string {
+	return makeUserName(u)
+}
+
+// GetUsers returns users with given ids. Users that can't be
+// retrieved (e.g. we don't have access to them) are skipped
+func (c *Client) GetUsers(ids []string) ([]*User, error) {
+	var records []RecordRequest
+	for _, id := range ids {
+		records = append(records, RecordRequest{Table: TableUser, ID: ToDashID(id)})
+	}
+	if len(records) == 0 {
+		return nil, nil
+	}
+	rsp, err := c.GetRecordValues(records)
+	if err != nil {
+		return nil, err
+	}
+	var res []*User
+	for _, r := range rsp.Results {
+		if r.User != nil {
+			res = append(res, r.User)
+		}
+	}
+	return res, nil
+}
+
+// GetMe returns the user the client is logged in as (with AuthToken)
+func (c *Client) GetMe() (*User, error) {
+	rsp, err := c.LoadUserContent()
+	if err != nil {
+		return nil, err
+	}
+	if rsp.User == nil {
+		return nil, errors.New("couldn't get current user. Is AuthToken set?")
+	}
+	return rsp.User, nil
+}
+
+// AddUsers adds users with given ids that are not yet in the directory,
+// using Client.GetUsers
+func (d *UserDirectory) AddUsers(c *Client, ids []string) error {
+	var missing []string
+	for _, id := range ids {
+		if d.UserByID(id) == nil {
+			missing = append(missing, id)
+		}
+	}
+	users, err := c.GetUsers(missing)
+	if err != nil {
+		return err
+	}
+	for _, u := range users {
+		d.Add(u)
+	}
+	return nil
+}
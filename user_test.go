@@ -0,0 +1,71 @@
+package notionapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUserDirectory(t *testing.T) {
+	d := NewUserDirectory()
+	p := &Page{
+		idToUser: map[string]*User{},
+	}
+	p.idToUser["u1"] = &User{ID: "6682351e-44bb-4f9c-a0e1-49b703265bdb", GivenName: "Jane", FamilyName: "Doe"}
+	d.AddPage(p)
+	d.Add(&User{ID: "94167af6-5670-4327-9811-dc923edd1f04", GivenName: "John", FamilyName: "Smith"})
+	assert.Equal(t, 2, d.Len())
+	assert.Equal(t, "Jane Doe", d.NameByID("6682351e44bb4f9ca0e149b703265bdb"))
+	assert.Equal(t, "John Smith", d.NameByID("94167af6-5670-4327-9811-dc923edd1f04"))
+	assert.Equal(t, "unknown", d.NameByID("unknown"))
+
+	done := make(chan bool)
+	for i := 0; i < 4; i++ {
+		go func() {
+			d.Add(&User{ID: "6682351e-44bb-4f9c-a0e1-49b703265bdb", GivenName: "Jane"})
+			_ = d.NameByID("6682351e44bb4f9ca0e149b703265bdb")
+			done <- true
+		}()
+	}
+	for i := 0; i < 4; i++ {
+		<-done
+	}
+}
+
+// pretends to be a server that knows only one user
+func getUserRecordValues(req *http.Request, d []byte) interface{} {
+	var rr getRecordValuesRequest
+	_ = json.Unmarshal(d, &rr)
+	var results []interface{}
+	for _, r := range rr.Requests {
+		if r.ID != "6682351e-44bb-4f9c-a0e1-49b703265bdb" {
+			results = append(results, map[string]interface{}{"role": "none"})
+			continue
+		}
+		results = append(results, map[string]interface{}{
+			"role": "reader",
+			"value": map[string]interface{}{
+				"id":          r.ID,
+				"email":       "jane@example.com",
+				"given_name":  "Jane",
+				"family_name": "Doe",
+			},
+		})
+	}
+	return map[string]interface{}{"results": results}
+}
+
+func TestGetUsers(t *testing.T) {
+	c, _ := newFakeClient(map[string]fakeHandler{"/api/v3/getRecordValues": getUserRecordValues})
+	users, err := c.GetUsers([]string{"6682351e44bb4f9ca0e149b703265bdb", "94167af6567043279811dc923edd1f04"})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(users))
+	assert.Equal(t, "Jane Doe", users[0].Name())
+	assert.Equal(t, "jane@example.com", users[0].Email)
+
+	d := NewUserDirectory()
+	assert.NoError(t, d.AddUsers(c, []string{"6682351e44bb4f9ca0e149b703265bdb"}))
+	assert.Equal(t, "Jane Doe", d.NameByID("6682351e44bb4f9ca0e149b703265bdb"))
+}
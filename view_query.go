@@ -0,0 +1,356 @@
+package notionapi
+
+import (
+	"encoding/json"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Query2Filter is a filter in the newer format of collection view queries
+// (query2). It's either a group of filters (Filters) or a filter of
+// a single property
+type Query2Filter struct {
+	// "and" or "or" for groups
+	Operator string          `json:"operator"`
+	Filters  []*Query2Filter `json:"filters"`
+
+	Property string `json:"property"`
+	Filter   *struct {
+		// e.g. "string_contains", "enum_is"
+		Operator string `json:"operator"`
+		Value    *struct {
+			Type  string      `json:"type"`
+			Value interface{} `json:"value"`
+		} `json:"value"`
+	} `json:"filter"`
+}
+
+// Query2 is the newer format of collection view query, stored as query2
+type Query2 struct {
+	Filter *Query2Filter `json:"filter"`
+	Sort   []*QuerySort  `json:"sort"`
+}
+
+// GetQuery2 returns query2 of the collection view or nil if it doesn't have it
+func (cv *CollectionView) GetQuery2() *Query2 {
+	v, ok := cv.RawJSON["query2"]
+	if !ok || v == nil {
+		return nil
+	}
+	d, err := json.Marshal(v)
+	if err != nil {
+		return nil
+	}
+	var res Query2
+	if err = json.Unmarshal(d, &res); err != nil {
+		return nil
+	}
+	return &res
+}
+
+// propertyString returns value of a property of a row as a string
+func propertyString(row *Block, schema *ColumnSchema, propID string) string {
+	if schema != nil {
+		switch schema.Type {
+		case ColumnTypeCreatedTime:
+			return strconv.FormatInt(row.CreatedTime, 10)
+		case ColumnTypeLastEditedTime:
+			return strconv.FormatInt(row.LastEditedTime, 10)
+		case ColumnTypeCreatedBy:
+			return row.CreatedBy
+		case ColumnTypeLastEditedBy:
+			return row.LastEditedBy
+		}
+	}
+	spans := row.GetProperty(propID)
+	if d := TextSpansDate(spans); d != nil {
+		return d.StartDate
+	}
+	if schema != nil && (schema.Type == ColumnTypeRelation || schema.Type == ColumnTypePerson) {
+		var ids []string
+		for _, ts := range spans {
+			for _, attr := range ts.Attrs {
+				switch AttrGetType(attr) {
+				case AttrPage, AttrUser:
+					ids = append(ids, attr[1])
+				}
+			}
+		}
+		return strings.Join(ids, ",")
+	}
+	return TextSpansToString(spans)
+}
+
+func filterValueString(v interface{}) string {
+	switch vt := v.(type) {
+	case string:
+		return vt
+	case bool:
+		if vt {
+			return "Yes"
+		}
+		return "No"
+	case float64:
+		return strconv.FormatFloat(vt, 'f', -1, 64)
+	case map[string]interface{}:
+		if vt["type"] == "relative" {
+			s, _ := vt["value"].(string)
+			return relativeDate(s, time.Now())
+		}
+		// e.g. date {"type": "date", "start_date": "2020-01-01"} or
+		// person/relation {"table": "notion_user", "id": "..."}
+		for _, k := range []string{"start_date", "id", "value"} {
+			if s, ok := vt[k].(string); ok {
+				return s
+			}
+		}
+	}
+	return ""
+}
+
+// relativeDate resolves a relative date filter value (e.g. "today",
+// "one_week_ago") to a date in 2006-01-02 format. Returns "" for
+// unknown values, which matches all rows
+func relativeDate(s string, now time.Time) string {
+	var t time.Time
+	switch s {
+	case "today":
+		t = now
+	case "tomorrow":
+		t = now.AddDate(0, 0, 1)
+	case "yesterday":
+		t = now.AddDate(0, 0, -1)
+	case "one_week_ago":
+		t = now.AddDate(0, 0, -7)
+	case "one_week_from_now":
+		t = now.AddDate(0, 0, 7)
+	case "one_month_ago":
+		t = now.AddDate(0, -1, 0)
+	case "one_month_from_now":
+		t = now.AddDate(0, 1, 0)
+	default:
+		return ""
+	}
+	return t.Format("2006-01-02")
+}
+
+func splitValues(s string) []string {
+	var res []string
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			res = append(res, v)
+		}
+	}
+	return res
+}
+
+func containsFold(a []string, s string) bool {
+	for _, v := range a {
+		if strings.EqualFold(v, s) || ToNoDashID(v) != "" && ToNoDashID(v) == ToNoDashID(s) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchFilter returns true if val matches the filter. Unknown operators
+// and date and number filters without a value match everything so that
+// we don't hide rows we shouldn't
+func matchFilter(operator string, val string, filterVal string) bool {
+	if filterVal == "" && (strings.HasPrefix(operator, "date_") || strings.HasPrefix(operator, "number_")) {
+		return true
+	}
+	lval := strings.ToLower(val)
+	lfilter := strings.ToLower(filterVal)
+	switch operator {
+	case "is_empty":
+		return val == ""
+	case "is_not_empty":
+		return val != ""
+	case "string_is", "enum_is", "date_is":
+		return lval == lfilter
+	case "string_is_not", "enum_is_not":
+		return lval != lfilter
+	case "string_contains":
+		return strings.Contains(lval, lfilter)
+	case "string_does_not_contain":
+		return !strings.Contains(lval, lfilter)
+	case "string_starts_with":
+		return strings.HasPrefix(lval, lfilter)
+	case "string_ends_with":
+		return strings.HasSuffix(lval, lfilter)
+	case "enum_contains", "relation_contains", "person_contains":
+		return containsFold(splitValues(val), filterVal)
+	case "enum_does_not_contain", "relation_does_not_contain", "person_does_not_contain":
+		return !containsFold(splitValues(val), filterVal)
+	case "checkbox_is":
+		return (val == "Yes") == (filterVal == "Yes")
+	case "checkbox_is_not":
+		return (val == "Yes") != (filterVal == "Yes")
+	case "date_is_before":
+		return val != "" && val < filterVal
+	case "date_is_after":
+		return val != "" && val > filterVal
+	case "date_is_on_or_before":
+		return val != "" && val <= filterVal
+	case "date_is_on_or_after":
+		return val != "" && val >= filterVal
+	}
+	if strings.HasPrefix(operator, "number_") {
+		v, err1 := strconv.ParseFloat(val, 64)
+		f, err2 := strconv.ParseFloat(filterVal, 64)
+		if err1 != nil || err2 != nil {
+			return false
+		}
+		switch operator {
+		case "number_equals":
+			return v == f
+		case "number_does_not_equal":
+			return v != f
+		case "number_greater_than":
+			return v > f
+		case "number_less_than":
+			return v < f
+		case "number_greater_than_or_equal_to":
+			return v >= f
+		case "number_less_than_or_equal_to":
+			return v <= f
+		}
+	}
+	return true
+}
+
+func (t *TableView) matchQuery2Filter(row *Block, f *Query2Filter) bool {
+	if f == nil {
+		return true
+	}
+	if f.Property == "" {
+		if len(f.Filters) == 0 {
+			return true
+		}
+		isOr := f.Operator == "or"
+		for _, sub := range f.Filters {
+			m := t.matchQuery2Filter(row, sub)
+			if isOr && m {
+				return true
+			}
+			if !isOr && !m {
+				return false
+			}
+		}
+		return !isOr
+	}
+	if f.Filter == nil {
+		return true
+	}
+	val := propertyString(row, t.Collection.Schema[f.Property], f.Property)
+	filterVal := ""
+	if v := f.Filter.Value; v != nil {
+		if v.Type == "relative" {
+			s, _ := v.Value.(string)
+			filterVal = relativeDate(s, time.Now())
+		} else {
+			filterVal = filterValueString(v.Value)
+		}
+	}
+	return matchFilter(f.Filter.Operator, val, filterVal)
+}
+
+func (t *TableView) matchLegacyFilter(row *Block, q *Query) bool {
+	if len(q.Filter) == 0 {
+		return true
+	}
+	isOr := q.FilterOperator == "or"
+	for _, f := range q.Filter {
+		val := propertyString(row, t.Collection.Schema[f.Property], f.Property)
+		m := matchFilter(f.Comparator, val, f.Value)
+		if isOr && m {
+			return true
+		}
+		if !isOr && !m {
+			return false
+		}
+	}
+	return !isOr
+}
+
+// compareRows compares values of a property of 2 rows.
+// Empty values are always last
+func compareRows(a, b *Block, schema *ColumnSchema, propID string) int {
+	va := propertyString(a, schema, propID)
+	vb := propertyString(b, schema, propID)
+	if va == "" || vb == "" {
+		switch {
+		case va == vb:
+			return 0
+		case va == "":
+			return 1
+		}
+		return -1
+	}
+	fa, errA := strconv.ParseFloat(va, 64)
+	fb, errB := strconv.ParseFloat(vb, 64)
+	if errA == nil && errB == nil {
+		switch {
+		case fa < fb:
+			return -1
+		case fa > fb:
+			return 1
+		}
+		return 0
+	}
+	return strings.Compare(strings.ToLower(va), strings.ToLower(vb))
+}
+
+func (t *TableView) sortRows(sorts []*QuerySort) {
+	if len(sorts) == 0 {
+		return
+	}
+	sort.SliceStable(t.Rows, func(i, j int) bool {
+		for _, s := range sorts {
+			c := compareRows(t.Rows[i].Page, t.Rows[j].Page, t.Collection.Schema[s.Property], s.Property)
+			if c == 0 {
+				continue
+			}
+			if s.Direction == "descending" {
+				// empty values are still last
+				if propertyString(t.Rows[i].Page, t.Collection.Schema[s.Property], s.Property) == "" {
+					return false
+				}
+				if propertyString(t.Rows[j].Page, t.Collection.Schema[s.Property], s.Property) == "" {
+					return true
+				}
+				return c > 0
+			}
+			return c < 0
+		}
+		return false
+	})
+}
+
+// applyViewQuery filters and sorts rows according to the query of
+// the collection view, so that they're the same as shown by Notion.
+// The server doesn't always apply them for us (e.g. for query2)
+func (t *TableView) applyViewQuery() {
+	cv := t.CollectionView
+	q2 := cv.GetQuery2()
+	q := cv.Query
+	var rows []*TableRow
+	for _, tr := range t.Rows {
+		if q != nil && !t.matchLegacyFilter(tr.Page, q) {
+			continue
+		}
+		if q2 != nil && !t.matchQuery2Filter(tr.Page, q2.Filter) {
+			continue
+		}
+		rows = append(rows, tr)
+	}
+	t.Rows = rows
+	if q2 != nil && len(q2.Sort) > 0 {
+		t.sortRows(q2.Sort)
+	} else if q != nil {
+		t.sortRows(q.Sort)
+	}
+}
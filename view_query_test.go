@@ -0,0 +1,68 @@
+package notionapi
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApplyViewQuery(t *testing.T) {
+	schema := map[string]*ColumnSchema{
+		"title": {Name: "Name", Type: ColumnTypeTitle},
+		"num":   {Name: "Points", Type: ColumnTypeNumber},
+		"stat":  {Name: "Status", Type: ColumnTypeSelect},
+	}
+	query2 := map[string]interface{}{
+		"filter": map[string]interface{}{
+			"operator": "and",
+			"filters": []interface{}{
+				map[string]interface{}{
+					"property": "stat",
+					"filter": map[string]interface{}{
+						"operator": "enum_is_not",
+						"value":    map[string]interface{}{"type": "exact", "value": "Done"},
+					},
+				},
+			},
+		},
+		"sort": []interface{}{
+			map[string]interface{}{"property": "num", "direction": "descending"},
+		},
+	}
+	tv := &TableView{
+		CollectionView: &CollectionView{RawJSON: map[string]interface{}{"query2": query2}},
+		Collection:     &Collection{Schema: schema},
+	}
+	rows := [][]string{{"a", "1", "Todo"}, {"b", "5", "Done"}, {"c", "", "Todo"}, {"d", "10", ""}}
+	for _, r := range rows {
+		props := map[string]interface{}{"title": textValue(r[0])}
+		if r[1] != "" {
+			props["num"] = textValue(r[1])
+		}
+		if r[2] != "" {
+			props["stat"] = textValue(r[2])
+		}
+		tv.Rows = append(tv.Rows, &TableRow{Page: &Block{ID: r[0], Properties: props}})
+	}
+	tv.applyViewQuery()
+	var ids []string
+	for _, tr := range tv.Rows {
+		ids = append(ids, tr.Page.ID)
+	}
+	assert.Equal(t, []string{"d", "a", "c"}, ids)
+
+	assert.True(t, matchFilter("string_contains", "Hello World", "world"))
+	assert.False(t, matchFilter("number_greater_than", "3", "5"))
+	assert.True(t, matchFilter("enum_contains", "a,b", "b"))
+	assert.True(t, matchFilter("unknown_operator", "", "x"))
+	assert.True(t, matchFilter("number_greater_than", "3", ""))
+	assert.True(t, matchFilter("date_is_before", "2020-01-01", ""))
+
+	now := time.Date(2020, 3, 31, 12, 0, 0, 0, time.UTC)
+	assert.Equal(t, "2020-03-31", relativeDate("today", now))
+	assert.Equal(t, "2020-03-24", relativeDate("one_week_ago", now))
+	assert.Equal(t, "", relativeDate("some_day", now))
+	today := time.Now().Format("2006-01-02")
+	assert.Equal(t, today, filterValueString(map[string]interface{}{"type": "relative", "value": "today"}))
+}
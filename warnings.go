@@ -0,0 +1,77 @@
+package notionapi
+
+import "fmt"
+
+const (
+	// WarningDropped means a block was not rendered at all
+	WarningDropped = "dropped"
+	// WarningLossy means a block was rendered but lost some information
+	// e.g. a video rendered as a link
+	WarningLossy = "lossy"
+)
+
+const (
+	// FormatMarkdown is ConversionWarning.Format of tomarkdown
+	FormatMarkdown = "markdown"
+	// FormatPlainText is ConversionWarning.Format of Page.PlainText
+	FormatPlainText = "plaintext"
+)
+
+// ConversionWarning describes a block that a converter couldn't fully
+// represent in its output format
+type ConversionWarning struct {
+	// Format is FormatMarkdown etc.
+	Format string `json:"format"`
+	// Kind is WarningDropped or WarningLossy
+	Kind      string `json:"kind"`
+	BlockID   string `json:"block_id"`
+	BlockType string `json:"block_type"`
+	// Message explains what was lost
+	Message string `json:"message"`
+}
+
+func (w *ConversionWarning) String() string {
+	return fmt.Sprintf("%s: %s block '%s' (%s): %s", w.Format, w.Kind, w.BlockID, w.BlockType, w.Message)
+}
+
+// returns a reason why text of a block is not in PlainText or ""
+func plainTextDropReason(block *Block) string {
+	switch block.Type {
+	case BlockImage, BlockVideo, BlockAudio, BlockFile, BlockPDF:
+		return "media has no text"
+	case BlockEmbed, BlockGist, BlockMaps, BlockCodepen, BlockTweet,
+		BlockFigma, BlockDrive, BlockDropbox, BlockBookmark:
+		return "embedded content has no text"
+	case BlockEquation:
+		return "equations are not readable as text"
+	case BlockCollectionView, BlockCollectionViewPage:
+		return "databases are not included"
+	}
+	return ""
+}
+
+// PlainTextWarnings returns blocks of the page that are not in PlainText.
+// Sub-pages are not reported because their titles are included
+func (p *Page) PlainTextWarnings() []*ConversionWarning {
+	var res []*ConversionWarning
+	root := p.Root()
+	Walk([]*Block{root}, &Visitor{
+		Enter: func(block *Block, depth int) bool {
+			if block == root {
+				return true
+			}
+			if reason := plainTextDropReason(block); reason != "" {
+				w := &ConversionWarning{
+					Format:    FormatPlainText,
+					Kind:      WarningDropped,
+					BlockID:   block.ID,
+					BlockType: block.Type,
+					Message:   reason,
+				}
+				res = append(res, w)
+			}
+			return !isPageBlock(block)
+		},
+	})
+	return res
+}
@@ -0,0 +1,269 @@
+package notionapi
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// ChangeEvent describes a change detected by Watcher
+type ChangeEvent struct {
+	// PageID is id of the changed page (in dash format)
+	PageID string
+	// SpaceID is set if the change was found in the activity log of
+	// a watched space
+	SpaceID string
+	// ActivityID is id of the activity, if the change was found in
+	// the activity log
+	ActivityID string
+	// Version and PrevVersion are versions of a watched page. Version is 0
+	// if the page was deleted or is no longer accessible
+	Version     int64
+	PrevVersion int64
+	// Time is when the change was detected
+	Time time.Time
+}
+
+// Watcher polls versions of pages and activity logs of spaces at
+// an interval and sends changes to Events, so that we can react to edits
+// without downloading everything again
+type Watcher struct {
+	Client *Client
+	// PageIDs are pages whose versions we check
+	PageIDs []string
+	// SpaceIDs are spaces whose activity logs we check
+	SpaceIDs []string
+	// Interval is time between polls
+	Interval time.Duration
+	// Store, if set, persists versions of pages and seen activities so
+	// that after a restart we report changes made while we weren't
+	// running. Without it, the first poll only records the state
+	Store VersionStore
+
+	// Events receives changes. It's closed after Stop
+	Events chan *ChangeEvent
+	// Errors receives errors of polling. Errors are dropped if nobody
+	// reads them
+	Errors chan error
+
+	// maps page id to its last known version
+	versions map[string]int64
+	// maps space id to ids of activities we've seen
+	seenActivities map[string]map[string]bool
+	didLoadState   bool
+	stop           chan struct{}
+	wg             sync.WaitGroup
+}
+
+// number of most recent activities Watcher checks in a space
+const watcherActivityLimit = 20
+
+// NewWatcher returns a Watcher of pages. Use Watcher.SpaceIDs to also
+// watch spaces. Call Start to start watching
+func NewWatcher(client *Client, pageIDs []string, interval time.Duration) *Watcher {
+	return &Watcher{
+		Client:   client,
+		PageIDs:  pageIDs,
+		Interval: interval,
+		Events:   make(chan *ChangeEvent, 64),
+		Errors:   make(chan error, 1),
+	}
+}
+
+// Poll checks for changes once and returns them. The first poll only
+// records current versions and returns no changes
+func (w *Watcher) Poll() ([]*ChangeEvent, error) {
+	if err := w.loadState(); err != nil {
+		return nil, err
+	}
+	var res []*ChangeEvent
+	changes, err := w.pollPages()
+	if err != nil {
+		return nil, err
+	}
+	res = append(res, changes...)
+	for _, spaceID := range w.SpaceIDs {
+		changes, err = w.pollSpace(spaceID)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, changes...)
+	}
+	if err = w.saveState(); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// loadState loads state from Store before the first poll
+func (w *Watcher) loadState() error {
+	if w.Store == nil || w.didLoadState {
+		return nil
+	}
+	state, err := w.Store.Load()
+	if err != nil {
+		return err
+	}
+	w.didLoadState = true
+	if state == nil {
+		return nil
+	}
+	w.versions = map[string]int64{}
+	for id, ver := range state.PageVersions {
+		w.versions[ToDashID(id)] = ver
+	}
+	w.seenActivities = map[string]map[string]bool{}
+	for spaceID, ids := range state.SeenActivities {
+		seen := map[string]bool{}
+		for _, id := range ids {
+			seen[id] = true
+		}
+		w.seenActivities[spaceID] = seen
+	}
+	return nil
+}
+
+func (w *Watcher) saveState() error {
+	if w.Store == nil {
+		return nil
+	}
+	state := &WatcherState{
+		PageVersions:   w.versions,
+		SeenActivities: map[string][]string{},
+	}
+	for spaceID, seen := range w.seenActivities {
+		ids := []string{}
+		for id := range seen {
+			ids = append(ids, id)
+		}
+		sort.Strings(ids)
+		state.SeenActivities[spaceID] = ids
+	}
+	return w.Store.Save(state)
+}
+
+func (w *Watcher) pollPages() ([]*ChangeEvent, error) {
+	if len(w.PageIDs) == 0 {
+		return nil, nil
+	}
+	isFirst := w.versions == nil
+	if isFirst {
+		w.versions = map[string]int64{}
+	}
+	rsp, err := w.Client.GetBlockRecords(w.PageIDs)
+	if err != nil {
+		return nil, err
+	}
+	var res []*ChangeEvent
+	for i, rec := range rsp.Results {
+		if i >= len(w.PageIDs) {
+			break
+		}
+		id := ToDashID(w.PageIDs[i])
+		var ver int64
+		if rec.Block != nil {
+			ver = rec.Block.Version
+		}
+		prev, known := w.versions[id]
+		w.versions[id] = ver
+		if isFirst || !known || prev == ver {
+			continue
+		}
+		ev := &ChangeEvent{
+			PageID:      id,
+			Version:     ver,
+			PrevVersion: prev,
+			Time:        time.Now(),
+		}
+		res = append(res, ev)
+	}
+	return res, nil
+}
+
+func (w *Watcher) pollSpace(spaceID string) ([]*ChangeEvent, error) {
+	if w.seenActivities == nil {
+		w.seenActivities = map[string]map[string]bool{}
+	}
+	seen, ok := w.seenActivities[spaceID]
+	isFirst := !ok
+	rsp, err := w.Client.GetActivityLog(spaceID, "", watcherActivityLimit)
+	if err != nil {
+		return nil, err
+	}
+	// we only need to remember the most recent activities because
+	// older ones are not returned again
+	w.seenActivities[spaceID] = map[string]bool{}
+	for _, id := range rsp.ActivityIDs {
+		w.seenActivities[spaceID][id] = true
+	}
+	var res []*ChangeEvent
+	for _, id := range rsp.ActivityIDs {
+		if seen[id] {
+			continue
+		}
+		if isFirst {
+			continue
+		}
+		ev := &ChangeEvent{
+			SpaceID:    spaceID,
+			ActivityID: id,
+			Time:       time.Now(),
+		}
+		if rsp.RecordMap == nil {
+			res = append(res, ev)
+			continue
+		}
+		if r := rsp.RecordMap.Activities[id]; r != nil && r.Activity != nil {
+			ev.PageID = r.Activity.NavigableBlockID
+			if ev.PageID == "" {
+				ev.PageID = r.Activity.ParentID
+			}
+		}
+		res = append(res, ev)
+	}
+	return res, nil
+}
+
+// Start starts polling in a goroutine
+func (w *Watcher) Start() {
+	w.stop = make(chan struct{})
+	w.wg.Add(1)
+	go func() {
+		defer w.wg.Done()
+		ticker := time.NewTicker(w.Interval)
+		defer ticker.Stop()
+		for {
+			w.pollAndSend()
+			select {
+			case <-w.stop:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+func (w *Watcher) pollAndSend() {
+	changes, err := w.Poll()
+	if err != nil {
+		select {
+		case w.Errors <- err:
+		default:
+		}
+		return
+	}
+	for _, ev := range changes {
+		select {
+		case w.Events <- ev:
+		case <-w.stop:
+			return
+		}
+	}
+}
+
+// Stop stops polling and closes Events
+func (w *Watcher) Stop() {
+	close(w.stop)
+	w.wg.Wait()
+	close(w.Events)
+}
@@ -0,0 +1,135 @@
+package notionapi
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// WatcherState is what Watcher remembers between polls
+type WatcherState struct {
+	// PageVersions maps id of a page (in dash format) to its last
+	// known version
+	PageVersions map[string]int64 `json:"page_versions"`
+	// SeenActivities maps id of a space to ids of its most recent
+	// activities we've seen
+	SeenActivities map[string][]string `json:"seen_activities"`
+}
+
+// VersionStore persists state of a Watcher so that a restarted watcher
+// neither replays changes it already reported nor misses changes made
+// while it wasn't running
+type VersionStore interface {
+	// Load returns saved state or nil if nothing was saved yet
+	Load() (*WatcherState, error)
+	// Save saves state after each poll
+	Save(state *WatcherState) error
+}
+
+// FileVersionStore is a VersionStore that keeps state in a JSON file
+type FileVersionStore struct {
+	Path string
+}
+
+// NewFileVersionStore returns a VersionStore that keeps state in a file
+func NewFileVersionStore(path string) *FileVersionStore {
+	return &FileVersionStore{
+		Path: path,
+	}
+}
+
+// Load reads state from the file. Returns nil if the file doesn't exist
+func (s *FileVersionStore) Load() (*WatcherState, error) {
+	d, err := ioutil.ReadFile(s.Path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var state WatcherState
+	if err = json.Unmarshal(d, &state); err != nil {
+		return nil, err
+	}
+	return &state, nil
+}
+
+// Save writes state to the file. We write to a temporary file and rename
+// it so that a crash doesn't leave a partially written file
+func (s *FileVersionStore) Save(state *WatcherState) error {
+	d, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	f, err := ioutil.TempFile(filepath.Dir(s.Path), filepath.Base(s.Path)+".tmp")
+	if err != nil {
+		return err
+	}
+	tmpPath := f.Name()
+	_, err = f.Write(d)
+	if err2 := f.Close(); err == nil {
+		err = err2
+	}
+	if err == nil {
+		err = os.Rename(tmpPath, s.Path)
+	}
+	if err != nil {
+		_ = os.Remove(tmpPath)
+	}
+	return err
+}
+
+// RedisClient is a subset of a Redis client used by RedisVersionStore.
+// It's easy to implement with any Redis client e.g. go-redis:
+//
+//	func (c *myClient) Get(key string) (string, error) {
+//		s, err := c.rdb.Get(ctx, key).Result()
+//		if err == redis.Nil {
+//			return "", nil
+//		}
+//		return s, err
+//	}
+type RedisClient interface {
+	// Get returns a value of a key or "" if the key doesn't exist
+	Get(key string) (string, error)
+	Set(key string, value string) error
+}
+
+// RedisVersionStore is a VersionStore that keeps state as JSON
+// under a Redis key, which allows running the watcher on any machine
+type RedisVersionStore struct {
+	Client RedisClient
+	Key    string
+}
+
+// NewRedisVersionStore returns a VersionStore that keeps state in Redis
+// under key
+func NewRedisVersionStore(client RedisClient, key string) *RedisVersionStore {
+	return &RedisVersionStore{
+		Client: client,
+		Key:    key,
+	}
+}
+
+// Load reads state from Redis. Returns nil if the key doesn't exist
+func (s *RedisVersionStore) Load() (*WatcherState, error) {
+	v, err := s.Client.Get(s.Key)
+	if err != nil || v == "" {
+		return nil, err
+	}
+	var state WatcherState
+	if err = json.Unmarshal([]byte(v), &state); err != nil {
+		return nil, err
+	}
+	return &state, nil
+}
+
+// Save writes state to Redis
+func (s *RedisVersionStore) Save(state *WatcherState) error {
+	d, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	return s.Client.Set(s.Key, string(d))
+}
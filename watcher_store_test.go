@@ -0,0 +1,55 @@
+package notionapi
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type memoryRedisClient struct {
+	values map[string]string
+}
+
+func (c *memoryRedisClient) Get(key string) (string, error) {
+	return c.values[key], nil
+}
+
+func (c *memoryRedisClient) Set(key string, value string) error {
+	c.values[key] = value
+	return nil
+}
+
+func TestWatcherStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "watcher")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	stores := []VersionStore{
+		NewFileVersionStore(filepath.Join(dir, "state.json")),
+		NewRedisVersionStore(&memoryRedisClient{values: map[string]string{}}, "watcher"),
+	}
+	for _, store := range stores {
+		c, _ := newFakeClient(map[string]fakeHandler{"/api/v3/getRecordValues": (&versionsServer{}).getRecordValues})
+		w := NewWatcher(c, []string{"94167af6567043279811dc923edd1f04"}, time.Minute)
+		w.Store = store
+		changes, err := w.Poll()
+		assert.NoError(t, err)
+		assert.Equal(t, 0, len(changes))
+
+		// a restarted watcher reports the change made while it wasn't running
+		w = NewWatcher(c, []string{"94167af6567043279811dc923edd1f04"}, time.Minute)
+		w.Store = store
+		changes, err = w.Poll()
+		assert.NoError(t, err)
+		assert.Equal(t, 1, len(changes))
+		assert.Equal(t, int64(1), changes[0].PrevVersion)
+		assert.Equal(t, int64(2), changes[0].Version)
+
+		state, err := store.Load()
+		assert.NoError(t, err)
+		assert.Equal(t, int64(2), state.PageVersions["94167af6-5670-4327-9811-dc923edd1f04"])
+	}
+}
@@ -0,0 +1,48 @@
+package notionapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// pretends to be a server where a page gets a new version on every request
+type versionsServer struct {
+	version int64
+}
+
+func (s *versionsServer) getRecordValues(req *http.Request, d []byte) interface{} {
+	var rr getRecordValuesRequest
+	_ = json.Unmarshal(d, &rr)
+	s.version++
+	var results []interface{}
+	for _, r := range rr.Requests {
+		results = append(results, map[string]interface{}{
+			"role":  "reader",
+			"value": map[string]interface{}{"id": r.ID, "type": BlockPage, "version": s.version},
+		})
+	}
+	return map[string]interface{}{"results": results}
+}
+
+func TestWatcher(t *testing.T) {
+	c, _ := newFakeClient(map[string]fakeHandler{"/api/v3/getRecordValues": (&versionsServer{}).getRecordValues})
+	w := NewWatcher(c, []string{"94167af6567043279811dc923edd1f04"}, time.Millisecond)
+	changes, err := w.Poll()
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(changes))
+	changes, err = w.Poll()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(changes))
+	assert.Equal(t, "94167af6-5670-4327-9811-dc923edd1f04", changes[0].PageID)
+	assert.Equal(t, int64(1), changes[0].PrevVersion)
+	assert.Equal(t, int64(2), changes[0].Version)
+
+	w.Start()
+	ev := <-w.Events
+	assert.True(t, ev.Version > 2)
+	w.Stop()
+}